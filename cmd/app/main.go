@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,6 +15,8 @@ import (
 	"theb-backend/internal/config"
 	"theb-backend/internal/db"
 	"theb-backend/internal/logger"
+	"theb-backend/internal/router"
+	"theb-backend/internal/tracing"
 )
 
 // @title THEB API
@@ -37,19 +40,39 @@ import (
 // @description Type "Bearer" followed by a space and JWT token.
 
 func main() {
+	configPath := flag.String("config", "", "config directory, or environment file next to base.yaml (default ./config)")
+	flag.Parse()
+
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.Load(config.Options{Path: *configPath})
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if flag.NArg() > 0 {
+		if err := runCommand(cfg, flag.Args()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Initialize logger
-	logger.Init(cfg.Logging.Level, cfg.Logging.Format)
+	if err := logger.Init(cfg.Logging); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
 	logger.Info("Starting THEB Backend Server", map[string]interface{}{
 		"env":  cfg.App.Env,
 		"port": cfg.App.Port,
 	})
 
+	// Initialize tracing before any instrumented client is created
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, cfg.App)
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
 	// Initialize database connection
 	database, err := db.InitPostgres(cfg)
 	if err != nil {
@@ -106,6 +129,28 @@ func main() {
 		}
 	}()
 
+	// Start the admin server for metrics scraping on its own port
+	var adminSrv *http.Server
+	if cfg.Admin.Enabled {
+		adminSrv = &http.Server{
+			Addr:         fmt.Sprintf("%s:%d", cfg.App.Host, cfg.Admin.Port),
+			Handler:      router.NewAdmin(),
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+		}
+
+		go func() {
+			logger.Info("Admin server starting", map[string]interface{}{
+				"address": adminSrv.Addr,
+			})
+			if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("Admin server failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -113,6 +158,16 @@ func main() {
 
 	logger.Info("Server shutting down...", nil)
 
+	// Fail readiness and give load balancers time to stop routing here
+	// before the listener closes
+	application.Drain()
+	if cfg.Health.DrainDelay > 0 {
+		logger.Info("Draining before shutdown", map[string]interface{}{
+			"delay": cfg.Health.DrainDelay.String(),
+		})
+		time.Sleep(cfg.Health.DrainDelay)
+	}
+
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -123,16 +178,52 @@ func main() {
 		})
 	}
 
-	// Close database connections
-	sqlDB, _ := database.DB()
-	if sqlDB != nil {
-		sqlDB.Close()
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(ctx); err != nil {
+			logger.Error("Admin server forced to shutdown", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
 	}
 
-	// Close Redis connection
-	if redisClient != nil {
-		redisClient.Close()
+	// Stop background workers, WebSocket hubs and jobs, then close Redis and Postgres
+	if err := application.Shutdown(ctx); err != nil {
+		logger.Error("Failed to shut down cleanly", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	// Flush spans recorded during shutdown last
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("Failed to flush traces", map[string]interface{}{
+			"error": err.Error(),
+		})
 	}
 
 	logger.Info("Server exited gracefully", nil)
 }
+
+// runCommand runs a maintenance subcommand instead of the server:
+//
+//	config print [--redacted]   print the effective configuration
+func runCommand(cfg *config.Config, args []string) error {
+	if len(args) < 2 || args[0] != "config" || args[1] != "print" {
+		return fmt.Errorf("unknown command: %v", args)
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redact := flags.Bool("redacted", false, "mask secrets")
+	if err := flags.Parse(args[2:]); err != nil {
+		return err
+	}
+
+	if *redact {
+		cfg = cfg.Redacted()
+	}
+	out, err := cfg.YAML()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
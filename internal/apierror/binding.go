@@ -0,0 +1,100 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ruleMessages holds field messages by validation rule, with the rule's
+// parameter substituted for %s where the message uses it
+var (
+	ruleMessages = map[string]map[string]string{
+		"required": {LocaleEnglish: "This field is required", LocaleArabic: "هذا الحقل مطلوب"},
+		"oneof":    {LocaleEnglish: "Must be one of: %s", LocaleArabic: "يجب أن تكون إحدى القيم: %s"},
+		"min":      {LocaleEnglish: "Must be at least %s", LocaleArabic: "يجب ألا تقل عن %s"},
+		"gte":      {LocaleEnglish: "Must be at least %s", LocaleArabic: "يجب ألا تقل عن %s"},
+		"max":      {LocaleEnglish: "Must be at most %s", LocaleArabic: "يجب ألا تزيد عن %s"},
+		"lte":      {LocaleEnglish: "Must be at most %s", LocaleArabic: "يجب ألا تزيد عن %s"},
+		"gt":       {LocaleEnglish: "Must be greater than %s", LocaleArabic: "يجب أن تكون أكبر من %s"},
+		"lt":       {LocaleEnglish: "Must be less than %s", LocaleArabic: "يجب أن تكون أقل من %s"},
+		"len":      {LocaleEnglish: "Must have length %s", LocaleArabic: "يجب أن يكون الطول %s"},
+		"uuid":     {LocaleEnglish: "Must be a valid ID", LocaleArabic: "يجب أن يكون معرّفًا صالحًا"},
+		"email":    {LocaleEnglish: "Must be a valid email address", LocaleArabic: "يجب أن يكون بريدًا إلكترونيًا صالحًا"},
+		"url":      {LocaleEnglish: "Must be a valid URL", LocaleArabic: "يجب أن يكون رابطًا صالحًا"},
+		"type":     {LocaleEnglish: "Has the wrong type", LocaleArabic: "نوع القيمة غير صحيح"},
+	}
+	ruleMessagesMu sync.RWMutex
+)
+
+// defaultRuleMessage is used for rules without their own message
+var defaultRuleMessage = map[string]string{LocaleEnglish: "Is invalid", LocaleArabic: "القيمة غير صالحة"}
+
+// RegisterRule sets the field messages for a validation rule, e.g. a custom
+// validator tag. Use %s in a message for the rule's parameter.
+func RegisterRule(rule, en, ar string) {
+	ruleMessagesMu.Lock()
+	defer ruleMessagesMu.Unlock()
+	ruleMessages[rule] = map[string]string{LocaleEnglish: en, LocaleArabic: ar}
+}
+
+// LocalisedMessage returns the field error's message in locale
+func (f FieldError) LocalisedMessage(locale string) string {
+	if f.Message != "" {
+		return f.Message
+	}
+
+	ruleMessagesMu.RLock()
+	messages, ok := ruleMessages[f.Rule]
+	ruleMessagesMu.RUnlock()
+	if !ok {
+		messages = defaultRuleMessage
+	}
+
+	msg, ok := messages[locale]
+	if !ok {
+		msg = messages[LocaleEnglish]
+	}
+	if strings.Contains(msg, "%s") {
+		return fmt.Sprintf(msg, strings.ReplaceAll(f.Param, " ", ", "))
+	}
+	return msg
+}
+
+// FromBinding converts an error from gin's ShouldBind* into VALIDATION_FAILED with
+// a detail per rejected field, or BAD_REQUEST when the body cannot be decoded
+func FromBinding(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			details = append(details, FieldError{Field: fieldPath(fe), Rule: fe.Tag(), Param: fe.Param()})
+		}
+		return &Error{Code: CodeValidationFailed, Details: details, Cause: err}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &Error{Code: CodeValidationFailed, Details: []FieldError{Field(typeErr.Field, "type")}, Cause: err}
+	}
+
+	if errors.Is(err, io.EOF) {
+		return Wrap(CodeBadRequest, errors.New("request body is empty"))
+	}
+	return Wrap(CodeBadRequest, err)
+}
+
+// fieldPath returns the field's path without the top-level struct name,
+// e.g. "stops[0].lat" for "CreateRideRequest.stops[0].lat"
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		return rest
+	}
+	return fe.Field()
+}
@@ -0,0 +1,234 @@
+package apierror
+
+import "net/http"
+
+// Code is a stable, machine-readable error code. Clients drive their UI from
+// codes, so a published code is never renamed or reused for another meaning.
+type Code string
+
+// General codes
+const (
+	CodeBadRequest         Code = "BAD_REQUEST"
+	CodeValidationFailed   Code = "VALIDATION_FAILED"
+	CodeUnauthorized       Code = "UNAUTHORIZED"
+	CodeForbidden          Code = "FORBIDDEN"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeConflict           Code = "CONFLICT"
+	CodeRateLimited        Code = "RATE_LIMITED"
+	CodeInternal           Code = "INTERNAL_ERROR"
+	CodeServiceUnavailable Code = "SERVICE_UNAVAILABLE"
+)
+
+// Authentication codes
+const (
+	CodeAuthHeaderMissing Code = "AUTH_HEADER_MISSING"
+	CodeAuthHeaderInvalid Code = "AUTH_HEADER_INVALID"
+	CodeAuthTokenInvalid  Code = "AUTH_TOKEN_INVALID"
+	CodeAuthTokenExpired  Code = "AUTH_TOKEN_EXPIRED"
+)
+
+// Ride codes
+const (
+	CodeRideNotFound          Code = "RIDE_NOT_FOUND"
+	CodeRideNotCompleted      Code = "RIDE_NOT_COMPLETED"
+	CodeRideInvalidTransition Code = "RIDE_INVALID_TRANSITION"
+	CodeRidePickupOutOfArea   Code = "RIDE_PICKUP_OUT_OF_AREA"
+	CodeRideDropoffOutOfArea  Code = "RIDE_DROPOFF_OUT_OF_AREA"
+	CodeRideRestrictedArea    Code = "RIDE_RESTRICTED_AREA"
+	CodeRideNotParticipant    Code = "RIDE_NOT_PARTICIPANT"
+	CodeRideCaptainOnly       Code = "RIDE_CAPTAIN_ONLY"
+	CodeRideCaptainOffline    Code = "RIDE_CAPTAIN_OFFLINE"
+	CodeRideOfferedElsewhere  Code = "RIDE_OFFERED_ELSEWHERE"
+	CodeRideNoPendingOffer    Code = "RIDE_NO_PENDING_OFFER"
+)
+
+// Rating codes
+const (
+	CodeRatingInvalidValue     Code = "RATING_INVALID_VALUE"
+	CodeRatingInvalidTag       Code = "RATING_INVALID_TAG"
+	CodeRatingWindowClosed     Code = "RATING_WINDOW_CLOSED"
+	CodeRatingNotParticipant   Code = "RATING_NOT_PARTICIPANT"
+	CodeRatingAlreadySubmitted Code = "RATING_ALREADY_SUBMITTED"
+	CodeReviewCaseNotFound     Code = "REVIEW_CASE_NOT_FOUND"
+	CodeReviewCaseResolved     Code = "REVIEW_CASE_RESOLVED"
+)
+
+// Wallet and settlement codes
+const (
+	CodeAmountInvalid               Code = "AMOUNT_INVALID"
+	CodeWalletSelfTopUp             Code = "WALLET_SELF_TOP_UP"
+	CodeWalletInsufficientFunds     Code = "WALLET_INSUFFICIENT_FUNDS"
+	CodeWalletRefundExceedsFare     Code = "WALLET_REFUND_EXCEEDS_FARE"
+	CodeWalletPassengerNotFound     Code = "WALLET_PASSENGER_NOT_FOUND"
+	CodeSettlementBelowMinimum      Code = "SETTLEMENT_BELOW_MINIMUM"
+	CodeSettlementExceedsBalance    Code = "SETTLEMENT_EXCEEDS_BALANCE"
+	CodeSettlementExceedsDebt       Code = "SETTLEMENT_EXCEEDS_DEBT"
+	CodeSettlementRequestOpen       Code = "SETTLEMENT_REQUEST_OPEN"
+	CodeSettlementRequestNotFound   Code = "SETTLEMENT_REQUEST_NOT_FOUND"
+	CodeSettlementInvalidTransition Code = "SETTLEMENT_INVALID_TRANSITION"
+	CodeCaptainNotFound             Code = "CAPTAIN_NOT_FOUND"
+	CodeCaptainCommissionDebtLimit  Code = "CAPTAIN_COMMISSION_DEBT_LIMIT"
+	CodeCaptainOffline              Code = "CAPTAIN_OFFLINE"
+)
+
+// Payment codes
+const (
+	CodePaymentNotFound         Code = "PAYMENT_NOT_FOUND"
+	CodePaymentGatewayUnknown   Code = "PAYMENT_GATEWAY_UNKNOWN"
+	CodePaymentInvalidSignature Code = "PAYMENT_INVALID_SIGNATURE"
+	CodePaymentInvalidState     Code = "PAYMENT_INVALID_STATE"
+	CodePaymentMockDisabled     Code = "PAYMENT_MOCK_DISABLED"
+	CodePaymentCardDeclined     Code = "PAYMENT_CARD_DECLINED"
+	CodePaymentNotAuthorized    Code = "PAYMENT_NOT_AUTHORIZED"
+	CodePaymentRefundExceeds    Code = "PAYMENT_REFUND_EXCEEDS_AMOUNT"
+)
+
+// Notification codes
+const (
+	CodePushTokenInvalid     Code = "PUSH_TOKEN_INVALID"
+	CodeDeviceNotFound       Code = "DEVICE_NOT_FOUND"
+	CodeNotificationNotFound Code = "NOTIFICATION_NOT_FOUND"
+	CodeTemplateNotFound     Code = "TEMPLATE_NOT_FOUND"
+	CodeTemplateInvalid      Code = "TEMPLATE_INVALID"
+	CodeLocaleUnsupported    Code = "LOCALE_UNSUPPORTED"
+)
+
+// Settings codes
+const (
+	CodeSettingNotFound           Code = "SETTING_NOT_FOUND"
+	CodeSettingInvalidValue       Code = "SETTING_INVALID_VALUE"
+	CodeSettingScheduleInPast     Code = "SETTING_SCHEDULE_IN_PAST"
+	CodeSettingScheduleNotFound   Code = "SETTING_SCHEDULE_NOT_FOUND"
+	CodeSettingScheduleNotPending Code = "SETTING_SCHEDULE_NOT_PENDING"
+)
+
+// Zone codes
+const (
+	CodeZoneNotFound        Code = "ZONE_NOT_FOUND"
+	CodeZoneInvalidGeometry Code = "ZONE_INVALID_GEOMETRY"
+	CodeZoneNameTaken       Code = "ZONE_NAME_TAKEN"
+)
+
+// City codes
+const (
+	CodeCityNotFound             Code = "CITY_NOT_FOUND"
+	CodeCityCodeTaken            Code = "CITY_CODE_TAKEN"
+	CodeCityAccessDenied         Code = "CITY_ACCESS_DENIED"
+	CodeCitySettingNotOverridden Code = "CITY_SETTING_NOT_OVERRIDDEN"
+	CodeCityAdminNotFound        Code = "CITY_ADMIN_NOT_FOUND"
+)
+
+// definition is a code's HTTP status and its message in each supported locale
+type definition struct {
+	status   int
+	messages map[string]string
+}
+
+func def(status int, en, ar string) definition {
+	return definition{status: status, messages: map[string]string{LocaleEnglish: en, LocaleArabic: ar}}
+}
+
+// catalogue holds every code the API returns
+var catalogue = map[Code]definition{
+	CodeBadRequest:         def(http.StatusBadRequest, "The request is malformed", "الطلب غير صالح"),
+	CodeValidationFailed:   def(http.StatusBadRequest, "Some fields are invalid", "بعض الحقول غير صالحة"),
+	CodeUnauthorized:       def(http.StatusUnauthorized, "Authentication is required", "يجب تسجيل الدخول"),
+	CodeForbidden:          def(http.StatusForbidden, "You do not have permission to do this", "ليس لديك صلاحية للقيام بذلك"),
+	CodeNotFound:           def(http.StatusNotFound, "The resource was not found", "المورد غير موجود"),
+	CodeConflict:           def(http.StatusConflict, "The request conflicts with the current state", "الطلب يتعارض مع الحالة الحالية"),
+	CodeRateLimited:        def(http.StatusTooManyRequests, "Too many requests. Please try again later", "طلبات كثيرة. يرجى المحاولة لاحقًا"),
+	CodeInternal:           def(http.StatusInternalServerError, "Something went wrong. Please try again", "حدث خطأ ما. يرجى المحاولة مرة أخرى"),
+	CodeServiceUnavailable: def(http.StatusServiceUnavailable, "The service is temporarily unavailable", "الخدمة غير متاحة مؤقتًا"),
+
+	CodeAuthHeaderMissing: def(http.StatusUnauthorized, "Authorization header is required", "ترويسة التفويض مطلوبة"),
+	CodeAuthHeaderInvalid: def(http.StatusUnauthorized, "Authorization header must be a Bearer token", "يجب أن تكون ترويسة التفويض رمز Bearer"),
+	CodeAuthTokenInvalid:  def(http.StatusUnauthorized, "The access token is invalid", "رمز الدخول غير صالح"),
+	CodeAuthTokenExpired:  def(http.StatusUnauthorized, "The access token has expired", "انتهت صلاحية رمز الدخول"),
+
+	CodeRideNotFound:          def(http.StatusNotFound, "Ride not found", "الرحلة غير موجودة"),
+	CodeRideNotCompleted:      def(http.StatusConflict, "The ride is not completed", "الرحلة لم تكتمل بعد"),
+	CodeRideInvalidTransition: def(http.StatusConflict, "The ride cannot move to that status", "لا يمكن نقل الرحلة إلى هذه الحالة"),
+	CodeRidePickupOutOfArea:   def(http.StatusBadRequest, "THEB does not operate at this pickup location yet", "خدمة ذهب غير متوفرة في موقع الانطلاق هذا بعد"),
+	CodeRideDropoffOutOfArea:  def(http.StatusBadRequest, "The destination is outside the service area", "الوجهة خارج منطقة الخدمة"),
+	CodeRideRestrictedArea:    def(http.StatusBadRequest, "Rides cannot start or end in this area", "لا يمكن بدء الرحلات أو إنهاؤها في هذه المنطقة"),
+	CodeRideNotParticipant:    def(http.StatusForbidden, "Only the ride's passenger or captain can access it", "يمكن لراكب الرحلة أو كابتنها فقط الوصول إليها"),
+	CodeRideCaptainOnly:       def(http.StatusForbidden, "Only the ride's captain can make this change", "يمكن لكابتن الرحلة فقط إجراء هذا التغيير"),
+	CodeRideCaptainOffline:    def(http.StatusConflict, "Go online before accepting rides", "يرجى الاتصال قبل قبول الرحلات"),
+	CodeRideOfferedElsewhere:  def(http.StatusConflict, "The ride is offered to another captain", "الرحلة معروضة على كابتن آخر"),
+	CodeRideNoPendingOffer:    def(http.StatusConflict, "You have no pending offer for this ride", "لا يوجد لديك عرض قائم لهذه الرحلة"),
+
+	CodeRatingInvalidValue:     def(http.StatusBadRequest, "Rating must be between 1 and 5", "يجب أن يكون التقييم بين 1 و 5"),
+	CodeRatingInvalidTag:       def(http.StatusBadRequest, "Invalid feedback tag", "وسم الملاحظات غير صالح"),
+	CodeRatingWindowClosed:     def(http.StatusConflict, "The rating window has closed", "انتهت مهلة التقييم"),
+	CodeRatingNotParticipant:   def(http.StatusForbidden, "Only the ride's passenger or captain can rate it", "يمكن لراكب الرحلة أو كابتنها فقط تقييمها"),
+	CodeRatingAlreadySubmitted: def(http.StatusConflict, "You have already rated this ride", "لقد قمت بتقييم هذه الرحلة مسبقًا"),
+	CodeReviewCaseNotFound:     def(http.StatusNotFound, "Review case not found", "حالة المراجعة غير موجودة"),
+	CodeReviewCaseResolved:     def(http.StatusConflict, "Review case is already resolved", "تم حل حالة المراجعة مسبقًا"),
+
+	CodeAmountInvalid:               def(http.StatusBadRequest, "Amount must be positive", "يجب أن يكون المبلغ موجبًا"),
+	CodeWalletSelfTopUp:             def(http.StatusBadRequest, "Captains cannot top up their own wallet", "لا يمكن للكابتن شحن محفظته بنفسه"),
+	CodeWalletInsufficientFunds:     def(http.StatusConflict, "Insufficient funds", "الرصيد غير كافٍ"),
+	CodeWalletRefundExceedsFare:     def(http.StatusBadRequest, "Refund exceeds the ride fare", "المبلغ المسترد يتجاوز أجرة الرحلة"),
+	CodeWalletPassengerNotFound:     def(http.StatusNotFound, "Passenger not found", "الراكب غير موجود"),
+	CodeSettlementBelowMinimum:      def(http.StatusBadRequest, "Amount is below the minimum cash-out", "المبلغ أقل من الحد الأدنى للسحب"),
+	CodeSettlementExceedsBalance:    def(http.StatusBadRequest, "Amount exceeds available balance", "المبلغ يتجاوز الرصيد المتاح"),
+	CodeSettlementExceedsDebt:       def(http.StatusBadRequest, "Amount exceeds commission owed", "المبلغ يتجاوز العمولة المستحقة"),
+	CodeSettlementRequestOpen:       def(http.StatusConflict, "An open request of this type already exists", "يوجد طلب مفتوح من هذا النوع مسبقًا"),
+	CodeSettlementRequestNotFound:   def(http.StatusNotFound, "Cash-out request not found", "طلب السحب غير موجود"),
+	CodeSettlementInvalidTransition: def(http.StatusConflict, "The request cannot move to that status", "لا يمكن نقل الطلب إلى هذه الحالة"),
+	CodeCaptainNotFound:             def(http.StatusNotFound, "Captain profile not found", "ملف الكابتن غير موجود"),
+	CodeCaptainCommissionDebtLimit:  def(http.StatusForbidden, "Commission debt exceeds the allowed limit; please settle before going online", "دين العمولة يتجاوز الحد المسموح؛ يرجى التسديد قبل الاتصال"),
+	CodeCaptainOffline:              def(http.StatusConflict, "Go online before sharing your location", "يرجى الاتصال قبل مشاركة موقعك"),
+
+	CodePaymentNotFound:         def(http.StatusNotFound, "Payment not found", "الدفعة غير موجودة"),
+	CodePaymentGatewayUnknown:   def(http.StatusNotFound, "Unknown payment gateway", "بوابة الدفع غير معروفة"),
+	CodePaymentInvalidSignature: def(http.StatusUnauthorized, "Invalid webhook signature", "توقيع الإشعار غير صالح"),
+	CodePaymentInvalidState:     def(http.StatusConflict, "Operation not allowed in the current payment state", "العملية غير مسموحة في حالة الدفع الحالية"),
+	CodePaymentMockDisabled:     def(http.StatusNotFound, "Mock gateway is not enabled", "بوابة الدفع التجريبية غير مفعلة"),
+	CodePaymentCardDeclined:     def(http.StatusPaymentRequired, "The card was declined", "تم رفض البطاقة"),
+	CodePaymentNotAuthorized:    def(http.StatusPaymentRequired, "The card payment could not be authorized", "تعذر تفويض الدفع بالبطاقة"),
+	CodePaymentRefundExceeds:    def(http.StatusBadRequest, "Refund exceeds the captured amount", "المبلغ المسترد يتجاوز المبلغ المحصل"),
+
+	CodePushTokenInvalid:     def(http.StatusBadRequest, "Invalid push token", "رمز الإشعارات غير صالح"),
+	CodeDeviceNotFound:       def(http.StatusNotFound, "Device not found", "الجهاز غير موجود"),
+	CodeNotificationNotFound: def(http.StatusNotFound, "Notification not found", "الإشعار غير موجود"),
+	CodeTemplateNotFound:     def(http.StatusNotFound, "Notification template not found", "قالب الإشعار غير موجود"),
+	CodeTemplateInvalid:      def(http.StatusBadRequest, "Invalid notification template", "قالب الإشعار غير صالح"),
+	CodeLocaleUnsupported:    def(http.StatusBadRequest, "Unsupported locale", "اللغة غير مدعومة"),
+
+	CodeSettingNotFound:           def(http.StatusNotFound, "Unknown setting", "الإعداد غير معروف"),
+	CodeSettingInvalidValue:       def(http.StatusBadRequest, "Invalid setting value", "قيمة الإعداد غير صالحة"),
+	CodeSettingScheduleInPast:     def(http.StatusBadRequest, "Effective time must be in the future", "يجب أن يكون وقت السريان في المستقبل"),
+	CodeSettingScheduleNotFound:   def(http.StatusNotFound, "Scheduled change not found", "التغيير المجدول غير موجود"),
+	CodeSettingScheduleNotPending: def(http.StatusConflict, "Scheduled change is no longer pending", "التغيير المجدول لم يعد معلقًا"),
+
+	CodeZoneNotFound:        def(http.StatusNotFound, "Zone not found", "المنطقة غير موجودة"),
+	CodeZoneInvalidGeometry: def(http.StatusBadRequest, "Zone geometry must be a valid GeoJSON Polygon or MultiPolygon", "يجب أن يكون شكل المنطقة مضلعًا صالحًا بصيغة GeoJSON"),
+	CodeZoneNameTaken:       def(http.StatusConflict, "Another zone already has this name", "يوجد منطقة أخرى بهذا الاسم"),
+
+	CodeCityNotFound:             def(http.StatusNotFound, "City not found", "المدينة غير موجودة"),
+	CodeCityCodeTaken:            def(http.StatusConflict, "Another city already has this code", "يوجد مدينة أخرى بهذا الرمز"),
+	CodeCityAccessDenied:         def(http.StatusForbidden, "You do not manage this city", "أنت لا تدير هذه المدينة"),
+	CodeCitySettingNotOverridden: def(http.StatusNotFound, "The city does not override this setting", "المدينة لا تعدّل هذا الإعداد"),
+	CodeCityAdminNotFound:        def(http.StatusNotFound, "The admin does not manage this city", "المسؤول لا يدير هذه المدينة"),
+}
+
+// Status returns the HTTP status for code; unknown codes are internal errors
+func (c Code) Status() int {
+	if d, ok := catalogue[c]; ok {
+		return d.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Message returns the code's message in locale, falling back to English
+func (c Code) Message(locale string) string {
+	d, ok := catalogue[c]
+	if !ok {
+		d = catalogue[CodeInternal]
+	}
+	if msg, ok := d.messages[locale]; ok {
+		return msg
+	}
+	return d.messages[LocaleEnglish]
+}
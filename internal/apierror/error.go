@@ -0,0 +1,75 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error is an error with a stable code, returned to clients in the standard envelope.
+// The cause is logged but never sent to the client.
+type Error struct {
+	Code    Code
+	Details []FieldError
+	Cause   error
+}
+
+// FieldError describes why one request field was rejected. Message overrides the
+// localised message for Rule when set.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+// New creates an error with the given code
+func New(code Code) *Error {
+	return &Error{Code: code}
+}
+
+// Wrap creates an error with the given code and an underlying cause
+func Wrap(code Code, cause error) *Error {
+	return &Error{Code: code, Cause: cause}
+}
+
+// Validation creates a VALIDATION_FAILED error for the given fields
+func Validation(details ...FieldError) *Error {
+	return &Error{Code: CodeValidationFailed, Details: details}
+}
+
+// Field creates a field error for a rule, e.g. Field("id", "uuid")
+func Field(field, rule string) FieldError {
+	return FieldError{Field: field, Rule: rule}
+}
+
+// WithDetails returns a copy of e with the given field errors appended
+func (e *Error) WithDetails(details ...FieldError) *Error {
+	copied := *e
+	copied.Details = append(append([]FieldError(nil), e.Details...), details...)
+	return &copied
+}
+
+// Status returns the HTTP status of the error's code
+func (e *Error) Status() int {
+	return e.Code.Status()
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Code, e.Cause)
+	}
+	return string(e.Code)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// From returns err as an *Error, or an INTERNAL_ERROR wrapping it when it carries no code
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return Wrap(CodeInternal, err)
+}
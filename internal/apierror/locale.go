@@ -0,0 +1,52 @@
+package apierror
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported message locales
+const (
+	LocaleEnglish = "en"
+	LocaleArabic  = "ar"
+)
+
+// DefaultLocale is used when Accept-Language names no supported locale
+const DefaultLocale = LocaleEnglish
+
+// NegotiateLocale picks the supported locale the client prefers most from an
+// Accept-Language header, e.g. "ar-JO,ar;q=0.9,en;q=0.8" gives "ar"
+func NegotiateLocale(header string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if base != LocaleEnglish && base != LocaleArabic {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{locale: base, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return DefaultLocale
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].locale
+}
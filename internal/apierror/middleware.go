@@ -0,0 +1,86 @@
+package apierror
+
+import (
+	"net/http"
+
+	"theb-backend/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response is the body of every error response
+type Response struct {
+	Error Body `json:"error"`
+} // @name ErrorResponse
+
+// Body describes the error in a Response
+type Body struct {
+	Code      Code     `json:"code" example:"VALIDATION_FAILED"`
+	Message   string   `json:"message" example:"Some fields are invalid"`
+	Details   []Detail `json:"details,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+} // @name ErrorBody
+
+// Detail describes one rejected field in a Body
+type Detail struct {
+	Field   string `json:"field" example:"amount"`
+	Rule    string `json:"rule" example:"gt"`
+	Message string `json:"message" example:"Must be greater than 0"`
+} // @name ErrorDetail
+
+// NewResponse builds the response body for err in the request's locale
+func NewResponse(c *gin.Context, err *Error) Response {
+	locale := NegotiateLocale(c.GetHeader("Accept-Language"))
+
+	body := Body{
+		Code:      err.Code,
+		Message:   err.Code.Message(locale),
+		RequestID: c.GetString("request_id"),
+	}
+	for _, detail := range err.Details {
+		body.Details = append(body.Details, Detail{
+			Field:   detail.Field,
+			Rule:    detail.Rule,
+			Message: detail.LocalisedMessage(locale),
+		})
+	}
+	return Response{Error: body}
+}
+
+// Abort writes err as an error response and stops the handler chain. Errors
+// without a code become INTERNAL_ERROR; server errors with a cause are logged.
+func Abort(c *gin.Context, err error) {
+	apiErr := From(err)
+	_ = c.Error(err)
+
+	if apiErr.Status() >= http.StatusInternalServerError && apiErr.Cause != nil {
+		logger.FromContext(c.Request.Context()).Error("Request failed", map[string]interface{}{
+			"code":  apiErr.Code,
+			"error": apiErr.Cause.Error(),
+		})
+	}
+
+	c.AbortWithStatusJSON(apiErr.Status(), NewResponse(c, apiErr))
+}
+
+// AbortBinding writes the error from a failed ShouldBind* call and stops the handler chain
+func AbortBinding(c *gin.Context, err error) {
+	Abort(c, FromBinding(err))
+}
+
+// Middleware converts errors that handlers attach with c.Error but do not write
+// into the standard error response. Panics are handled by middleware.Recovery.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if last := c.Errors.Last(); last != nil && !c.Writer.Written() {
+			Abort(c, last.Err)
+		}
+	}
+}
+
+// NotFound answers requests that match no route
+func NotFound(c *gin.Context) {
+	Abort(c, New(CodeNotFound))
+}
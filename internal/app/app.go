@@ -1,9 +1,31 @@
 package app
 
 import (
+	"context"
+
 	"theb-backend/internal/config"
 	"theb-backend/internal/container"
+	"theb-backend/internal/errreport"
+	"theb-backend/internal/events"
+	"theb-backend/internal/health"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/metrics"
+	"theb-backend/internal/module"
+	"theb-backend/internal/realtime"
 	"theb-backend/internal/router"
+	"theb-backend/internal/service/captain"
+	"theb-backend/internal/service/city"
+	"theb-backend/internal/service/ledger"
+	"theb-backend/internal/service/notification"
+	"theb-backend/internal/service/order"
+	"theb-backend/internal/service/payment"
+	"theb-backend/internal/service/pricing"
+	"theb-backend/internal/service/rating"
+	"theb-backend/internal/service/ride"
+	"theb-backend/internal/service/settings"
+	"theb-backend/internal/service/wallet"
+	"theb-backend/internal/service/zone"
+	"theb-backend/internal/validation"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
@@ -17,28 +39,108 @@ type Application struct {
 	router    *gin.Engine
 }
 
-// New creates a new application instance
-func New(cfg *config.Config, db *gorm.DB, redis *redis.Client) (*Application, error) {
+// Modules returns every service module in boot order. A module comes after the
+// modules whose services it uses.
+func Modules() []module.Module {
+	return []module.Module{
+		settings.Module{},
+		city.Module{},
+		zone.Module{},
+		ledger.Module{},
+		order.Module{},
+		captain.Module{},
+		payment.Module{},
+		wallet.Module{},
+		pricing.Module{},
+		rating.Module{},
+		ride.Module{},
+		notification.Module{},
+	}
+}
+
+// New creates a new application instance with every module enabled in config
+func New(cfg *config.Config, db *gorm.DB, redisClient *redis.Client) (*Application, error) {
+	return NewWithModules(cfg, db, redisClient, Modules()...)
+}
+
+// NewWithModules creates an application that boots only the given modules,
+// further filtered by the module flags in config
+func NewWithModules(cfg *config.Config, db *gorm.DB, redisClient *redis.Client, modules ...module.Module) (*Application, error) {
 	// Set Gin mode
 	if !cfg.App.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Request validation rules used by every module's bindings
+	if err := validation.Register(cfg.Validation); err != nil {
+		return nil, err
+	}
+
 	// Initialize dependency injection container
 	ctn := container.New()
 
-	// Register core dependencies
-	ctn.Register("config", cfg)
-	ctn.Register("db", db)
-	ctn.Register("redis", redis)
+	// Register core dependencies. They are registered first, so they stop last.
+	container.Supply(ctn, cfg)
+	container.Supply(ctn, db, container.OnStop(func(ctx context.Context, db *gorm.DB) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	}))
+	container.Supply(ctn, redisClient, container.OnStop(func(ctx context.Context, client *redis.Client) error {
+		if client == nil {
+			return nil
+		}
+		return client.Close()
+	}))
 
-	// Register all services
-	if err := registerServices(ctn); err != nil {
+	// Error tracker for recovered panics
+	container.Provide(ctn, func(ctn *container.Container) (errreport.Reporter, error) {
+		return errreport.New(cfg.Errors, cfg.App)
+	}, container.OnStop(func(ctx context.Context, reporter errreport.Reporter) error {
+		return reporter.Close()
+	}))
+
+	// Connection pool stats for /metrics
+	if err := metrics.RegisterDB(db); err != nil {
+		return nil, err
+	}
+	if err := metrics.RegisterRedis(redisClient); err != nil {
+		return nil, err
+	}
+
+	// Per-user WebSocket connections shared by the service modules
+	container.Provide(ctn, func(ctn *container.Container) (*realtime.Hub, error) {
+		return realtime.NewHub(redisClient, cfg.CORS), nil
+	},
+		container.OnStart(func(ctx context.Context, hub *realtime.Hub) error {
+			hub.Start()
+			return nil
+		}),
+		container.OnStop(func(ctx context.Context, hub *realtime.Hub) error {
+			return hub.Stop(ctx)
+		}),
+	)
+
+	// Register infrastructure, then every enabled module
+	registry := module.NewRegistry(modules...)
+	if err := registerServices(ctn, registry); err != nil {
+		return nil, err
+	}
+	registerHealth(ctn, registry)
+
+	// Start the event bus, job scheduler and other workers now that every module
+	// has subscribed its handlers and scheduled its jobs
+	if err := ctn.Start(context.Background()); err != nil {
 		return nil, err
 	}
 
 	// Initialize router
-	r := router.New(cfg, ctn)
+	r, err := router.New(cfg, ctn, registry)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Application{
 		config:    cfg,
@@ -52,18 +154,72 @@ func (a *Application) Router() *gin.Engine {
 	return a.router
 }
 
-// registerServices registers all service dependencies
-func registerServices(ctn *container.Container) error {
-	// TODO: Register service modules here
-	// Example:
-	// auth.RegisterService(ctn)
-	// user.RegisterService(ctn)
-	// location.RegisterService(ctn)
-	// order.RegisterService(ctn)
-	// payment.RegisterService(ctn)
-	// rating.RegisterService(ctn)
-	// notification.RegisterService(ctn)
-	// captain.RegisterService(ctn)
-
-	return nil
+// Drain fails readiness checks so load balancers stop sending traffic before shutdown
+func (a *Application) Drain() {
+	if checker, err := container.Resolve[*health.Checker](a.container); err == nil {
+		checker.Drain()
+	}
+}
+
+// Shutdown stops every service in reverse dependency order: workers, WebSockets
+// and the job scheduler first, then Redis and Postgres
+func (a *Application) Shutdown(ctx context.Context) error {
+	return a.container.Stop(ctx)
+}
+
+// registerServices registers the job scheduler and event bus, which modules use
+// while registering, and then the enabled modules
+func registerServices(ctn *container.Container, registry *module.Registry) error {
+	if err := jobs.RegisterService(ctn); err != nil {
+		return err
+	}
+	if err := events.RegisterService(ctn); err != nil {
+		return err
+	}
+
+	return registry.Register(ctn)
+}
+
+// registerHealth provides the readiness checker. Redis is only critical in production;
+// elsewhere the app runs without it in a degraded state.
+func registerHealth(ctn *container.Container, registry *module.Registry) {
+	container.Provide(ctn, func(ctn *container.Container) (*health.Checker, error) {
+		cfg, err := container.Resolve[*config.Config](ctn)
+		if err != nil {
+			return nil, err
+		}
+		db, err := container.Resolve[*gorm.DB](ctn)
+		if err != nil {
+			return nil, err
+		}
+		redisClient, err := container.Resolve[*redis.Client](ctn)
+		if err != nil {
+			return nil, err
+		}
+		scheduler, err := container.Resolve[*jobs.Scheduler](ctn)
+		if err != nil {
+			return nil, err
+		}
+		bus, err := container.Resolve[*events.Bus](ctn)
+		if err != nil {
+			return nil, err
+		}
+		relay, err := container.Resolve[*events.Relay](ctn)
+		if err != nil {
+			return nil, err
+		}
+
+		models := append([]interface{}{&events.OutboxEvent{}, &events.ProcessedEvent{}}, registry.Migrated()...)
+
+		checker := health.NewChecker(cfg.Health.CheckTimeout)
+		checker.Add(health.Postgres(db))
+		checker.Add(health.Redis(redisClient, cfg.App.Env == "production"))
+		checker.Add(health.Migrations(db, models...))
+		checker.Add(health.Workers(map[string]health.Worker{
+			"scheduler":    scheduler,
+			"event_bus":    bus,
+			"outbox_relay": relay,
+		}))
+		return checker, nil
+	})
 }
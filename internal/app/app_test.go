@@ -0,0 +1,44 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/module"
+)
+
+func TestModulesRequires(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ModulesConfig
+		message string
+	}{
+		{name: "every module"},
+		{name: "without optional modules", cfg: config.ModulesConfig{"pricing": false, "ride": false, "rating": false, "notification": false, "payment": false, "wallet": false}},
+		{name: "ledger only", cfg: config.ModulesConfig{
+			"settings": false, "city": false, "zone": false, "wallet": false, "captain": false, "payment": false,
+			"order": false, "pricing": false, "ride": false, "rating": false, "notification": false,
+		}},
+		{name: "city disabled", cfg: config.ModulesConfig{"city": false}, message: "zone requires city"},
+		{name: "ledger disabled", cfg: config.ModulesConfig{"ledger": false}, message: "captain requires ledger"},
+		{name: "order disabled", cfg: config.ModulesConfig{"order": false}, message: "captain requires order"},
+		{name: "pricing disabled", cfg: config.ModulesConfig{"pricing": false}, message: "ride requires pricing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := module.NewRegistry(Modules()...).Enabled(tt.cfg)
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("Enabled() returned %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, module.ErrMissingDependency) || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Enabled() = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -2,13 +2,12 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"time"
 
-	"gopkg.in/yaml.v3"
+	"theb-backend/pkg/geo"
 )
 
-// Config holds all application configuration
+// Config holds all application configuration. Fields tagged secret are masked by Redacted.
 type Config struct {
 	App        AppConfig        `yaml:"app"`
 	Database   DatabaseConfig   `yaml:"database"`
@@ -20,6 +19,18 @@ type Config struct {
 	CORS       CORSConfig       `yaml:"cors"`
 	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
 	Logging    LoggingConfig    `yaml:"logging"`
+	Captain    CaptainConfig    `yaml:"captain"`
+	Payments   PaymentsConfig   `yaml:"payments"`
+	Ratings    RatingsConfig    `yaml:"ratings"`
+	Events     EventsConfig     `yaml:"events"`
+	Jobs       JobsConfig       `yaml:"jobs"`
+	Modules    ModulesConfig    `yaml:"modules"`
+	Admin      AdminConfig      `yaml:"admin"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	Health     HealthConfig     `yaml:"health"`
+	Errors     ErrorsConfig     `yaml:"errors"`
+	Validation ValidationConfig `yaml:"validation"`
+	Cities     CitiesConfig     `yaml:"cities"`
 }
 
 // AppConfig contains application settings
@@ -36,27 +47,31 @@ type DatabaseConfig struct {
 	Host            string        `yaml:"host"`
 	Port            int           `yaml:"port"`
 	User            string        `yaml:"user"`
-	Password        string        `yaml:"password"`
+	Password        string        `yaml:"password" secret:"true"`
 	Name            string        `yaml:"name"`
 	SSLMode         string        `yaml:"sslmode"`
 	Timezone        string        `yaml:"timezone"`
 	MaxOpenConns    int           `yaml:"max_open_conns"`
 	MaxIdleConns    int           `yaml:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// SlowQueryThreshold logs queries taking longer at WARN; zero disables it
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold"`
+	// LogQueryParams inlines bound values into logged SQL instead of placeholders
+	LogQueryParams bool `yaml:"log_query_params"`
 }
 
 // RedisConfig contains Redis settings
 type RedisConfig struct {
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
-	Password string `yaml:"password"`
+	Password string `yaml:"password" secret:"true"`
 	DB       int    `yaml:"db"`
 	PoolSize int    `yaml:"pool_size"`
 }
 
 // JWTConfig contains JWT settings
 type JWTConfig struct {
-	Secret             string        `yaml:"secret"`
+	Secret             string        `yaml:"secret" secret:"true"`
 	AccessTokenExpiry  time.Duration `yaml:"access_token_expiry"`
 	RefreshTokenExpiry time.Duration `yaml:"refresh_token_expiry"`
 }
@@ -69,20 +84,40 @@ type OTPConfig struct {
 
 // GoogleMapsConfig contains Google Maps API settings
 type GoogleMapsConfig struct {
-	APIKey string `yaml:"api_key"`
+	// Enabled requires an API key; without it map lookups are unavailable
+	Enabled bool   `yaml:"enabled"`
+	APIKey  string `yaml:"api_key" secret:"true"`
 }
 
 // ExpoPushConfig contains Expo Push Notification settings
 type ExpoPushConfig struct {
-	Token string `yaml:"token"`
+	Token string `yaml:"token" secret:"true"`
+	// BaseURL is the Expo push API root; empty uses the public Expo endpoint
+	BaseURL string `yaml:"base_url"`
+	// Fake serves the push API from an in-process fake server instead of Expo
+	Fake bool `yaml:"fake"`
+	// BatchWindow is how long the sender waits to fill a batch before flushing
+	BatchWindow  time.Duration `yaml:"batch_window"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	// ReceiptDelay is how long after sending receipts are first checked
+	ReceiptDelay    time.Duration `yaml:"receipt_delay"`
+	ReceiptInterval time.Duration `yaml:"receipt_interval"`
 }
 
 // CORSConfig contains CORS settings
 type CORSConfig struct {
+	// AllowedOrigins are exact origins, subdomain patterns such as https://*.theb.app,
+	// or "*" for any origin without credentials. WebSocket handshakes are checked
+	// against them too.
 	AllowedOrigins   []string `yaml:"allowed_origins"`
 	AllowedMethods   []string `yaml:"allowed_methods"`
 	AllowedHeaders   []string `yaml:"allowed_headers"`
 	AllowCredentials bool     `yaml:"allow_credentials"`
+	// ExposedHeaders are response headers readable by browser scripts
+	ExposedHeaders []string `yaml:"exposed_headers"`
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration `yaml:"max_age"`
 }
 
 // RateLimitConfig contains rate limiting settings
@@ -95,55 +130,168 @@ type RateLimitConfig struct {
 type LoggingConfig struct {
 	Level  string `yaml:"level"`
 	Format string `yaml:"format"`
-	Output string `yaml:"output"`
+	// Output is stdout, stderr or a file path; files are rotated per File
+	Output   string            `yaml:"output"`
+	File     LogFileConfig     `yaml:"file"`
+	Sampling LogSamplingConfig `yaml:"sampling"`
+	// RedactKeys adds keys to redact on top of the built-in phone, otp, token and password.
+	// A key matches field, group and map keys containing its words, e.g. api_key matches X-Api-Key.
+	RedactKeys []string `yaml:"redact_keys"`
 }
 
-// Load reads configuration from file based on APP_ENV
-func Load() (*Config, error) {
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		env = "development"
-	}
+// LogFileConfig contains log file rotation settings
+type LogFileConfig struct {
+	MaxSizeMB  int  `yaml:"max_size_mb"`
+	MaxBackups int  `yaml:"max_backups"`
+	MaxAgeDays int  `yaml:"max_age_days"`
+	Compress   bool `yaml:"compress"`
+}
+
+// LogSamplingConfig limits repeated debug messages. Within each Tick the first
+// Initial entries of a message are logged, then every Thereafter-th. Zero Initial disables sampling.
+type LogSamplingConfig struct {
+	Initial    int           `yaml:"initial"`
+	Thereafter int           `yaml:"thereafter"`
+	Tick       time.Duration `yaml:"tick"`
+}
+
+// CaptainConfig contains captain earnings and settlement settings.
+// Money amounts are in fils (1 JOD = 1000 fils).
+type CaptainConfig struct {
+	CommissionRate    float64 `yaml:"commission_rate"`
+	MaxCommissionDebt int64   `yaml:"max_commission_debt"`
+	MinCashOut        int64   `yaml:"min_cash_out"`
+	// OfflineAfter marks online captains offline when no location update arrived for this long
+	OfflineAfter time.Duration `yaml:"offline_after"`
+}
+
+// PaymentsConfig contains card payment gateway settings
+type PaymentsConfig struct {
+	Gateway       string `yaml:"gateway"`
+	WebhookSecret string `yaml:"webhook_secret" secret:"true"`
+	// AuthorizationBuffer is added on top of the fare estimate when authorizing, e.g. 0.2 for 20%
+	AuthorizationBuffer float64    `yaml:"authorization_buffer"`
+	Mock                MockConfig `yaml:"mock"`
+}
 
-	configFile := fmt.Sprintf("config/%s.yaml", env)
-	data, err := os.ReadFile(configFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
+// MockConfig contains settings for the local mock payment gateway
+type MockConfig struct {
+	WebhookURL   string        `yaml:"webhook_url"`
+	WebhookDelay time.Duration `yaml:"webhook_delay"`
+}
 
-	// Expand environment variables in config
-	expanded := os.ExpandEnv(string(data))
+// RatingsConfig contains ride rating settings
+type RatingsConfig struct {
+	// Window is how long after completion a ride can still be rated
+	Window time.Duration `yaml:"window"`
+	// AverageOverRides is how many recent ratings the rolling average covers
+	AverageOverRides int `yaml:"average_over_rides"`
+	// ReviewThreshold opens an ops review case for ratings below this value
+	ReviewThreshold int `yaml:"review_threshold"`
+}
 
-	var cfg Config
-	if err := yaml.Unmarshal([]byte(expanded), &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
+// EventsConfig contains outbox relay and event stream settings
+type EventsConfig struct {
+	RelayInterval  time.Duration `yaml:"relay_interval"`
+	RelayBatchSize int           `yaml:"relay_batch_size"`
+	// StreamMaxLen is the Redis stream length above which acknowledged events are trimmed
+	StreamMaxLen int64 `yaml:"stream_max_len"`
+	// ClaimIdle is how long an unacknowledged event waits before it is redelivered
+	ClaimIdle time.Duration `yaml:"claim_idle"`
+	// Retention is how long published events and consumer dedupe records are kept
+	Retention time.Duration `yaml:"retention"`
+}
 
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
-	}
+// JobsConfig contains background job scheduler settings
+type JobsConfig struct {
+	// PollInterval is how often due delayed jobs are picked up
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// Schedules overrides a cron job's schedule by job name; "disabled" turns it off
+	Schedules map[string]string `yaml:"schedules"`
+}
 
-	return &cfg, nil
+// AdminConfig contains the admin listener serving /metrics
+type AdminConfig struct {
+	Enabled bool `yaml:"enabled"`
+	Port    int  `yaml:"port"`
 }
 
-// Validate checks if the configuration is valid
-func (c *Config) Validate() error {
-	if c.App.Port <= 0 || c.App.Port > 65535 {
-		return fmt.Errorf("invalid port number: %d", c.App.Port)
-	}
+// TracingConfig contains OpenTelemetry tracing settings
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the OTLP/HTTP collector host:port
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	// SampleRatio is the share of new traces recorded; incoming sampled traces are always kept
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
 
-	if c.Database.Name == "" {
-		return fmt.Errorf("database name is required")
-	}
+// HealthConfig contains readiness check settings
+type HealthConfig struct {
+	// CheckTimeout bounds each dependency check
+	CheckTimeout time.Duration `yaml:"check_timeout"`
+	// DrainDelay is how long readiness fails before the server stops accepting
+	// requests, so load balancers take the instance out of rotation first
+	DrainDelay time.Duration `yaml:"drain_delay"`
+}
+
+// ErrorsConfig contains error reporting settings
+type ErrorsConfig struct {
+	// Reporter is none or file; panics are always logged either way
+	Reporter string `yaml:"reporter"`
+	// File is where the file reporter appends one JSON event per line
+	File string `yaml:"file"`
+}
+
+// ValidationConfig contains request validation rules
+type ValidationConfig struct {
+	Phone PhoneConfig `yaml:"phone"`
+	// ServiceArea is the polygon pickup points must fall in; empty allows anywhere
+	ServiceArea []geo.Point `yaml:"service_area"`
+}
+
+// PhoneConfig contains the phone numbers accepted by the phone validation rule
+type PhoneConfig struct {
+	// CountryCode is the calling code without +, e.g. 962
+	CountryCode string `yaml:"country_code"`
+	// MobilePrefixes are the accepted starts of the national number, e.g. 79 for 079
+	MobilePrefixes []string `yaml:"mobile_prefixes"`
+	// NationalLength is the number of digits after the country code
+	NationalLength int `yaml:"national_length"`
+}
+
+// CitiesConfig contains multi-city settings
+type CitiesConfig struct {
+	// Default is the city created on first boot, so a single-city deployment
+	// works before any city is added through the admin API
+	Default CityConfig `yaml:"default"`
+}
+
+// CityConfig describes a city
+type CityConfig struct {
+	// Code is the city's stable identifier, e.g. mafraq
+	Code     string `yaml:"code"`
+	Name     string `yaml:"name"`
+	NameAr   string `yaml:"name_ar"`
+	Timezone string `yaml:"timezone"`
+	// Currency is the ISO 4217 code fares are charged in
+	Currency string `yaml:"currency"`
+	// MinorUnits is how many minor units make one currency unit, e.g. 1000 fils per JOD
+	MinorUnits int64 `yaml:"minor_units"`
+	// FareRounding rounds fares to a multiple of this many minor units
+	FareRounding int64 `yaml:"fare_rounding"`
+	// SupportPhone is the number passengers and captains call for help
+	SupportPhone string `yaml:"support_phone"`
+}
 
-	if c.JWT.Secret == "" || c.JWT.Secret == "dev-jwt-secret-change-in-production" {
-		if c.App.Env == "production" {
-			return fmt.Errorf("JWT secret must be changed in production")
-		}
-	}
+// ModulesConfig enables or disables service modules by name. Modules not listed are enabled.
+type ModulesConfig map[string]bool
 
-	return nil
+// Enabled reports whether the named module should be booted
+func (m ModulesConfig) Enabled(name string) bool {
+	enabled, listed := m[name]
+	return !listed || enabled
 }
 
 // DSN returns the PostgreSQL connection string
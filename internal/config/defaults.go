@@ -0,0 +1,138 @@
+package config
+
+import "time"
+
+// Default returns the built-in configuration that config files and environment
+// variables are layered on. Values are safe for production; anything local-only,
+// such as the mock payment webhook or fake push server, is off.
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			Name: "THEB",
+			Env:  "development",
+			Port: 8080,
+			Host: "0.0.0.0",
+		},
+		Database: DatabaseConfig{
+			Host:               "localhost",
+			Port:               5432,
+			User:               "postgres",
+			Name:               "theb_db",
+			SSLMode:            "require",
+			Timezone:           "Asia/Amman",
+			MaxOpenConns:       25,
+			MaxIdleConns:       5,
+			ConnMaxLifetime:    5 * time.Minute,
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+		Redis: RedisConfig{
+			Host:     "localhost",
+			Port:     6379,
+			PoolSize: 10,
+		},
+		JWT: JWTConfig{
+			AccessTokenExpiry:  15 * time.Minute,
+			RefreshTokenExpiry: 7 * 24 * time.Hour,
+		},
+		OTP: OTPConfig{
+			Expiry: 5 * time.Minute,
+			Length: 6,
+		},
+		ExpoPush: ExpoPushConfig{
+			BaseURL:         "https://exp.host/--/api/v2/push",
+			BatchWindow:     500 * time.Millisecond,
+			MaxAttempts:     5,
+			RetryBackoff:    time.Second,
+			ReceiptDelay:    15 * time.Minute,
+			ReceiptInterval: 5 * time.Minute,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Origin", "Content-Type", "Authorization", "X-Request-ID"},
+			ExposedHeaders: []string{"X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"},
+			MaxAge:         24 * time.Hour,
+		},
+		RateLimit: RateLimitConfig{
+			PerMinute:  60,
+			OTPPerHour: 3,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+			Output: "stdout",
+			File: LogFileConfig{
+				MaxSizeMB:  100,
+				MaxBackups: 5,
+				MaxAgeDays: 14,
+				Compress:   true,
+			},
+			Sampling: LogSamplingConfig{
+				Initial:    10,
+				Thereafter: 100,
+				Tick:       time.Second,
+			},
+		},
+		Captain: CaptainConfig{
+			CommissionRate:    0.15,
+			MaxCommissionDebt: 20000,
+			MinCashOut:        5000,
+			OfflineAfter:      2 * time.Minute,
+		},
+		Payments: PaymentsConfig{
+			Gateway:             "mock",
+			AuthorizationBuffer: 0.2,
+			Mock: MockConfig{
+				WebhookDelay: 3 * time.Second,
+			},
+		},
+		Ratings: RatingsConfig{
+			Window:           72 * time.Hour,
+			AverageOverRides: 100,
+			ReviewThreshold:  3,
+		},
+		Events: EventsConfig{
+			RelayInterval:  time.Second,
+			RelayBatchSize: 100,
+			StreamMaxLen:   100000,
+			ClaimIdle:      time.Minute,
+			Retention:      7 * 24 * time.Hour,
+		},
+		Jobs: JobsConfig{
+			PollInterval: time.Second,
+		},
+		Admin: AdminConfig{
+			Enabled: true,
+			Port:    9090,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			SampleRatio: 1,
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+		},
+		Errors: ErrorsConfig{
+			Reporter: "none",
+			File:     "logs/errors.jsonl",
+		},
+		Validation: ValidationConfig{
+			Phone: PhoneConfig{
+				CountryCode:    "962",
+				MobilePrefixes: []string{"77", "78", "79"},
+				NationalLength: 9,
+			},
+		},
+		Cities: CitiesConfig{
+			Default: CityConfig{
+				Code:         "mafraq",
+				Name:         "Mafraq",
+				NameAr:       "المفرق",
+				Timezone:     "Asia/Amman",
+				Currency:     "JOD",
+				MinorUnits:   1000,
+				FareRounding: 50,
+			},
+		},
+	}
+}
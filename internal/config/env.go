@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts the environment variables that override config values. The
+// variable name is the prefix plus the YAML path in upper case joined by
+// underscores, e.g. APP_DATABASE_MAX_OPEN_CONNS for database.max_open_conns.
+// Appending _FILE reads the value from a file, e.g. APP_JWT_SECRET_FILE.
+const EnvPrefix = "APP_"
+
+// fileSuffix marks variables naming a file that holds the value
+const fileSuffix = "_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv overrides cfg with APP_* variables from environ, then with APP_*_FILE
+// secrets files, so a mounted secret wins over a plain variable. Lists are
+// comma-separated; map entries such as modules.order use APP_MODULES_ORDER.
+func applyEnv(cfg *Config, environ []string) error {
+	vars := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if name, value, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(name, EnvPrefix) {
+			vars[name] = value
+		}
+	}
+
+	var errs []error
+	walkFields(reflect.ValueOf(cfg).Elem(), strings.TrimSuffix(EnvPrefix, "_"), func(name string, field reflect.Value) {
+		if value, ok := vars[name]; ok {
+			if err := setField(field, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+		if field.Kind() == reflect.Map {
+			if err := setMapEntries(field, name+"_", vars); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+
+	walkFields(reflect.ValueOf(cfg).Elem(), strings.TrimSuffix(EnvPrefix, "_"), func(name string, field reflect.Value) {
+		path, ok := vars[name+fileSuffix]
+		if !ok {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", name, fileSuffix, err))
+			return
+		}
+		if err := setField(field, strings.TrimRight(string(data), "\r\n")); err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", name, fileSuffix, err))
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// walkFields calls fn with the variable name of every leaf field under v.
+// Nested structs are walked; maps and lists are leaves.
+func walkFields(v reflect.Value, prefix string, fn func(name string, field reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		key := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		name := prefix + "_" + strings.ToUpper(key)
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			walkFields(field, name, fn)
+			continue
+		}
+		fn(name, field)
+	}
+}
+
+// setMapEntries sets map entries from variables named prefix plus the upper-case key
+func setMapEntries(field reflect.Value, prefix string, vars map[string]string) error {
+	var errs []error
+	for name, value := range vars {
+		if !strings.HasPrefix(name, prefix) || strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+
+		entry := reflect.New(field.Type().Elem()).Elem()
+		if err := setField(entry, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if field.IsNil() {
+			field.Set(reflect.MakeMap(field.Type()))
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, prefix))
+		field.SetMapIndex(reflect.ValueOf(key).Convert(field.Type().Key()), entry)
+	}
+	return errors.Join(errs...)
+}
+
+// setField parses value into field according to the field's type
+func setField(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", field.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		return fmt.Errorf("set entries individually, e.g. %s<KEY>", EnvPrefix)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
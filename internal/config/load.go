@@ -0,0 +1,159 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDir is the config directory looked up relative to the working directory,
+// then next to the executable
+const DefaultDir = "config"
+
+// baseFile holds settings shared by every environment
+const baseFile = "base.yaml"
+
+// envRef matches ${NAME} references expanded from the environment inside values
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Options selects the files Load reads
+type Options struct {
+	// Path is a config directory holding base.yaml and <env>.yaml, or an
+	// environment file whose directory holds base.yaml. Empty uses DefaultDir.
+	Path string
+	// Env selects <env>.yaml. Empty uses APP_ENV, then development.
+	Env string
+}
+
+// Load builds the configuration in layers, each overriding the previous one:
+// built-in defaults, base.yaml, <env>.yaml, APP_* environment variables and
+// finally APP_*_FILE secrets files. Unknown keys in config files are an error.
+func Load(opts Options) (*Config, error) {
+	env := opts.Env
+	if env == "" {
+		env = os.Getenv("APP_ENV")
+	}
+	if env == "" {
+		env = "development"
+	}
+
+	base, envFile, err := resolveFiles(opts.Path, env)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	if _, err := os.Stat(base); err == nil {
+		if err := decodeFile(base, cfg); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := decodeFile(envFile, cfg); err != nil {
+		return nil, err
+	}
+
+	if err := applyEnv(cfg, os.Environ()); err != nil {
+		return nil, err
+	}
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// resolveFiles returns the base and environment file paths for path and env
+func resolveFiles(path, env string) (string, string, error) {
+	if path == "" {
+		path = defaultDir()
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read config: %w", err)
+	}
+	if !info.IsDir() {
+		return filepath.Join(filepath.Dir(path), baseFile), path, nil
+	}
+
+	return filepath.Join(path, baseFile), filepath.Join(path, env+".yaml"), nil
+}
+
+// defaultDir finds the config directory from the working directory, falling back
+// to the one next to the executable so the binary runs from anywhere
+func defaultDir() string {
+	if _, err := os.Stat(DefaultDir); err == nil {
+		return DefaultDir
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		dir := filepath.Join(filepath.Dir(exe), DefaultDir)
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+	}
+
+	return DefaultDir
+}
+
+// decodeFile merges the YAML file into cfg. Keys absent from the file keep their
+// current value; lists and scalars present in the file replace it.
+func decodeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	expandNode(&doc)
+	expanded, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	// Reject unknown keys, so a typo cannot silently fall back to a default
+	decoder := yaml.NewDecoder(bytes.NewReader(expanded))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// expandNode replaces ${NAME} references in scalar values with environment
+// variables. Expansion happens after parsing, so a $ inside a value, or inside
+// the variable's value, is never interpreted as YAML or as another reference.
+func expandNode(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && envRef.MatchString(node.Value) {
+		node.Value = envRef.ReplaceAllStringFunc(node.Value, func(ref string) string {
+			return os.Getenv(envRef.FindStringSubmatch(ref)[1])
+		})
+		// Let the expanded value resolve to its own type, e.g. a port number
+		node.Tag = ""
+		node.Style &^= yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+	}
+
+	for _, child := range node.Content {
+		expandNode(child)
+	}
+}
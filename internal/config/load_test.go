@@ -0,0 +1,215 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writeConfigDir writes the repository's base.yaml and a test.yaml made of
+// development.yaml with overrides merged in, and returns the directory
+func writeConfigDir(t *testing.T, overrides map[string]interface{}) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	base, err := os.ReadFile(filepath.Join("..", "..", DefaultDir, baseFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, baseFile), base, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("..", "..", DefaultDir, "development.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		t.Fatal(err)
+	}
+	merge(env, overrides)
+	if data, err = yaml.Marshal(env); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "test.yaml"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// merge copies src into dst, merging nested maps
+func merge(dst, src map[string]interface{}) {
+	for key, value := range src {
+		nested, ok := value.(map[string]interface{})
+		if current, isMap := dst[key].(map[string]interface{}); ok && isMap {
+			merge(current, nested)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "jwt")
+	if err := os.WriteFile(secretFile, []byte("secret-from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string]interface{}
+		env       map[string]string
+		check     func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "defaults fill keys no file sets",
+			check: func(t *testing.T, cfg *Config) {
+				if want := Default().Events.RelayBatchSize; cfg.Events.RelayBatchSize != want {
+					t.Errorf("events.relay_batch_size = %d, want the default %d", cfg.Events.RelayBatchSize, want)
+				}
+			},
+		},
+		{
+			name: "base keeps keys the environment file omits",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Database.Name != "theb_db" {
+					t.Errorf("database.name = %q, want theb_db from base.yaml", cfg.Database.Name)
+				}
+			},
+		},
+		{
+			name:      "environment file overrides base",
+			overrides: map[string]interface{}{"app": map[string]interface{}{"port": 8181}},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.App.Port != 8181 {
+					t.Errorf("app.port = %d, want 8181", cfg.App.Port)
+				}
+				if cfg.App.Name != "THEB" {
+					t.Errorf("app.name = %q, want THEB kept from base.yaml", cfg.App.Name)
+				}
+			},
+		},
+		{
+			name:      "variable overrides environment file",
+			overrides: map[string]interface{}{"app": map[string]interface{}{"port": 8181}},
+			env:       map[string]string{"APP_APP_PORT": "9191", "APP_DATABASE_CONN_MAX_LIFETIME": "90s"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.App.Port != 9191 {
+					t.Errorf("app.port = %d, want 9191", cfg.App.Port)
+				}
+				if cfg.Database.ConnMaxLifetime.String() != "1m30s" {
+					t.Errorf("database.conn_max_lifetime = %s, want 1m30s", cfg.Database.ConnMaxLifetime)
+				}
+			},
+		},
+		{
+			name: "secrets file overrides variable",
+			env:  map[string]string{"APP_JWT_SECRET": "secret-from-env", "APP_JWT_SECRET_FILE": secretFile},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.JWT.Secret != "secret-from-file" {
+					t.Errorf("jwt.secret = %q, want the file's value without its newline", cfg.JWT.Secret)
+				}
+			},
+		},
+		{
+			name: "list variable",
+			env:  map[string]string{"APP_CORS_ALLOWED_ORIGINS": "https://a.example, https://b.example,"},
+			check: func(t *testing.T, cfg *Config) {
+				if got := strings.Join(cfg.CORS.AllowedOrigins, " "); got != "https://a.example https://b.example" {
+					t.Errorf("cors.allowed_origins = %q", got)
+				}
+			},
+		},
+		{
+			name:      "map entry variables",
+			overrides: map[string]interface{}{"modules": map[string]interface{}{"rating": false}},
+			env:       map[string]string{"APP_MODULES_NOTIFICATION": "false", "APP_JOBS_SCHEDULES_SURGE_COMPUTE": "@every 1m"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Modules.Enabled("notification") || cfg.Modules.Enabled("rating") {
+					t.Errorf("modules = %v, want notification and rating disabled", cfg.Modules)
+				}
+				if got := cfg.Jobs.Schedules["surge_compute"]; got != "@every 1m" {
+					t.Errorf("jobs.schedules.surge_compute = %q, want @every 1m", got)
+				}
+			},
+		},
+		{
+			name:      "references expand from the environment",
+			overrides: map[string]interface{}{"database": map[string]interface{}{"host": "${TEST_DB_HOST}"}, "redis": map[string]interface{}{"port": "${TEST_REDIS_PORT}"}},
+			env:       map[string]string{"TEST_DB_HOST": "db.internal", "TEST_REDIS_PORT": "6380"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Database.Host != "db.internal" || cfg.Redis.Port != 6380 {
+					t.Errorf("database.host/redis.port = %s/%d, want db.internal/6380", cfg.Database.Host, cfg.Redis.Port)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigDir(t, tt.overrides)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(Options{Path: dir, Env: "test"})
+			if err != nil {
+				t.Fatalf("Load() returned %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]interface{}
+		env       map[string]string
+		want      string
+	}{
+		{
+			name:      "unknown key",
+			overrides: map[string]interface{}{"database": map[string]interface{}{"hots": "localhost"}},
+			want:      "field hots not found",
+		},
+		{
+			name: "unparsable variable",
+			env:  map[string]string{"APP_APP_PORT": "eighty"},
+			want: "APP_APP_PORT",
+		},
+		{
+			name: "whole map variable",
+			env:  map[string]string{"APP_MODULES": "order=false"},
+			want: "set entries individually",
+		},
+		{
+			name: "missing secrets file",
+			env:  map[string]string{"APP_JWT_SECRET_FILE": filepath.Join(os.TempDir(), "missing-theb-secret")},
+			want: "APP_JWT_SECRET_FILE",
+		},
+		{
+			name:      "invalid value",
+			overrides: map[string]interface{}{"app": map[string]interface{}{"port": 70000}},
+			want:      "app.port",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigDir(t, tt.overrides)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			_, err := Load(Options{Path: dir, Env: "test"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
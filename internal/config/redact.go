@@ -0,0 +1,38 @@
+package config
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secret values in printed config
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of the config with every field tagged secret masked.
+// Empty secrets stay empty, so a missing value is still visible.
+func (c *Config) Redacted() *Config {
+	copied := *c
+	redact(reflect.ValueOf(&copied).Elem())
+	return &copied
+}
+
+// YAML renders the config in the format of the config files
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+func redact(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		switch {
+		case t.Field(i).Tag.Get("secret") == "true":
+			if field.Kind() == reflect.String && field.String() != "" {
+				field.SetString(redacted)
+			}
+		case field.Kind() == reflect.Struct && field.Type() != durationType:
+			redact(field)
+		}
+	}
+}
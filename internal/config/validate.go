@@ -0,0 +1,449 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// devJWTSecret is the placeholder secret shipped in development.yaml
+const devJWTSecret = "dev-jwt-secret-change-in-production"
+
+// minJWTSecretBits is the estimated entropy a production JWT secret needs
+const minJWTSecretBits = 128
+
+var (
+	logLevels      = []string{"debug", "info", "warn", "error", "fatal"}
+	logFormats     = []string{"json", "text"}
+	sslModes       = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	insecureSSL    = []string{"disable", "allow", "prefer"}
+	traceExporters = []string{"none", "stdout", "otlp"}
+	errorReporters = []string{"none", "file"}
+	httpMethods    = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+	cityCode     = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// FieldError is one invalid setting, identified by its YAML path
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError lists every invalid setting found by Validate
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = err.Error()
+	}
+	return fmt.Sprintf("%d invalid settings:\n  %s", len(e.Errors), strings.Join(lines, "\n  "))
+}
+
+// validator collects field errors
+type validator struct {
+	errs []FieldError
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(path, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.fail(path, "is required")
+	}
+}
+
+func (v *validator) positive(path string, value int64) {
+	if value <= 0 {
+		v.fail(path, "must be positive, got %d", value)
+	}
+}
+
+func (v *validator) duration(path string, value time.Duration) {
+	if value <= 0 {
+		v.fail(path, "must be a positive duration, got %s", value)
+	}
+}
+
+func (v *validator) port(path string, value int) {
+	if value <= 0 || value > 65535 {
+		v.fail(path, "must be a port between 1 and 65535, got %d", value)
+	}
+}
+
+func (v *validator) oneOf(path, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.fail(path, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// Validate checks every section and returns a *ValidationError listing all
+// problems at once, so a broken deploy can be fixed in one pass
+func (c *Config) Validate() error {
+	v := &validator{}
+	production := c.App.Env == "production"
+
+	// App
+	v.required("app.name", c.App.Name)
+	v.required("app.env", c.App.Env)
+	v.port("app.port", c.App.Port)
+
+	// Database
+	db := c.Database
+	v.required("database.host", db.Host)
+	v.port("database.port", db.Port)
+	v.required("database.user", db.User)
+	v.required("database.name", db.Name)
+	v.oneOf("database.sslmode", db.SSLMode, sslModes)
+	v.required("database.timezone", db.Timezone)
+	v.positive("database.max_open_conns", int64(db.MaxOpenConns))
+	if db.MaxIdleConns < 0 {
+		v.fail("database.max_idle_conns", "must not be negative, got %d", db.MaxIdleConns)
+	} else if db.MaxIdleConns > db.MaxOpenConns {
+		v.fail("database.max_idle_conns", "must not exceed max_open_conns (%d), got %d", db.MaxOpenConns, db.MaxIdleConns)
+	}
+	v.duration("database.conn_max_lifetime", db.ConnMaxLifetime)
+	if db.SlowQueryThreshold < 0 {
+		v.fail("database.slow_query_threshold", "must not be negative, got %s", db.SlowQueryThreshold)
+	}
+
+	// Redis
+	v.required("redis.host", c.Redis.Host)
+	v.port("redis.port", c.Redis.Port)
+	if c.Redis.DB < 0 || c.Redis.DB > 15 {
+		v.fail("redis.db", "must be between 0 and 15, got %d", c.Redis.DB)
+	}
+	v.positive("redis.pool_size", int64(c.Redis.PoolSize))
+
+	// JWT
+	v.required("jwt.secret", c.JWT.Secret)
+	v.duration("jwt.access_token_expiry", c.JWT.AccessTokenExpiry)
+	v.duration("jwt.refresh_token_expiry", c.JWT.RefreshTokenExpiry)
+	if c.JWT.RefreshTokenExpiry > 0 && c.JWT.RefreshTokenExpiry <= c.JWT.AccessTokenExpiry {
+		v.fail("jwt.refresh_token_expiry", "must be longer than access_token_expiry (%s)", c.JWT.AccessTokenExpiry)
+	}
+
+	// OTP
+	v.duration("otp.expiry", c.OTP.Expiry)
+	if c.OTP.Length < 4 || c.OTP.Length > 8 {
+		v.fail("otp.length", "must be between 4 and 8 digits, got %d", c.OTP.Length)
+	}
+
+	// Providers
+	if c.GoogleMaps.Enabled {
+		v.required("google_maps.api_key", c.GoogleMaps.APIKey)
+	}
+	c.validateExpoPush(v)
+
+	c.validateCORS(v)
+
+	// Rate limits
+	v.positive("rate_limit.per_minute", int64(c.RateLimit.PerMinute))
+	v.positive("rate_limit.otp_per_hour", int64(c.RateLimit.OTPPerHour))
+
+	c.validateLogging(v)
+
+	// Captain
+	if c.Captain.CommissionRate < 0 || c.Captain.CommissionRate >= 1 {
+		v.fail("captain.commission_rate", "must be at least 0 and below 1, got %v", c.Captain.CommissionRate)
+	}
+	if c.Captain.MaxCommissionDebt < 0 {
+		v.fail("captain.max_commission_debt", "must not be negative, got %d", c.Captain.MaxCommissionDebt)
+	}
+	if c.Captain.MinCashOut < 0 {
+		v.fail("captain.min_cash_out", "must not be negative, got %d", c.Captain.MinCashOut)
+	}
+	v.duration("captain.offline_after", c.Captain.OfflineAfter)
+
+	// Payments
+	v.required("payments.gateway", c.Payments.Gateway)
+	v.required("payments.webhook_secret", c.Payments.WebhookSecret)
+	if c.Payments.AuthorizationBuffer < 0 {
+		v.fail("payments.authorization_buffer", "must not be negative, got %v", c.Payments.AuthorizationBuffer)
+	}
+	if c.Payments.Mock.WebhookDelay < 0 {
+		v.fail("payments.mock.webhook_delay", "must not be negative, got %s", c.Payments.Mock.WebhookDelay)
+	}
+
+	// Ratings
+	v.duration("ratings.window", c.Ratings.Window)
+	v.positive("ratings.average_over_rides", int64(c.Ratings.AverageOverRides))
+	if c.Ratings.ReviewThreshold < 1 || c.Ratings.ReviewThreshold > 5 {
+		v.fail("ratings.review_threshold", "must be between 1 and 5, got %d", c.Ratings.ReviewThreshold)
+	}
+
+	// Events
+	v.duration("events.relay_interval", c.Events.RelayInterval)
+	v.positive("events.relay_batch_size", int64(c.Events.RelayBatchSize))
+	v.positive("events.stream_max_len", c.Events.StreamMaxLen)
+	v.duration("events.claim_idle", c.Events.ClaimIdle)
+	v.duration("events.retention", c.Events.Retention)
+
+	// Jobs
+	v.duration("jobs.poll_interval", c.Jobs.PollInterval)
+	names := make([]string, 0, len(c.Jobs.Schedules))
+	for name := range c.Jobs.Schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec := c.Jobs.Schedules[name]
+		if spec == "disabled" {
+			continue
+		}
+		if _, err := cron.ParseStandard(spec); err != nil {
+			v.fail("jobs.schedules."+name, "invalid schedule %q: %v", spec, err)
+		}
+	}
+
+	// Admin
+	if c.Admin.Enabled {
+		v.port("admin.port", c.Admin.Port)
+		if c.Admin.Port == c.App.Port {
+			v.fail("admin.port", "must differ from app.port (%d)", c.App.Port)
+		}
+	}
+
+	// Tracing
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, traceExporters)
+	if c.Tracing.Exporter == "otlp" {
+		v.required("tracing.endpoint", c.Tracing.Endpoint)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.fail("tracing.sample_ratio", "must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	// Health
+	v.duration("health.check_timeout", c.Health.CheckTimeout)
+	if c.Health.DrainDelay < 0 {
+		v.fail("health.drain_delay", "must not be negative, got %s", c.Health.DrainDelay)
+	}
+
+	// Validation
+	c.validateValidation(v)
+
+	// Cities
+	c.validateCities(v)
+
+	// Error reporting
+	v.oneOf("errors.reporter", c.Errors.Reporter, errorReporters)
+	if c.Errors.Reporter == "file" {
+		v.required("errors.file", c.Errors.File)
+	}
+
+	if production {
+		c.validateProduction(v)
+	}
+
+	if len(v.errs) > 0 {
+		return &ValidationError{Errors: v.errs}
+	}
+	return nil
+}
+
+func (c *Config) validateExpoPush(v *validator) {
+	expo := c.ExpoPush
+	if !expo.Fake {
+		v.required("expo_push.token", expo.Token)
+		if expo.BaseURL != "" {
+			if u, err := url.Parse(expo.BaseURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				v.fail("expo_push.base_url", "must be an http(s) URL, got %q", expo.BaseURL)
+			}
+		}
+	}
+	v.duration("expo_push.batch_window", expo.BatchWindow)
+	v.positive("expo_push.max_attempts", int64(expo.MaxAttempts))
+	v.duration("expo_push.retry_backoff", expo.RetryBackoff)
+	v.duration("expo_push.receipt_delay", expo.ReceiptDelay)
+	v.duration("expo_push.receipt_interval", expo.ReceiptInterval)
+}
+
+func (c *Config) validateCORS(v *validator) {
+	for i, origin := range c.CORS.AllowedOrigins {
+		path := fmt.Sprintf("cors.allowed_origins[%d]", i)
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				v.fail(path, `"*" cannot be used with allow_credentials; list the origins instead`)
+			}
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" ||
+			(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			v.fail(path, "must be an origin such as https://theb.app, got %q", origin)
+			continue
+		}
+		// A wildcard may only stand for the leading subdomain labels
+		if host := u.Hostname(); strings.Contains(host, "*") &&
+			(!strings.HasPrefix(host, "*.") || strings.Contains(host[2:], "*") || !strings.Contains(host[2:], ".")) {
+			v.fail(path, "wildcards must be a leading subdomain such as https://*.theb.app, got %q", origin)
+		}
+	}
+
+	if len(c.CORS.AllowedMethods) == 0 {
+		v.fail("cors.allowed_methods", "must list at least one method")
+	}
+	for i, method := range c.CORS.AllowedMethods {
+		v.oneOf(fmt.Sprintf("cors.allowed_methods[%d]", i), method, httpMethods)
+	}
+	for i, header := range c.CORS.AllowedHeaders {
+		if strings.TrimSpace(header) == "" || strings.ContainsAny(header, " ,:") {
+			v.fail(fmt.Sprintf("cors.allowed_headers[%d]", i), "must be a header name, got %q", header)
+		}
+	}
+	for i, header := range c.CORS.ExposedHeaders {
+		if strings.TrimSpace(header) == "" || strings.ContainsAny(header, " ,:") {
+			v.fail(fmt.Sprintf("cors.exposed_headers[%d]", i), "must be a header name, got %q", header)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		v.fail("cors.max_age", "must not be negative, got %s", c.CORS.MaxAge)
+	}
+}
+
+func (c *Config) validateValidation(v *validator) {
+	phone := c.Validation.Phone
+	if !isDigits(phone.CountryCode) {
+		v.fail("validation.phone.country_code", "must be digits without +, got %q", phone.CountryCode)
+	}
+	v.positive("validation.phone.national_length", int64(phone.NationalLength))
+	if len(phone.MobilePrefixes) == 0 {
+		v.fail("validation.phone.mobile_prefixes", "must not be empty")
+	}
+	for i, prefix := range phone.MobilePrefixes {
+		if !isDigits(prefix) || len(prefix) >= phone.NationalLength {
+			v.fail(fmt.Sprintf("validation.phone.mobile_prefixes[%d]", i), "must be digits shorter than national_length, got %q", prefix)
+		}
+	}
+
+	area := c.Validation.ServiceArea
+	if len(area) > 0 && len(area) < 3 {
+		v.fail("validation.service_area", "must have at least 3 points, got %d", len(area))
+	}
+	for i, point := range area {
+		if !point.Valid() {
+			v.fail(fmt.Sprintf("validation.service_area[%d]", i), "lat must be within ±90 and lng within ±180, got %v,%v", point.Lat, point.Lng)
+		}
+	}
+}
+
+func (c *Config) validateCities(v *validator) {
+	city := c.Cities.Default
+	if !cityCode.MatchString(city.Code) {
+		v.fail("cities.default.code", "must be lowercase letters, digits and dashes, got %q", city.Code)
+	}
+	v.required("cities.default.name", city.Name)
+	if _, err := time.LoadLocation(city.Timezone); err != nil || city.Timezone == "" {
+		v.fail("cities.default.timezone", "must be an IANA time zone such as Asia/Amman, got %q", city.Timezone)
+	}
+	if !currencyCode.MatchString(city.Currency) {
+		v.fail("cities.default.currency", "must be an ISO 4217 code such as JOD, got %q", city.Currency)
+	}
+	v.positive("cities.default.minor_units", city.MinorUnits)
+	v.positive("cities.default.fare_rounding", city.FareRounding)
+	if city.SupportPhone != "" && !strings.HasPrefix(city.SupportPhone, "+") {
+		v.fail("cities.default.support_phone", "must be in E.164 form such as +962791234567, got %q", city.SupportPhone)
+	}
+}
+
+// isDigits reports whether s is non-empty and only ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) validateLogging(v *validator) {
+	log := c.Logging
+	v.oneOf("logging.level", strings.ToLower(log.Level), logLevels)
+	v.oneOf("logging.format", log.Format, logFormats)
+	v.required("logging.output", log.Output)
+
+	if log.Output != "stdout" && log.Output != "stderr" {
+		v.positive("logging.file.max_size_mb", int64(log.File.MaxSizeMB))
+		if log.File.MaxBackups < 0 {
+			v.fail("logging.file.max_backups", "must not be negative, got %d", log.File.MaxBackups)
+		}
+		if log.File.MaxAgeDays < 0 {
+			v.fail("logging.file.max_age_days", "must not be negative, got %d", log.File.MaxAgeDays)
+		}
+	}
+
+	if log.Sampling.Initial < 0 {
+		v.fail("logging.sampling.initial", "must not be negative, got %d", log.Sampling.Initial)
+	} else if log.Sampling.Initial > 0 {
+		v.positive("logging.sampling.thereafter", int64(log.Sampling.Thereafter))
+		v.duration("logging.sampling.tick", log.Sampling.Tick)
+	}
+}
+
+// validateProduction adds the rules that only apply to production
+func (c *Config) validateProduction(v *validator) {
+	for _, mode := range insecureSSL {
+		if c.Database.SSLMode == mode {
+			v.fail("database.sslmode", "must require TLS in production, got %q", mode)
+		}
+	}
+
+	if c.JWT.Secret == devJWTSecret {
+		v.fail("jwt.secret", "must be changed from the development placeholder in production")
+	} else if c.JWT.Secret != "" {
+		if bits := entropyBits(c.JWT.Secret); bits < minJWTSecretBits {
+			v.fail("jwt.secret", "is too weak for production: about %d bits of entropy, need %d; use at least 32 random bytes", int(bits), minJWTSecretBits)
+		}
+	}
+
+	if c.Payments.Gateway == "" || c.Payments.Gateway == "mock" {
+		v.fail("payments.gateway", "must be a real card gateway in production, got %q", c.Payments.Gateway)
+	}
+	if c.Payments.WebhookSecret == "dev-webhook-secret" {
+		v.fail("payments.webhook_secret", "must be changed from the development placeholder in production")
+	}
+	if c.ExpoPush.Fake {
+		v.fail("expo_push.fake", "must be false in production")
+	}
+}
+
+// entropyBits estimates a secret's entropy as its length times the Shannon
+// entropy of its characters, so long but repetitive secrets score low
+func entropyBits(secret string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range secret {
+		counts[r]++
+		total++
+	}
+
+	perChar := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		perChar -= p * math.Log2(p)
+	}
+	return perChar * float64(total)
+}
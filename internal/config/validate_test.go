@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// loadValid loads the development config, which must validate
+func loadValid(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := Load(Options{Path: writeConfigDir(t, nil), Env: "test"})
+	if err != nil {
+		t.Fatalf("Load() returned %v", err)
+	}
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(cfg *Config)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(cfg *Config) {},
+		},
+		{
+			name: "every invalid setting is reported at once",
+			mutate: func(cfg *Config) {
+				cfg.App.Port = 0
+				cfg.Database.MaxIdleConns = cfg.Database.MaxOpenConns + 1
+				cfg.Redis.DB = 16
+			},
+			want: []string{"app.port", "database.max_idle_conns", "redis.db"},
+		},
+		{
+			name: "refresh tokens outlive access tokens",
+			mutate: func(cfg *Config) {
+				cfg.JWT.RefreshTokenExpiry = cfg.JWT.AccessTokenExpiry
+			},
+			want: []string{"jwt.refresh_token_expiry"},
+		},
+		{
+			name: "invalid job schedule",
+			mutate: func(cfg *Config) {
+				cfg.Jobs.Schedules = map[string]string{"surge_compute": "every minute", "push_receipts": "disabled"}
+			},
+			want: []string{"jobs.schedules.surge_compute"},
+		},
+		{
+			name: "admin port shared with the app",
+			mutate: func(cfg *Config) {
+				cfg.Admin.Enabled = true
+				cfg.Admin.Port = cfg.App.Port
+			},
+			want: []string{"admin.port"},
+		},
+		{
+			name: "production rejects development placeholders",
+			mutate: func(cfg *Config) {
+				cfg.App.Env = "production"
+			},
+			want: []string{"database.sslmode", "jwt.secret", "payments.gateway", "payments.webhook_secret", "expo_push.fake"},
+		},
+		{
+			name: "production rejects a weak secret",
+			mutate: func(cfg *Config) {
+				cfg.App.Env = "production"
+				cfg.Database.SSLMode = "require"
+				cfg.JWT.Secret = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+				cfg.Payments.Gateway = "checkout"
+				cfg.Payments.WebhookSecret = "whsec-3f9a1c"
+				cfg.ExpoPush.Fake = false
+				cfg.ExpoPush.Token = "expo-access-token"
+			},
+			want: []string{"jwt.secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadValid(t)
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			var paths []string
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				for _, fe := range verr.Errors {
+					paths = append(paths, fe.Path)
+				}
+			} else if err != nil {
+				t.Fatalf("Validate() returned %T %v, want a *ValidationError", err, err)
+			}
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("invalid settings = %v, want %v", paths, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := loadValid(t)
+	cfg.Database.Password = "db-pass"
+	cfg.GoogleMaps.APIKey = ""
+
+	redactedCfg := cfg.Redacted()
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "secret", got: redactedCfg.Database.Password, want: redacted},
+		{name: "nested secret", got: redactedCfg.JWT.Secret, want: redacted},
+		{name: "empty secret stays empty", got: redactedCfg.GoogleMaps.APIKey, want: ""},
+		{name: "other fields are kept", got: redactedCfg.Database.Host, want: cfg.Database.Host},
+		{name: "the original is unchanged", got: cfg.Database.Password, want: "db-pass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
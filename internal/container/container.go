@@ -1,63 +1,367 @@
 package container
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 )
 
-// Container is a simple dependency injection container
+var (
+	// ErrNotFound is returned when no provider is registered for a type and name
+	ErrNotFound = errors.New("service not registered")
+	// ErrDuplicate is returned when a type and name are registered twice
+	ErrDuplicate = errors.New("service already registered")
+	// ErrCycle is returned when a factory depends on itself, directly or indirectly
+	ErrCycle = errors.New("dependency cycle")
+	// ErrScopeRequired is returned when a scoped service is resolved outside a scope
+	ErrScopeRequired = errors.New("scoped service resolved outside a scope")
+	// ErrStopped is returned when resolving from a container that has been stopped
+	ErrStopped = errors.New("container stopped")
+)
+
+// Lifetime controls how often a provider's factory runs
+type Lifetime int
+
+const (
+	// Singleton services are built once per container
+	Singleton Lifetime = iota
+	// Scoped services are built once per scope
+	Scoped
+)
+
+// key identifies a service by its type and an optional name
+type key struct {
+	typ  reflect.Type
+	name string
+}
+
+func (k key) String() string {
+	if k.name == "" {
+		return k.typ.String()
+	}
+	return fmt.Sprintf("%s[%s]", k.typ, k.name)
+}
+
+// provider builds one service
+type provider struct {
+	key      key
+	lifetime Lifetime
+	factory  func(c *Container) (interface{}, error)
+	hooks    []Hook
+	// deps are the services declared with DependsOn
+	deps []key
+}
+
+// registry holds the providers and singletons shared by a container and its scopes
+type registry struct {
+	mu        sync.RWMutex
+	providers map[key]*provider
+	// order is the registration order, used to build singletons deterministically on Start
+	order []key
+	// build serialises factory calls so every singleton is built exactly once
+	build sync.Mutex
+	root  *instances
+	// errs collects registration errors, reported by Start
+	errs []error
+}
+
+// instances holds the services built for the root container or one scope
+type instances struct {
+	mu     sync.RWMutex
+	values map[key]interface{}
+	// built lists services in construction order: supplied values as they are
+	// supplied, and factory-built values after whatever their factory resolved
+	built   []built
+	started bool
+	stopped bool
+}
+
+type built struct {
+	key   key
+	value interface{}
+	hooks []Hook
+	// deps are the services this one uses: those its factory or the tracked
+	// container supplying it resolved, and those declared with DependsOn
+	deps []key
+}
+
+// Container is a typed dependency injection container. Services are keyed by
+// type and an optional name and built lazily by their factories, which resolve
+// their own dependencies from the container they are given.
 type Container struct {
-	services map[string]interface{}
-	mu       sync.RWMutex
+	reg   *registry
+	scope *instances
+	// path is the chain of services being built by the current resolution
+	path []key
+	// resolved records what is resolved through this container, for the service
+	// its factory builds or the values supplied through it; nil when untracked
+	resolved *[]key
 }
 
 // New creates a new container
 func New() *Container {
 	return &Container{
-		services: make(map[string]interface{}),
+		reg: &registry{
+			providers: make(map[key]*provider),
+			root:      &instances{values: make(map[key]interface{})},
+		},
 	}
 }
 
-// Register registers a service in the container
-func (c *Container) Register(name string, service interface{}) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.services[name] = service
+// Scope creates a child container. Singletons are shared with the parent;
+// scoped services are built once per scope and stopped with it.
+func (c *Container) Scope() *Container {
+	return &Container{
+		reg:   c.reg,
+		scope: &instances{values: make(map[key]interface{}), started: true},
+	}
 }
 
-// Get retrieves a service from the container
-func (c *Container) Get(name string) (interface{}, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// Tracked returns a view of the container that records what is resolved through
+// it. A value supplied through the view depends on everything resolved through it
+// before, so registration code that builds services by hand passes their
+// dependencies on to the lifecycle order.
+func (c *Container) Tracked() *Container {
+	return &Container{reg: c.reg, scope: c.scope, path: c.path, resolved: &[]key{}}
+}
 
-	service, exists := c.services[name]
-	if !exists {
-		return nil, fmt.Errorf("service %s not found", name)
+// Option configures a registration
+type Option func(*provider)
+
+// Named registers the service under a name, so several values of one type can coexist
+func Named(name string) Option {
+	return func(p *provider) {
+		p.key.name = name
 	}
+}
 
-	return service, nil
+// DependsOn declares that the service uses T, registered under opts, so T starts
+// before it and stops after it. Dependencies a factory resolves are recorded
+// without it; use it for values built outside the container.
+func DependsOn[T any](opts ...Option) Option {
+	dep := &provider{key: key{typ: typeOf[T]()}}
+	for _, opt := range opts {
+		opt(dep)
+	}
+	return func(p *provider) {
+		p.deps = append(p.deps, dep.key)
+	}
 }
 
-// Has checks if a service exists in the container
-func (c *Container) Has(name string) bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	_, exists := c.services[name]
-	return exists
+// AsScoped builds the service once per scope instead of once per container
+func AsScoped() Option {
+	return func(p *provider) {
+		p.lifetime = Scoped
+	}
+}
+
+// Provide registers a lazy factory for T. The factory runs on first resolution
+// and may resolve other services from the container it receives. Registration
+// mistakes such as duplicates are reported by Start.
+func Provide[T any](c *Container, factory func(c *Container) (T, error), opts ...Option) {
+	c.register(typeOf[T](), func(c *Container) (interface{}, error) {
+		return factory(c)
+	}, opts)
+}
+
+// Supply registers an already built value of T as a singleton
+func Supply[T any](c *Container, value T, opts ...Option) {
+	p := c.register(typeOf[T](), nil, opts)
+	if p == nil {
+		return
+	}
+	if p.lifetime == Scoped {
+		c.fail(fmt.Errorf("%s: supplied values cannot be scoped", p.key))
+		return
+	}
+	if err := c.reg.root.add(p, value, c.tracked()); err != nil {
+		c.fail(err)
+	}
 }
 
-// GetTyped retrieves a service with type assertion
-func GetTyped[T any](c *Container, name string) (T, error) {
+// Resolve returns the service registered for T, building it and its dependencies if needed
+func Resolve[T any](c *Container, opts ...Option) (T, error) {
 	var zero T
-	service, err := c.Get(name)
+	p := &provider{key: key{typ: typeOf[T]()}}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	value, err := c.resolve(p.key)
 	if err != nil {
 		return zero, err
 	}
+	return asType[T](value), nil
+}
+
+// MustResolve is like Resolve but panics on error. Use it only during wiring.
+func MustResolve[T any](c *Container, opts ...Option) T {
+	value, err := Resolve[T](c, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Has reports whether a provider is registered for T
+func Has[T any](c *Container, opts ...Option) bool {
+	p := &provider{key: key{typ: typeOf[T]()}}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	c.reg.mu.RLock()
+	defer c.reg.mu.RUnlock()
+	_, exists := c.reg.providers[p.key]
+	return exists
+}
+
+// register adds a provider and returns it, or records an error and returns nil
+func (c *Container) register(typ reflect.Type, factory func(c *Container) (interface{}, error), opts []Option) *provider {
+	p := &provider{key: key{typ: typ}, lifetime: Singleton, factory: factory}
+	for _, opt := range opts {
+		opt(p)
+	}
+	for _, hook := range p.hooks {
+		if hook.typ != typ {
+			c.fail(fmt.Errorf("%s: lifecycle hook is for %s", p.key, hook.typ))
+			return nil
+		}
+	}
+
+	c.reg.mu.Lock()
+	defer c.reg.mu.Unlock()
+	if _, exists := c.reg.providers[p.key]; exists {
+		c.reg.errs = append(c.reg.errs, fmt.Errorf("%s: %w", p.key, ErrDuplicate))
+		return nil
+	}
+	c.reg.providers[p.key] = p
+	c.reg.order = append(c.reg.order, p.key)
+
+	return p
+}
 
-	typed, ok := service.(T)
-	if !ok {
-		return zero, fmt.Errorf("service %s is not of expected type", name)
+func (c *Container) fail(err error) {
+	c.reg.mu.Lock()
+	defer c.reg.mu.Unlock()
+	c.reg.errs = append(c.reg.errs, err)
+}
+
+// resolve returns the instance for k and records it when the container is tracked
+func (c *Container) resolve(k key) (interface{}, error) {
+	value, err := c.lookup(k)
+	if err == nil && c.resolved != nil {
+		*c.resolved = append(*c.resolved, k)
 	}
+	return value, err
+}
+
+// tracked returns a copy of what has been resolved through the container
+func (c *Container) tracked() []key {
+	if c.resolved == nil {
+		return nil
+	}
+	return append([]key(nil), *c.resolved...)
+}
+
+// lookup returns the cached instance for k or builds it
+func (c *Container) lookup(k key) (interface{}, error) {
+	c.reg.mu.RLock()
+	p, exists := c.reg.providers[k]
+	c.reg.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", k, ErrNotFound)
+	}
+
+	target := c.reg.root
+	if p.lifetime == Scoped {
+		if c.scope == nil {
+			return nil, fmt.Errorf("%s: %w", k, ErrScopeRequired)
+		}
+		target = c.scope
+	}
+
+	if value, ok := target.get(k); ok {
+		return value, nil
+	}
+
+	for i, pending := range c.path {
+		if pending == k {
+			chain := make([]string, 0, len(c.path)-i+1)
+			for _, step := range c.path[i:] {
+				chain = append(chain, step.String())
+			}
+			chain = append(chain, k.String())
+			return nil, fmt.Errorf("%w: %s", ErrCycle, strings.Join(chain, " -> "))
+		}
+	}
+
+	// Only the outermost resolution takes the build lock; nested factory calls
+	// run on the same goroutine and already hold it
+	if len(c.path) == 0 {
+		c.reg.build.Lock()
+		defer c.reg.build.Unlock()
+
+		if value, ok := target.get(k); ok {
+			return value, nil
+		}
+	}
+
+	if target.isStopped() {
+		return nil, fmt.Errorf("%s: %w", k, ErrStopped)
+	}
+
+	// Singletons must not capture scoped services, so their factories see no scope
+	scope := c.scope
+	if p.lifetime == Singleton {
+		scope = nil
+	}
+	path := make([]key, len(c.path), len(c.path)+1)
+	copy(path, c.path)
+	child := &Container{reg: c.reg, scope: scope, path: append(path, k), resolved: &[]key{}}
+
+	value, err := p.factory(child)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s: %w", k, err)
+	}
+	if err := target.add(p, value, child.tracked()); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (in *instances) get(k key) (interface{}, bool) {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	value, ok := in.values[k]
+	return value, ok
+}
+
+func (in *instances) isStopped() bool {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	return in.stopped
+}
+
+// add caches a built value that used deps and starts it right away if its
+// container already started
+func (in *instances) add(p *provider, value interface{}, deps []key) error {
+	in.mu.Lock()
+	in.values[p.key] = value
+	in.built = append(in.built, built{key: p.key, value: value, hooks: p.hooks, deps: append(deps, p.deps...)})
+	started := in.started
+	in.mu.Unlock()
+
+	if started {
+		return runStart(context.Background(), p.key, value, p.hooks)
+	}
+	return nil
+}
 
-	return typed, nil
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
 }
@@ -0,0 +1,299 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type (
+	database struct{ dsn string }
+	cache    struct{ db *database }
+	service  struct{ cache *cache }
+	request  struct{ id int64 }
+	handler  struct{ req *request }
+)
+
+func TestResolve(t *testing.T) {
+	ctn := New()
+	var builds int32
+	Provide(ctn, func(c *Container) (*database, error) {
+		atomic.AddInt32(&builds, 1)
+		return &database{dsn: "postgres://"}, nil
+	})
+	Provide(ctn, func(c *Container) (*cache, error) {
+		db, err := Resolve[*database](c)
+		return &cache{db: db}, err
+	})
+
+	if atomic.LoadInt32(&builds) != 0 {
+		t.Fatal("factory ran before resolution")
+	}
+
+	first, err := Resolve[*cache](ctn)
+	if err != nil {
+		t.Fatalf("Resolve() returned %v", err)
+	}
+	second := MustResolve[*cache](ctn)
+	db := MustResolve[*database](ctn)
+
+	if first != second || first.db != db {
+		t.Error("singleton resolved to different instances")
+	}
+	if n := atomic.LoadInt32(&builds); n != 1 {
+		t.Errorf("database built %d times, want 1", n)
+	}
+}
+
+func TestResolveConcurrently(t *testing.T) {
+	ctn := New()
+	var builds int32
+	Provide(ctn, func(c *Container) (*database, error) {
+		atomic.AddInt32(&builds, 1)
+		return &database{}, nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]*database, 50)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = MustResolve[*database](ctn)
+		}(i)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&builds); n != 1 {
+		t.Errorf("database built %d times, want 1", n)
+	}
+	for _, db := range results {
+		if db != results[0] {
+			t.Fatal("concurrent resolutions returned different instances")
+		}
+	}
+}
+
+func TestNamed(t *testing.T) {
+	ctn := New()
+	Supply(ctn, &database{dsn: "primary"})
+	Supply(ctn, &database{dsn: "replica"}, Named("replica"))
+
+	primary := MustResolve[*database](ctn)
+	replica := MustResolve[*database](ctn, Named("replica"))
+	if primary.dsn != "primary" || replica.dsn != "replica" {
+		t.Errorf("resolved %q and %q, want primary and replica", primary.dsn, replica.dsn)
+	}
+	if !Has[*database](ctn, Named("replica")) || Has[*database](ctn, Named("archive")) {
+		t.Error("Has does not match the named registrations")
+	}
+}
+
+func TestMissingProvider(t *testing.T) {
+	ctn := New()
+	Provide(ctn, func(c *Container) (*service, error) {
+		cache, err := Resolve[*cache](c)
+		return &service{cache: cache}, err
+	})
+
+	tests := []struct {
+		name     string
+		resolve  func() error
+		contains string
+	}{
+		{
+			name:     "unregistered type",
+			resolve:  func() error { _, err := Resolve[*database](ctn); return err },
+			contains: "*container.database",
+		},
+		{
+			name:     "unregistered name",
+			resolve:  func() error { _, err := Resolve[*service](ctn, Named("other")); return err },
+			contains: "*container.service[other]",
+		},
+		{
+			name:     "unregistered dependency",
+			resolve:  func() error { _, err := Resolve[*service](ctn); return err },
+			contains: "failed to build *container.service: *container.cache",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.resolve()
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("error = %v, want ErrNotFound", err)
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("error %q does not name %q", err, tt.contains)
+			}
+		})
+	}
+}
+
+func TestCycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(ctn *Container)
+		chain string
+	}{
+		{
+			name: "self",
+			setup: func(ctn *Container) {
+				Provide(ctn, func(c *Container) (*database, error) {
+					return Resolve[*database](c)
+				})
+			},
+			chain: "*container.database -> *container.database",
+		},
+		{
+			name: "indirect",
+			setup: func(ctn *Container) {
+				Provide(ctn, func(c *Container) (*database, error) {
+					_, err := Resolve[*service](c)
+					return &database{}, err
+				})
+				Provide(ctn, func(c *Container) (*cache, error) {
+					db, err := Resolve[*database](c)
+					return &cache{db: db}, err
+				})
+				Provide(ctn, func(c *Container) (*service, error) {
+					cache, err := Resolve[*cache](c)
+					return &service{cache: cache}, err
+				})
+			},
+			chain: "*container.database -> *container.service -> *container.cache -> *container.database",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctn := New()
+			tt.setup(ctn)
+
+			err := ctn.Start(context.Background())
+			if !errors.Is(err, ErrCycle) {
+				t.Fatalf("Start() = %v, want ErrCycle", err)
+			}
+			if !strings.Contains(err.Error(), tt.chain) {
+				t.Errorf("error %q does not show the chain %q", err, tt.chain)
+			}
+		})
+	}
+}
+
+func TestRegistrationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(ctn *Container)
+		wantErr error
+	}{
+		{
+			name: "duplicate provider",
+			setup: func(ctn *Container) {
+				Supply(ctn, &database{})
+				Provide(ctn, func(c *Container) (*database, error) { return &database{}, nil })
+			},
+			wantErr: ErrDuplicate,
+		},
+		{
+			name: "duplicate name",
+			setup: func(ctn *Container) {
+				Supply(ctn, &database{}, Named("replica"))
+				Supply(ctn, &database{}, Named("replica"))
+			},
+			wantErr: ErrDuplicate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctn := New()
+			tt.setup(ctn)
+			if err := ctn.Start(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Start() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("scoped supply", func(t *testing.T) {
+		ctn := New()
+		Supply(ctn, &request{}, AsScoped())
+		if err := ctn.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "cannot be scoped") {
+			t.Errorf("Start() = %v, want a scoped supply error", err)
+		}
+	})
+
+	t.Run("hook for another type", func(t *testing.T) {
+		ctn := New()
+		Supply(ctn, &database{}, OnStart(func(ctx context.Context, c *cache) error { return nil }))
+		if err := ctn.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "lifecycle hook is for") {
+			t.Errorf("Start() = %v, want a hook type error", err)
+		}
+	})
+}
+
+func TestScopes(t *testing.T) {
+	ctn := New()
+	var nextID int64
+	Supply(ctn, &database{})
+	Provide(ctn, func(c *Container) (*request, error) {
+		return &request{id: atomic.AddInt64(&nextID, 1)}, nil
+	}, AsScoped())
+	Provide(ctn, func(c *Container) (*handler, error) {
+		req, err := Resolve[*request](c)
+		return &handler{req: req}, err
+	}, AsScoped())
+	// A singleton that captures a scoped service would leak it across scopes
+	Provide(ctn, func(c *Container) (*service, error) {
+		_, err := Resolve[*request](c)
+		return &service{}, err
+	})
+
+	if _, err := Resolve[*request](ctn); !errors.Is(err, ErrScopeRequired) {
+		t.Errorf("scoped from root = %v, want ErrScopeRequired", err)
+	}
+
+	first, second := ctn.Scope(), ctn.Scope()
+	a := MustResolve[*request](first)
+	if again := MustResolve[*request](first); again != a {
+		t.Error("scoped service built twice in one scope")
+	}
+	if h := MustResolve[*handler](first); h.req != a {
+		t.Error("scoped dependency not shared within the scope")
+	}
+	b := MustResolve[*request](second)
+	if a == b || a.id == b.id {
+		t.Error("scopes share a scoped service")
+	}
+
+	if MustResolve[*database](first) != MustResolve[*database](second) {
+		t.Error("scopes do not share singletons")
+	}
+	if _, err := Resolve[*service](first); !errors.Is(err, ErrScopeRequired) {
+		t.Errorf("singleton resolving a scoped service = %v, want ErrScopeRequired", err)
+	}
+
+	if err := first.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() returned %v", err)
+	}
+	if again := MustResolve[*request](first); again != a {
+		t.Error("stopped scope lost a built service")
+	}
+	unused := ctn.Scope()
+	if err := unused.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() returned %v", err)
+	}
+	if _, err := Resolve[*request](unused); !errors.Is(err, ErrStopped) {
+		t.Errorf("unbuilt service from a stopped scope = %v, want ErrStopped", err)
+	}
+	if _, err := Resolve[*handler](second); err != nil {
+		t.Errorf("stopping one scope affected another: %v", err)
+	}
+	if _, err := Resolve[*database](first); err != nil {
+		t.Errorf("stopping a scope affected singletons: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Hook is a lifecycle callback attached to a registration with OnStart or OnStop
+type Hook struct {
+	typ   reflect.Type
+	start func(ctx context.Context, value interface{}) error
+	stop  func(ctx context.Context, value interface{}) error
+}
+
+// OnStart runs fn when the container starts, after the services the value depends
+// on. Values built after Start are started as soon as they are built.
+func OnStart[T any](fn func(ctx context.Context, value T) error) Option {
+	return func(p *provider) {
+		p.hooks = append(p.hooks, Hook{
+			typ: typeOf[T](),
+			start: func(ctx context.Context, value interface{}) error {
+				return fn(ctx, asType[T](value))
+			},
+		})
+	}
+}
+
+// OnStop runs fn when the container or scope stops, before the services the value depends on
+func OnStop[T any](fn func(ctx context.Context, value T) error) Option {
+	return func(p *provider) {
+		p.hooks = append(p.hooks, Hook{
+			typ: typeOf[T](),
+			stop: func(ctx context.Context, value interface{}) error {
+				return fn(ctx, asType[T](value))
+			},
+		})
+	}
+}
+
+// Start reports registration errors, builds every singleton in registration order and
+// runs their start hooks in dependency order: a service starts after those its factory
+// resolved, those resolved through the tracked container it was supplied through and
+// those declared with DependsOn. Services with no dependency between them start in
+// construction order. If a hook fails, services already started are stopped again
+// in reverse order.
+func (c *Container) Start(ctx context.Context) error {
+	c.reg.mu.RLock()
+	errs := append([]error(nil), c.reg.errs...)
+	order := make([]key, 0, len(c.reg.order))
+	for _, k := range c.reg.order {
+		p := c.reg.providers[k]
+		for _, dep := range p.deps {
+			if _, exists := c.reg.providers[dep]; !exists {
+				errs = append(errs, fmt.Errorf("%s: depends on %s: %w", k, dep, ErrNotFound))
+			}
+		}
+		if p.lifetime == Singleton {
+			order = append(order, k)
+		}
+	}
+	c.reg.mu.RUnlock()
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, k := range order {
+		if _, err := c.resolve(k); err != nil {
+			return err
+		}
+	}
+
+	in := c.reg.root
+	in.mu.Lock()
+	if in.started {
+		in.mu.Unlock()
+		return nil
+	}
+	in.started = true
+	services := sorted(in.built)
+	in.mu.Unlock()
+
+	for i, svc := range services {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := runStart(ctx, svc.key, svc.value, svc.hooks); err != nil {
+			stopErr := runStops(ctx, services[:i])
+			return errors.Join(err, stopErr)
+		}
+	}
+
+	return nil
+}
+
+// Stop runs stop hooks in reverse dependency order, which is the reverse of the
+// order Start ran them in. On a scope only the scoped services are stopped.
+// Every hook runs even if an earlier one fails; the errors are joined.
+func (c *Container) Stop(ctx context.Context) error {
+	in := c.reg.root
+	if c.scope != nil {
+		in = c.scope
+	}
+
+	in.mu.Lock()
+	if in.stopped {
+		in.mu.Unlock()
+		return nil
+	}
+	in.stopped = true
+	started := in.started
+	services := sorted(in.built)
+	in.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	return runStops(ctx, services)
+}
+
+// sorted returns services in dependency order, keeping construction order between
+// services that do not depend on each other. Dependencies outside services, such
+// as the singletons a scoped service uses, are already running and are skipped.
+func sorted(services []built) []built {
+	index := make(map[key]int, len(services))
+	for i, svc := range services {
+		index[svc.key] = i
+	}
+
+	order := make([]built, 0, len(services))
+	visited := make([]bool, len(services))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		// Marking before the dependencies are visited stops a declared cycle from
+		// recursing forever; its services keep construction order
+		visited[i] = true
+		for _, dep := range services[i].deps {
+			if j, ok := index[dep]; ok {
+				visit(j)
+			}
+		}
+		order = append(order, services[i])
+	}
+	for i := range services {
+		visit(i)
+	}
+	return order
+}
+
+func runStart(ctx context.Context, k key, value interface{}, hooks []Hook) error {
+	for _, hook := range hooks {
+		if hook.start == nil {
+			continue
+		}
+		if err := hook.start(ctx, value); err != nil {
+			return fmt.Errorf("failed to start %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func runStops(ctx context.Context, services []built) error {
+	var errs []error
+	for i := len(services) - 1; i >= 0; i-- {
+		svc := services[i]
+		for _, hook := range svc.hooks {
+			if hook.stop == nil {
+				continue
+			}
+			if err := hook.stop(ctx, svc.value); err != nil {
+				errs = append(errs, fmt.Errorf("failed to stop %s: %w", svc.key, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// asType converts a stored value back to T, allowing nil interface and pointer values
+func asType[T any](value interface{}) T {
+	if value == nil {
+		var zero T
+		return zero
+	}
+	return value.(T)
+}
@@ -0,0 +1,285 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder logs lifecycle hook calls in the order they run
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls
+	r.calls = nil
+	return calls
+}
+
+// hooks returns start and stop hooks for T that record name and fail with the given errors
+func hooks[T any](r *recorder, name string, startErr, stopErr error) []Option {
+	return []Option{
+		OnStart(func(ctx context.Context, value T) error {
+			r.record("start " + name)
+			return startErr
+		}),
+		OnStop(func(ctx context.Context, value T) error {
+			r.record("stop " + name)
+			return stopErr
+		}),
+	}
+}
+
+// provideChain registers service -> cache -> database in reverse dependency order,
+// so construction order differs from registration order
+func provideChain(ctn *Container, r *recorder, startErrs map[string]error) {
+	Provide(ctn, func(c *Container) (*service, error) {
+		cache, err := Resolve[*cache](c)
+		return &service{cache: cache}, err
+	}, hooks[*service](r, "service", startErrs["service"], nil)...)
+	Provide(ctn, func(c *Container) (*cache, error) {
+		db, err := Resolve[*database](c)
+		return &cache{db: db}, err
+	}, hooks[*cache](r, "cache", startErrs["cache"], nil)...)
+	Provide(ctn, func(c *Container) (*database, error) {
+		return &database{}, nil
+	}, hooks[*database](r, "database", startErrs["database"], nil)...)
+}
+
+func TestStartStopOrder(t *testing.T) {
+	r := &recorder{}
+	ctn := New()
+	provideChain(ctn, r, nil)
+
+	if err := ctn.Start(context.Background()); err != nil {
+		t.Fatalf("Start() returned %v", err)
+	}
+	if got, want := r.take(), []string{"start database", "start cache", "start service"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("start order = %v, want %v", got, want)
+	}
+
+	// A second Start is a no-op
+	if err := ctn.Start(context.Background()); err != nil {
+		t.Fatalf("second Start() returned %v", err)
+	}
+	if got := r.take(); len(got) != 0 {
+		t.Errorf("second Start() ran hooks %v", got)
+	}
+
+	if err := ctn.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() returned %v", err)
+	}
+	if got, want := r.take(), []string{"stop service", "stop cache", "stop database"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stop order = %v, want %v", got, want)
+	}
+
+	if err := ctn.Stop(context.Background()); err != nil {
+		t.Fatalf("second Stop() returned %v", err)
+	}
+	if got := r.take(); len(got) != 0 {
+		t.Errorf("second Stop() ran hooks %v", got)
+	}
+}
+
+func TestSuppliedValuesStartInSupplyOrder(t *testing.T) {
+	r := &recorder{}
+	ctn := New()
+	Supply(ctn, &cache{}, hooks[*cache](r, "cache", nil, nil)...)
+	Supply(ctn, &database{}, hooks[*database](r, "database", nil, nil)...)
+
+	if err := ctn.Start(context.Background()); err != nil {
+		t.Fatalf("Start() returned %v", err)
+	}
+	if err := ctn.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() returned %v", err)
+	}
+
+	want := []string{"start cache", "start database", "stop database", "stop cache"}
+	if got := r.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("hooks = %v, want %v", got, want)
+	}
+}
+
+func TestStartFollowsDependencies(t *testing.T) {
+	r := &recorder{}
+	ctn := New()
+
+	// Registered and supplied before what it uses, which a tracked container
+	// records for cache and DependsOn declares for service
+	Supply(ctn, &service{}, append(hooks[*service](r, "service", nil, nil), DependsOn[*cache]())...)
+	Provide(ctn, func(c *Container) (*database, error) {
+		return &database{}, nil
+	}, hooks[*database](r, "database", nil, nil)...)
+
+	tracked := ctn.Tracked()
+	db, err := Resolve[*database](tracked)
+	if err != nil {
+		t.Fatalf("Resolve() returned %v", err)
+	}
+	Supply(tracked, &cache{db: db}, hooks[*cache](r, "cache", nil, nil)...)
+
+	if err := ctn.Start(context.Background()); err != nil {
+		t.Fatalf("Start() returned %v", err)
+	}
+	if err := ctn.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() returned %v", err)
+	}
+
+	want := []string{"start database", "start cache", "start service", "stop service", "stop cache", "stop database"}
+	if got := r.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("hooks = %v, want %v", got, want)
+	}
+}
+
+func TestStartUnknownDependency(t *testing.T) {
+	ctn := New()
+	Supply(ctn, &service{}, DependsOn[*cache]())
+
+	if err := ctn.Start(context.Background()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Start() returned %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestStartRollback(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		failing   string
+		wantCalls []string
+	}{
+		{
+			name:      "first service fails",
+			failing:   "database",
+			wantCalls: []string{"start database"},
+		},
+		{
+			name:      "middle service fails",
+			failing:   "cache",
+			wantCalls: []string{"start database", "start cache", "stop database"},
+		},
+		{
+			name:      "last service fails",
+			failing:   "service",
+			wantCalls: []string{"start database", "start cache", "start service", "stop cache", "stop database"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			ctn := New()
+			provideChain(ctn, r, map[string]error{tt.failing: errBoom})
+
+			err := ctn.Start(context.Background())
+			if !errors.Is(err, errBoom) {
+				t.Fatalf("Start() = %v, want the hook's error", err)
+			}
+			if !strings.Contains(err.Error(), "failed to start *container."+tt.failing) {
+				t.Errorf("error %q does not name the failing service", err)
+			}
+			if got := r.take(); !reflect.DeepEqual(got, tt.wantCalls) {
+				t.Errorf("hooks = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestStartCancelled(t *testing.T) {
+	r := &recorder{}
+	ctn := New()
+	provideChain(ctn, r, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ctn.Start(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Start() = %v, want context.Canceled", err)
+	}
+	if got := r.take(); len(got) != 0 {
+		t.Errorf("cancelled Start() ran hooks %v", got)
+	}
+}
+
+func TestStopRunsEveryHook(t *testing.T) {
+	errCache, errDatabase := errors.New("cache stuck"), errors.New("database stuck")
+	r := &recorder{}
+	ctn := New()
+	Supply(ctn, &database{}, hooks[*database](r, "database", nil, errDatabase)...)
+	Supply(ctn, &cache{}, hooks[*cache](r, "cache", nil, errCache)...)
+	Supply(ctn, &service{}, hooks[*service](r, "service", nil, nil)...)
+
+	if err := ctn.Start(context.Background()); err != nil {
+		t.Fatalf("Start() returned %v", err)
+	}
+	r.take()
+
+	err := ctn.Stop(context.Background())
+	if !errors.Is(err, errCache) || !errors.Is(err, errDatabase) {
+		t.Errorf("Stop() = %v, want both hook errors", err)
+	}
+	if got, want := r.take(), []string{"stop service", "stop cache", "stop database"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stop order = %v, want %v", got, want)
+	}
+}
+
+func TestStopBeforeStart(t *testing.T) {
+	r := &recorder{}
+	ctn := New()
+	provideChain(ctn, r, nil)
+	MustResolve[*service](ctn)
+
+	if err := ctn.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() returned %v", err)
+	}
+	if got := r.take(); len(got) != 0 {
+		t.Errorf("Stop() before Start() ran hooks %v", got)
+	}
+}
+
+func TestBuiltAfterStart(t *testing.T) {
+	r := &recorder{}
+	ctn := New()
+	Supply(ctn, &database{}, hooks[*database](r, "database", nil, nil)...)
+	Provide(ctn, func(c *Container) (*request, error) {
+		return &request{}, nil
+	}, append(hooks[*request](r, "request", nil, nil), AsScoped())...)
+
+	if err := ctn.Start(context.Background()); err != nil {
+		t.Fatalf("Start() returned %v", err)
+	}
+	r.take()
+
+	scope := ctn.Scope()
+	MustResolve[*request](scope)
+	if got, want := r.take(), []string{"start request"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hooks on build = %v, want %v", got, want)
+	}
+
+	// Stopping a scope stops only its own services
+	if err := scope.Stop(context.Background()); err != nil {
+		t.Fatalf("scope Stop() returned %v", err)
+	}
+	if got, want := r.take(), []string{"stop request"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("scope stop = %v, want %v", got, want)
+	}
+
+	if err := ctn.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() returned %v", err)
+	}
+	if got, want := r.take(), []string{"stop database"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("root stop = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/logger"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger writes GORM logs through the application logger, so SQL entries carry
+// the request context's fields. Failed queries log at ERROR, queries slower than the
+// threshold at WARN and, with debug logging, every query at DEBUG.
+type GormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+	logParams     bool
+}
+
+// NewGormLogger creates a GORM logger whose verbosity follows the application log level
+func NewGormLogger(logging config.LoggingConfig, database config.DatabaseConfig) *GormLogger {
+	return &GormLogger{
+		level:         gormLevel(logging.Level),
+		slowThreshold: database.SlowQueryThreshold,
+		logParams:     database.LogQueryParams,
+	}
+}
+
+// gormLevel maps the application log level to GORM's: debug logs every query,
+// info and warn log slow and failed queries, error logs failures only
+func gormLevel(level string) gormlogger.LogLevel {
+	switch level {
+	case "debug":
+		return gormlogger.Info
+	case "error":
+		return gormlogger.Error
+	case "fatal":
+		return gormlogger.Silent
+	default:
+		return gormlogger.Warn
+	}
+}
+
+// LogMode returns a copy of the logger at the given level
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs GORM informational messages
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logger.FromContext(ctx).Info(fmt.Sprintf(msg, data...), nil)
+	}
+}
+
+// Warn logs GORM warnings
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logger.FromContext(ctx).Warn(fmt.Sprintf(msg, data...), nil)
+	}
+}
+
+// Error logs GORM errors
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logger.FromContext(ctx).Error(fmt.Sprintf(msg, data...), nil)
+	}
+}
+
+// Trace logs one executed statement
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	log := logger.FromContext(ctx)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		log.Error("Database query failed", map[string]interface{}{
+			"error":       err.Error(),
+			"sql":         sql,
+			"rows":        rows,
+			"duration_ms": elapsed.Milliseconds(),
+		})
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log.Warn("Slow database query", map[string]interface{}{
+			"sql":          sql,
+			"rows":         rows,
+			"duration_ms":  elapsed.Milliseconds(),
+			"threshold_ms": l.slowThreshold.Milliseconds(),
+		})
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		log.Debug("Database query", map[string]interface{}{
+			"sql":         sql,
+			"rows":        rows,
+			"duration_ms": elapsed.Milliseconds(),
+		})
+	}
+}
+
+// ParamsFilter keeps bound values such as phone numbers and OTPs out of logged SQL
+// unless LogQueryParams is set
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.logParams {
+		return sql, params
+	}
+	return sql, nil
+}
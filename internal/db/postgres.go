@@ -8,7 +8,7 @@ import (
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 // InitPostgres initializes PostgreSQL connection
@@ -16,7 +16,7 @@ func InitPostgres(cfg *config.Config) (*gorm.DB, error) {
 	dsn := cfg.Database.DSN()
 
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: NewGormLogger(cfg.Logging, cfg.Database),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
@@ -27,6 +27,16 @@ func InitPostgres(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Trace queries as spans of the calling request or job; bound values stay out
+	// of spans for the same reason they stay out of logs
+	if err := db.Use(gormtracing.NewPlugin(
+		gormtracing.WithDBName(cfg.Database.Name),
+		gormtracing.WithoutMetrics(),
+		gormtracing.WithoutQueryVariables(),
+	)); err != nil {
+		return nil, fmt.Errorf("failed to enable query tracing: %w", err)
+	}
+
 	// Get underlying sql.DB to configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"theb-backend/internal/config"
+	"theb-backend/internal/tracing"
 
 	"github.com/go-redis/redis/v8"
 )
@@ -18,6 +19,8 @@ func InitRedis(cfg *config.Config) (*redis.Client, error) {
 		PoolSize: cfg.Redis.PoolSize,
 	})
 
+	client.AddHook(tracing.RedisHook{})
+
 	// Test connection
 	ctx := context.Background()
 	if err := client.Ping(ctx).Err(); err != nil {
@@ -0,0 +1,180 @@
+package errreport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"theb-backend/internal/config"
+)
+
+// Reporters selectable in config
+const (
+	ReporterNone = "none"
+	ReporterFile = "file"
+)
+
+// Event levels
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// Reporter sends error events to an error tracker. Implementations must be safe
+// for concurrent use and should not block the request for long.
+type Reporter interface {
+	Report(ctx context.Context, event *Event) error
+	Close() error
+}
+
+// Event is an error occurrence. Fields and JSON names follow Sentry's event
+// payload, so a Sentry client can forward events without reshaping them.
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Exception   []Exception       `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	User        *User             `json:"user,omitempty"`
+	Request     *Request          `json:"request,omitempty"`
+}
+
+// Exception is an error or panic value with the stack it was raised on
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace lists frames oldest call first, as Sentry expects
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is one call in a Stacktrace
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// User identifies the user the event happened for
+type User struct {
+	ID string `json:"id"`
+}
+
+// Request describes the HTTP request the event happened in
+type Request struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	QueryString string `json:"query_string,omitempty"`
+}
+
+// NewPanicEvent creates an event for a recovered panic value. It must be called
+// from the deferred function that recovered, so the stack still holds the panic site.
+func NewPanicEvent(recovered interface{}) *Event {
+	return &Event{
+		EventID:   newEventID(),
+		Timestamp: time.Now().UTC(),
+		Level:     LevelFatal,
+		Platform:  "go",
+		Message:   fmt.Sprint(recovered),
+		Exception: []Exception{{
+			Type:       fmt.Sprintf("%T", recovered),
+			Value:      fmt.Sprint(recovered),
+			Stacktrace: panicStacktrace(),
+		}},
+		Tags: make(map[string]string),
+	}
+}
+
+// New creates the reporter selected in config
+func New(cfg config.ErrorsConfig, app config.AppConfig) (Reporter, error) {
+	switch cfg.Reporter {
+	case "", ReporterNone:
+		return Nop{}, nil
+	case ReporterFile:
+		return NewFileReporter(cfg.File, app.Env)
+	default:
+		return nil, fmt.Errorf("unsupported error reporter: %s", cfg.Reporter)
+	}
+}
+
+// Nop discards every event
+type Nop struct{}
+
+// Report discards the event
+func (Nop) Report(context.Context, *Event) error { return nil }
+
+// Close does nothing
+func (Nop) Close() error { return nil }
+
+// appModule prefixes functions that belong to this application rather than a dependency
+const appModule = "theb-backend/"
+
+// panicStacktrace returns the frames from the panic site outwards, skipping the
+// runtime's panic machinery and the recovering function
+func panicStacktrace() *Stacktrace {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var all []runtime.Frame
+	panicAt := -1
+	for {
+		frame, more := frames.Next()
+		all = append(all, frame)
+		if frame.Function == "runtime.gopanic" {
+			panicAt = len(all)
+		}
+		if !more {
+			break
+		}
+	}
+	if panicAt > 0 {
+		all = all[panicAt:]
+	}
+
+	// Sentry orders frames oldest first
+	trace := &Stacktrace{Frames: make([]Frame, 0, len(all))}
+	for i := len(all) - 1; i >= 0; i-- {
+		frame := all[i]
+		trace.Frames = append(trace.Frames, Frame{
+			Function: frame.Function,
+			Module:   packageOf(frame.Function),
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    strings.HasPrefix(frame.Function, appModule),
+		})
+	}
+	return trace
+}
+
+// packageOf returns the package path of a fully qualified function name,
+// e.g. "theb-backend/internal/router" for "theb-backend/internal/router.New.func1"
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// newEventID returns a random 32 hex character ID, the format Sentry uses
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,60 @@
+package errreport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileReporter appends events to a local file as JSON lines, for development
+// where there is no error tracker
+type FileReporter struct {
+	mu          sync.Mutex
+	file        *os.File
+	environment string
+	serverName  string
+}
+
+// NewFileReporter opens path for appending, creating it and its directory if needed
+func NewFileReporter(path, environment string) (*FileReporter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create error report directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open error report file: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	return &FileReporter{file: file, environment: environment, serverName: hostname}, nil
+}
+
+// Report writes the event as one line
+func (r *FileReporter) Report(_ context.Context, event *Event) error {
+	if event.Environment == "" {
+		event.Environment = r.environment
+	}
+	if event.ServerName == "" {
+		event.ServerName = r.serverName
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode error event: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file
+func (r *FileReporter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
@@ -0,0 +1,171 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"theb-backend/internal/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Handler processes one event inside a transaction that also records the event
+// as processed for the handler's consumer. Returning an error rolls both back
+// and the event is redelivered. Writes made through tx commit exactly once with
+// the record; anything else the handler does may repeat and must be idempotent,
+// e.g. keyed on EventID.
+type Handler func(ctx context.Context, tx *gorm.DB, env Envelope) error
+
+type subscription struct {
+	consumer string
+	handler  Handler
+}
+
+// Bus dispatches events arriving from the transport to subscribed consumers.
+// Delivery is at-least-once. A handler that succeeds is not run again for the
+// same event, since its processed_events row commits with its writes through tx;
+// a handler that fails, or whose transaction fails to commit, runs again.
+type Bus struct {
+	db        *gorm.DB
+	transport Transport
+
+	mu            sync.RWMutex
+	subscriptions map[string][]subscription
+
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running atomic.Bool
+}
+
+// NewBus creates an event bus that consumes from transport
+func NewBus(db *gorm.DB, transport Transport) *Bus {
+	return &Bus{
+		db:            db,
+		transport:     transport,
+		subscriptions: make(map[string][]subscription),
+	}
+}
+
+// Subscribe registers handler for an event type under a consumer name. The
+// consumer name is the idempotency scope and must stay stable across releases.
+func (b *Bus) Subscribe(consumer, eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions[eventType] = append(b.subscriptions[eventType], subscription{consumer: consumer, handler: handler})
+}
+
+// On subscribes a handler that receives the decoded event
+func On[T Event](b *Bus, consumer string, handler func(ctx context.Context, tx *gorm.DB, event T) error) {
+	var zero T
+	b.Subscribe(consumer, zero.EventType(), func(ctx context.Context, tx *gorm.DB, env Envelope) error {
+		event, err := Decode[T](env)
+		if err != nil {
+			return err
+		}
+		return handler(ctx, tx, event)
+	})
+}
+
+// Dispatch runs every subscription for the envelope's type. Consumers that
+// already processed the event are skipped; failures are returned together so
+// the transport redelivers and only the failed consumers run again.
+func (b *Bus) Dispatch(ctx context.Context, env Envelope) error {
+	b.mu.RLock()
+	subscriptions := b.subscriptions[env.Type]
+	b.mu.RUnlock()
+
+	// Events belong to a ride, so consumer logs carry its ID
+	ctx = logger.WithRideID(ctx, env.AggregateID.String())
+	ctx = context.WithValue(ctx, eventIDKey{}, env.ID)
+
+	var errs []error
+	for _, sub := range subscriptions {
+		if err := b.process(ctx, sub, env); err != nil {
+			logger.FromContext(ctx).Error("Event consumer failed", map[string]interface{}{
+				"error":    err.Error(),
+				"consumer": sub.consumer,
+				"event_id": env.ID.String(),
+				"type":     env.Type,
+			})
+			errs = append(errs, fmt.Errorf("%s: %w", sub.consumer, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Bus) process(ctx context.Context, sub subscription, env Envelope) error {
+	return b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ProcessedEvent{
+			Consumer:    sub.consumer,
+			EventID:     env.ID,
+			ProcessedAt: time.Now().UTC(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		return sub.handler(ctx, tx, env)
+	})
+}
+
+type eventIDKey struct{}
+
+// EventID returns the ID of the event being handled, which handlers use to make
+// side effects outside tx idempotent
+func EventID(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(eventIDKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// Start consumes envelopes from the transport and dispatches them until Stop
+func (b *Bus) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+
+	b.running.Store(true)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer b.running.Store(false)
+		if err := b.transport.Run(ctx, b.Dispatch); err != nil {
+			logger.Error("Event transport stopped", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+// Running reports whether the bus is consuming from its transport
+func (b *Bus) Running() bool {
+	return b.running.Load()
+}
+
+// Stop stops consuming and waits for the event being dispatched
+func (b *Bus) Stop(ctx context.Context) error {
+	if b.cancel != nil {
+		b.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
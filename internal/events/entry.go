@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/jobs"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// RegisterService provides the outbox, event bus, transport and relay and schedules the
+// outbox cleanup job. It must run after the scheduler and before the service modules,
+// which subscribe to the bus while registering. The bus and relay start with the container.
+func RegisterService(ctn *container.Container) error {
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	scheduler, err := container.Resolve[*jobs.Scheduler](ctn)
+	if err != nil {
+		return err
+	}
+
+	if err := db.AutoMigrate(&OutboxEvent{}, &ProcessedEvent{}, &DeadLetterEvent{}); err != nil {
+		return fmt.Errorf("failed to migrate event tables: %w", err)
+	}
+
+	container.Provide(ctn, func(ctn *container.Container) (Transport, error) {
+		cfg, err := container.Resolve[*config.Config](ctn)
+		if err != nil {
+			return nil, err
+		}
+		redisClient, err := container.Resolve[*redis.Client](ctn)
+		if err != nil {
+			return nil, err
+		}
+
+		if redisClient != nil {
+			return NewRedisStreamTransport(redisClient, db, cfg.Events.StreamMaxLen, cfg.Events.ClaimIdle), nil
+		}
+		return NewLocalTransport(db), nil
+	})
+
+	container.Provide(ctn, func(ctn *container.Container) (*Outbox, error) {
+		return NewOutbox(), nil
+	})
+
+	container.Provide(ctn, func(ctn *container.Container) (*Bus, error) {
+		transport, err := container.Resolve[Transport](ctn)
+		if err != nil {
+			return nil, err
+		}
+		return NewBus(db, transport), nil
+	},
+		container.OnStart(func(ctx context.Context, bus *Bus) error {
+			bus.Start()
+			return nil
+		}),
+		container.OnStop(func(ctx context.Context, bus *Bus) error {
+			return bus.Stop(ctx)
+		}),
+	)
+
+	container.Provide(ctn, func(ctn *container.Container) (*Relay, error) {
+		cfg, err := container.Resolve[*config.Config](ctn)
+		if err != nil {
+			return nil, err
+		}
+		transport, err := container.Resolve[Transport](ctn)
+		if err != nil {
+			return nil, err
+		}
+		return NewRelay(db, transport, cfg.Events), nil
+	},
+		container.OnStart(func(ctx context.Context, relay *Relay) error {
+			relay.Start()
+			return nil
+		}),
+		container.OnStop(func(ctx context.Context, relay *Relay) error {
+			return relay.Stop(ctx)
+		}),
+	)
+
+	relay, err := container.Resolve[*Relay](ctn)
+	if err != nil {
+		return err
+	}
+
+	return scheduler.Schedule(jobs.CronJob{
+		Name:     "outbox_cleanup",
+		Schedule: "@hourly",
+		Run:      relay.Cleanup,
+	})
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a domain event recorded in the outbox
+type Event interface {
+	EventType() string
+}
+
+// Event types
+const (
+	TypeRideRequested  = "ride.requested"
+	TypeRideMatched    = "ride.matched"
+	TypeRideCompleted  = "ride.completed"
+	TypePaymentSettled = "payment.settled"
+)
+
+// RideRequested is recorded when a passenger requests a ride
+type RideRequested struct {
+	RideID       uuid.UUID `json:"ride_id"`
+	PassengerID  uuid.UUID `json:"passenger_id"`
+	PickupLat    float64   `json:"pickup_lat"`
+	PickupLng    float64   `json:"pickup_lng"`
+	DropoffLat   float64   `json:"dropoff_lat"`
+	DropoffLng   float64   `json:"dropoff_lng"`
+	FareEstimate int64     `json:"fare_estimate"`
+}
+
+// EventType implements Event
+func (RideRequested) EventType() string { return TypeRideRequested }
+
+// RideMatched is recorded when a captain accepts a ride
+type RideMatched struct {
+	RideID        uuid.UUID `json:"ride_id"`
+	PassengerID   uuid.UUID `json:"passenger_id"`
+	CaptainID     uuid.UUID `json:"captain_id"`
+	CaptainUserID uuid.UUID `json:"captain_user_id"`
+	CaptainName   string    `json:"captain_name"`
+	Vehicle       string    `json:"vehicle"`
+	// RequestedAt is when the passenger requested the ride
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// EventType implements Event
+func (RideMatched) EventType() string { return TypeRideMatched }
+
+// RideCompleted is recorded when a ride reaches the completed status
+type RideCompleted struct {
+	RideID        uuid.UUID `json:"ride_id"`
+	PassengerID   uuid.UUID `json:"passenger_id"`
+	CaptainID     uuid.UUID `json:"captain_id"`
+	CaptainUserID uuid.UUID `json:"captain_user_id"`
+	Fare          int64     `json:"fare"`
+	PaymentMethod string    `json:"payment_method"`
+}
+
+// EventType implements Event
+func (RideCompleted) EventType() string { return TypeRideCompleted }
+
+// PaymentSettled is recorded when a ride's payment has been captured
+type PaymentSettled struct {
+	PaymentID   uuid.UUID `json:"payment_id"`
+	RideID      uuid.UUID `json:"ride_id"`
+	PassengerID uuid.UUID `json:"passenger_id"`
+	Amount      int64     `json:"amount"`
+	Method      string    `json:"method"`
+}
+
+// EventType implements Event
+func (PaymentSettled) EventType() string { return TypePaymentSettled }
+
+// Envelope is an event as it travels from the outbox to consumers.
+// ID is the outbox row ID and is what consumers deduplicate on.
+type Envelope struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID uuid.UUID       `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+// Decode unmarshals the envelope's payload into a typed event
+func Decode[T Event](env Envelope) (T, error) {
+	var event T
+	if env.Type != event.EventType() {
+		return event, fmt.Errorf("event %s is %s, not %s", env.ID, env.Type, event.EventType())
+	}
+	if err := json.Unmarshal(env.Payload, &event); err != nil {
+		return event, fmt.Errorf("invalid %s payload: %w", env.Type, err)
+	}
+	return event, nil
+}
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is an event written in the same transaction as the change it describes
+// and published by the relay afterwards
+type OutboxEvent struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Type        string     `gorm:"type:varchar(64);not null"`
+	AggregateID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Payload     []byte     `gorm:"type:jsonb;not null"`
+	Attempts    int        `gorm:"not null;default:0"`
+	LastError   string     `gorm:"type:text"`
+	CreatedAt   time.Time  `gorm:"not null"`
+	PublishedAt *time.Time `gorm:"index:idx_outbox_events_unpublished,where:published_at IS NULL"`
+}
+
+// TableName overrides the default table name
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// ProcessedEvent records that a consumer has handled an event, making redeliveries no-ops
+type ProcessedEvent struct {
+	Consumer    string    `gorm:"type:varchar(64);primaryKey"`
+	EventID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProcessedAt time.Time `gorm:"not null;index"`
+}
+
+// TableName overrides the default table name
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
+
+// DeadLetterEvent is an event the transport gave up delivering. Its outbox row is
+// already published, so this is the only record left of the consumers it missed.
+type DeadLetterEvent struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Type        string    `gorm:"type:varchar(64);not null"`
+	AggregateID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Payload     []byte    `gorm:"type:jsonb;not null"`
+	Attempts    int       `gorm:"not null"`
+	LastError   string    `gorm:"type:text"`
+	OccurredAt  time.Time `gorm:"not null"`
+	FailedAt    time.Time `gorm:"not null;index"`
+}
+
+// TableName overrides the default table name
+func (DeadLetterEvent) TableName() string {
+	return "dead_letter_events"
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outbox records domain events inside the caller's transaction
+type Outbox struct{}
+
+// NewOutbox creates a new outbox
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+// Add stores event in tx. It is published only if tx commits, so the change and
+// its event are never observed without each other.
+func (o *Outbox) Add(tx *gorm.DB, aggregateID uuid.UUID, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.EventType(), err)
+	}
+
+	return tx.Create(&OutboxEvent{
+		ID:          uuid.New(),
+		Type:        event.EventType(),
+		AggregateID: aggregateID,
+		Payload:     payload,
+		CreatedAt:   time.Now().UTC(),
+	}).Error
+}
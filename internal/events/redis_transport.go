@@ -0,0 +1,260 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"theb-backend/internal/logger"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const (
+	// streamName is the Redis stream carrying domain events
+	streamName = "events:domain"
+	// groupName is the consumer group shared by all instances, so each event
+	// is handled by one of them
+	groupName = "theb-backend"
+	// payloadField holds the JSON envelope inside a stream entry
+	payloadField = "envelope"
+
+	readCount = 50
+	readBlock = 5 * time.Second
+	// streamRetries bounds deliveries of one entry, counting reclaims
+	streamRetries = 5
+)
+
+// RedisStreamTransport carries envelopes between instances over a Redis stream.
+// Entries stay pending until acknowledged; entries left pending by a crashed or
+// failing consumer are reclaimed after ClaimIdle and delivered again, until an
+// entry that still fails after streamRetries deliveries is dead-lettered.
+type RedisStreamTransport struct {
+	client    *redis.Client
+	db        *gorm.DB
+	consumer  string
+	maxLen    int64
+	claimIdle time.Duration
+}
+
+// NewRedisStreamTransport creates a Redis Streams transport that dead-letters into db.
+// The stream is trimmed once it is longer than maxLen, but never past an entry that
+// is still unacknowledged; claimIdle is how long an entry may stay unacknowledged
+// before another consumer takes it over.
+func NewRedisStreamTransport(client *redis.Client, db *gorm.DB, maxLen int64, claimIdle time.Duration) *RedisStreamTransport {
+	host, _ := os.Hostname()
+	return &RedisStreamTransport{
+		client:    client,
+		db:        db,
+		consumer:  fmt.Sprintf("%s-%d", host, os.Getpid()),
+		maxLen:    maxLen,
+		claimIdle: claimIdle,
+	}
+}
+
+// Publish appends the envelope to the stream. It does not trim: a length cap on
+// XADD can drop entries that were never acknowledged.
+func (t *RedisStreamTransport) Publish(ctx context.Context, env Envelope) error {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	return t.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamName,
+		Values: map[string]interface{}{payloadField: data},
+	}).Err()
+}
+
+// Run reads new entries for this consumer and periodically reclaims stale ones and trims the stream
+func (t *RedisStreamTransport) Run(ctx context.Context, deliver DeliverFunc) error {
+	err := t.client.XGroupCreateMkStream(ctx, streamName, groupName, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create event consumer group: %w", err)
+	}
+
+	lastClaim := time.Now()
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= t.claimIdle {
+			t.reclaim(ctx, deliver)
+			t.trim(ctx)
+			lastClaim = time.Now()
+		}
+
+		streams, err := t.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    groupName,
+			Consumer: t.consumer,
+			Streams:  []string{streamName, ">"},
+			Count:    readCount,
+			Block:    readBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			logger.Warn("Failed to read event stream", map[string]interface{}{
+				"error": err.Error(),
+			})
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		for _, stream := range streams {
+			t.handle(ctx, stream.Messages, deliver)
+		}
+	}
+
+	return nil
+}
+
+// reclaim takes over entries other consumers left unacknowledged for longer than claimIdle
+func (t *RedisStreamTransport) reclaim(ctx context.Context, deliver DeliverFunc) {
+	start := "0-0"
+	for {
+		messages, next, err := t.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   streamName,
+			Group:    groupName,
+			MinIdle:  t.claimIdle,
+			Start:    start,
+			Count:    readCount,
+			Consumer: t.consumer,
+		}).Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("Failed to reclaim pending events", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			return
+		}
+
+		t.handle(ctx, messages, deliver)
+		if next == "0-0" || len(messages) == 0 {
+			return
+		}
+		start = next
+	}
+}
+
+// handle delivers stream entries and acknowledges those that succeeded or cannot be decoded
+func (t *RedisStreamTransport) handle(ctx context.Context, messages []redis.XMessage, deliver DeliverFunc) {
+	for _, message := range messages {
+		raw, _ := message.Values[payloadField].(string)
+
+		var env Envelope
+		if err := json.Unmarshal([]byte(raw), &env); err != nil {
+			logger.Error("Discarding malformed event", map[string]interface{}{
+				"error":     err.Error(),
+				"stream_id": message.ID,
+			})
+			t.ack(ctx, message.ID)
+			continue
+		}
+
+		if err := deliver(ctx, env); err != nil {
+			t.failed(ctx, message.ID, env, err)
+			continue
+		}
+		t.ack(ctx, message.ID)
+	}
+}
+
+// failed leaves an entry pending, to be reclaimed and retried after claimIdle, until
+// it has been delivered streamRetries times; it is then dead-lettered and acknowledged.
+// An entry whose dead-letter row cannot be written stays pending.
+func (t *RedisStreamTransport) failed(ctx context.Context, id string, env Envelope, cause error) {
+	pending, err := t.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: streamName,
+		Group:  groupName,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to read event delivery count", map[string]interface{}{
+				"error":     err.Error(),
+				"stream_id": id,
+			})
+		}
+		return
+	}
+
+	deliveries := pending[0].RetryCount
+	if deliveries < streamRetries {
+		return
+	}
+
+	if err := deadLetter(ctx, t.db, env, int(deliveries), cause); err != nil {
+		logger.Error("Failed to dead-letter event, leaving it pending", map[string]interface{}{
+			"error":             cause.Error(),
+			"dead_letter_error": err.Error(),
+			"event_id":          env.ID.String(),
+			"stream_id":         id,
+		})
+		return
+	}
+	t.ack(ctx, id)
+}
+
+// trim removes acknowledged entries once the stream is longer than maxLen. Entries
+// from the oldest one still pending onwards are kept, and with none pending, those
+// from the group's last delivered entry onwards, which may not have been read yet.
+func (t *RedisStreamTransport) trim(ctx context.Context) {
+	length, err := t.client.XLen(ctx, streamName).Result()
+	if err != nil || length <= t.maxLen {
+		return
+	}
+
+	minID, err := t.oldestUnacknowledged(ctx)
+	if err == nil && minID != "" {
+		err = t.client.XTrimMinID(ctx, streamName, minID).Err()
+	}
+	if err != nil && ctx.Err() == nil {
+		logger.Warn("Failed to trim event stream", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// oldestUnacknowledged returns the ID of the oldest entry the group may still need
+func (t *RedisStreamTransport) oldestUnacknowledged(ctx context.Context) (string, error) {
+	pending, err := t.client.XPending(ctx, streamName, groupName).Result()
+	if err != nil {
+		return "", err
+	}
+	if pending.Count > 0 {
+		return pending.Lower, nil
+	}
+
+	groups, err := t.client.XInfoGroups(ctx, streamName).Result()
+	if err != nil {
+		return "", err
+	}
+	for _, group := range groups {
+		if group.Name == groupName {
+			return group.LastDeliveredID, nil
+		}
+	}
+	return "", nil
+}
+
+func (t *RedisStreamTransport) ack(ctx context.Context, id string) {
+	if err := t.client.XAck(ctx, streamName, groupName, id).Err(); err != nil {
+		logger.Warn("Failed to acknowledge event", map[string]interface{}{
+			"error":     err.Error(),
+			"stream_id": id,
+		})
+	}
+}
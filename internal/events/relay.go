@@ -0,0 +1,152 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Relay publishes committed outbox rows to the transport in creation order.
+// Rows are locked with SKIP LOCKED so several instances can relay concurrently.
+// A crash between publishing and marking a row published causes a duplicate,
+// which consumers absorb through their processed_events records.
+type Relay struct {
+	db        *gorm.DB
+	transport Transport
+	cfg       config.EventsConfig
+
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running atomic.Bool
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(db *gorm.DB, transport Transport, cfg config.EventsConfig) *Relay {
+	return &Relay{db: db, transport: transport, cfg: cfg}
+}
+
+// Start begins polling the outbox
+func (r *Relay) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.running.Store(true)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer r.running.Store(false)
+
+		ticker := time.NewTicker(r.cfg.RelayInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.drain(ctx)
+			}
+		}
+	}()
+}
+
+// Running reports whether the relay is polling the outbox
+func (r *Relay) Running() bool {
+	return r.running.Load()
+}
+
+// Stop waits for the current batch to finish
+func (r *Relay) Stop(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain publishes batches until the outbox is empty or publishing fails
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, err := r.publishBatch(ctx)
+		if err != nil {
+			logger.Error("Failed to relay outbox events", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		if published < r.cfg.RelayBatchSize {
+			return
+		}
+	}
+}
+
+// publishBatch publishes up to RelayBatchSize unpublished rows and returns how many succeeded
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	published := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL").
+			Order("created_at").
+			Limit(r.cfg.RelayBatchSize).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+
+		for i := range rows {
+			row := &rows[i]
+			err := r.transport.Publish(ctx, Envelope{
+				ID:          row.ID,
+				Type:        row.Type,
+				AggregateID: row.AggregateID,
+				Payload:     row.Payload,
+				OccurredAt:  row.CreatedAt,
+			})
+			if err != nil {
+				// Stop here so later events are not published ahead of this one
+				return tx.Model(row).Updates(map[string]interface{}{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": err.Error(),
+				}).Error
+			}
+
+			if err := tx.Model(row).Update("published_at", time.Now().UTC()).Error; err != nil {
+				return err
+			}
+			published++
+		}
+
+		return nil
+	})
+
+	return published, err
+}
+
+// Cleanup deletes published rows and dedupe records older than the retention period.
+// It runs as a scheduled job.
+func (r *Relay) Cleanup(ctx context.Context) error {
+	cutoff := time.Now().UTC().Add(-r.cfg.Retention)
+
+	if err := r.db.WithContext(ctx).Where("published_at < ?", cutoff).Delete(&OutboxEvent{}).Error; err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Where("processed_at < ?", cutoff).Delete(&ProcessedEvent{}).Error
+}
@@ -0,0 +1,114 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"theb-backend/internal/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeliverFunc hands an envelope to the bus; an error asks the transport to redeliver it
+type DeliverFunc func(ctx context.Context, env Envelope) error
+
+// Transport carries published envelopes to the instance that consumes them
+type Transport interface {
+	// Publish hands an envelope to the transport; once it returns nil the relay marks it published
+	Publish(ctx context.Context, env Envelope) error
+	// Run consumes envelopes and calls deliver until ctx is cancelled
+	Run(ctx context.Context, deliver DeliverFunc) error
+}
+
+// localRetries bounds redelivery attempts of the in-process transport
+const localRetries = 5
+
+// LocalTransport delivers envelopes within this process. It is used when Redis is
+// not available (development); envelopes in flight are lost if the process dies.
+// An envelope that still fails after localRetries attempts is dead-lettered.
+type LocalTransport struct {
+	db    *gorm.DB
+	queue chan Envelope
+}
+
+// NewLocalTransport creates an in-process transport that dead-letters into db
+func NewLocalTransport(db *gorm.DB) *LocalTransport {
+	return &LocalTransport{db: db, queue: make(chan Envelope, 1000)}
+}
+
+// Publish queues the envelope
+func (t *LocalTransport) Publish(ctx context.Context, env Envelope) error {
+	select {
+	case t.queue <- env:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run delivers queued envelopes, retrying failures with backoff
+func (t *LocalTransport) Run(ctx context.Context, deliver DeliverFunc) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case env := <-t.queue:
+			backoff := time.Second
+			for attempt := 1; ; attempt++ {
+				err := deliver(ctx, env)
+				if err == nil {
+					break
+				}
+				if attempt == localRetries || ctx.Err() != nil {
+					if dlErr := deadLetter(ctx, t.db, env, attempt, err); dlErr != nil {
+						logger.Error("Dropping event after failed deliveries", map[string]interface{}{
+							"error":             err.Error(),
+							"dead_letter_error": dlErr.Error(),
+							"event_id":          env.ID.String(),
+							"type":              env.Type,
+							"attempts":          attempt,
+						})
+					}
+					break
+				}
+
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+				}
+				backoff *= 2
+			}
+		}
+	}
+}
+
+// deadLetter records an envelope that could not be delivered. Once the transport
+// gives up on it, this row is the only record left of the consumers it missed.
+// It is written even while shutting down, which is when deliveries are cut short.
+func deadLetter(ctx context.Context, db *gorm.DB, env Envelope, attempts int, cause error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&DeadLetterEvent{
+		ID:          env.ID,
+		Type:        env.Type,
+		AggregateID: env.AggregateID,
+		Payload:     env.Payload,
+		Attempts:    attempts,
+		LastError:   cause.Error(),
+		OccurredAt:  env.OccurredAt,
+		FailedAt:    time.Now().UTC(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	logger.Error("Dead-lettered event after failed deliveries", map[string]interface{}{
+		"error":    cause.Error(),
+		"event_id": env.ID.String(),
+		"type":     env.Type,
+		"attempts": attempts,
+	})
+	return nil
+}
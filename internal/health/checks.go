@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotConfigured is reported for an optional dependency that was not set up
+	ErrNotConfigured = errors.New("not configured")
+	// ErrWorkerStopped is reported when a background worker is not running
+	ErrWorkerStopped = errors.New("worker not running")
+)
+
+// Worker is a background loop whose liveness is part of readiness
+type Worker interface {
+	Running() bool
+}
+
+// Postgres checks that the database accepts queries
+func Postgres(db *gorm.DB) Check {
+	return Check{
+		Name:     "postgres",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// Redis checks that Redis answers PING. client may be nil when Redis is optional.
+func Redis(client *redis.Client, critical bool) Check {
+	return Check{
+		Name:     "redis",
+		Critical: critical,
+		Run: func(ctx context.Context) error {
+			if client == nil {
+				return ErrNotConfigured
+			}
+			return client.Ping(ctx).Err()
+		},
+	}
+}
+
+// Migrations checks that the table of every model exists. Once they all do,
+// the result is remembered, since applied migrations are not rolled back at runtime.
+func Migrations(db *gorm.DB, models ...interface{}) Check {
+	var applied atomic.Bool
+
+	return Check{
+		Name:     "migrations",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			if applied.Load() {
+				return nil
+			}
+
+			migrator := db.WithContext(ctx).Migrator()
+			var missing []string
+			for _, model := range models {
+				if !migrator.HasTable(model) {
+					missing = append(missing, fmt.Sprintf("%T", model))
+				}
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("missing tables for %s", strings.Join(missing, ", "))
+			}
+
+			applied.Store(true)
+			return nil
+		},
+	}
+}
+
+// Workers checks that every named background worker is running
+func Workers(workers map[string]Worker) Check {
+	return Check{
+		Name:     "workers",
+		Critical: true,
+		Run: func(ctx context.Context) error {
+			var stopped []string
+			for name, worker := range workers {
+				if !worker.Running() {
+					stopped = append(stopped, name)
+				}
+			}
+			if len(stopped) > 0 {
+				sort.Strings(stopped)
+				return fmt.Errorf("%w: %s", ErrWorkerStopped, strings.Join(stopped, ", "))
+			}
+			return nil
+		},
+	}
+}
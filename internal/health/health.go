@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status of a single check or of the whole instance
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+const defaultTimeout = 2 * time.Second
+
+// ErrDraining is reported while the instance is shutting down
+var ErrDraining = errors.New("shutting down")
+
+// Check probes one dependency. A failing critical check fails readiness;
+// a failing non-critical check only marks the instance degraded.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the readiness of the instance and each of its dependencies
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Ready reports whether the instance should receive traffic. Degraded instances still do.
+func (r Report) Ready() bool {
+	return r.Status != StatusFail
+}
+
+// Checker runs readiness checks concurrently, each bounded by a timeout
+type Checker struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	checks   []Check
+	draining atomic.Bool
+}
+
+// NewChecker creates a checker. A zero timeout uses the default.
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a check
+func (c *Checker) Add(check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check)
+}
+
+// Drain makes readiness fail from now on, so load balancers stop routing to the
+// instance while in-flight requests finish
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Draining reports whether Drain has been called
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Run executes every check and combines their results
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]Check(nil), c.checks...)
+	c.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks)+1)}
+	for i, check := range checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+
+		if result.Status != StatusOK {
+			if check.Critical {
+				report.Status = StatusFail
+			} else if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		}
+	}
+
+	if c.Draining() {
+		report.Status = StatusFail
+		report.Checks["shutdown"] = Result{Status: StatusFail, Critical: true, Error: ErrDraining.Error()}
+	}
+
+	return report
+}
+
+// run executes one check with the timeout, converting panics into failures
+func (c *Checker) run(ctx context.Context, check Check) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	started := time.Now()
+	result = Result{Status: StatusOK, Critical: check.Critical}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result.Status = StatusFail
+			result.Error = "check panicked"
+		}
+		result.LatencyMs = time.Since(started).Milliseconds()
+	}()
+
+	if err := check.Run(ctx); err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
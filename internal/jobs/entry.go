@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RegisterService provides the scheduler. Service modules schedule their jobs while
+// registering; the scheduler starts with the container and stops before Redis closes.
+func RegisterService(ctn *container.Container) error {
+	container.Provide(ctn, func(ctn *container.Container) (*Scheduler, error) {
+		cfg, err := container.Resolve[*config.Config](ctn)
+		if err != nil {
+			return nil, err
+		}
+		redisClient, err := container.Resolve[*redis.Client](ctn)
+		if err != nil {
+			return nil, err
+		}
+
+		if redisClient != nil {
+			return NewScheduler(cfg.Jobs, NewRedisStore(redisClient), NewRedisLocker(redisClient)), nil
+		}
+		return NewScheduler(cfg.Jobs, NewMemoryStore(), NewLocalLocker()), nil
+	},
+		container.OnStart(func(ctx context.Context, scheduler *Scheduler) error {
+			scheduler.Start()
+			return nil
+		}),
+		container.OnStop(func(ctx context.Context, scheduler *Scheduler) error {
+			return scheduler.Stop(ctx)
+		}),
+	)
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Locker grants a named lock to one replica until it expires
+type Locker interface {
+	// Acquire takes key for ttl and reports false if another replica holds it
+	Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// RedisLocker implements Locker with SET NX PX, so locks are shared by all replicas
+type RedisLocker struct {
+	client *redis.Client
+}
+
+// NewRedisLocker creates a Redis-backed locker
+func NewRedisLocker(client *redis.Client) *RedisLocker {
+	return &RedisLocker{client: client}
+}
+
+// Acquire implements Locker
+func (l *RedisLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return l.client.SetNX(ctx, key, time.Now().UTC().Format(time.RFC3339), ttl).Result()
+}
+
+// LocalLocker implements Locker in memory for single-instance development setups
+type LocalLocker struct {
+	mu    sync.Mutex
+	locks map[string]time.Time
+}
+
+// NewLocalLocker creates an in-memory locker
+func NewLocalLocker() *LocalLocker {
+	return &LocalLocker{locks: make(map[string]time.Time)}
+}
+
+// Acquire implements Locker
+func (l *LocalLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for held, expires := range l.locks {
+		if now.After(expires) {
+			delete(l.locks, held)
+		}
+	}
+	if _, held := l.locks[key]; held {
+		return false, nil
+	}
+	l.locks[key] = now.Add(ttl)
+
+	return true, nil
+}
@@ -0,0 +1,541 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScheduleDisabled turns a cron job off when used as its schedule override
+const ScheduleDisabled = "disabled"
+
+const (
+	defaultTimeout     = 5 * time.Minute
+	defaultMaxAttempts = 3
+	defaultBackoff     = time.Second
+	maxBackoff         = 5 * time.Minute
+	// delayedBatch bounds tasks taken from the store per poll
+	delayedBatch = 100
+	// claimMargin is added to the longest task timeout to get the claim visibility,
+	// leaving time to ack or re-push a task after its last attempt times out
+	claimMargin = time.Minute
+)
+
+var (
+	// ErrUnknownJob is returned when enqueueing a task without a registered handler
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrDuplicateJob is returned when two jobs are registered under one name
+	ErrDuplicateJob = errors.New("job already registered")
+)
+
+// Options tune how a job runs. Zero values use the defaults.
+type Options struct {
+	// Timeout bounds one attempt and is the lock TTL for cron jobs
+	Timeout time.Duration
+	// MaxAttempts is how many times a failing run is tried
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles for each further retry
+	Backoff time.Duration
+}
+
+// CronJob runs on a schedule, on one replica per scheduled time
+type CronJob struct {
+	Name string
+	// Schedule is a standard five-field cron expression or a descriptor such as
+	// "@hourly" or "@every 5m". It can be overridden per job in config.
+	Schedule string
+	Options  Options
+	Run      func(ctx context.Context) error
+}
+
+// TaskHandler runs delayed tasks enqueued under Name
+type TaskHandler struct {
+	Name    string
+	Options Options
+	Run     func(ctx context.Context, payload json.RawMessage) error
+}
+
+// alignedSchedule runs "@every" jobs on multiples of the interval since the epoch
+// rather than relative to process start, so all replicas agree on run times
+type alignedSchedule struct {
+	every time.Duration
+}
+
+// Next implements cron.Schedule
+func (a alignedSchedule) Next(t time.Time) time.Time {
+	return t.Truncate(a.every).Add(a.every)
+}
+
+type cronEntry struct {
+	job      CronJob
+	schedule cron.Schedule
+	next     time.Time
+	running  bool
+}
+
+// Scheduler runs cron jobs and delayed tasks. Cron runs are guarded by a lock
+// keyed by job name and scheduled time, so with Redis each occurrence runs on
+// exactly one replica; delayed tasks are claimed atomically from the store and
+// acked once they finish, so a task whose replica dies is claimed again.
+// Failing runs are retried with exponential backoff.
+type Scheduler struct {
+	cfg    config.JobsConfig
+	store  Store
+	locker Locker
+
+	mu       sync.Mutex
+	entries  []*cronEntry
+	handlers map[string]TaskHandler
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	running  atomic.Bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler. Register jobs before calling Start.
+func NewScheduler(cfg config.JobsConfig, store Store, locker Locker) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cfg:      cfg,
+		store:    store,
+		locker:   locker,
+		handlers: make(map[string]TaskHandler),
+		stop:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Schedule registers a cron job. A schedule override of "disabled" skips it.
+func (s *Scheduler) Schedule(job CronJob) error {
+	spec := s.spec(job.Name, job.Schedule)
+	if spec == ScheduleDisabled {
+		logger.Info("Cron job disabled", map[string]interface{}{
+			"job": job.Name,
+		})
+		return nil
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q for job %s: %w", spec, job.Name, err)
+	}
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		schedule = alignedSchedule{every: every.Delay}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.entries {
+		if entry.job.Name == job.Name {
+			return fmt.Errorf("%w: %s", ErrDuplicateJob, job.Name)
+		}
+	}
+
+	job.Options = withDefaults(job.Options)
+	s.entries = append(s.entries, &cronEntry{job: job, schedule: schedule})
+
+	return nil
+}
+
+// Interval returns the time between runs of the cron job name scheduled on spec,
+// after any override. It is zero when the job is disabled or its schedule invalid.
+func (s *Scheduler) Interval(name, spec string) time.Duration {
+	schedule, err := cron.ParseStandard(s.spec(name, spec))
+	if err != nil {
+		return 0
+	}
+	next := schedule.Next(time.Now())
+	return schedule.Next(next).Sub(next)
+}
+
+// spec returns the schedule of the cron job name: its override, or spec
+func (s *Scheduler) spec(name, spec string) string {
+	if override, ok := s.cfg.Schedules[name]; ok {
+		return override
+	}
+	return spec
+}
+
+// Handle registers the handler for delayed tasks named handler.Name
+func (s *Scheduler) Handle(handler TaskHandler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.handlers[handler.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, handler.Name)
+	}
+
+	handler.Options = withDefaults(handler.Options)
+	s.handlers[handler.Name] = handler
+
+	return nil
+}
+
+// Enqueue schedules a delayed task to run at runAt with payload encoded as JSON
+func (s *Scheduler) Enqueue(ctx context.Context, name string, payload interface{}, runAt time.Time) error {
+	s.mu.Lock()
+	_, ok := s.handlers[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Push(ctx, Task{
+		ID:      uuid.New(),
+		Name:    name,
+		Payload: data,
+		RunAt:   runAt.UTC(),
+		Attempt: 1,
+	})
+}
+
+// Start begins running cron jobs and polling for due tasks
+func (s *Scheduler) Start() {
+	s.running.Store(true)
+	s.wg.Add(2)
+	go s.cronLoop()
+	go s.delayedLoop()
+}
+
+// Stop stops scheduling new runs and waits for running jobs to finish.
+// If ctx expires first, running jobs are cancelled and Stop returns without them.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	defer s.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Running reports whether both scheduling loops are alive
+func (s *Scheduler) Running() bool {
+	return s.running.Load()
+}
+
+func (s *Scheduler) cronLoop() {
+	defer s.wg.Done()
+	defer s.running.Store(false)
+
+	now := time.Now()
+	s.mu.Lock()
+	for _, entry := range s.entries {
+		entry.next = entry.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	for {
+		timer := time.NewTimer(s.untilNext())
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		s.mu.Lock()
+		for _, entry := range s.entries {
+			if entry.next.After(now) {
+				continue
+			}
+			scheduled := entry.next
+			entry.next = entry.schedule.Next(now)
+
+			if entry.running {
+				logger.Warn("Skipping cron run, previous run still in progress", map[string]interface{}{
+					"job": entry.job.Name,
+				})
+				continue
+			}
+			entry.running = true
+
+			s.wg.Add(1)
+			go s.runCron(entry, scheduled)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// untilNext returns the delay until the earliest scheduled run
+func (s *Scheduler) untilNext() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) == 0 {
+		return time.Hour
+	}
+
+	earliest := s.entries[0].next
+	for _, entry := range s.entries[1:] {
+		if entry.next.Before(earliest) {
+			earliest = entry.next
+		}
+	}
+
+	delay := time.Until(earliest)
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+func (s *Scheduler) runCron(entry *cronEntry, scheduled time.Time) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		entry.running = false
+		s.mu.Unlock()
+	}()
+
+	// The lock is keyed by the scheduled time and left to expire, so a replica
+	// with a slightly late clock cannot run the same occurrence again
+	job := entry.job
+	lockKey := "jobs:lock:" + job.Name + ":" + strconv.FormatInt(scheduled.Unix(), 10)
+	ttl := job.Options.Timeout * time.Duration(job.Options.MaxAttempts)
+
+	ok, err := s.locker.Acquire(s.ctx, lockKey, ttl)
+	if err != nil {
+		logger.Error("Failed to acquire job lock", map[string]interface{}{
+			"error": err.Error(),
+			"job":   job.Name,
+		})
+		return
+	}
+	if !ok {
+		// Another replica owns this occurrence
+		return
+	}
+	s.attempt(job.Name, job.Options, func(ctx context.Context) error {
+		return job.Run(ctx)
+	})
+}
+
+func (s *Scheduler) delayedLoop() {
+	defer s.wg.Done()
+	defer s.running.Store(false)
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.pollDelayed()
+		}
+	}
+}
+
+func (s *Scheduler) pollDelayed() {
+	tasks, err := s.store.Claim(s.ctx, time.Now(), s.claimVisibility(), delayedBatch)
+	if err != nil {
+		logger.Error("Failed to load due jobs", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, task := range tasks {
+		s.mu.Lock()
+		handler, ok := s.handlers[task.Name]
+		s.mu.Unlock()
+		if !ok {
+			logger.Error("Dropping job without handler", map[string]interface{}{
+				"job":     task.Name,
+				"task_id": task.ID.String(),
+			})
+			s.ack(task)
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(task Task) {
+			defer s.wg.Done()
+			s.runTask(handler, task)
+		}(task)
+	}
+}
+
+// claimVisibility is how long a claimed task stays hidden from other pollers:
+// the longest handler timeout plus claimMargin
+func (s *Scheduler) claimVisibility() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	longest := defaultTimeout
+	for _, handler := range s.handlers {
+		longest = max(longest, handler.Options.Timeout)
+	}
+	return longest + claimMargin
+}
+
+// runTask runs one attempt of a delayed task and re-enqueues it with backoff on failure,
+// so a long backoff does not hold a worker or block shutdown. The claim is acked
+// only after that, so a task is never lost between the two.
+func (s *Scheduler) runTask(handler TaskHandler, task Task) {
+	defer s.ack(task)
+
+	err := s.runOnce(handler.Name, handler.Options.Timeout, func(ctx context.Context) error {
+		return handler.Run(ctx, task.Payload)
+	})
+	if err == nil {
+		return
+	}
+
+	if task.Attempt >= handler.Options.MaxAttempts {
+		logger.Error("Job failed permanently", map[string]interface{}{
+			"error":    err.Error(),
+			"job":      task.Name,
+			"task_id":  task.ID.String(),
+			"attempts": task.Attempt,
+		})
+		return
+	}
+
+	delay := backoff(handler.Options.Backoff, task.Attempt)
+	logger.Warn("Job failed, retrying", map[string]interface{}{
+		"error":    err.Error(),
+		"job":      task.Name,
+		"task_id":  task.ID.String(),
+		"attempt":  task.Attempt,
+		"retry_in": delay.String(),
+	})
+
+	task.Attempt++
+	task.RunAt = time.Now().UTC().Add(delay)
+	if err := s.store.Push(context.Background(), task); err != nil {
+		logger.Error("Failed to re-enqueue job", map[string]interface{}{
+			"error":   err.Error(),
+			"job":     task.Name,
+			"task_id": task.ID.String(),
+		})
+	}
+}
+
+// ack releases a task's claim. A failed ack only means the task runs again
+// once its claim expires.
+func (s *Scheduler) ack(task Task) {
+	if err := s.store.Ack(context.Background(), task); err != nil {
+		logger.Error("Failed to ack job", map[string]interface{}{
+			"error":   err.Error(),
+			"job":     task.Name,
+			"task_id": task.ID.String(),
+		})
+	}
+}
+
+// attempt runs fn until it succeeds, attempts run out or the scheduler stops,
+// waiting with exponential backoff between attempts
+func (s *Scheduler) attempt(name string, opts Options, fn func(ctx context.Context) error) {
+	for attempt := 1; ; attempt++ {
+		err := s.runOnce(name, opts.Timeout, fn)
+		if err == nil {
+			return
+		}
+
+		if attempt >= opts.MaxAttempts {
+			logger.Error("Job failed permanently", map[string]interface{}{
+				"error":    err.Error(),
+				"job":      name,
+				"attempts": attempt,
+			})
+			return
+		}
+
+		delay := backoff(opts.Backoff, attempt)
+		logger.Warn("Job failed, retrying", map[string]interface{}{
+			"error":    err.Error(),
+			"job":      name,
+			"attempt":  attempt,
+			"retry_in": delay.String(),
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-s.stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// runOnce runs fn with a timeout, converting panics into errors
+func (s *Scheduler) runOnce(name string, timeout time.Duration, fn func(ctx context.Context) error) (err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, "job "+name, trace.WithAttributes(attribute.String("job.name", name)))
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job %s panicked: %v", name, recovered)
+		}
+		tracing.End(span, err)
+	}()
+
+	started := time.Now()
+	err = fn(ctx)
+	logger.Debug("Job finished", map[string]interface{}{
+		"job":      name,
+		"duration": time.Since(started).String(),
+		"success":  err == nil,
+	})
+
+	return err
+}
+
+func backoff(base time.Duration, attempt int) time.Duration {
+	delay := base << (attempt - 1)
+	if delay <= 0 || delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+func withDefaults(opts Options) Options {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBackoff
+	}
+	return opts
+}
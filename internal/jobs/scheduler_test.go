@@ -0,0 +1,160 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"theb-backend/internal/config"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryStoreClaim(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	store := NewMemoryStore()
+
+	due := Task{ID: uuid.New(), Name: "due", RunAt: now.Add(-time.Second), Attempt: 1}
+	later := Task{ID: uuid.New(), Name: "later", RunAt: now.Add(time.Hour), Attempt: 1}
+	for _, task := range []Task{later, due} {
+		if err := store.Push(ctx, task); err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+	}
+
+	claim := func(at time.Time) []Task {
+		t.Helper()
+		tasks, err := store.Claim(ctx, at, time.Minute, 10)
+		if err != nil {
+			t.Fatalf("Claim() error = %v", err)
+		}
+		return tasks
+	}
+
+	if tasks := claim(now); len(tasks) != 1 || tasks[0].ID != due.ID {
+		t.Fatalf("Claim() = %v, want only the due task", tasks)
+	}
+	if tasks := claim(now.Add(30 * time.Second)); len(tasks) != 0 {
+		t.Errorf("Claim() during the claim = %v, want none", tasks)
+	}
+	if tasks := claim(now.Add(2 * time.Minute)); len(tasks) != 1 || tasks[0].ID != due.ID {
+		t.Errorf("Claim() after the claim expired = %v, want the due task again", tasks)
+	}
+
+	if err := store.Ack(ctx, due); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if tasks := claim(now.Add(10 * time.Minute)); len(tasks) != 0 {
+		t.Errorf("Claim() after ack = %v, want none", tasks)
+	}
+}
+
+func TestSchedulerDelayedTasks(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int32
+		runs     int32
+	}{
+		{name: "runs once", runs: 1},
+		{name: "retries a failure", failures: 1, runs: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			scheduler := NewScheduler(config.JobsConfig{PollInterval: 5 * time.Millisecond}, store, NewLocalLocker())
+
+			var runs atomic.Int32
+			done := make(chan string, 1)
+			err := scheduler.Handle(TaskHandler{
+				Name:    "ride_reminder",
+				Options: Options{Backoff: time.Millisecond},
+				Run: func(ctx context.Context, payload json.RawMessage) error {
+					if runs.Add(1) <= tt.failures {
+						return errors.New("temporary failure")
+					}
+					var rideID string
+					if err := json.Unmarshal(payload, &rideID); err != nil {
+						return err
+					}
+					done <- rideID
+					return nil
+				},
+			})
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			if err := scheduler.Enqueue(context.Background(), "ride_reminder", "ride-1", time.Now()); err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+
+			scheduler.Start()
+			select {
+			case rideID := <-done:
+				if rideID != "ride-1" {
+					t.Errorf("payload = %q, want ride-1", rideID)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("task did not run")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := scheduler.Stop(ctx); err != nil {
+				t.Fatalf("Stop() error = %v", err)
+			}
+
+			if got := runs.Load(); got != tt.runs {
+				t.Errorf("runs = %d, want %d", got, tt.runs)
+			}
+			// Finished tasks are acked, so nothing is claimed again once claims expire
+			tasks, err := store.Claim(context.Background(), time.Now().Add(time.Hour), time.Minute, 10)
+			if err != nil {
+				t.Fatalf("Claim() error = %v", err)
+			}
+			if len(tasks) != 0 {
+				t.Errorf("tasks left after the run = %v, want none", tasks)
+			}
+		})
+	}
+}
+
+func TestSchedulerEnqueueUnknownJob(t *testing.T) {
+	scheduler := NewScheduler(config.JobsConfig{}, NewMemoryStore(), NewLocalLocker())
+
+	err := scheduler.Enqueue(context.Background(), "missing", nil, time.Now())
+	if !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Enqueue() error = %v, want %v", err, ErrUnknownJob)
+	}
+}
+
+func TestSchedulerInterval(t *testing.T) {
+	scheduler := NewScheduler(config.JobsConfig{Schedules: map[string]string{
+		"overridden": "@every 2m",
+		"hourly":     "0 * * * *",
+		"off":        ScheduleDisabled,
+	}}, NewMemoryStore(), NewLocalLocker())
+
+	tests := []struct {
+		name string
+		spec string
+		want time.Duration
+	}{
+		{name: "default", spec: "@every 30s", want: 30 * time.Second},
+		{name: "overridden", spec: "@every 30s", want: 2 * time.Minute},
+		{name: "hourly", spec: "@every 30s", want: time.Hour},
+		{name: "off", spec: "@every 30s", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheduler.Interval(tt.name, tt.spec); got != tt.want {
+				t.Errorf("Interval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// Task is a delayed job waiting for its run time
+type Task struct {
+	ID      uuid.UUID       `json:"id"`
+	Name    string          `json:"name"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	RunAt   time.Time       `json:"run_at"`
+	Attempt int             `json:"attempt"`
+
+	// member is the task as stored in Redis, used to ack it
+	member string
+}
+
+// Store keeps delayed tasks until they are due and while they run. A claimed
+// task stays in the store until it is acked; if its claim expires first, as
+// when the replica running it dies, it is claimed again.
+type Store interface {
+	// Push adds a task
+	Push(ctx context.Context, task Task) error
+	// Claim returns up to limit tasks due at or before now, including tasks whose
+	// earlier claim has expired, and hides them from other callers for visibility
+	Claim(ctx context.Context, now time.Time, visibility time.Duration, limit int) ([]Task, error)
+	// Ack removes a claimed task once it has finished or been re-pushed for a retry
+	Ack(ctx context.Context, task Task) error
+}
+
+const (
+	// delayedKey is the sorted set of delayed tasks scored by run time in milliseconds
+	delayedKey = "jobs:delayed"
+	// claimedKey is the sorted set of claimed tasks scored by claim expiry in milliseconds
+	claimedKey = "jobs:delayed:claimed"
+)
+
+// claimScript atomically takes expired claims and due tasks and claims them
+// until ARGV[2]
+var claimScript = redis.NewScript(`
+local tasks = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+local remaining = tonumber(ARGV[3]) - #tasks
+if remaining > 0 then
+	local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, remaining)
+	if #due > 0 then
+		redis.call("ZREM", KEYS[1], unpack(due))
+		for _, task in ipairs(due) do
+			table.insert(tasks, task)
+		end
+	end
+end
+for _, task in ipairs(tasks) do
+	redis.call("ZADD", KEYS[2], ARGV[2], task)
+end
+return tasks
+`)
+
+// RedisStore implements Store with Redis sorted sets
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a Redis-backed task store
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Push implements Store
+func (s *RedisStore) Push(ctx context.Context, task Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	return s.client.ZAdd(ctx, delayedKey, &redis.Z{
+		Score:  float64(task.RunAt.UnixMilli()),
+		Member: data,
+	}).Err()
+}
+
+// Claim implements Store
+func (s *RedisStore) Claim(ctx context.Context, now time.Time, visibility time.Duration, limit int) ([]Task, error) {
+	members, err := claimScript.Run(ctx, s.client, []string{delayedKey, claimedKey},
+		strconv.FormatInt(now.UnixMilli(), 10),
+		strconv.FormatInt(now.Add(visibility).UnixMilli(), 10),
+		limit,
+	).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(members))
+	for _, member := range members {
+		var task Task
+		if err := json.Unmarshal([]byte(member), &task); err != nil {
+			// Unreadable tasks would be claimed forever
+			s.client.ZRem(ctx, claimedKey, member)
+			continue
+		}
+		task.member = member
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// Ack implements Store
+func (s *RedisStore) Ack(ctx context.Context, task Task) error {
+	return s.client.ZRem(ctx, claimedKey, task.member).Err()
+}
+
+// claim is a task hidden from Claim until expires
+type claim struct {
+	task    Task
+	expires time.Time
+}
+
+// MemoryStore implements Store in memory for single-instance development setups.
+// Tasks are lost on restart.
+type MemoryStore struct {
+	mu      sync.Mutex
+	tasks   []Task
+	claimed map[uuid.UUID]claim
+}
+
+// NewMemoryStore creates an in-memory task store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{claimed: make(map[uuid.UUID]claim)}
+}
+
+// Push implements Store
+func (s *MemoryStore) Push(ctx context.Context, task Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks = append(s.tasks, task)
+	sort.Slice(s.tasks, func(i, j int) bool { return s.tasks[i].RunAt.Before(s.tasks[j].RunAt) })
+	return nil
+}
+
+// Claim implements Store
+func (s *MemoryStore) Claim(ctx context.Context, now time.Time, visibility time.Duration, limit int) ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Task
+	for _, c := range s.claimed {
+		if len(due) < limit && !c.expires.After(now) {
+			due = append(due, c.task)
+		}
+	}
+
+	n := 0
+	for n < len(s.tasks) && len(due) < limit && !s.tasks[n].RunAt.After(now) {
+		due = append(due, s.tasks[n])
+		n++
+	}
+	s.tasks = s.tasks[n:]
+
+	for _, task := range due {
+		s.claimed[task.ID] = claim{task: task, expires: now.Add(visibility)}
+	}
+	return due, nil
+}
+
+// Ack implements Store
+func (s *MemoryStore) Ack(ctx context.Context, task Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.claimed, task.ID)
+	return nil
+}
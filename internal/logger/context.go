@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextKey struct{}
+
+// contextFields are the request-scoped fields added to every entry logged with the context
+type contextFields struct {
+	requestID string
+	userID    string
+	rideID    string
+}
+
+func fieldsFrom(ctx context.Context) contextFields {
+	if ctx == nil {
+		return contextFields{}
+	}
+	fields, _ := ctx.Value(contextKey{}).(contextFields)
+	return fields
+}
+
+// WithRequestID returns a context whose log entries carry request_id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	fields := fieldsFrom(ctx)
+	fields.requestID = requestID
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+// WithUserID returns a context whose log entries carry user_id
+func WithUserID(ctx context.Context, userID string) context.Context {
+	fields := fieldsFrom(ctx)
+	fields.userID = userID
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+// WithRideID returns a context whose log entries carry ride_id
+func WithRideID(ctx context.Context, rideID string) context.Context {
+	fields := fieldsFrom(ctx)
+	fields.rideID = rideID
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+// RequestID returns the request ID stored in ctx, if any
+func RequestID(ctx context.Context) string {
+	return fieldsFrom(ctx).requestID
+}
+
+// attrs returns the non-empty fields as log attributes
+func (f contextFields) attrs() []slog.Attr {
+	attrs := make([]slog.Attr, 0, 3)
+	if f.requestID != "" {
+		attrs = append(attrs, slog.String("request_id", f.requestID))
+	}
+	if f.userID != "" {
+		attrs = append(attrs, slog.String("user_id", f.userID))
+	}
+	if f.rideID != "" {
+		attrs = append(attrs, slog.String("ride_id", f.rideID))
+	}
+	return attrs
+}
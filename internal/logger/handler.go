@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+	"unicode"
+
+	"theb-backend/internal/config"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redacted replaces the value of sensitive fields
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are redacted wherever they appear as whole words of a field, group
+// or map key, e.g. access_token, phoneNumber or X-Authorization but not tokens_removed
+var sensitiveKeys = []string{"phone", "otp", "token", "password", "secret", "authorization"}
+
+// handler adds the request-scoped fields of the context and samples debug entries
+type handler struct {
+	inner   slog.Handler
+	sampler *sampler
+}
+
+func newHandler(out io.Writer, cfg config.LoggingConfig, level slog.Level) slog.Handler {
+	keys := append(append([]string(nil), sensitiveKeys...), cfg.RedactKeys...)
+
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceAttr(newRedactor(keys)),
+	}
+
+	var inner slog.Handler
+	if cfg.Format == "json" {
+		inner = slog.NewJSONHandler(out, opts)
+	} else {
+		inner = slog.NewTextHandler(out, opts)
+	}
+
+	return &handler{inner: inner, sampler: newSampler(cfg.Sampling)}
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelDebug && !h.sampler.allow(r.Message) {
+		return nil
+	}
+
+	r.AddAttrs(fieldsFrom(ctx).attrs()...)
+	if ctx != nil {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{inner: h.inner.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{inner: h.inner.WithGroup(name), sampler: h.sampler}
+}
+
+// replaceAttr keeps the field names of the previous logger and redacts sensitive keys
+func replaceAttr(r redactor) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.TimeKey:
+				return slog.String("timestamp", a.Value.Time().UTC().Format(time.RFC3339))
+			case slog.MessageKey:
+				a.Key = "message"
+				return a
+			case slog.LevelKey:
+				if level, ok := a.Value.Any().(slog.Level); ok && level >= LevelFatal {
+					return slog.String(slog.LevelKey, "FATAL")
+				}
+				return a
+			}
+		}
+
+		// ReplaceAttr sees the fields inside a group but not the group itself
+		for _, group := range groups {
+			if r.sensitive(group) {
+				return slog.String(a.Key, redacted)
+			}
+		}
+		if r.sensitive(a.Key) {
+			return slog.String(a.Key, redacted)
+		}
+		if a.Value.Kind() == slog.KindAny {
+			return slog.Any(a.Key, r.value(a.Value.Any()))
+		}
+		return a
+	}
+}
+
+// redactor matches keys against sensitive words
+type redactor struct {
+	keys [][]string
+}
+
+func newRedactor(keys []string) redactor {
+	r := redactor{keys: make([][]string, 0, len(keys))}
+	for _, key := range keys {
+		if words := words(key); len(words) > 0 {
+			r.keys = append(r.keys, words)
+		}
+	}
+	return r
+}
+
+// sensitive reports whether key contains the words of a sensitive key in a row,
+// so a configured api_key matches x_api_key but not api_version
+func (r redactor) sensitive(key string) bool {
+	have := words(key)
+	for _, want := range r.keys {
+		for i := 0; i+len(want) <= len(have); i++ {
+			if slices.Equal(have[i:i+len(want)], want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// value returns v with sensitive map entries redacted at any depth. Maps with
+// string keys and slices are copied rather than changed; other values are kept.
+func (r redactor) value(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		out := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if r.sensitive(key) {
+				out[key] = redacted
+				continue
+			}
+			out[key] = r.value(iter.Value().Interface())
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		switch rv.Type().Elem().Kind() {
+		case reflect.Map, reflect.Slice, reflect.Array, reflect.Interface:
+		default:
+			return v
+		}
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = r.value(rv.Index(i).Interface())
+		}
+		return out
+	}
+	return v
+}
+
+// words splits a key into lower-case words at punctuation and camel case, so
+// accessToken, access_token and Access-Token all give access and token
+func words(key string) []string {
+	var out []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			out = append(out, string(word))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(key)
+	for i, c := range runes {
+		switch {
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			flush()
+			continue
+		case unicode.IsUpper(c) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1]))):
+			// A capital starts a word after a lower-case letter, or ends an acronym as in OTPCode
+			flush()
+		}
+		word = append(word, unicode.ToLower(c))
+	}
+	flush()
+	return out
+}
@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"theb-backend/internal/config"
+)
+
+func TestHandlerRedaction(t *testing.T) {
+	tests := []struct {
+		name  string
+		group string
+		attrs []slog.Attr
+		want  map[string]interface{}
+	}{
+		{
+			name:  "sensitive word in a field",
+			attrs: []slog.Attr{slog.String("access_token", "abc"), slog.String("phoneNumber", "0790000000")},
+			want:  map[string]interface{}{"access_token": redacted, "phoneNumber": redacted},
+		},
+		{
+			name:  "sensitive text inside a longer word",
+			attrs: []slog.Attr{slog.Int("tokens_removed", 3), slog.String("otpauth", "x")},
+			want:  map[string]interface{}{"tokens_removed": float64(3), "otpauth": "x"},
+		},
+		{
+			name:  "header names",
+			attrs: []slog.Attr{slog.String("X-Authorization", "Bearer abc"), slog.String("OTPCode", "1234")},
+			want:  map[string]interface{}{"X-Authorization": redacted, "OTPCode": redacted},
+		},
+		{
+			name: "nested maps",
+			attrs: []slog.Attr{slog.Any("user", map[string]interface{}{
+				"name":    "Sami",
+				"contact": map[string]string{"phone": "0790000000", "city": "Amman"},
+			})},
+			want: map[string]interface{}{"user": map[string]interface{}{
+				"name":    "Sami",
+				"contact": map[string]interface{}{"phone": redacted, "city": "Amman"},
+			}},
+		},
+		{
+			name: "maps in a slice",
+			attrs: []slog.Attr{slog.Any("devices", []map[string]interface{}{
+				{"push_token": "ExponentPushToken[x]", "platform": "ios"},
+			})},
+			want: map[string]interface{}{"devices": []interface{}{
+				map[string]interface{}{"push_token": redacted, "platform": "ios"},
+			}},
+		},
+		{
+			name:  "fields of a group",
+			attrs: []slog.Attr{slog.Group("auth", slog.String("token", "abc"), slog.String("method", "otp"))},
+			want:  map[string]interface{}{"auth": map[string]interface{}{"token": redacted, "method": "otp"}},
+		},
+		{
+			name:  "every field of a sensitive group",
+			attrs: []slog.Attr{slog.Group("password", slog.String("hash", "x"), slog.Int("length", 12))},
+			want:  map[string]interface{}{"password": map[string]interface{}{"hash": redacted, "length": redacted}},
+		},
+		{
+			name:  "fields under a logger group",
+			group: "request",
+			attrs: []slog.Attr{slog.String("authorization", "Bearer abc"), slog.String("path", "/v1")},
+			want:  map[string]interface{}{"request": map[string]interface{}{"authorization": redacted, "path": "/v1"}},
+		},
+		{
+			name:  "configured key of several words",
+			attrs: []slog.Attr{slog.String("x_api_key", "k"), slog.String("api_version", "2")},
+			want:  map[string]interface{}{"x_api_key": redacted, "api_version": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := newHandler(&buf, config.LoggingConfig{Format: "json", RedactKeys: []string{"API_Key"}}, slog.LevelDebug)
+			if tt.group != "" {
+				h = h.WithGroup(tt.group)
+			}
+			slog.New(h).LogAttrs(context.Background(), slog.LevelInfo, "test", tt.attrs...)
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+			}
+			for key, want := range tt.want {
+				if !reflect.DeepEqual(got[key], want) {
+					t.Errorf("%s = %#v, want %#v", key, got[key], want)
+				}
+			}
+		})
+	}
+}
@@ -1,119 +1,155 @@
 package logger
 
 import (
-	"encoding/json"
-	"fmt"
-	"log"
+	"context"
+	"log/slog"
 	"os"
-	"time"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"theb-backend/internal/config"
 )
 
-// Level represents log level
-type Level int
+// LevelFatal is logged by Fatal before the process exits
+const LevelFatal = slog.Level(12)
 
-const (
-	DebugLevel Level = iota
-	InfoLevel
-	WarnLevel
-	ErrorLevel
-	FatalLevel
-)
+var base atomic.Pointer[slog.Logger]
 
-var (
-	currentLevel Level
-	jsonFormat   bool
-)
+func init() {
+	base.Store(slog.New(newHandler(os.Stdout, config.LoggingConfig{}, slog.LevelInfo)))
+}
+
+// Init configures the global logger from config. Until it is called, text logs go to stdout at info level.
+func Init(cfg config.LoggingConfig) error {
+	out, err := openOutput(cfg)
+	if err != nil {
+		return err
+	}
 
-// Init initializes the logger
-func Init(level, format string) {
-	switch level {
+	base.Store(slog.New(newHandler(out, cfg, ParseLevel(cfg.Level))))
+	return nil
+}
+
+// ParseLevel maps a config level name to a slog level, defaulting to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
 	case "debug":
-		currentLevel = DebugLevel
-	case "info":
-		currentLevel = InfoLevel
+		return slog.LevelDebug
 	case "warn":
-		currentLevel = WarnLevel
+		return slog.LevelWarn
 	case "error":
-		currentLevel = ErrorLevel
+		return slog.LevelError
 	case "fatal":
-		currentLevel = FatalLevel
+		return LevelFatal
 	default:
-		currentLevel = InfoLevel
+		return slog.LevelInfo
 	}
+}
 
-	jsonFormat = format == "json"
-	log.SetFlags(0)
+// Slog returns the underlying slog logger for adapters such as the GORM logger
+func Slog() *slog.Logger {
+	return base.Load()
 }
 
-// Debug logs debug message
-func Debug(message string, fields map[string]interface{}) {
-	if currentLevel <= DebugLevel {
-		logMessage("DEBUG", message, fields)
+// Logger writes entries carrying the request-scoped fields of its context
+// and any fields added with With
+type Logger struct {
+	ctx   context.Context
+	attrs []slog.Attr
+}
+
+// FromContext returns a logger that adds the request_id, user_id and ride_id stored in ctx
+func FromContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	return &Logger{ctx: ctx}
+}
+
+// With returns a logger that adds fields to every entry
+func (l *Logger) With(fields map[string]interface{}) *Logger {
+	attrs := make([]slog.Attr, 0, len(l.attrs)+len(fields))
+	attrs = append(attrs, l.attrs...)
+	attrs = append(attrs, toAttrs(fields)...)
+	return &Logger{ctx: l.ctx, attrs: attrs}
+}
+
+// Debug logs debug message
+func (l *Logger) Debug(message string, fields map[string]interface{}) {
+	l.log(slog.LevelDebug, message, fields)
 }
 
 // Info logs info message
-func Info(message string, fields map[string]interface{}) {
-	if currentLevel <= InfoLevel {
-		logMessage("INFO", message, fields)
-	}
+func (l *Logger) Info(message string, fields map[string]interface{}) {
+	l.log(slog.LevelInfo, message, fields)
 }
 
 // Warn logs warning message
-func Warn(message string, fields map[string]interface{}) {
-	if currentLevel <= WarnLevel {
-		logMessage("WARN", message, fields)
-	}
+func (l *Logger) Warn(message string, fields map[string]interface{}) {
+	l.log(slog.LevelWarn, message, fields)
 }
 
 // Error logs error message
-func Error(message string, fields map[string]interface{}) {
-	if currentLevel <= ErrorLevel {
-		logMessage("ERROR", message, fields)
-	}
+func (l *Logger) Error(message string, fields map[string]interface{}) {
+	l.log(slog.LevelError, message, fields)
 }
 
 // Fatal logs fatal message and exits
-func Fatal(message string, fields map[string]interface{}) {
-	logMessage("FATAL", message, fields)
+func (l *Logger) Fatal(message string, fields map[string]interface{}) {
+	l.log(LevelFatal, message, fields)
 	os.Exit(1)
 }
 
-func logMessage(level, message string, fields map[string]interface{}) {
-	if jsonFormat {
-		logJSON(level, message, fields)
-	} else {
-		logText(level, message, fields)
+func (l *Logger) log(level slog.Level, message string, fields map[string]interface{}) {
+	logger := base.Load()
+	if !logger.Enabled(l.ctx, level) {
+		return
 	}
-}
 
-func logJSON(level, message string, fields map[string]interface{}) {
-	entry := map[string]interface{}{
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"level":     level,
-		"message":   message,
+	attrs := l.attrs
+	if len(fields) > 0 {
+		attrs = append(append([]slog.Attr(nil), l.attrs...), toAttrs(fields)...)
 	}
+	logger.LogAttrs(l.ctx, level, message, attrs...)
+}
 
-	if fields != nil {
-		for k, v := range fields {
-			entry[k] = v
-		}
-	}
+// Debug logs debug message
+func Debug(message string, fields map[string]interface{}) {
+	FromContext(context.Background()).Debug(message, fields)
+}
+
+// Info logs info message
+func Info(message string, fields map[string]interface{}) {
+	FromContext(context.Background()).Info(message, fields)
+}
 
-	data, _ := json.Marshal(entry)
-	fmt.Println(string(data))
+// Warn logs warning message
+func Warn(message string, fields map[string]interface{}) {
+	FromContext(context.Background()).Warn(message, fields)
 }
 
-func logText(level, message string, fields map[string]interface{}) {
-	timestamp := time.Now().UTC().Format("2006-01-02 15:04:05")
-	output := fmt.Sprintf("[%s] %s - %s", timestamp, level, message)
+// Error logs error message
+func Error(message string, fields map[string]interface{}) {
+	FromContext(context.Background()).Error(message, fields)
+}
 
-	if fields != nil && len(fields) > 0 {
-		output += " |"
-		for k, v := range fields {
-			output += fmt.Sprintf(" %s=%v", k, v)
-		}
+// Fatal logs fatal message and exits
+func Fatal(message string, fields map[string]interface{}) {
+	FromContext(context.Background()).Fatal(message, fields)
+}
+
+// toAttrs converts fields to attributes sorted by key, so text output is stable
+func toAttrs(fields map[string]interface{}) []slog.Attr {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
-	fmt.Println(output)
+	attrs := make([]slog.Attr, len(keys))
+	for i, k := range keys {
+		attrs[i] = slog.Any(k, fields[k])
+	}
+	return attrs
 }
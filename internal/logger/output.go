@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"theb-backend/internal/config"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// openOutput returns the writer named by cfg.Output. Any value other than stdout
+// or stderr is a file path, rotated by size and age.
+func openOutput(cfg config.LoggingConfig) (io.Writer, error) {
+	switch cfg.Output {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Output), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	return &lumberjack.Logger{
+		Filename:   cfg.Output,
+		MaxSize:    cfg.File.MaxSizeMB,
+		MaxBackups: cfg.File.MaxBackups,
+		MaxAge:     cfg.File.MaxAgeDays,
+		Compress:   cfg.File.Compress,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"theb-backend/internal/config"
+)
+
+// sampler drops repeats of the same message within a tick once Initial entries were
+// logged, keeping every Thereafter-th. A nil sampler allows everything.
+type sampler struct {
+	initial    int
+	thereafter int
+	tick       time.Duration
+
+	mu     sync.Mutex
+	window time.Time
+	counts map[string]int
+}
+
+func newSampler(cfg config.LogSamplingConfig) *sampler {
+	if cfg.Initial <= 0 {
+		return nil
+	}
+	if cfg.Tick <= 0 {
+		cfg.Tick = time.Second
+	}
+
+	return &sampler{
+		initial:    cfg.Initial,
+		thereafter: cfg.Thereafter,
+		tick:       cfg.Tick,
+		counts:     make(map[string]int),
+	}
+}
+
+func (s *sampler) allow(message string) bool {
+	if s == nil {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.window) >= s.tick {
+		s.window = now
+		s.counts = make(map[string]int)
+	}
+
+	s.counts[message]++
+	n := s.counts[message]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
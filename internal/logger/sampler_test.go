@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"theb-backend/internal/config"
+)
+
+func TestSampler(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.LogSamplingConfig
+		want []bool
+	}{
+		{
+			name: "disabled",
+			cfg:  config.LogSamplingConfig{},
+			want: []bool{true, true, true, true},
+		},
+		{
+			name: "initial entries only",
+			cfg:  config.LogSamplingConfig{Initial: 2, Tick: time.Hour},
+			want: []bool{true, true, false, false, false},
+		},
+		{
+			name: "every thereafter-th after the initial entries",
+			cfg:  config.LogSamplingConfig{Initial: 1, Thereafter: 2, Tick: time.Hour},
+			want: []bool{true, false, true, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSampler(tt.cfg)
+			for i, want := range tt.want {
+				if got := s.allow("repeated"); got != want {
+					t.Errorf("entry %d allowed = %v, want %v", i+1, got, want)
+				}
+			}
+			if !s.allow("other") {
+				t.Error("a different message was dropped")
+			}
+		})
+	}
+}
+
+func TestSamplerNewTick(t *testing.T) {
+	s := newSampler(config.LogSamplingConfig{Initial: 1, Tick: 10 * time.Millisecond})
+	if !s.allow("repeated") || s.allow("repeated") {
+		t.Fatal("expected only the first entry in the tick")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !s.allow("repeated") {
+		t.Error("the first entry of a new tick was dropped")
+	}
+}
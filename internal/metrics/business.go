@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"theb-backend/internal/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Dispatch offer results for DispatchOffers
+const (
+	OfferAccepted = "accepted"
+	OfferDeclined = "declined"
+	OfferExpired  = "expired"
+)
+
+var (
+	// DispatchTimeToMatch observes the time from a ride request to a captain accepting it
+	DispatchTimeToMatch = promauto.With(Registry).NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "dispatch",
+		Name:      "time_to_match_seconds",
+		Help:      "Time from ride request to captain match.",
+		Buckets:   []float64{5, 10, 20, 30, 45, 60, 90, 120, 180, 300, 600},
+	})
+
+	// DispatchOffers counts ride offers sent to captains by result. The acceptance
+	// rate is the accepted share of all results.
+	DispatchOffers = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "dispatch",
+		Name:      "offers_total",
+		Help:      "Ride offers to captains by result.",
+	}, []string{"result"})
+)
+
+// scrapeTimeout bounds the database queries behind business gauges
+const scrapeTimeout = 5 * time.Second
+
+var (
+	ridesByStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "rides", "by_status"),
+		"Rides in each status.", []string{"status"}, nil,
+	)
+	onlineCaptainsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "captains", "online"),
+		"Captains currently online.", nil, nil,
+	)
+	surgeMultiplierDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "surge", "multiplier"),
+		"Surge multiplier quoted in each zone.", []string{"zone"}, nil,
+	)
+)
+
+// RegisterRideCounts exposes rides by status, counted by fn on every scrape.
+// Statuses missing from the result are reported as zero.
+func RegisterRideCounts(statuses []string, fn func(ctx context.Context) (map[string]int64, error)) error {
+	return register(collectorFunc{desc: ridesByStatusDesc, collect: func(ctx context.Context, ch chan<- prometheus.Metric) error {
+		counts, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			ch <- prometheus.MustNewConstMetric(ridesByStatusDesc, prometheus.GaugeValue, float64(counts[status]), status)
+		}
+		return nil
+	}})
+}
+
+// RegisterOnlineCaptains exposes the number of online captains, counted by fn on every scrape
+func RegisterOnlineCaptains(fn func(ctx context.Context) (int64, error)) error {
+	return register(collectorFunc{desc: onlineCaptainsDesc, collect: func(ctx context.Context, ch chan<- prometheus.Metric) error {
+		count, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(onlineCaptainsDesc, prometheus.GaugeValue, float64(count))
+		return nil
+	}})
+}
+
+// RegisterSurgeMultipliers exposes the quoted surge multiplier by zone name, read by fn on every scrape
+func RegisterSurgeMultipliers(fn func(ctx context.Context) (map[string]float64, error)) error {
+	return register(collectorFunc{desc: surgeMultiplierDesc, collect: func(ctx context.Context, ch chan<- prometheus.Metric) error {
+		multipliers, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		for zone, multiplier := range multipliers {
+			ch <- prometheus.MustNewConstMetric(surgeMultiplierDesc, prometheus.GaugeValue, multiplier, zone)
+		}
+		return nil
+	}})
+}
+
+// collectorFunc queries the database at scrape time, so every replica reports
+// current values without a background refresh
+type collectorFunc struct {
+	desc    *prometheus.Desc
+	collect func(ctx context.Context, ch chan<- prometheus.Metric) error
+}
+
+func (c collectorFunc) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c collectorFunc) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	if err := c.collect(ctx, ch); err != nil {
+		logger.Warn("Failed to collect metric", map[string]interface{}{
+			"error":  err.Error(),
+			"metric": c.desc.String(),
+		})
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+	}
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every application metric
+const namespace = "theb"
+
+// Registry holds every metric exposed on the admin /metrics endpoint
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// register adds a collector. One already registered under the same metrics is
+// replaced, so an application built again in the same process reports its own
+// database and pools rather than those of the first build.
+func register(c prometheus.Collector) error {
+	err := Registry.Register(c)
+	var already prometheus.AlreadyRegisteredError
+	if !errors.As(err, &already) {
+		return err
+	}
+	Registry.Unregister(already.ExistingCollector)
+	return Registry.Register(c)
+}
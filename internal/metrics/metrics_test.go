@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegisterReplacesExisting(t *testing.T) {
+	opts := prometheus.GaugeOpts{Namespace: namespace, Name: "register_test", Help: "Test gauge."}
+	t.Cleanup(func() {
+		Registry.Unregister(prometheus.NewGaugeFunc(opts, func() float64 { return 0 }))
+	})
+
+	for _, value := range []float64{1, 2} {
+		value := value
+		gauge := prometheus.NewGaugeFunc(opts, func() float64 { return value })
+		if err := register(gauge); err != nil {
+			t.Fatalf("register() error = %v", err)
+		}
+		if got := testutil.ToFloat64(gauge); got != value {
+			t.Fatalf("gauge = %v, want %v", got, value)
+		}
+	}
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "theb_register_test" {
+			continue
+		}
+		if got := family.GetMetric()[0].GetGauge().GetValue(); got != 2 {
+			t.Errorf("registry reports %v, want the latest collector's 2", got)
+		}
+		return
+	}
+	t.Error("registry does not report the gauge")
+}
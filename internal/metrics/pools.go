@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+// RegisterDB exposes the Postgres connection pool stats from sql.DB.Stats
+func RegisterDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return register(collectors.NewDBStatsCollector(sqlDB, "postgres"))
+}
+
+// RegisterRedis exposes the Redis connection pool stats. client may be nil.
+func RegisterRedis(client *redis.Client) error {
+	if client == nil {
+		return nil
+	}
+	return register(&redisPoolCollector{client: client})
+}
+
+// RegisterWebSocketHub exposes the active connections of the hub serving route
+func RegisterWebSocketHub(route string, connections func() int) error {
+	return register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Subsystem:   "websocket",
+		Name:        "connections",
+		Help:        "Active WebSocket connections by route.",
+		ConstLabels: prometheus.Labels{"route": route},
+	}, func() float64 {
+		return float64(connections())
+	}))
+}
+
+var (
+	redisHitsDesc     = redisDesc("hits_total", "Times a free connection was found in the pool.")
+	redisMissesDesc   = redisDesc("misses_total", "Times a free connection was not found in the pool.")
+	redisTimeoutsDesc = redisDesc("timeouts_total", "Times a wait for a connection timed out.")
+	redisTotalDesc    = redisDesc("connections", "Connections in the pool.")
+	redisIdleDesc     = redisDesc("idle_connections", "Idle connections in the pool.")
+	redisStaleDesc    = redisDesc("stale_connections_total", "Stale connections removed from the pool.")
+)
+
+func redisDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", name), help, nil, nil)
+}
+
+// redisPoolCollector reads the pool stats on every scrape
+type redisPoolCollector struct {
+	client *redis.Client
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisHitsDesc
+	ch <- redisMissesDesc
+	ch <- redisTimeoutsDesc
+	ch <- redisTotalDesc
+	ch <- redisIdleDesc
+	ch <- redisStaleDesc
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(redisMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(redisTimeoutsDesc, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisTotalDesc, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisIdleDesc, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(redisStaleDesc, prometheus.CounterValue, float64(stats.StaleConns))
+}
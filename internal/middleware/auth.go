@@ -1,11 +1,23 @@
 package middleware
 
 import (
-	"net/http"
+	"errors"
 	"strings"
 
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// User roles carried in the JWT "role" claim
+const (
+	RolePassenger = "passenger"
+	RoleCaptain   = "captain"
+	RoleBoth      = "both"
+	RoleAdmin     = "admin"
 )
 
 // AuthMiddleware validates JWT tokens
@@ -13,20 +25,14 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header required",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.New(apierror.CodeAuthHeaderMissing))
 			return
 		}
 
 		// Extract token from "Bearer <token>"
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.New(apierror.CodeAuthHeaderInvalid))
 			return
 		}
 
@@ -37,11 +43,12 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return []byte(jwtSecret), nil
 		})
 
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			apierror.Abort(c, apierror.New(apierror.CodeAuthTokenExpired))
+			return
+		}
 		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid or expired token",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
 			return
 		}
 
@@ -49,6 +56,8 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		if claims, ok := token.Claims.(jwt.MapClaims); ok {
 			c.Set("user_id", claims["user_id"])
 			c.Set("role", claims["role"])
+			c.Set("name", claims["name"])
+			withUserLogContext(c, claims["user_id"])
 		}
 
 		c.Next()
@@ -75,6 +84,8 @@ func OptionalAuth(jwtSecret string) gin.HandlerFunc {
 				if claims, ok := token.Claims.(jwt.MapClaims); ok {
 					c.Set("user_id", claims["user_id"])
 					c.Set("role", claims["role"])
+					c.Set("name", claims["name"])
+					withUserLogContext(c, claims["user_id"])
 				}
 			}
 		}
@@ -82,3 +93,91 @@ func OptionalAuth(jwtSecret string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// queryTokenKey holds the ?access_token= StripAccessToken removed from the URL
+const queryTokenKey = "query_access_token"
+
+// StripAccessToken removes ?access_token= from the request URL so tracing and
+// request logs never record it, keeping it for QueryToken. It must run before
+// the tracing middleware.
+func StripAccessToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if token := query.Get("access_token"); token != "" {
+			c.Set(queryTokenKey, token)
+			query.Del("access_token")
+			c.Request.URL.RawQuery = query.Encode()
+			c.Request.RequestURI = c.Request.URL.RequestURI()
+		}
+
+		c.Next()
+	}
+}
+
+// QueryToken lets WebSocket clients, which cannot set headers from the browser,
+// pass the access token as ?access_token=. It must be used before AuthMiddleware,
+// on a router that runs StripAccessToken.
+func QueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.GetString(queryTokenKey); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole allows the request only if the authenticated user has one of the given roles.
+// It must be used after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		roleName, _ := role.(string)
+
+		for _, allowed := range roles {
+			if roleName == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		apierror.Abort(c, apierror.New(apierror.CodeForbidden))
+	}
+}
+
+// CurrentUserID returns the authenticated user's ID from the request context
+func CurrentUserID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	idString, ok := value.(string)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+// CurrentUserName returns the authenticated user's display name from the token's
+// name claim, or "" when the token has none
+func CurrentUserName(c *gin.Context) string {
+	value, _ := c.Get("name")
+	name, _ := value.(string)
+	return name
+}
+
+// withUserLogContext adds the authenticated user to the request context's log fields
+func withUserLogContext(c *gin.Context, userID interface{}) {
+	if id, ok := userID.(string); ok && id != "" {
+		c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), id))
+	}
+}
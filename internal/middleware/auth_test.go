@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStripAccessToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantURI    string
+		wantHeader string
+	}{
+		{name: "token moves to the header", target: "/ws/user?access_token=secret&lang=ar", wantURI: "/ws/user?lang=ar", wantHeader: "Bearer secret"},
+		{name: "token alone leaves no query", target: "/ws/user?access_token=secret", wantURI: "/ws/user", wantHeader: "Bearer secret"},
+		{name: "no token", target: "/ws/user?lang=ar", wantURI: "/ws/user?lang=ar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var uri, url, header string
+
+			router := gin.New()
+			router.Use(StripAccessToken())
+			router.GET("/ws/user", QueryToken(), func(c *gin.Context) {
+				uri = c.Request.RequestURI
+				url = c.Request.URL.String()
+				header = c.GetHeader("Authorization")
+				c.Status(http.StatusOK)
+			})
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if uri != tt.wantURI || url != tt.wantURI {
+				t.Errorf("RequestURI = %q, URL = %q, want %q", uri, url, tt.wantURI)
+			}
+			if header != tt.wantHeader {
+				t.Errorf("Authorization = %q, want %q", header, tt.wantHeader)
+			}
+		})
+	}
+}
@@ -1,39 +1,196 @@
 package middleware
 
 import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
 	"theb-backend/internal/config"
 
 	"github.com/gin-gonic/gin"
 )
 
-// CORS middleware handles Cross-Origin Resource Sharing
+// originPattern matches subdomains of a host, e.g. https://*.theb.app
+type originPattern struct {
+	scheme string
+	// suffix is the host without the wildcard label, e.g. ".theb.app"
+	suffix string
+	port   string
+}
+
+func (p originPattern) match(u *url.URL) bool {
+	host := u.Hostname()
+	return u.Scheme == p.scheme && u.Port() == p.port &&
+		len(host) > len(p.suffix) && strings.HasSuffix(host, p.suffix)
+}
+
+// corsPolicy is CORSConfig prepared for matching requests
+type corsPolicy struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	patterns    []originPattern
+	methods     map[string]bool
+	headers     map[string]bool
+	credentials bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+func newCORSPolicy(cfg config.CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		origins:       make(map[string]bool),
+		methods:       make(map[string]bool),
+		headers:       make(map[string]bool),
+		allowMethods:  strings.Join(cfg.AllowedMethods, ", "),
+		allowHeaders:  strings.Join(cfg.AllowedHeaders, ", "),
+		exposeHeaders: strings.Join(cfg.ExposedHeaders, ", "),
+		maxAge:        strconv.Itoa(int(cfg.MaxAge.Seconds())),
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			p.anyOrigin = true
+			continue
+		}
+
+		origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+		if u, err := url.Parse(origin); err == nil && strings.HasPrefix(u.Hostname(), "*.") {
+			p.patterns = append(p.patterns, originPattern{
+				scheme: u.Scheme,
+				suffix: u.Hostname()[1:],
+				port:   u.Port(),
+			})
+			continue
+		}
+		p.origins[origin] = true
+	}
+	for _, method := range cfg.AllowedMethods {
+		p.methods[strings.ToUpper(method)] = true
+	}
+	for _, header := range cfg.AllowedHeaders {
+		p.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	// A wildcard origin is never combined with credentials, since that would let
+	// any site make authenticated requests
+	p.credentials = cfg.AllowCredentials && !p.anyOrigin
+
+	return p
+}
+
+// allowOrigin reports whether a request from origin may read the response
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range p.patterns {
+		if pattern.match(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowPreflight reports whether the method and headers a preflight asks for are allowed
+func (p *corsPolicy) allowPreflight(method, headers string) bool {
+	if !p.methods[strings.ToUpper(method)] {
+		return false
+	}
+	for _, header := range strings.Split(headers, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !p.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
+
+// CORS applies the configured Cross-Origin Resource Sharing policy. Allowed origins
+// are echoed back, never reflected blindly; preflights asking for a disallowed origin,
+// method or header get 403. Requests without an Origin header pass through untouched.
 func CORS(cfg *config.Config) gin.HandlerFunc {
+	policy := newCORSPolicy(cfg.CORS)
+
 	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		// Responses differ by origin, so shared caches must key on it
+		header.Add("Vary", "Origin")
+
 		origin := c.Request.Header.Get("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		allowed := policy.allowOrigin(origin)
+		requestMethod := c.Request.Header.Get("Access-Control-Request-Method")
+
+		// Preflight
+		if c.Request.Method == http.MethodOptions && requestMethod != "" {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+
+			if !allowed || !policy.allowPreflight(requestMethod, c.Request.Header.Get("Access-Control-Request-Headers")) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
 
-		// Check if origin is allowed
-		allowed := false
-		for _, allowedOrigin := range cfg.CORS.AllowedOrigins {
-			if allowedOrigin == "*" || allowedOrigin == origin {
-				allowed = true
-				break
+			policy.setOrigin(header, origin)
+			header.Set("Access-Control-Allow-Methods", policy.allowMethods)
+			if policy.allowHeaders != "" {
+				header.Set("Access-Control-Allow-Headers", policy.allowHeaders)
 			}
+			header.Set("Access-Control-Max-Age", policy.maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
 		}
 
 		if allowed {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			policy.setOrigin(header, origin)
+			if policy.exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", policy.exposeHeaders)
+			}
 		}
 
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
-		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
+		c.Next()
+	}
+}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+// CheckOrigin returns a WebSocket origin check that accepts the configured CORS
+// origins. Requests without an Origin header come from native apps, not
+// browsers, and are accepted.
+func CheckOrigin(cfg config.CORSConfig) func(r *http.Request) bool {
+	policy := newCORSPolicy(cfg)
 
-		c.Next()
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || policy.allowOrigin(origin)
+	}
+}
+
+// setOrigin sets the allow-origin and allow-credentials headers for an allowed origin
+func (p *corsPolicy) setOrigin(header http.Header, origin string) {
+	if p.anyOrigin {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	header.Set("Access-Control-Allow-Origin", origin)
+	if p.credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
 	}
 }
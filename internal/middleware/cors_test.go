@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"theb-backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func corsConfig(origins []string, credentials bool) *config.Config {
+	return &config.Config{CORS: config.CORSConfig{
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Request-ID"},
+		AllowCredentials: credentials,
+		ExposedHeaders:   []string{"X-Request-ID", "RateLimit-Remaining"},
+		MaxAge:           time.Hour,
+	}}
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	origins := []string{"https://theb.app", "http://localhost:3000", "https://*.theb.app"}
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		method  string
+		headers map[string]string
+
+		wantStatus      int
+		wantOrigin      string
+		wantCredentials string
+		wantExpose      string
+		wantMethods     string
+		wantAllowHeads  string
+		wantMaxAge      string
+	}{
+		{
+			name:       "no origin passes through without CORS headers",
+			cfg:        corsConfig(origins, true),
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "exact origin is echoed with credentials and exposed headers",
+			cfg:             corsConfig(origins, true),
+			method:          http.MethodGet,
+			headers:         map[string]string{"Origin": "https://theb.app"},
+			wantStatus:      http.StatusOK,
+			wantOrigin:      "https://theb.app",
+			wantCredentials: "true",
+			wantExpose:      "X-Request-ID, RateLimit-Remaining",
+		},
+		{
+			name:       "credentials are omitted when not configured",
+			cfg:        corsConfig(origins, false),
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "http://localhost:3000"},
+			wantStatus: http.StatusOK,
+			wantOrigin: "http://localhost:3000",
+			wantExpose: "X-Request-ID, RateLimit-Remaining",
+		},
+		{
+			name:       "disallowed origin gets no CORS headers",
+			cfg:        corsConfig(origins, true),
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://evil.example"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "wildcard subdomain matches",
+			cfg:             corsConfig(origins, true),
+			method:          http.MethodGet,
+			headers:         map[string]string{"Origin": "https://admin.theb.app"},
+			wantStatus:      http.StatusOK,
+			wantOrigin:      "https://admin.theb.app",
+			wantCredentials: "true",
+			wantExpose:      "X-Request-ID, RateLimit-Remaining",
+		},
+		{
+			name:            "wildcard subdomain matches nested subdomains",
+			cfg:             corsConfig(origins, true),
+			method:          http.MethodGet,
+			headers:         map[string]string{"Origin": "https://a.b.theb.app"},
+			wantStatus:      http.StatusOK,
+			wantOrigin:      "https://a.b.theb.app",
+			wantCredentials: "true",
+			wantExpose:      "X-Request-ID, RateLimit-Remaining",
+		},
+		{
+			name:       "wildcard subdomain does not match a lookalike domain",
+			cfg:        corsConfig(origins, true),
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://eviltheb.app"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wildcard subdomain requires the same scheme",
+			cfg:        corsConfig(origins, true),
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "http://admin.theb.app"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wildcard subdomain requires the same port",
+			cfg:        corsConfig(origins, true),
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://admin.theb.app:8443"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "any origin is not reflected and never gets credentials",
+			cfg:        corsConfig([]string{"*"}, true),
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://anywhere.example"},
+			wantStatus: http.StatusOK,
+			wantOrigin: "*",
+			wantExpose: "X-Request-ID, RateLimit-Remaining",
+		},
+		{
+			name:   "allowed preflight returns the configured policy",
+			cfg:    corsConfig(origins, true),
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://theb.app",
+				"Access-Control-Request-Method":  "PUT",
+				"Access-Control-Request-Headers": "content-type, authorization",
+			},
+			wantStatus:      http.StatusNoContent,
+			wantOrigin:      "https://theb.app",
+			wantCredentials: "true",
+			wantMethods:     "GET, POST, PUT, DELETE, OPTIONS",
+			wantAllowHeads:  "Content-Type, Authorization, X-Request-ID",
+			wantMaxAge:      "3600",
+		},
+		{
+			name:   "preflight from disallowed origin is forbidden",
+			cfg:    corsConfig(origins, true),
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://evil.example",
+				"Access-Control-Request-Method": "GET",
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "preflight for disallowed method is forbidden",
+			cfg:    corsConfig(origins, true),
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://theb.app",
+				"Access-Control-Request-Method": "PATCH",
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "preflight for disallowed header is forbidden",
+			cfg:    corsConfig(origins, true),
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://theb.app",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "Content-Type, X-Debug",
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "OPTIONS without a requested method is not a preflight",
+			cfg:    corsConfig(origins, true),
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin": "https://theb.app",
+			},
+			wantStatus:      http.StatusOK,
+			wantOrigin:      "https://theb.app",
+			wantCredentials: "true",
+			wantExpose:      "X-Request-ID, RateLimit-Remaining",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(CORS(tt.cfg))
+			router.GET("/resource", func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.OPTIONS("/resource", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(tt.method, "/resource", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			checks := []struct {
+				header string
+				want   string
+			}{
+				{"Access-Control-Allow-Origin", tt.wantOrigin},
+				{"Access-Control-Allow-Credentials", tt.wantCredentials},
+				{"Access-Control-Expose-Headers", tt.wantExpose},
+				{"Access-Control-Allow-Methods", tt.wantMethods},
+				{"Access-Control-Allow-Headers", tt.wantAllowHeads},
+				{"Access-Control-Max-Age", tt.wantMaxAge},
+			}
+			for _, check := range checks {
+				if got := rec.Header().Get(check.header); got != check.want {
+					t.Errorf("%s = %q, want %q", check.header, got, check.want)
+				}
+			}
+
+			if vary := rec.Header().Values("Vary"); len(vary) == 0 || vary[0] != "Origin" {
+				t.Errorf("Vary = %v, want it to start with Origin", vary)
+			}
+		})
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	check := CheckOrigin(corsConfig([]string{"https://theb.app", "https://*.theb.app"}, true).CORS)
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{name: "native apps send no origin", want: true},
+		{name: "exact origin", origin: "https://theb.app", want: true},
+		{name: "subdomain pattern", origin: "https://admin.theb.app", want: true},
+		{name: "other site", origin: "https://evil.example"},
+		{name: "lookalike host", origin: "https://theb.app.evil.example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws/user", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if got := check(req); got != tt.want {
+				t.Errorf("CheckOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
@@ -31,16 +31,13 @@ func Logger() gin.HandlerFunc {
 			"user_agent": c.Request.UserAgent(),
 		}
 
-		if requestID := c.GetString("request_id"); requestID != "" {
-			fields["request_id"] = requestID
-		}
-
+		log := logger.FromContext(c.Request.Context())
 		if statusCode >= 500 {
-			logger.Error("HTTP Request", fields)
+			log.Error("HTTP Request", fields)
 		} else if statusCode >= 400 {
-			logger.Warn("HTTP Request", fields)
+			log.Warn("HTTP Request", fields)
 		} else {
-			logger.Info("HTTP Request", fields)
+			log.Info("HTTP Request", fields)
 		}
 	}
 }
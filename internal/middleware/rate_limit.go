@@ -1,10 +1,11 @@
 package middleware
 
 import (
-	"net/http"
 	"sync"
 	"time"
 
+	"theb-backend/internal/apierror"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -73,10 +74,7 @@ func RateLimit(requestsPerMinute int) gin.HandlerFunc {
 
 		if v.count > requestsPerMinute {
 			mu.Unlock()
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded. Please try again later.",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.New(apierror.CodeRateLimited))
 			return
 		}
 
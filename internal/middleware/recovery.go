@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"syscall"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/errreport"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery recovers panics in later handlers and answers with INTERNAL_ERROR in the
+// standard error body. The panic is logged with its stack, counted and sent to reporter;
+// the request's log context already carries request_id and user_id.
+func Recovery(reporter errreport.Reporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// net/http's sentinel for aborting a response; it must reach the server
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			ctx := c.Request.Context()
+			log := logger.FromContext(ctx)
+			route := c.FullPath()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			// The client went away mid-response; nothing to report or write
+			if isBrokenConnection(recovered) {
+				log.Warn("Client connection lost", map[string]interface{}{
+					"error":  fmt.Sprint(recovered),
+					"method": c.Request.Method,
+					"route":  route,
+				})
+				c.Abort()
+				return
+			}
+
+			log.Error("Panic recovered", map[string]interface{}{
+				"panic":  fmt.Sprint(recovered),
+				"stack":  string(debug.Stack()),
+				"method": c.Request.Method,
+				"route":  route,
+			})
+			metrics.HTTPPanics.WithLabelValues(c.Request.Method, route).Inc()
+
+			event := errreport.NewPanicEvent(recovered)
+			event.Tags["method"] = c.Request.Method
+			event.Tags["route"] = route
+			if requestID := c.GetString("request_id"); requestID != "" {
+				event.Tags["request_id"] = requestID
+			}
+			if userID, ok := CurrentUserID(c); ok {
+				event.User = &errreport.User{ID: userID.String()}
+			}
+			event.Request = &errreport.Request{
+				Method:      c.Request.Method,
+				URL:         c.Request.URL.Path,
+				QueryString: c.Request.URL.RawQuery,
+			}
+			if err := reporter.Report(ctx, event); err != nil {
+				log.Warn("Failed to report panic", map[string]interface{}{
+					"error":    err.Error(),
+					"event_id": event.EventID,
+				})
+			}
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			apierror.Abort(c, apierror.New(apierror.CodeInternal))
+		}()
+
+		c.Next()
+	}
+}
+
+// isBrokenConnection reports whether a panic came from writing to a client that disconnected
+func isBrokenConnection(recovered interface{}) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if errors.As(opErr, &syscallErr) {
+		return errors.Is(syscallErr.Err, syscall.EPIPE) || errors.Is(syscallErr.Err, syscall.ECONNRESET)
+	}
+	return false
+}
@@ -1,11 +1,16 @@
 package middleware
 
 import (
+	"theb-backend/internal/logger"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// RequestID adds a unique request ID to each request
+// RequestID adds a unique request ID to each request and to the request context,
+// so entries logged with logger.FromContext carry it, and to the request's span
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader("X-Request-ID")
@@ -14,7 +19,9 @@ func RequestID() gin.HandlerFunc {
 		}
 
 		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), requestID))
 		c.Writer.Header().Set("X-Request-ID", requestID)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request_id", requestID))
 
 		c.Next()
 	}
@@ -0,0 +1,180 @@
+package module
+
+import (
+	"errors"
+	"fmt"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMissingDependency is returned when an enabled module requires one that is disabled or unknown
+	ErrMissingDependency = errors.New("required module is not enabled")
+	// ErrDependencyOrder is returned when a module is added before a module it requires
+	ErrDependencyOrder = errors.New("required module is added later")
+)
+
+// Module is a domain package that plugs itself into the application
+type Module interface {
+	// Name identifies the module in config and logs
+	Name() string
+	// Requires names the modules whose services this module resolves while registering
+	// or handling events. They must be enabled and added before it.
+	Requires() []string
+	// Migrations returns the models whose tables the module owns
+	Migrations() []interface{}
+	// Register adds the module's services to the container. Tables are migrated first.
+	Register(ctn *container.Container) error
+	// Jobs returns the cron jobs the module schedules
+	Jobs(ctn *container.Container) ([]jobs.CronJob, error)
+	// Routes mounts the module's endpoints on the v1 API and WebSocket groups
+	Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error
+}
+
+// Registry boots modules in the order they were added, skipping those disabled in config.
+// A module must be added after the modules it requires.
+type Registry struct {
+	modules  []Module
+	migrated []interface{}
+}
+
+// NewRegistry creates a registry of the given modules
+func NewRegistry(modules ...Module) *Registry {
+	return &Registry{modules: modules}
+}
+
+// Enabled returns the modules not disabled in cfg. Unknown names in cfg are an error,
+// so a typo cannot silently leave a module running, and so is an enabled module
+// whose required modules are not enabled before it.
+func (r *Registry) Enabled(cfg config.ModulesConfig) ([]Module, error) {
+	known := make(map[string]bool, len(r.modules))
+	for _, m := range r.modules {
+		known[m.Name()] = true
+	}
+	for name := range cfg {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown module in config: %s", name)
+		}
+	}
+
+	enabled := make([]Module, 0, len(r.modules))
+	for _, m := range r.modules {
+		if cfg.Enabled(m.Name()) {
+			enabled = append(enabled, m)
+		}
+	}
+	if err := checkRequires(enabled); err != nil {
+		return nil, err
+	}
+	return enabled, nil
+}
+
+// checkRequires reports the first enabled module whose required modules are not
+// all enabled before it, so a disabled dependency fails boot by name rather than
+// with the first service its dependant cannot resolve
+func checkRequires(modules []Module) error {
+	position := make(map[string]int, len(modules))
+	for i, m := range modules {
+		position[m.Name()] = i
+	}
+
+	for i, m := range modules {
+		for _, name := range m.Requires() {
+			j, ok := position[name]
+			if !ok {
+				return fmt.Errorf("%w: %s requires %s", ErrMissingDependency, m.Name(), name)
+			}
+			if j > i {
+				return fmt.Errorf("%w: %s requires %s", ErrDependencyOrder, m.Name(), name)
+			}
+		}
+	}
+	return nil
+}
+
+// Register migrates, registers and schedules the jobs of every enabled module
+func (r *Registry) Register(ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	scheduler, err := container.Resolve[*jobs.Scheduler](ctn)
+	if err != nil {
+		return err
+	}
+
+	modules, err := r.Enabled(cfg.Modules)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range modules {
+		if models := m.Migrations(); len(models) > 0 {
+			if err := db.AutoMigrate(models...); err != nil {
+				return fmt.Errorf("failed to migrate %s tables: %w", m.Name(), err)
+			}
+			r.migrated = append(r.migrated, models...)
+		}
+
+		// Values a module supplies depend on what it resolved, for the lifecycle order
+		if err := m.Register(ctn.Tracked()); err != nil {
+			return fmt.Errorf("failed to register %s module: %w", m.Name(), err)
+		}
+
+		moduleJobs, err := m.Jobs(ctn)
+		if err != nil {
+			return fmt.Errorf("failed to load %s jobs: %w", m.Name(), err)
+		}
+		for _, job := range moduleJobs {
+			if err := scheduler.Schedule(job); err != nil {
+				return fmt.Errorf("failed to schedule %s job %s: %w", m.Name(), job.Name, err)
+			}
+		}
+	}
+
+	names := make([]string, len(modules))
+	for i, m := range modules {
+		names[i] = m.Name()
+	}
+	logger.Info("Modules registered", map[string]interface{}{
+		"modules": names,
+	})
+
+	return nil
+}
+
+// Migrated returns the models of every module migrated by Register
+func (r *Registry) Migrated() []interface{} {
+	return r.migrated
+}
+
+// Routes mounts the endpoints of every enabled module
+func (r *Registry) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+
+	modules, err := r.Enabled(cfg.Modules)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range modules {
+		if err := m.Routes(v1, ws, ctn); err != nil {
+			return fmt.Errorf("failed to mount %s routes: %w", m.Name(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/jobs"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// registered records the order in which fake modules register
+type registered struct {
+	names []string
+}
+
+// fakeModule provides a service named after itself and resolves those of the modules it requires
+type fakeModule struct {
+	name     string
+	requires []string
+	log      *registered
+}
+
+// provided is the service a fake module registers, keyed by module name
+type provided struct {
+	module string
+}
+
+func (m fakeModule) Name() string { return m.name }
+
+func (m fakeModule) Requires() []string { return m.requires }
+
+func (m fakeModule) Migrations() []interface{} { return nil }
+
+func (m fakeModule) Register(ctn *container.Container) error {
+	for _, name := range m.requires {
+		if _, err := container.Resolve[*provided](ctn, container.Named(name)); err != nil {
+			return err
+		}
+	}
+	container.Supply(ctn, &provided{module: m.name}, container.Named(m.name))
+	m.log.names = append(m.log.names, m.name)
+	return nil
+}
+
+func (m fakeModule) Jobs(ctn *container.Container) ([]jobs.CronJob, error) {
+	return []jobs.CronJob{{
+		Name:     m.name + "_job",
+		Schedule: "@every 1h",
+		Run:      func(ctx context.Context) error { return nil },
+	}}, nil
+}
+
+func (m fakeModule) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	v1.GET("/"+m.name, func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return nil
+}
+
+// newRegistry returns a registry of settings <- city <- zone, ledger <- wallet
+func newRegistry(log *registered) *Registry {
+	return NewRegistry(
+		fakeModule{name: "settings", log: log},
+		fakeModule{name: "city", requires: []string{"settings"}, log: log},
+		fakeModule{name: "zone", requires: []string{"city"}, log: log},
+		fakeModule{name: "ledger", log: log},
+		fakeModule{name: "wallet", requires: []string{"ledger"}, log: log},
+	)
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []Module
+		cfg     config.ModulesConfig
+		want    []string
+		wantErr error
+		message string
+	}{
+		{
+			name: "everything enabled by default",
+			want: []string{"settings", "city", "zone", "ledger", "wallet"},
+		},
+		{
+			name: "dependants disabled with their dependency",
+			cfg:  config.ModulesConfig{"city": false, "zone": false},
+			want: []string{"settings", "ledger", "wallet"},
+		},
+		{
+			name: "independent subset",
+			cfg:  config.ModulesConfig{"settings": false, "city": false, "zone": false},
+			want: []string{"ledger", "wallet"},
+		},
+		{
+			name:    "disabled dependency is named",
+			cfg:     config.ModulesConfig{"ledger": false},
+			wantErr: ErrMissingDependency,
+			message: "wallet requires ledger",
+		},
+		{
+			name:    "indirect dependency disabled",
+			cfg:     config.ModulesConfig{"settings": false},
+			wantErr: ErrMissingDependency,
+			message: "city requires settings",
+		},
+		{
+			name: "dependency added after its dependant",
+			modules: []Module{
+				fakeModule{name: "wallet", requires: []string{"ledger"}},
+				fakeModule{name: "ledger"},
+			},
+			wantErr: ErrDependencyOrder,
+			message: "wallet requires ledger",
+		},
+		{
+			name:    "dependency that is not a module",
+			modules: []Module{fakeModule{name: "wallet", requires: []string{"ledgr"}}},
+			wantErr: ErrMissingDependency,
+			message: "wallet requires ledgr",
+		},
+		{
+			name:    "unknown module in config",
+			cfg:     config.ModulesConfig{"walet": false},
+			message: "unknown module in config: walet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newRegistry(&registered{})
+			if tt.modules != nil {
+				registry = NewRegistry(tt.modules...)
+			}
+
+			enabled, err := registry.Enabled(tt.cfg)
+			if tt.message != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.message) {
+					t.Fatalf("Enabled() = %v, want an error containing %q", err, tt.message)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Enabled() = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Enabled() returned %v", err)
+			}
+
+			names := make([]string, len(enabled))
+			for i, m := range enabled {
+				names[i] = m.Name()
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Enabled() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+// newContainer returns a container with the services Register resolves
+func newContainer(modules config.ModulesConfig) *container.Container {
+	ctn := container.New()
+	container.Supply(ctn, &config.Config{Modules: modules})
+	container.Supply(ctn, (*gorm.DB)(nil))
+	container.Supply(ctn, jobs.NewScheduler(config.JobsConfig{}, jobs.NewMemoryStore(), jobs.NewLocalLocker()))
+	return ctn
+}
+
+func TestRegisterSubset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	log := &registered{}
+	registry := newRegistry(log)
+	ctn := newContainer(config.ModulesConfig{"city": false, "zone": false})
+
+	if err := registry.Register(ctn); err != nil {
+		t.Fatalf("Register() returned %v", err)
+	}
+	if want := []string{"settings", "ledger", "wallet"}; !reflect.DeepEqual(log.names, want) {
+		t.Errorf("registered %v, want %v", log.names, want)
+	}
+	if container.Has[*provided](ctn, container.Named("city")) {
+		t.Error("disabled module registered its services")
+	}
+
+	engine := gin.New()
+	if err := registry.Routes(engine.Group("/api/v1"), engine.Group("/ws"), ctn); err != nil {
+		t.Fatalf("Routes() returned %v", err)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/settings": http.StatusNoContent,
+		"/api/v1/wallet":   http.StatusNoContent,
+		"/api/v1/city":     http.StatusNotFound,
+		"/api/v1/zone":     http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestRegisterMissingDependency(t *testing.T) {
+	log := &registered{}
+	registry := newRegistry(log)
+	ctn := newContainer(config.ModulesConfig{"ledger": false})
+
+	err := registry.Register(ctn)
+	if !errors.Is(err, ErrMissingDependency) || !strings.Contains(err.Error(), "wallet requires ledger") {
+		t.Fatalf("Register() = %v, want the missing ledger module named", err)
+	}
+	if len(log.names) != 0 {
+		t.Errorf("modules %v registered before the dependency check", log.names)
+	}
+}
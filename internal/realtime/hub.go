@@ -0,0 +1,286 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/config"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// channel carries events between instances so a user connected anywhere receives them
+	channel = "realtime:user_events"
+
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	sendBuffer = 32
+)
+
+// Event is a message pushed to a user's WebSocket
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// envelope is an event addressed to a user, as published on Redis
+type envelope struct {
+	UserID uuid.UUID       `json:"user_id"`
+	Event  json.RawMessage `json:"event"`
+}
+
+// client is one open WebSocket of a user
+type client struct {
+	userID uuid.UUID
+	conn   *websocket.Conn
+	send   chan []byte
+}
+
+// Hub tracks each user's WebSocket connections and delivers events to them.
+// With Redis, events are fanned out to every instance through pub/sub;
+// without it (development) delivery is local to this process.
+type Hub struct {
+	redis    *redis.Client
+	upgrader websocket.Upgrader
+
+	mu      sync.RWMutex
+	clients map[uuid.UUID]map[*client]struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewHub creates a hub. redisClient may be nil. Browsers do not apply CORS to
+// WebSocket handshakes, so the hub checks Origin against the CORS origins itself.
+func NewHub(redisClient *redis.Client, cors config.CORSConfig) *Hub {
+	return &Hub{
+		redis: redisClient,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     middleware.CheckOrigin(cors),
+		},
+		clients: make(map[uuid.UUID]map[*client]struct{}),
+	}
+}
+
+// Start subscribes to events published by other instances
+func (h *Hub) Start() {
+	if h.redis == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	pubsub := h.redis.Subscribe(ctx, channel)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var env envelope
+				if err := json.Unmarshal([]byte(message.Payload), &env); err != nil {
+					logger.Warn("Invalid realtime event", map[string]interface{}{
+						"error": err.Error(),
+					})
+					continue
+				}
+				h.deliver(env.UserID, env.Event)
+			}
+		}
+	}()
+}
+
+// Stop closes every connection and the Redis subscription
+func (h *Hub) Stop(ctx context.Context) error {
+	if h.cancel != nil {
+		h.cancel()
+	}
+
+	h.mu.Lock()
+	for _, clients := range h.clients {
+		for c := range clients {
+			c.conn.Close()
+		}
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Send delivers an event to all of a user's open connections
+func (h *Hub) Send(ctx context.Context, userID uuid.UUID, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if h.redis == nil {
+		h.deliver(userID, data)
+		return nil
+	}
+
+	payload, err := json.Marshal(envelope{UserID: userID, Event: data})
+	if err != nil {
+		return err
+	}
+
+	return h.redis.Publish(ctx, channel, payload).Err()
+}
+
+// Connections returns the number of open connections on this instance
+func (h *Hub) Connections() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	count := 0
+	for _, clients := range h.clients {
+		count += len(clients)
+	}
+	return count
+}
+
+// Serve upgrades an authenticated request to the user's WebSocket
+func (h *Hub) Serve(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error
+		return
+	}
+
+	cl := &client{userID: userID, conn: conn, send: make(chan []byte, sendBuffer)}
+	h.add(cl)
+
+	h.wg.Add(2)
+	go h.writePump(cl)
+	go h.readPump(cl)
+}
+
+func (h *Hub) add(cl *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[cl.userID] == nil {
+		h.clients[cl.userID] = make(map[*client]struct{})
+	}
+	h.clients[cl.userID][cl] = struct{}{}
+}
+
+func (h *Hub) remove(cl *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients := h.clients[cl.userID]
+	if _, ok := clients[cl]; !ok {
+		return
+	}
+	delete(clients, cl)
+	if len(clients) == 0 {
+		delete(h.clients, cl.userID)
+	}
+	close(cl.send)
+}
+
+// deliver queues data on this instance's connections of the user. Slow
+// connections whose buffer is full are dropped; the client reconnects and
+// reloads the inbox.
+func (h *Hub) deliver(userID uuid.UUID, data []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for cl := range h.clients[userID] {
+		select {
+		case cl.send <- data:
+		default:
+			cl.conn.Close()
+		}
+	}
+}
+
+// readPump discards client messages and keeps the read deadline alive with pongs
+func (h *Hub) readPump(cl *client) {
+	defer h.wg.Done()
+	defer func() {
+		h.remove(cl)
+		cl.conn.Close()
+	}()
+
+	cl.conn.SetReadLimit(4096)
+	_ = cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	cl.conn.SetPongHandler(func(string) error {
+		return cl.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := cl.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump writes queued events and periodic pings
+func (h *Hub) writePump(cl *client) {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		cl.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-cl.send:
+			_ = cl.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				_ = cl.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := cl.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = cl.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := cl.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -2,28 +2,67 @@ package router
 
 import (
 	"net/http"
+	"strings"
 
+	"theb-backend/internal/apierror"
 	"theb-backend/internal/config"
 	"theb-backend/internal/container"
+	"theb-backend/internal/errreport"
+	"theb-backend/internal/health"
+	"theb-backend/internal/metrics"
 	"theb-backend/internal/middleware"
+	"theb-backend/internal/module"
+	"theb-backend/internal/realtime"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-// New creates and configures the router
-func New(cfg *config.Config, ctn *container.Container) *gin.Engine {
+// New creates and configures the router and mounts the routes of every enabled module
+func New(cfg *config.Config, ctn *container.Container, modules *module.Registry) (*gin.Engine, error) {
 	router := gin.New()
 
-	// Global middleware
-	router.Use(gin.Recovery())
+	reporter, err := container.Resolve[errreport.Reporter](ctn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Global middleware. WebSocket access tokens are taken out of the URL before
+	// anything records it. Tracing runs next so the request ID and logs join its span;
+	// WebSocket connections are skipped since they would become hours-long spans,
+	// and health probes since they would drown out real traffic.
+	router.Use(middleware.StripAccessToken())
+	router.Use(otelgin.Middleware(cfg.App.Name, otelgin.WithFilter(func(r *http.Request) bool {
+		return !strings.HasPrefix(r.URL.Path, "/ws/") && !strings.HasPrefix(r.URL.Path, "/health")
+	})))
+	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger())
+	router.Use(middleware.Metrics())
+	// Panics and errors become the standard error envelope here, inside the logger
+	// and metrics so they record the final status
+	router.Use(middleware.Recovery(reporter))
+	router.Use(apierror.Middleware())
 	router.Use(middleware.CORS(cfg))
-	router.Use(middleware.RequestID())
+	router.NoRoute(apierror.NotFound)
 
-	// Health check endpoint
+	// Health check endpoints. Liveness only says the process serves requests;
+	// readiness checks dependencies and fails while the instance drains.
+	checker, err := container.Resolve[*health.Checker](ctn)
+	if err != nil {
+		return nil, err
+	}
+	router.GET("/health/live", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+	})
+	router.GET("/health/ready", func(c *gin.Context) {
+		report := checker.Run(c.Request.Context())
+		c.JSON(readyStatus(report), report)
+	})
 	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ok",
+		report := checker.Run(c.Request.Context())
+		c.JSON(readyStatus(report), gin.H{
+			"status": report.Status,
+			"checks": report.Checks,
 			"app":    cfg.App.Name,
 			"env":    cfg.App.Env,
 		})
@@ -32,16 +71,6 @@ func New(cfg *config.Config, ctn *container.Container) *gin.Engine {
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
-		// TODO: Register service routes here
-		// Example:
-		// auth := v1.Group("/auth")
-		// {
-		//     authHandler := getAuthHandler(ctn)
-		//     auth.POST("/login", authHandler.Login)
-		//     auth.POST("/verify", authHandler.Verify)
-		//     auth.POST("/refresh", authHandler.Refresh)
-		// }
-
 		v1.GET("/ping", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"message": "pong"})
 		})
@@ -50,14 +79,39 @@ func New(cfg *config.Config, ctn *container.Container) *gin.Engine {
 	// WebSocket routes
 	ws := router.Group("/ws")
 	{
-		// TODO: Register WebSocket routes here
-		// ws.GET("/location/stream", wsHandler.LocationStream)
-		// ws.GET("/rides/:id", wsHandler.RideUpdates)
-
 		ws.GET("/ping", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"message": "websocket ready"})
 		})
+
+		hub, err := container.Resolve[*realtime.Hub](ctn)
+		if err != nil {
+			return nil, err
+		}
+		ws.GET("/user", middleware.QueryToken(), middleware.AuthMiddleware(cfg.JWT.Secret), hub.Serve)
+		if err := metrics.RegisterWebSocketHub("/ws/user", hub.Connections); err != nil {
+			return nil, err
+		}
+	}
+
+	// Service module routes
+	if err := modules.Routes(v1, ws, ctn); err != nil {
+		return nil, err
+	}
+
+	return router, nil
+}
+
+// readyStatus maps a readiness report to 200, or 503 when the instance should not get traffic
+func readyStatus(report health.Report) int {
+	if report.Ready() {
+		return http.StatusOK
 	}
+	return http.StatusServiceUnavailable
+}
 
-	return router
+// NewAdmin creates the handler served on the admin port, kept off the public listener
+func NewAdmin() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return mux
 }
@@ -0,0 +1,116 @@
+package dtos
+
+import (
+	"time"
+
+	"theb-backend/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+// SetOnlineRequest toggles captain availability
+type SetOnlineRequest struct {
+	Online *bool `json:"online" binding:"required"`
+} // @name SetOnlineRequest
+
+// OnlineStatusResponse is the captain's availability after a toggle
+type OnlineStatusResponse struct {
+	CaptainID uuid.UUID  `json:"captain_id"`
+	CityID    *uuid.UUID `json:"city_id,omitempty"`
+	IsOnline  bool       `json:"is_online"`
+} // @name OnlineStatusResponse
+
+// UpdateLocationRequest is an online captain's live location
+type UpdateLocationRequest struct {
+	Location geo.Point `json:"location"`
+} // @name UpdateLocationRequest
+
+// LocationResponse is the captain's recorded location
+type LocationResponse struct {
+	CaptainID uuid.UUID `json:"captain_id"`
+	Location  geo.Point `json:"location"`
+} // @name LocationResponse
+
+// AssignCityRequest moves a captain to a city
+type AssignCityRequest struct {
+	CityID uuid.UUID `json:"city_id" binding:"required"`
+} // @name AssignCaptainCityRequest
+
+// CaptainCityResponse is the city a captain works in
+type CaptainCityResponse struct {
+	CaptainID uuid.UUID  `json:"captain_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	CityID    *uuid.UUID `json:"city_id,omitempty"`
+} // @name CaptainCityResponse
+
+// BalanceResponse is the captain's position with the platform, in fils
+type BalanceResponse struct {
+	Balance        int64  `json:"balance"`
+	Available      int64  `json:"available_for_cash_out"`
+	CommissionDebt int64  `json:"commission_debt"`
+	DebtLimit      int64  `json:"commission_debt_limit"`
+	Blocked        bool   `json:"blocked"`
+	Currency       string `json:"currency"`
+} // @name CaptainBalanceResponse
+
+// EarningsDay is a captain's completed rides over one UTC day, in fils
+type EarningsDay struct {
+	Day        string `json:"day" example:"2024-05-01"`
+	Rides      int64  `json:"rides"`
+	Fares      int64  `json:"fares"`
+	Commission int64  `json:"commission"`
+	Net        int64  `json:"net"`
+} // @name CaptainEarningsDay
+
+// EarningsResponse lists a captain's daily earnings, newest first. Today's
+// rides appear once the nightly rollup has run.
+type EarningsResponse struct {
+	Days     []EarningsDay `json:"days"`
+	Currency string        `json:"currency"`
+} // @name CaptainEarningsResponse
+
+// CashOutRequest asks for the captain's earnings to be paid out
+type CashOutRequest struct {
+	Amount int64  `json:"amount" binding:"required,gt=0"`
+	Method string `json:"method" binding:"required,oneof=cash bank_transfer cliq"`
+} // @name CaptainCashOutRequest
+
+// SettlementRequest declares a commission debt payment by the captain
+type SettlementRequest struct {
+	Amount          int64  `json:"amount" binding:"required,gt=0"`
+	Method          string `json:"method" binding:"required,oneof=cash bank_transfer cliq"`
+	ReferenceNumber string `json:"reference_number" binding:"omitempty,max=64"`
+} // @name CaptainSettlementRequest
+
+// RejectRequest rejects a cash-out or settlement
+type RejectRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+} // @name CashOutRejectRequest
+
+// MarkPaidRequest records the payment reference for a cash-out or settlement
+type MarkPaidRequest struct {
+	ReferenceNumber string `json:"reference_number" binding:"required,max=64"`
+} // @name CashOutMarkPaidRequest
+
+// CashOutResponse is a cash-out or settlement request
+type CashOutResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	Type            string     `json:"type"`
+	Amount          int64      `json:"amount"`
+	Status          string     `json:"status"`
+	Method          string     `json:"method,omitempty"`
+	ReferenceNumber string     `json:"reference_number,omitempty"`
+	RejectReason    string     `json:"reject_reason,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	PaidAt          *time.Time `json:"paid_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+} // @name CashOutResponse
+
+// CashOutListResponse is a page of cash-out and settlement requests
+type CashOutListResponse struct {
+	Requests []CashOutResponse `json:"requests"`
+	Page     int               `json:"page"`
+	PerPage  int               `json:"per_page"`
+	Total    int64             `json:"total"`
+} // @name CashOutListResponse
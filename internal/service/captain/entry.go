@@ -0,0 +1,184 @@
+package captain
+
+import (
+	"context"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/events"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/metrics"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/captain/handlers"
+	"theb-backend/internal/service/captain/models"
+	"theb-backend/internal/service/captain/repositories"
+	"theb-backend/internal/service/captain/services"
+	cities "theb-backend/internal/service/city/services"
+	ledger "theb-backend/internal/service/ledger/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// Module wires captain availability, presence, earnings and cash-outs
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "captain" }
+
+// Requires returns the modules whose services captain resolves or whose rides it
+// rolls up into earnings
+func (Module) Requires() []string { return []string{"ledger", "city", "order"} }
+
+// Migrations returns the captain tables
+func (Module) Migrations() []interface{} {
+	return []interface{}{&models.Captain{}, &models.CashOutRequest{}, &models.DailyEarnings{}}
+}
+
+// Register registers the captain repositories, settlement, presence and captain
+// services and handlers in the container, exposes online captains as a metric and
+// collects commission from completed rides
+func (Module) Register(ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	redisClient, err := container.Resolve[*redis.Client](ctn)
+	if err != nil {
+		return err
+	}
+	ledgerService, err := container.Resolve[*ledger.LedgerService](ctn)
+	if err != nil {
+		return err
+	}
+	bus, err := container.Resolve[*events.Bus](ctn)
+	if err != nil {
+		return err
+	}
+	cityService, err := container.Resolve[*cities.CityService](ctn)
+	if err != nil {
+		return err
+	}
+
+	captainRepo := repositories.NewCaptainRepository(db)
+	cashOutRepo := repositories.NewCashOutRepository(db)
+	settlementService := services.NewSettlementService(cfg.Captain, ledgerService, cashOutRepo)
+	presenceService := services.NewPresenceService(captainRepo, redisClient, cfg.Captain.OfflineAfter)
+	captainService := services.NewCaptainService(captainRepo, settlementService, presenceService, cityService)
+	earningsService := services.NewEarningsService(repositories.NewEarningsRepository(db))
+
+	container.Supply(ctn, captainRepo)
+	container.Supply(ctn, cashOutRepo)
+	container.Supply(ctn, settlementService)
+	container.Supply(ctn, presenceService)
+	container.Supply(ctn, captainService)
+	container.Supply(ctn, earningsService)
+	container.Supply(ctn, handlers.NewCaptainHandler(captainService, cityService))
+	container.Supply(ctn, handlers.NewSettlementHandler(settlementService, earningsService))
+
+	if err := metrics.RegisterOnlineCaptains(captainRepo.CountOnline); err != nil {
+		return err
+	}
+
+	// The commission posts in the event's transaction, so it commits exactly once
+	events.On(bus, "captain.commission", func(ctx context.Context, tx *gorm.DB, event events.RideCompleted) error {
+		_, err := settlementService.RecordRideCommission(tx, event.RideID, event.CaptainUserID, event.Fare)
+		return err
+	})
+
+	return nil
+}
+
+// Jobs returns the auto-offline job, since captains whose app stopped sending
+// locations should not be offered rides or counted as supply, and the nightly
+// rollup of completed rides into daily earnings
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return nil, err
+	}
+	captainRepo, err := container.Resolve[*repositories.CaptainRepository](ctn)
+	if err != nil {
+		return nil, err
+	}
+	presenceService, err := container.Resolve[*services.PresenceService](ctn)
+	if err != nil {
+		return nil, err
+	}
+	earningsService, err := container.Resolve[*services.EarningsService](ctn)
+	if err != nil {
+		return nil, err
+	}
+
+	return []jobs.CronJob{{
+		Name:     "captains_auto_offline",
+		Schedule: "@every 1m",
+		Run: func(ctx context.Context) error {
+			count, err := captainRepo.SetOfflineStale(ctx, time.Now().UTC().Add(-cfg.Captain.OfflineAfter))
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				logger.Info("Marked stale captains offline", map[string]interface{}{
+					"count": count,
+				})
+			}
+			_, err = presenceService.Prune(ctx)
+			return err
+		},
+	}, {
+		Name:     "captains_earnings_rollup",
+		Schedule: "15 0 * * *",
+		Run: func(ctx context.Context) error {
+			return earningsService.RollUp(ctx, time.Now())
+		},
+	}}, nil
+}
+
+// Routes mounts captain, captain city and cash-out admin endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	captainHandler, err := container.Resolve[*handlers.CaptainHandler](ctn)
+	if err != nil {
+		return err
+	}
+	settlementHandler, err := container.Resolve[*handlers.SettlementHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+
+	captains := v1.Group("/captains", auth, middleware.RequireRole(middleware.RoleCaptain, middleware.RoleBoth))
+	{
+		captains.POST("/online", captainHandler.SetOnline)
+		captains.PUT("/location", captainHandler.UpdateLocation)
+		captains.GET("/balance", settlementHandler.GetBalance)
+		captains.GET("/earnings", settlementHandler.GetEarnings)
+		captains.GET("/cashouts", settlementHandler.ListMine)
+		captains.POST("/cashouts", settlementHandler.RequestCashOut)
+		captains.POST("/settlements", settlementHandler.RequestSettlement)
+	}
+
+	v1.PUT("/admin/captains/:id/city", auth, middleware.RequireRole(middleware.RoleAdmin), captainHandler.AssignCity)
+
+	admin := v1.Group("/admin/cashouts", auth, middleware.RequireRole(middleware.RoleAdmin))
+	{
+		admin.GET("", settlementHandler.AdminList)
+		admin.POST("/:id/approve", settlementHandler.Approve)
+		admin.POST("/:id/reject", settlementHandler.Reject)
+		admin.POST("/:id/paid", settlementHandler.MarkPaid)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/captain/dtos"
+	"theb-backend/internal/service/captain/services"
+	cities "theb-backend/internal/service/city/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CaptainHandler serves captain availability and admin city assignment endpoints
+type CaptainHandler struct {
+	service *services.CaptainService
+	cities  *cities.CityService
+}
+
+// NewCaptainHandler creates a new captain handler
+func NewCaptainHandler(service *services.CaptainService, cityService *cities.CityService) *CaptainHandler {
+	return &CaptainHandler{service: service, cities: cityService}
+}
+
+// SetOnline toggles the authenticated captain's availability
+// @Summary Toggle captain online status
+// @ID captain-online
+// @Tags Captain
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.SetOnlineRequest true "Online flag"
+// @Success 200 {object} dtos.OnlineStatusResponse
+// @Router /api/v1/captains/online [post]
+func (h *CaptainHandler) SetOnline(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.SetOnlineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	captain, err := h.service.SetOnline(c.Request.Context(), userID, *req.Online)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCaptainNotFound):
+			apierror.Abort(c, apierror.Wrap(apierror.CodeCaptainNotFound, err))
+		case errors.Is(err, services.ErrCommissionDebtLimit):
+			apierror.Abort(c, apierror.Wrap(apierror.CodeCaptainCommissionDebtLimit, err))
+		default:
+			logger.FromContext(c.Request.Context()).Error("Failed to update online status", map[string]interface{}{
+				"error": err.Error(),
+			})
+			apierror.Abort(c, apierror.New(apierror.CodeInternal))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.OnlineStatusResponse{
+		CaptainID: captain.ID,
+		CityID:    captain.CityID,
+		IsOnline:  captain.IsOnline,
+	})
+}
+
+// UpdateLocation records the authenticated captain's live location. Apps send it
+// every few seconds while online; captains who stop sending it are marked offline.
+// @Summary Update captain location
+// @ID captain-location
+// @Tags Captain
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.UpdateLocationRequest true "Location"
+// @Success 200 {object} dtos.LocationResponse
+// @Router /api/v1/captains/location [put]
+func (h *CaptainHandler) UpdateLocation(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.UpdateLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	captain, err := h.service.UpdateLocation(c.Request.Context(), userID, req.Location)
+	if err != nil {
+		h.handleError(c, "Failed to update captain location", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.LocationResponse{
+		CaptainID: captain.ID,
+		Location:  req.Location,
+	})
+}
+
+// AssignCity moves a captain to a city. The admin must manage both the captain's
+// current city and the new one.
+// @Summary Assign a captain to a city (admin)
+// @ID admin-captains-city
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Captain ID"
+// @Param request body dtos.AssignCityRequest true "City"
+// @Success 200 {object} dtos.CaptainCityResponse
+// @Router /api/v1/admin/captains/{id}/city [put]
+func (h *CaptainHandler) AssignCity(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	captainID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return
+	}
+
+	var req dtos.AssignCityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	scope, err := h.cities.Scope(c.Request.Context(), adminID)
+	if err != nil {
+		h.handleError(c, "Failed to load admin cities", err)
+		return
+	}
+	current, err := h.service.Get(c.Request.Context(), captainID)
+	if err != nil {
+		h.handleError(c, "Failed to assign captain city", err)
+		return
+	}
+	if (current.CityID != nil && !scope.Allows(current.CityID)) || !scope.Allows(&req.CityID) {
+		apierror.Abort(c, apierror.New(apierror.CodeCityAccessDenied))
+		return
+	}
+
+	captain, err := h.service.AssignCity(c.Request.Context(), captainID, req.CityID)
+	if err != nil {
+		h.handleError(c, "Failed to assign captain city", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.CaptainCityResponse{
+		CaptainID: captain.ID,
+		UserID:    captain.UserID,
+		CityID:    captain.CityID,
+	})
+}
+
+func (h *CaptainHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrCaptainNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeCaptainNotFound, err))
+	case errors.Is(err, services.ErrUnknownCity):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeCityNotFound, err))
+	case errors.Is(err, services.ErrCaptainOffline):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeCaptainOffline, err))
+	default:
+		logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+			"error": err.Error(),
+		})
+		apierror.Abort(c, apierror.New(apierror.CodeInternal))
+	}
+}
@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/captain/dtos"
+	"theb-backend/internal/service/captain/models"
+	"theb-backend/internal/service/captain/services"
+	"theb-backend/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SettlementHandler serves captain balance, earnings, cash-out and settlement endpoints
+type SettlementHandler struct {
+	service  *services.SettlementService
+	earnings *services.EarningsService
+}
+
+// NewSettlementHandler creates a new settlement handler
+func NewSettlementHandler(service *services.SettlementService, earnings *services.EarningsService) *SettlementHandler {
+	return &SettlementHandler{service: service, earnings: earnings}
+}
+
+// GetBalance returns the authenticated captain's balance and commission debt
+// @Summary Get captain balance
+// @ID captain-balance
+// @Tags Captain
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dtos.BalanceResponse
+// @Router /api/v1/captains/balance [get]
+func (h *SettlementHandler) GetBalance(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	balance, err := h.service.Balance(c.Request.Context(), userID)
+	if err != nil {
+		h.internalError(c, "Failed to load captain balance", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BalanceResponse{
+		Balance:        balance.Balance,
+		Available:      balance.Available,
+		CommissionDebt: balance.CommissionDebt,
+		DebtLimit:      balance.DebtLimit,
+		Blocked:        balance.Blocked,
+		Currency:       "JOD",
+	})
+}
+
+// GetEarnings returns the authenticated captain's daily earnings
+// @Summary Get captain daily earnings
+// @ID captain-earnings
+// @Tags Captain
+// @Security BearerAuth
+// @Produce json
+// @Param days query int false "Number of past days, at most 90" default(90)
+// @Success 200 {object} dtos.EarningsResponse
+// @Router /api/v1/captains/earnings [get]
+func (h *SettlementHandler) GetEarnings(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	days, _ := strconv.Atoi(c.Query("days"))
+	earnings, err := h.earnings.Daily(c.Request.Context(), userID, days)
+	if err != nil {
+		h.internalError(c, "Failed to load captain earnings", err)
+		return
+	}
+
+	response := dtos.EarningsResponse{Days: make([]dtos.EarningsDay, 0, len(earnings)), Currency: "JOD"}
+	for _, day := range earnings {
+		response.Days = append(response.Days, dtos.EarningsDay{
+			Day:        day.Day.Format(time.DateOnly),
+			Rides:      day.Rides,
+			Fares:      day.Fares,
+			Commission: day.Commission,
+			Net:        day.Net,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RequestCashOut asks for the captain's earnings to be paid out
+// @Summary Request a cash-out
+// @ID captain-cashout-request
+// @Tags Captain
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.CashOutRequest true "Amount in fils and payout method"
+// @Success 201 {object} dtos.CashOutResponse
+// @Router /api/v1/captains/cashouts [post]
+func (h *SettlementHandler) RequestCashOut(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.CashOutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	request, err := h.service.RequestCashOut(c.Request.Context(), userID, req.Amount, req.Method)
+	if err != nil {
+		h.handleError(c, "Failed to request cash-out", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toResponse(request))
+}
+
+// RequestSettlement declares a commission debt payment
+// @Summary Settle commission debt
+// @ID captain-settlement-request
+// @Tags Captain
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.SettlementRequest true "Amount in fils and payment method"
+// @Success 201 {object} dtos.CashOutResponse
+// @Router /api/v1/captains/settlements [post]
+func (h *SettlementHandler) RequestSettlement(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.SettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	request, err := h.service.RequestSettlement(c.Request.Context(), userID, req.Amount, req.Method, req.ReferenceNumber)
+	if err != nil {
+		h.handleError(c, "Failed to request settlement", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toResponse(request))
+}
+
+// ListMine returns the authenticated captain's cash-out and settlement requests
+// @Summary List my cash-out requests
+// @ID captain-cashout-list
+// @Tags Captain
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} dtos.CashOutListResponse
+// @Router /api/v1/captains/cashouts [get]
+func (h *SettlementHandler) ListMine(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	h.list(c, &userID)
+}
+
+// AdminList returns cash-out and settlement requests for all captains
+// @Summary List cash-out requests (admin)
+// @ID admin-cashout-list
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Filter by status"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} dtos.CashOutListResponse
+// @Router /api/v1/admin/cashouts [get]
+func (h *SettlementHandler) AdminList(c *gin.Context) {
+	h.list(c, nil)
+}
+
+// Approve approves a pending request
+// @Summary Approve a cash-out request (admin)
+// @ID admin-cashout-approve
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Request ID"
+// @Success 200 {object} dtos.CashOutResponse
+// @Router /api/v1/admin/cashouts/{id}/approve [post]
+func (h *SettlementHandler) Approve(c *gin.Context) {
+	adminID, requestID, ok := h.adminAndRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	request, err := h.service.Approve(c.Request.Context(), adminID, requestID)
+	if err != nil {
+		h.handleError(c, "Failed to approve request", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toResponse(request))
+}
+
+// Reject rejects an open request and releases any held funds
+// @Summary Reject a cash-out request (admin)
+// @ID admin-cashout-reject
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Request ID"
+// @Param request body dtos.RejectRequest true "Rejection reason"
+// @Success 200 {object} dtos.CashOutResponse
+// @Router /api/v1/admin/cashouts/{id}/reject [post]
+func (h *SettlementHandler) Reject(c *gin.Context) {
+	adminID, requestID, ok := h.adminAndRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.RejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	request, err := h.service.Reject(c.Request.Context(), adminID, requestID, req.Reason)
+	if err != nil {
+		h.handleError(c, "Failed to reject request", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toResponse(request))
+}
+
+// MarkPaid records that an approved request was paid. A settlement credits at most
+// the commission the captain still owes.
+// @Summary Mark a cash-out request as paid (admin)
+// @ID admin-cashout-paid
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Request ID"
+// @Param request body dtos.MarkPaidRequest true "Payment reference number"
+// @Success 200 {object} dtos.CashOutResponse
+// @Router /api/v1/admin/cashouts/{id}/paid [post]
+func (h *SettlementHandler) MarkPaid(c *gin.Context) {
+	adminID, requestID, ok := h.adminAndRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.MarkPaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	request, err := h.service.MarkPaid(c.Request.Context(), adminID, requestID, req.ReferenceNumber)
+	if err != nil {
+		h.handleError(c, "Failed to mark request as paid", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toResponse(request))
+}
+
+func (h *SettlementHandler) list(c *gin.Context, userID *uuid.UUID) {
+	params := pagination.FromQuery(c)
+	requests, total, err := h.service.List(c.Request.Context(), userID, c.Query("status"), params)
+	if err != nil {
+		h.internalError(c, "Failed to load cash-out requests", err)
+		return
+	}
+
+	response := dtos.CashOutListResponse{
+		Requests: make([]dtos.CashOutResponse, 0, len(requests)),
+		Page:     params.Page,
+		PerPage:  params.PerPage,
+		Total:    total,
+	}
+	for i := range requests {
+		response.Requests = append(response.Requests, toResponse(&requests[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *SettlementHandler) adminAndRequestIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	requestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return adminID, requestID, true
+}
+
+func (h *SettlementHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAmount):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeAmountInvalid, err))
+	case errors.Is(err, services.ErrBelowMinimumCashOut):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettlementBelowMinimum, err))
+	case errors.Is(err, services.ErrExceedsBalance):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettlementExceedsBalance, err))
+	case errors.Is(err, services.ErrExceedsDebt):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettlementExceedsDebt, err))
+	case errors.Is(err, services.ErrRequestNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettlementRequestNotFound, err))
+	case errors.Is(err, services.ErrOpenRequestExists):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettlementRequestOpen, err))
+	case errors.Is(err, services.ErrInvalidTransition):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettlementInvalidTransition, err))
+	default:
+		h.internalError(c, message, err)
+	}
+}
+
+func (h *SettlementHandler) internalError(c *gin.Context, message string, err error) {
+	logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+		"error": err.Error(),
+	})
+	apierror.Abort(c, apierror.New(apierror.CodeInternal))
+}
+
+func toResponse(request *models.CashOutRequest) dtos.CashOutResponse {
+	return dtos.CashOutResponse{
+		ID:              request.ID,
+		UserID:          request.UserID,
+		Type:            request.Type,
+		Amount:          request.Amount,
+		Status:          request.Status,
+		Method:          request.Method,
+		ReferenceNumber: request.ReferenceNumber,
+		RejectReason:    request.RejectReason,
+		ReviewedAt:      request.ReviewedAt,
+		PaidAt:          request.PaidAt,
+		CreatedAt:       request.CreatedAt,
+	}
+}
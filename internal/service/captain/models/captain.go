@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Captain holds the details of a user who works as a captain
+type Captain struct {
+	ID              uuid.UUID  `gorm:"column:captain_id;type:uuid;primaryKey" json:"captain_id"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	CityID          *uuid.UUID `gorm:"type:uuid;index" json:"city_id,omitempty"`
+	VehicleType     string     `gorm:"type:varchar(32)" json:"vehicle_type"`
+	VehicleModel    string     `gorm:"type:varchar(64)" json:"vehicle_model"`
+	VehicleYear     string     `gorm:"type:varchar(4)" json:"vehicle_year"`
+	PlateNumber     string     `gorm:"type:varchar(16)" json:"plate_number"`
+	LicenseVerified bool       `gorm:"not null;default:false" json:"license_verified"`
+	IsOnline        bool       `gorm:"not null;default:false;index" json:"is_online"`
+	CurrentLat      *float64   `json:"current_lat,omitempty"`
+	CurrentLng      *float64   `json:"current_lng,omitempty"`
+	LastUpdated     *time.Time `json:"last_updated,omitempty"`
+}
+
+// TableName overrides the default table name
+func (Captain) TableName() string {
+	return "captains"
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cash-out request types
+const (
+	// RequestTypeCashOut pays a captain's positive balance out to them
+	RequestTypeCashOut = "cash_out"
+	// RequestTypeSettlement records a captain paying off commission debt
+	RequestTypeSettlement = "settlement"
+)
+
+// Cash-out request statuses
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusPaid     = "paid"
+)
+
+// CashOutRequest is a captain's request to withdraw earnings or settle commission debt.
+// Amount is in fils.
+type CashOutRequest struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type            string     `gorm:"type:varchar(16);not null" json:"type"`
+	Amount          int64      `gorm:"not null" json:"amount"`
+	Status          string     `gorm:"type:varchar(16);not null;index" json:"status"`
+	Method          string     `gorm:"type:varchar(32)" json:"method,omitempty"`
+	ReferenceNumber string     `gorm:"type:varchar(64)" json:"reference_number,omitempty"`
+	RejectReason    string     `gorm:"type:varchar(255)" json:"reject_reason,omitempty"`
+	ReviewedBy      *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	PaidAt          *time.Time `json:"paid_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (CashOutRequest) TableName() string {
+	return "captain_cash_out_requests"
+}
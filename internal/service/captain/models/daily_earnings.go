@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DailyEarnings sums a captain's completed rides over one UTC day. Amounts are in
+// fils; Net is what the captain kept after the platform's commission.
+type DailyEarnings struct {
+	UserID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	Day        time.Time `gorm:"type:date;primaryKey" json:"day"`
+	Rides      int64     `gorm:"not null" json:"rides"`
+	Fares      int64     `gorm:"not null" json:"fares"`
+	Commission int64     `gorm:"not null" json:"commission"`
+	Net        int64     `gorm:"not null" json:"net"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (DailyEarnings) TableName() string {
+	return "captain_daily_earnings"
+}
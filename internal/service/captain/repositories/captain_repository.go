@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"theb-backend/internal/service/captain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CaptainRepository provides data access for captains
+type CaptainRepository struct {
+	db *gorm.DB
+}
+
+// NewCaptainRepository creates a new captain repository
+func NewCaptainRepository(db *gorm.DB) *CaptainRepository {
+	return &CaptainRepository{db: db}
+}
+
+// FindByUserID returns the captain profile for a user, or nil if the user is not a captain
+func (r *CaptainRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*models.Captain, error) {
+	var captain models.Captain
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&captain).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &captain, nil
+}
+
+// FindByID returns a captain by captain ID, or nil if it does not exist
+func (r *CaptainRepository) FindByID(ctx context.Context, captainID uuid.UUID) (*models.Captain, error) {
+	var captain models.Captain
+	err := r.db.WithContext(ctx).Where("captain_id = ?", captainID).First(&captain).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &captain, nil
+}
+
+// SetOnline updates a captain's availability
+func (r *CaptainRepository) SetOnline(ctx context.Context, captainID uuid.UUID, online bool) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Captain{}).
+		Where("captain_id = ?", captainID).
+		Updates(map[string]interface{}{
+			"is_online":    online,
+			"last_updated": time.Now().UTC(),
+		}).Error
+}
+
+// SetLocation records a captain's live location
+func (r *CaptainRepository) SetLocation(ctx context.Context, captainID uuid.UUID, lat, lng float64) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Captain{}).
+		Where("captain_id = ?", captainID).
+		Updates(map[string]interface{}{
+			"current_lat":  lat,
+			"current_lng":  lng,
+			"last_updated": time.Now().UTC(),
+		}).Error
+}
+
+// OnlineWithLocation returns online captains whose location was updated at or after since
+func (r *CaptainRepository) OnlineWithLocation(ctx context.Context, since time.Time) ([]models.Captain, error) {
+	var captains []models.Captain
+	err := r.db.WithContext(ctx).
+		Where("is_online = ? AND last_updated >= ? AND current_lat IS NOT NULL AND current_lng IS NOT NULL", true, since).
+		Find(&captains).Error
+	return captains, err
+}
+
+// FindOnline returns the captains among captainIDs that are online
+func (r *CaptainRepository) FindOnline(ctx context.Context, captainIDs []uuid.UUID) ([]models.Captain, error) {
+	var captains []models.Captain
+	if len(captainIDs) == 0 {
+		return captains, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("captain_id IN ? AND is_online = ?", captainIDs, true).
+		Find(&captains).Error
+	return captains, err
+}
+
+// SetCity assigns a captain to a city
+func (r *CaptainRepository) SetCity(ctx context.Context, captainID, cityID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Captain{}).
+		Where("captain_id = ?", captainID).
+		Update("city_id", cityID).Error
+}
+
+// SetOfflineStale marks online captains offline when their last update is older than before
+func (r *CaptainRepository) SetOfflineStale(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Captain{}).
+		Where("is_online = ? AND last_updated < ?", true, before).
+		Updates(map[string]interface{}{
+			"is_online":    false,
+			"last_updated": time.Now().UTC(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// CountOnline returns the number of captains currently online
+func (r *CaptainRepository) CountOnline(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Captain{}).Where("is_online = ?", true).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"theb-backend/internal/service/captain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CashOutRepository provides data access for cash-out and settlement requests
+type CashOutRepository struct {
+	db *gorm.DB
+}
+
+// NewCashOutRepository creates a new cash-out repository
+func NewCashOutRepository(db *gorm.DB) *CashOutRepository {
+	return &CashOutRepository{db: db}
+}
+
+// Create inserts a new request inside tx
+func (r *CashOutRepository) Create(tx *gorm.DB, request *models.CashOutRequest) error {
+	return tx.Create(request).Error
+}
+
+// Save persists changes to a request inside tx
+func (r *CashOutRepository) Save(tx *gorm.DB, request *models.CashOutRequest) error {
+	return tx.Save(request).Error
+}
+
+// LockByID loads a request with a row lock held until tx ends, or nil if it does not exist
+func (r *CashOutRepository) LockByID(tx *gorm.DB, id uuid.UUID) (*models.CashOutRequest, error) {
+	var request models.CashOutRequest
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// HasOpen reports whether the user already has a pending or approved request of the given type
+func (r *CashOutRepository) HasOpen(tx *gorm.DB, userID uuid.UUID, requestType string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.CashOutRequest{}).
+		Where("user_id = ? AND type = ? AND status IN ?", userID, requestType, []string{models.StatusPending, models.StatusApproved}).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// List returns a page of requests, newest first, optionally filtered by user and status
+func (r *CashOutRepository) List(ctx context.Context, userID *uuid.UUID, status string, offset, limit int) ([]models.CashOutRequest, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.CashOutRequest{})
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var requests []models.CashOutRequest
+	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&requests).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return requests, total, nil
+}
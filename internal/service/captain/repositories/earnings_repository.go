@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"theb-backend/internal/service/captain/models"
+	ledgermodels "theb-backend/internal/service/ledger/models"
+	ordermodels "theb-backend/internal/service/order/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EarningsRepository provides data access for captains' daily earnings
+type EarningsRepository struct {
+	db *gorm.DB
+}
+
+// NewEarningsRepository creates a new earnings repository
+func NewEarningsRepository(db *gorm.DB) *EarningsRepository {
+	return &EarningsRepository{db: db}
+}
+
+// Sum totals the rides each captain completed in [from, to) with the commission
+// charged on them. Day is left for the caller to set.
+func (r *EarningsRepository) Sum(ctx context.Context, from, to time.Time) ([]models.DailyEarnings, error) {
+	var rows []models.DailyEarnings
+	err := r.db.WithContext(ctx).
+		Table("rides AS r").
+		Select("c.user_id, COUNT(*) AS rides, COALESCE(SUM(r.fare_final), 0) AS fares, COALESCE(SUM(-e.amount), 0) AS commission").
+		Joins("JOIN captains AS c ON c.captain_id = r.captain_id").
+		Joins("LEFT JOIN ledger_transactions AS t ON t.ride_id = r.ride_id AND t.kind = ?", ledgermodels.KindRideCommission).
+		Joins("LEFT JOIN ledger_entries AS e ON e.transaction_id = t.id AND e.amount < 0").
+		Where("r.status = ? AND r.completed_at >= ? AND r.completed_at < ?", ordermodels.StatusCompleted, from, to).
+		Group("c.user_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Replace stores the earnings of day, removing those of captains no longer in rows
+func (r *EarningsRepository) Replace(ctx context.Context, day time.Time, rows []models.DailyEarnings) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("day = ?", day).Delete(&models.DailyEarnings{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+// List returns a captain's earnings for the days in [from, to), newest first
+func (r *EarningsRepository) List(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.DailyEarnings, error) {
+	var rows []models.DailyEarnings
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND day >= ? AND day < ?", userID, from, to).
+		Order("day DESC").
+		Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"theb-backend/internal/service/captain/models"
+	"theb-backend/internal/service/captain/repositories"
+	cities "theb-backend/internal/service/city/services"
+	"theb-backend/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCaptainNotFound is returned when the user has no captain profile
+	ErrCaptainNotFound = errors.New("captain profile not found")
+	// ErrUnknownCity is returned when assigning a captain to a city that does not exist
+	ErrUnknownCity = errors.New("city does not exist")
+	// ErrCaptainOffline is returned when an offline captain sends their location
+	ErrCaptainOffline = errors.New("captain is offline")
+)
+
+// CaptainService implements captain availability, location and city assignment
+type CaptainService struct {
+	repo       *repositories.CaptainRepository
+	settlement *SettlementService
+	presence   *PresenceService
+	cities     *cities.CityService
+}
+
+// NewCaptainService creates a new captain service
+func NewCaptainService(repo *repositories.CaptainRepository, settlement *SettlementService, presence *PresenceService, cityService *cities.CityService) *CaptainService {
+	return &CaptainService{
+		repo:       repo,
+		settlement: settlement,
+		presence:   presence,
+		cities:     cityService,
+	}
+}
+
+// SetOnline toggles a captain's availability. Captains whose commission debt is
+// over the limit cannot go online until they settle; going offline is always allowed.
+// A captain going online without a city is assigned the default city.
+func (s *CaptainService) SetOnline(ctx context.Context, userID uuid.UUID, online bool) (*models.Captain, error) {
+	captain, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if captain == nil {
+		return nil, ErrCaptainNotFound
+	}
+
+	if online {
+		if err := s.settlement.CheckCanGoOnline(ctx, userID); err != nil {
+			return nil, err
+		}
+		if captain.CityID == nil {
+			cityID := s.cities.Default().ID
+			if err := s.repo.SetCity(ctx, captain.ID, cityID); err != nil {
+				return nil, err
+			}
+			captain.CityID = &cityID
+		}
+	}
+
+	if err := s.repo.SetOnline(ctx, captain.ID, online); err != nil {
+		return nil, err
+	}
+	captain.IsOnline = online
+
+	if !online {
+		if err := s.presence.Remove(ctx, captain.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return captain, nil
+}
+
+// UpdateLocation records an online captain's live location, which also keeps them
+// from being marked offline
+func (s *CaptainService) UpdateLocation(ctx context.Context, userID uuid.UUID, point geo.Point) (*models.Captain, error) {
+	captain, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if captain == nil {
+		return nil, ErrCaptainNotFound
+	}
+	if !captain.IsOnline {
+		return nil, ErrCaptainOffline
+	}
+
+	if err := s.presence.Update(ctx, captain.ID, point); err != nil {
+		return nil, err
+	}
+	captain.CurrentLat, captain.CurrentLng = &point.Lat, &point.Lng
+
+	return captain, nil
+}
+
+// Get returns a captain by captain ID
+func (s *CaptainService) Get(ctx context.Context, captainID uuid.UUID) (*models.Captain, error) {
+	captain, err := s.repo.FindByID(ctx, captainID)
+	if err != nil {
+		return nil, err
+	}
+	if captain == nil {
+		return nil, ErrCaptainNotFound
+	}
+	return captain, nil
+}
+
+// AssignCity moves a captain to a city; they are offered rides there only
+func (s *CaptainService) AssignCity(ctx context.Context, captainID, cityID uuid.UUID) (*models.Captain, error) {
+	if _, ok := s.cities.City(cityID); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCity, cityID)
+	}
+
+	captain, err := s.Get(ctx, captainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetCity(ctx, captain.ID, cityID); err != nil {
+		return nil, err
+	}
+	captain.CityID = &cityID
+
+	return captain, nil
+}
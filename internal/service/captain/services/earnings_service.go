@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"theb-backend/internal/service/captain/models"
+	"theb-backend/internal/service/captain/repositories"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// rollupDays is how many past days each rollup recomputes, so a missed night
+	// and rides completed late in the day are still counted
+	rollupDays = 2
+	// maxEarningsDays is the longest history a captain can list
+	maxEarningsDays = 90
+)
+
+// EarningsService rolls completed rides up into each captain's daily earnings
+type EarningsService struct {
+	repo *repositories.EarningsRepository
+}
+
+// NewEarningsService creates a new earnings service
+func NewEarningsService(repo *repositories.EarningsRepository) *EarningsService {
+	return &EarningsService{repo: repo}
+}
+
+// RollUp recomputes the earnings of the rollupDays UTC days before now. Each day
+// is replaced as a whole, so running it again gives the same result.
+func (s *EarningsService) RollUp(ctx context.Context, now time.Time) error {
+	today := day(now)
+	for i := rollupDays; i >= 1; i-- {
+		from := today.AddDate(0, 0, -i)
+		rows, err := s.repo.Sum(ctx, from, from.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+		for j := range rows {
+			rows[j].Day = from
+			rows[j].Net = rows[j].Fares - rows[j].Commission
+		}
+		if err := s.repo.Replace(ctx, from, rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Daily returns the captain's rolled-up earnings for the last days UTC days,
+// newest first. Today is not rolled up yet.
+func (s *EarningsService) Daily(ctx context.Context, userID uuid.UUID, days int) ([]models.DailyEarnings, error) {
+	if days <= 0 || days > maxEarningsDays {
+		days = maxEarningsDays
+	}
+	today := day(time.Now())
+	return s.repo.List(ctx, userID, today.AddDate(0, 0, -days), today)
+}
+
+// day returns the start of t's UTC day
+func day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"theb-backend/internal/service/captain/repositories"
+	"theb-backend/pkg/geo"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const (
+	// locationsKey is a Redis geo set of online captains' last locations
+	locationsKey = "captains:locations"
+	// seenKey is a sorted set of online captains scored by the unix time of their last location
+	seenKey = "captains:seen"
+)
+
+// Presence is an online captain's last known location
+type Presence struct {
+	CaptainID uuid.UUID
+	Point     geo.Point
+	SeenAt    time.Time
+}
+
+// PresenceService tracks where online captains are. Locations are kept in Redis so
+// supply can be counted without scanning the captains table; without Redis the
+// table's current location is used instead.
+type PresenceService struct {
+	repo  *repositories.CaptainRepository
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+// NewPresenceService creates a presence service. Captains whose last location is
+// older than ttl are not present. redisClient may be nil.
+func NewPresenceService(repo *repositories.CaptainRepository, redisClient *redis.Client, ttl time.Duration) *PresenceService {
+	return &PresenceService{
+		repo:  repo,
+		redis: redisClient,
+		ttl:   ttl,
+	}
+}
+
+// Update records a captain's location
+func (s *PresenceService) Update(ctx context.Context, captainID uuid.UUID, point geo.Point) error {
+	if err := s.repo.SetLocation(ctx, captainID, point.Lat, point.Lng); err != nil {
+		return err
+	}
+	if s.redis == nil {
+		return nil
+	}
+
+	member := captainID.String()
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.GeoAdd(ctx, locationsKey, &redis.GeoLocation{Name: member, Latitude: point.Lat, Longitude: point.Lng})
+		pipe.ZAdd(ctx, seenKey, &redis.Z{Score: float64(time.Now().Unix()), Member: member})
+		return nil
+	})
+	return err
+}
+
+// Remove forgets a captain's location, e.g. when they go offline
+func (s *PresenceService) Remove(ctx context.Context, captainID uuid.UUID) error {
+	if s.redis == nil {
+		return nil
+	}
+	return s.remove(ctx, captainID.String())
+}
+
+// Available returns the captains whose location is fresh
+func (s *PresenceService) Available(ctx context.Context) ([]Presence, error) {
+	since := time.Now().Add(-s.ttl)
+
+	if s.redis == nil {
+		captains, err := s.repo.OnlineWithLocation(ctx, since)
+		if err != nil {
+			return nil, err
+		}
+		present := make([]Presence, 0, len(captains))
+		for _, captain := range captains {
+			present = append(present, Presence{
+				CaptainID: captain.ID,
+				Point:     geo.Point{Lat: *captain.CurrentLat, Lng: *captain.CurrentLng},
+				SeenAt:    *captain.LastUpdated,
+			})
+		}
+		return present, nil
+	}
+
+	seen, err := s.redis.ZRangeByScoreWithScores(ctx, seenKey, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil || len(seen) == 0 {
+		return nil, err
+	}
+
+	members := make([]string, len(seen))
+	for i, z := range seen {
+		members[i] = z.Member.(string)
+	}
+	positions, err := s.redis.GeoPos(ctx, locationsKey, members...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	present := make([]Presence, 0, len(seen))
+	for i, pos := range positions {
+		captainID, err := uuid.Parse(members[i])
+		if pos == nil || err != nil {
+			continue
+		}
+		present = append(present, Presence{
+			CaptainID: captainID,
+			Point:     geo.Point{Lat: pos.Latitude, Lng: pos.Longitude},
+			SeenAt:    time.Unix(int64(seen[i].Score), 0).UTC(),
+		})
+	}
+	return present, nil
+}
+
+// Prune forgets captains whose location is stale and returns how many were removed
+func (s *PresenceService) Prune(ctx context.Context) (int, error) {
+	if s.redis == nil {
+		return 0, nil
+	}
+
+	stale, err := s.redis.ZRangeByScore(ctx, seenKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(time.Now().Add(-s.ttl).Unix(), 10),
+	}).Result()
+	if err != nil || len(stale) == 0 {
+		return 0, err
+	}
+	return len(stale), s.remove(ctx, stale...)
+}
+
+func (s *PresenceService) remove(ctx context.Context, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, member := range members {
+		args[i] = member
+	}
+
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, locationsKey, args...)
+		pipe.ZRem(ctx, seenKey, args...)
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/service/captain/models"
+	"theb-backend/internal/service/captain/repositories"
+	ledgermodels "theb-backend/internal/service/ledger/models"
+	ledger "theb-backend/internal/service/ledger/services"
+	"theb-backend/pkg/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidAmount is returned for zero or negative amounts
+	ErrInvalidAmount = errors.New("amount must be positive")
+	// ErrBelowMinimumCashOut is returned when a cash-out is smaller than the configured minimum
+	ErrBelowMinimumCashOut = errors.New("amount is below the minimum cash-out")
+	// ErrExceedsBalance is returned when a cash-out is larger than the available balance
+	ErrExceedsBalance = errors.New("amount exceeds available balance")
+	// ErrExceedsDebt is returned when a settlement is larger than the commission owed
+	ErrExceedsDebt = errors.New("amount exceeds commission owed")
+	// ErrOpenRequestExists is returned when the captain already has an open request of the same type
+	ErrOpenRequestExists = errors.New("an open request of this type already exists")
+	// ErrRequestNotFound is returned when a cash-out request does not exist
+	ErrRequestNotFound = errors.New("cash-out request not found")
+	// ErrInvalidTransition is returned when a request cannot move to the requested status
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrCommissionDebtLimit is returned when a captain owes more commission than allowed
+	ErrCommissionDebtLimit = errors.New("commission debt exceeds the allowed limit; please settle before going online")
+)
+
+// transitions lists the statuses each status may move to
+var transitions = map[string][]string{
+	models.StatusPending:  {models.StatusApproved, models.StatusRejected},
+	models.StatusApproved: {models.StatusPaid, models.StatusRejected},
+}
+
+// CaptainBalance summarises what the platform and a captain owe each other, in fils
+type CaptainBalance struct {
+	Balance        int64
+	Available      int64
+	CommissionDebt int64
+	DebtLimit      int64
+	Blocked        bool
+}
+
+// SettlementService manages captain commission, cash-outs and debt settlement on the ledger
+type SettlementService struct {
+	cfg    config.CaptainConfig
+	ledger *ledger.LedgerService
+	repo   *repositories.CashOutRepository
+}
+
+// NewSettlementService creates a new settlement service
+func NewSettlementService(cfg config.CaptainConfig, ledgerService *ledger.LedgerService, repo *repositories.CashOutRepository) *SettlementService {
+	return &SettlementService{
+		cfg:    cfg,
+		ledger: ledgerService,
+		repo:   repo,
+	}
+}
+
+// Balance returns the captain's current position with the platform
+func (s *SettlementService) Balance(ctx context.Context, userID uuid.UUID) (CaptainBalance, error) {
+	balance, err := s.ledger.Balance(ctx, userID, ledgermodels.AccountCaptainBalance)
+	if err != nil {
+		return CaptainBalance{}, err
+	}
+
+	return s.summarise(balance), nil
+}
+
+// CheckCanGoOnline returns ErrCommissionDebtLimit if the captain must settle first
+func (s *SettlementService) CheckCanGoOnline(ctx context.Context, userID uuid.UUID) error {
+	balance, err := s.Balance(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if balance.Blocked {
+		return ErrCommissionDebtLimit
+	}
+
+	return nil
+}
+
+// RecordRideCommission charges the platform commission for a completed ride to the captain
+// in tx, so it commits with the caller's own changes. It is idempotent per ride and
+// returns the commission amount.
+func (s *SettlementService) RecordRideCommission(tx *gorm.DB, rideID, userID uuid.UUID, fare int64) (int64, error) {
+	if fare <= 0 {
+		return 0, ErrInvalidAmount
+	}
+
+	commission := int64(math.Round(float64(fare) * s.cfg.CommissionRate))
+	if commission <= 0 {
+		return 0, nil
+	}
+
+	_, err := s.ledger.Post(tx, ledger.Posting{
+		Kind:        ledgermodels.KindRideCommission,
+		Reference:   "ride_commission:" + rideID.String(),
+		Description: "Platform commission",
+		RideID:      &rideID,
+		Lines: []ledger.Line{
+			{OwnerID: userID, AccountType: ledgermodels.AccountCaptainBalance, Amount: -commission},
+			{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemCommission, Amount: commission},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return commission, nil
+}
+
+// RequestCashOut creates a cash-out request and holds the amount until it is paid or rejected
+func (s *SettlementService) RequestCashOut(ctx context.Context, userID uuid.UUID, amount int64, method string) (*models.CashOutRequest, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if amount < s.cfg.MinCashOut {
+		return nil, ErrBelowMinimumCashOut
+	}
+
+	request := &models.CashOutRequest{
+		ID:     uuid.New(),
+		UserID: userID,
+		Type:   models.RequestTypeCashOut,
+		Amount: amount,
+		Status: models.StatusPending,
+		Method: method,
+	}
+
+	err := s.ledger.Transaction(ctx, func(tx *gorm.DB) error {
+		captainAccount := ledger.AccountRef{OwnerID: userID, AccountType: ledgermodels.AccountCaptainBalance}
+		balances, err := s.ledger.LockBalances(tx, captainAccount)
+		if err != nil {
+			return err
+		}
+		if amount > balances[captainAccount] {
+			return ErrExceedsBalance
+		}
+
+		open, err := s.repo.HasOpen(tx, userID, models.RequestTypeCashOut)
+		if err != nil {
+			return err
+		}
+		if open {
+			return ErrOpenRequestExists
+		}
+
+		if err := s.repo.Create(tx, request); err != nil {
+			return err
+		}
+
+		_, err = s.ledger.Post(tx, ledger.Posting{
+			Kind:        ledgermodels.KindCashOutHold,
+			Reference:   "cashout_hold:" + request.ID.String(),
+			Description: "Cash-out requested",
+			CreatedBy:   &userID,
+			Lines: []ledger.Line{
+				{OwnerID: userID, AccountType: ledgermodels.AccountCaptainBalance, Amount: -amount},
+				{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemPayoutsPending, Amount: amount},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// RequestSettlement records that a captain wants to pay off commission debt.
+// The ledger is only updated once an admin marks the settlement as paid.
+func (s *SettlementService) RequestSettlement(ctx context.Context, userID uuid.UUID, amount int64, method, referenceNumber string) (*models.CashOutRequest, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	request := &models.CashOutRequest{
+		ID:              uuid.New(),
+		UserID:          userID,
+		Type:            models.RequestTypeSettlement,
+		Amount:          amount,
+		Status:          models.StatusPending,
+		Method:          method,
+		ReferenceNumber: referenceNumber,
+	}
+
+	err := s.ledger.Transaction(ctx, func(tx *gorm.DB) error {
+		captainAccount := ledger.AccountRef{OwnerID: userID, AccountType: ledgermodels.AccountCaptainBalance}
+		balances, err := s.ledger.LockBalances(tx, captainAccount)
+		if err != nil {
+			return err
+		}
+		if amount > -balances[captainAccount] {
+			return ErrExceedsDebt
+		}
+
+		open, err := s.repo.HasOpen(tx, userID, models.RequestTypeSettlement)
+		if err != nil {
+			return err
+		}
+		if open {
+			return ErrOpenRequestExists
+		}
+
+		return s.repo.Create(tx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// List returns a page of requests, optionally filtered by captain and status
+func (s *SettlementService) List(ctx context.Context, userID *uuid.UUID, status string, params pagination.Params) ([]models.CashOutRequest, int64, error) {
+	return s.repo.List(ctx, userID, status, params.Offset(), params.PerPage)
+}
+
+// Approve marks a pending request as approved for payment
+func (s *SettlementService) Approve(ctx context.Context, adminID, requestID uuid.UUID) (*models.CashOutRequest, error) {
+	return s.transition(ctx, adminID, requestID, models.StatusApproved, func(tx *gorm.DB, request *models.CashOutRequest) error {
+		return nil
+	})
+}
+
+// Reject rejects an open request; held cash-out funds are returned to the captain
+func (s *SettlementService) Reject(ctx context.Context, adminID, requestID uuid.UUID, reason string) (*models.CashOutRequest, error) {
+	return s.transition(ctx, adminID, requestID, models.StatusRejected, func(tx *gorm.DB, request *models.CashOutRequest) error {
+		request.RejectReason = reason
+		if request.Type != models.RequestTypeCashOut {
+			return nil
+		}
+
+		_, err := s.ledger.Post(tx, ledger.Posting{
+			Kind:        ledgermodels.KindCashOutRelease,
+			Reference:   "cashout_release:" + request.ID.String(),
+			Description: "Cash-out rejected",
+			CreatedBy:   &adminID,
+			Lines: []ledger.Line{
+				{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemPayoutsPending, Amount: -request.Amount},
+				{OwnerID: request.UserID, AccountType: ledgermodels.AccountCaptainBalance, Amount: request.Amount},
+			},
+		})
+		return err
+	})
+}
+
+// MarkPaid records that money changed hands. Cash-outs release the held amount to the bank;
+// settlements reduce the captain's commission debt. The debt may have shrunk since the
+// settlement was requested, so only what is still owed is credited and the request's
+// amount is lowered to match; a settlement with nothing left owed is refused.
+func (s *SettlementService) MarkPaid(ctx context.Context, adminID, requestID uuid.UUID, referenceNumber string) (*models.CashOutRequest, error) {
+	return s.transition(ctx, adminID, requestID, models.StatusPaid, func(tx *gorm.DB, request *models.CashOutRequest) error {
+		if request.Type == models.RequestTypeSettlement {
+			captainAccount := ledger.AccountRef{OwnerID: request.UserID, AccountType: ledgermodels.AccountCaptainBalance}
+			balances, err := s.ledger.LockBalances(tx, captainAccount)
+			if err != nil {
+				return err
+			}
+			debt := -balances[captainAccount]
+			if debt <= 0 {
+				return ErrExceedsDebt
+			}
+			request.Amount = min(request.Amount, debt)
+		}
+
+		now := time.Now().UTC()
+		request.PaidAt = &now
+		request.ReferenceNumber = referenceNumber
+
+		posting := ledger.Posting{
+			Reference: "cashout_paid:" + request.ID.String(),
+			CreatedBy: &adminID,
+		}
+
+		if request.Type == models.RequestTypeCashOut {
+			posting.Kind = ledgermodels.KindCashOutPaid
+			posting.Description = "Cash-out paid, ref " + referenceNumber
+			posting.Lines = []ledger.Line{
+				{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemPayoutsPending, Amount: -request.Amount},
+				{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemBank, Amount: request.Amount},
+			}
+		} else {
+			posting.Kind = ledgermodels.KindCommissionSettlement
+			posting.Description = "Commission settled, ref " + referenceNumber
+			posting.Lines = []ledger.Line{
+				{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemBank, Amount: -request.Amount},
+				{OwnerID: request.UserID, AccountType: ledgermodels.AccountCaptainBalance, Amount: request.Amount},
+			}
+		}
+
+		_, err := s.ledger.Post(tx, posting)
+		return err
+	})
+}
+
+// transition locks a request, checks the status change is allowed, applies fn and saves it
+func (s *SettlementService) transition(ctx context.Context, adminID, requestID uuid.UUID, to string, fn func(tx *gorm.DB, request *models.CashOutRequest) error) (*models.CashOutRequest, error) {
+	var request *models.CashOutRequest
+	err := s.ledger.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		request, err = s.repo.LockByID(tx, requestID)
+		if err != nil {
+			return err
+		}
+		if request == nil {
+			return ErrRequestNotFound
+		}
+		if !canTransition(request.Status, to) {
+			return ErrInvalidTransition
+		}
+
+		if err := fn(tx, request); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		request.Status = to
+		request.ReviewedBy = &adminID
+		request.ReviewedAt = &now
+
+		return s.repo.Save(tx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (s *SettlementService) summarise(balance int64) CaptainBalance {
+	summary := CaptainBalance{
+		Balance:   balance,
+		DebtLimit: s.cfg.MaxCommissionDebt,
+	}
+	if balance > 0 {
+		summary.Available = balance
+	} else {
+		summary.CommissionDebt = -balance
+	}
+	summary.Blocked = summary.CommissionDebt > s.cfg.MaxCommissionDebt
+
+	return summary
+}
+
+func canTransition(from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
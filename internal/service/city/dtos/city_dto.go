@@ -0,0 +1,96 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CityRequest creates or updates a city. Amounts are in the city's minor units.
+type CityRequest struct {
+	Code         string `json:"code" binding:"required,max=32,slug"`
+	Name         string `json:"name" binding:"required,max=100"`
+	NameAr       string `json:"name_ar" binding:"omitempty,max=100"`
+	Timezone     string `json:"timezone" binding:"required,timezone"`
+	Currency     string `json:"currency" binding:"required,iso4217"`
+	MinorUnits   int64  `json:"minor_units" binding:"required,min=1,max=1000"`
+	FareRounding int64  `json:"fare_rounding" binding:"required,min=1,max=1000"`
+	SupportPhone string `json:"support_phone" binding:"omitempty,phone"`
+	// Features are feature flags by name, e.g. {"wallet_payments": true}
+	Features map[string]bool `json:"features" binding:"omitempty,max=50,dive,keys,max=64,endkeys"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+} // @name CityRequest
+
+// CityResponse is a city as the apps see it
+type CityResponse struct {
+	ID           uuid.UUID       `json:"id"`
+	Code         string          `json:"code"`
+	Name         string          `json:"name"`
+	NameAr       string          `json:"name_ar,omitempty"`
+	Timezone     string          `json:"timezone"`
+	Currency     string          `json:"currency"`
+	MinorUnits   int64           `json:"minor_units"`
+	SupportPhone string          `json:"support_phone,omitempty"`
+	Features     map[string]bool `json:"features"`
+} // @name CityResponse
+
+// CityListResponse lists cities
+type CityListResponse struct {
+	Cities []CityResponse `json:"cities"`
+} // @name CityListResponse
+
+// AdminCityResponse is a city with the fields only admins see
+type AdminCityResponse struct {
+	CityResponse
+	FareRounding int64      `json:"fare_rounding"`
+	Active       bool       `json:"active"`
+	UpdatedBy    *uuid.UUID `json:"updated_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+} // @name AdminCityResponse
+
+// AdminCityListResponse lists the cities an admin manages
+type AdminCityListResponse struct {
+	Cities []AdminCityResponse `json:"cities"`
+} // @name AdminCityListResponse
+
+// CitySettingRequest overrides a global setting in a city. Value is a JSON number,
+// boolean or string matching the setting's type; durations are strings such as "30s".
+type CitySettingRequest struct {
+	Value json.RawMessage `json:"value" binding:"required" swaggertype:"string"`
+} // @name CitySettingRequest
+
+// CitySettingResponse is a setting's value in a city
+type CitySettingResponse struct {
+	Key         string      `json:"key"`
+	Type        string      `json:"type"`
+	Value       interface{} `json:"value"`
+	GlobalValue interface{} `json:"global_value"`
+	Overridden  bool        `json:"overridden"`
+	UpdatedBy   *uuid.UUID  `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time  `json:"updated_at,omitempty"`
+} // @name CitySettingResponse
+
+// CitySettingListResponse lists every setting's value in a city
+type CitySettingListResponse struct {
+	Settings []CitySettingResponse `json:"settings"`
+} // @name CitySettingListResponse
+
+// CityAdminRequest grants an admin user a city
+type CityAdminRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+} // @name CityAdminRequest
+
+// CityAdminResponse is an admin granted a city
+type CityAdminResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+} // @name CityAdminResponse
+
+// CityAdminListResponse lists the admins granted a city
+type CityAdminListResponse struct {
+	Admins []CityAdminResponse `json:"admins"`
+} // @name CityAdminListResponse
@@ -0,0 +1,106 @@
+package city
+
+import (
+	"context"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/city/handlers"
+	"theb-backend/internal/service/city/models"
+	"theb-backend/internal/service/city/repositories"
+	"theb-backend/internal/service/city/services"
+	settings "theb-backend/internal/service/settings/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// Module wires cities: the markets THEB operates in, each with its own currency,
+// support number, feature flags and overrides of the global settings
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "city" }
+
+// Requires returns the modules whose services city resolves
+func (Module) Requires() []string { return []string{"settings"} }
+
+// Migrations returns the city tables
+func (Module) Migrations() []interface{} {
+	return []interface{}{&models.City{}, &models.CitySetting{}, &models.CityAdmin{}}
+}
+
+// Register registers the city repository, service and handler in the container.
+// The default city is seeded and cities are loaded when the container starts.
+func (Module) Register(ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	redisClient, err := container.Resolve[*redis.Client](ctn)
+	if err != nil {
+		return err
+	}
+	settingsService, err := container.Resolve[*settings.SettingsService](ctn)
+	if err != nil {
+		return err
+	}
+
+	repo := repositories.NewCityRepository(db)
+	service := services.NewCityService(repo, settingsService, redisClient, cfg.Cities.Default)
+
+	container.Supply(ctn, repo)
+	container.Supply(ctn, service,
+		container.OnStart(func(ctx context.Context, service *services.CityService) error {
+			return service.Start(ctx)
+		}),
+		container.OnStop(func(ctx context.Context, service *services.CityService) error {
+			return service.Stop(ctx)
+		}),
+	)
+	container.Supply(ctn, handlers.NewCityHandler(service, settingsService))
+
+	return nil
+}
+
+// Jobs returns no jobs
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) { return nil, nil }
+
+// Routes mounts the city and admin city endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	handler, err := container.Resolve[*handlers.CityHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+
+	v1.GET("/cities", auth, handler.List)
+
+	admin := v1.Group("/admin/cities", auth, middleware.RequireRole(middleware.RoleAdmin))
+	{
+		admin.GET("", handler.AdminList)
+		admin.POST("", handler.Create)
+		admin.GET("/:id", handler.Get)
+		admin.PUT("/:id", handler.Update)
+		admin.GET("/:id/settings", handler.Settings)
+		admin.PUT("/:id/settings/:key", handler.SetSetting)
+		admin.DELETE("/:id/settings/:key", handler.ClearSetting)
+		admin.GET("/:id/admins", handler.Admins)
+		admin.POST("/:id/admins", handler.AddAdmin)
+		admin.DELETE("/:id/admins/:user_id", handler.RemoveAdmin)
+	}
+
+	return nil
+}
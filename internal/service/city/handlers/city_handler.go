@@ -0,0 +1,548 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/city/dtos"
+	"theb-backend/internal/service/city/models"
+	"theb-backend/internal/service/city/services"
+	settings "theb-backend/internal/service/settings/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CityHandler serves the city endpoints for the apps and admins
+type CityHandler struct {
+	service  *services.CityService
+	settings *settings.SettingsService
+}
+
+// NewCityHandler creates a new city handler
+func NewCityHandler(service *services.CityService, settingsService *settings.SettingsService) *CityHandler {
+	return &CityHandler{service: service, settings: settingsService}
+}
+
+// List returns the active cities with their currency, support number and feature flags
+// @Summary List cities
+// @ID cities-list
+// @Tags City
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dtos.CityListResponse
+// @Router /api/v1/cities [get]
+func (h *CityHandler) List(c *gin.Context) {
+	cities := h.service.Active()
+
+	response := dtos.CityListResponse{Cities: make([]dtos.CityResponse, 0, len(cities))}
+	for i := range cities {
+		response.Cities = append(response.Cities, toCityResponse(&cities[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminList returns the cities the admin manages
+// @Summary List managed cities (admin)
+// @ID admin-cities-list
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dtos.AdminCityListResponse
+// @Router /api/v1/admin/cities [get]
+func (h *CityHandler) AdminList(c *gin.Context) {
+	_, scope, ok := h.authorise(c, nil, false)
+	if !ok {
+		return
+	}
+
+	cities, err := h.service.List(c.Request.Context(), scope)
+	if err != nil {
+		h.handleError(c, "Failed to load cities", err)
+		return
+	}
+
+	response := dtos.AdminCityListResponse{Cities: make([]dtos.AdminCityResponse, 0, len(cities))}
+	for i := range cities {
+		response.Cities = append(response.Cities, toAdminCityResponse(&cities[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get returns one city
+// @Summary Get a city (admin)
+// @ID admin-cities-get
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "City ID"
+// @Success 200 {object} dtos.AdminCityResponse
+// @Router /api/v1/admin/cities/{id} [get]
+func (h *CityHandler) Get(c *gin.Context) {
+	cityID, ok := cityParam(c)
+	if !ok {
+		return
+	}
+	if _, _, ok := h.authorise(c, &cityID, false); !ok {
+		return
+	}
+
+	city, err := h.service.Get(c.Request.Context(), cityID)
+	if err != nil {
+		h.handleError(c, "Failed to load city", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toAdminCityResponse(city))
+}
+
+// Create adds a city. Only admins not scoped to particular cities may add one.
+// @Summary Create a city (admin)
+// @ID admin-cities-create
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.CityRequest true "City"
+// @Success 201 {object} dtos.AdminCityResponse
+// @Router /api/v1/admin/cities [post]
+func (h *CityHandler) Create(c *gin.Context) {
+	adminID, _, ok := h.authorise(c, nil, true)
+	if !ok {
+		return
+	}
+
+	var req dtos.CityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	city, err := h.service.Create(c.Request.Context(), adminID, toCityInput(req))
+	if err != nil {
+		h.handleError(c, "Failed to create city", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toAdminCityResponse(city))
+}
+
+// Update replaces a city's details
+// @Summary Update a city (admin)
+// @ID admin-cities-update
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "City ID"
+// @Param request body dtos.CityRequest true "City"
+// @Success 200 {object} dtos.AdminCityResponse
+// @Router /api/v1/admin/cities/{id} [put]
+func (h *CityHandler) Update(c *gin.Context) {
+	cityID, ok := cityParam(c)
+	if !ok {
+		return
+	}
+	adminID, _, ok := h.authorise(c, &cityID, false)
+	if !ok {
+		return
+	}
+
+	var req dtos.CityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	city, err := h.service.Update(c.Request.Context(), adminID, cityID, toCityInput(req))
+	if err != nil {
+		h.handleError(c, "Failed to update city", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toAdminCityResponse(city))
+}
+
+// Settings returns every runtime setting's value in a city, marking the ones it overrides
+// @Summary List a city's settings (admin)
+// @ID admin-cities-settings-list
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "City ID"
+// @Success 200 {object} dtos.CitySettingListResponse
+// @Router /api/v1/admin/cities/{id}/settings [get]
+func (h *CityHandler) Settings(c *gin.Context) {
+	cityID, ok := cityParam(c)
+	if !ok {
+		return
+	}
+	if _, _, ok := h.authorise(c, &cityID, false); !ok {
+		return
+	}
+
+	overrides, err := h.service.Overrides(c.Request.Context(), cityID)
+	if err != nil {
+		h.handleError(c, "Failed to load city settings", err)
+		return
+	}
+	globals, err := h.settings.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, "Failed to load city settings", err)
+		return
+	}
+
+	byKey := make(map[string]models.CitySetting, len(overrides))
+	for _, override := range overrides {
+		byKey[override.Key] = override
+	}
+
+	response := dtos.CitySettingListResponse{Settings: make([]dtos.CitySettingResponse, 0, len(globals))}
+	for _, global := range globals {
+		item := dtos.CitySettingResponse{
+			Key:         global.Key,
+			Type:        global.Type,
+			Value:       typedValue(global.Definition, global.Value),
+			GlobalValue: typedValue(global.Definition, global.Value),
+		}
+		if override, ok := byKey[global.Key]; ok {
+			updatedAt := override.UpdatedAt
+			item.Value = typedValue(global.Definition, override.Value)
+			item.Overridden = true
+			item.UpdatedBy = override.UpdatedBy
+			item.UpdatedAt = &updatedAt
+		}
+		response.Settings = append(response.Settings, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetSetting overrides a global setting in a city, e.g. its own base_fare
+// @Summary Override a setting in a city (admin)
+// @ID admin-cities-settings-set
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "City ID"
+// @Param key path string true "Setting key"
+// @Param request body dtos.CitySettingRequest true "Value"
+// @Success 200 {object} dtos.CitySettingResponse
+// @Router /api/v1/admin/cities/{id}/settings/{key} [put]
+func (h *CityHandler) SetSetting(c *gin.Context) {
+	cityID, ok := cityParam(c)
+	if !ok {
+		return
+	}
+	adminID, _, ok := h.authorise(c, &cityID, false)
+	if !ok {
+		return
+	}
+
+	var req dtos.CitySettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	override, err := h.service.SetOverride(c.Request.Context(), adminID, cityID, c.Param("key"), rawValue(req.Value))
+	if err != nil {
+		h.handleError(c, "Failed to set city setting", err)
+		return
+	}
+
+	def, _ := h.settings.Lookup(override.Key)
+	updatedAt := override.UpdatedAt
+	c.JSON(http.StatusOK, dtos.CitySettingResponse{
+		Key:         override.Key,
+		Type:        def.Type,
+		Value:       typedValue(def, override.Value),
+		GlobalValue: h.globalValue(def),
+		Overridden:  true,
+		UpdatedBy:   override.UpdatedBy,
+		UpdatedAt:   &updatedAt,
+	})
+}
+
+// ClearSetting makes a city use the global value of a setting again
+// @Summary Remove a city's setting override (admin)
+// @ID admin-cities-settings-clear
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "City ID"
+// @Param key path string true "Setting key"
+// @Success 204
+// @Router /api/v1/admin/cities/{id}/settings/{key} [delete]
+func (h *CityHandler) ClearSetting(c *gin.Context) {
+	cityID, ok := cityParam(c)
+	if !ok {
+		return
+	}
+	if _, _, ok := h.authorise(c, &cityID, false); !ok {
+		return
+	}
+
+	if err := h.service.ClearOverride(c.Request.Context(), cityID, c.Param("key")); err != nil {
+		h.handleError(c, "Failed to clear city setting", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Admins returns the admins granted a city
+// @Summary List a city's admins (admin)
+// @ID admin-cities-admins-list
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "City ID"
+// @Success 200 {object} dtos.CityAdminListResponse
+// @Router /api/v1/admin/cities/{id}/admins [get]
+func (h *CityHandler) Admins(c *gin.Context) {
+	cityID, ok := cityParam(c)
+	if !ok {
+		return
+	}
+	if _, _, ok := h.authorise(c, &cityID, false); !ok {
+		return
+	}
+
+	admins, err := h.service.Admins(c.Request.Context(), cityID)
+	if err != nil {
+		h.handleError(c, "Failed to load city admins", err)
+		return
+	}
+
+	response := dtos.CityAdminListResponse{Admins: make([]dtos.CityAdminResponse, 0, len(admins))}
+	for _, admin := range admins {
+		response.Admins = append(response.Admins, toCityAdminResponse(&admin))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AddAdmin grants an admin user a city. The admin then manages only their granted
+// cities. Only admins not scoped to particular cities may grant one.
+// @Summary Grant an admin a city (admin)
+// @ID admin-cities-admins-add
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "City ID"
+// @Param request body dtos.CityAdminRequest true "Admin user"
+// @Success 201 {object} dtos.CityAdminResponse
+// @Router /api/v1/admin/cities/{id}/admins [post]
+func (h *CityHandler) AddAdmin(c *gin.Context) {
+	cityID, ok := cityParam(c)
+	if !ok {
+		return
+	}
+	adminID, _, ok := h.authorise(c, nil, true)
+	if !ok {
+		return
+	}
+
+	var req dtos.CityAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	admin, err := h.service.AddAdmin(c.Request.Context(), adminID, cityID, req.UserID)
+	if err != nil {
+		h.handleError(c, "Failed to grant city admin", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toCityAdminResponse(admin))
+}
+
+// RemoveAdmin revokes an admin's grant for a city
+// @Summary Revoke an admin's city (admin)
+// @ID admin-cities-admins-remove
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path string true "City ID"
+// @Param user_id path string true "Admin user ID"
+// @Success 204
+// @Router /api/v1/admin/cities/{id}/admins/{user_id} [delete]
+func (h *CityHandler) RemoveAdmin(c *gin.Context) {
+	cityID, ok := cityParam(c)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("user_id", "uuid")))
+		return
+	}
+	if _, _, ok := h.authorise(c, nil, true); !ok {
+		return
+	}
+
+	if err := h.service.RemoveAdmin(c.Request.Context(), cityID, userID); err != nil {
+		h.handleError(c, "Failed to revoke city admin", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// authorise resolves the admin and their scope, aborting unless the scope covers
+// cityID, when given, or every city, when unscoped is required
+func (h *CityHandler) authorise(c *gin.Context, cityID *uuid.UUID, unscoped bool) (uuid.UUID, services.Scope, bool) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return uuid.Nil, services.Scope{}, false
+	}
+
+	scope, err := h.service.Scope(c.Request.Context(), adminID)
+	if err != nil {
+		h.handleError(c, "Failed to load admin cities", err)
+		return uuid.Nil, services.Scope{}, false
+	}
+	if (unscoped && !scope.All) || (cityID != nil && !scope.Allows(cityID)) {
+		apierror.Abort(c, apierror.New(apierror.CodeCityAccessDenied))
+		return uuid.Nil, services.Scope{}, false
+	}
+	return adminID, scope, true
+}
+
+func (h *CityHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrCityNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeCityNotFound, err))
+	case errors.Is(err, services.ErrDuplicateCode):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeCityCodeTaken, err))
+	case errors.Is(err, services.ErrOverrideNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeCitySettingNotOverridden, err))
+	case errors.Is(err, services.ErrGrantNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeCityAdminNotFound, err))
+	case errors.Is(err, settings.ErrUnknownSetting):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettingNotFound, err))
+	case errors.Is(err, settings.ErrInvalidValue):
+		// The cause says which bound or type the value broke, which admins need
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettingInvalidValue, err).WithDetails(apierror.FieldError{
+			Field:   "value",
+			Rule:    "setting",
+			Message: err.Error(),
+		}))
+	default:
+		logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+			"error": err.Error(),
+		})
+		apierror.Abort(c, apierror.New(apierror.CodeInternal))
+	}
+}
+
+// cityParam parses the :id path parameter, aborting the request if it is not a UUID
+func cityParam(c *gin.Context) (uuid.UUID, bool) {
+	cityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return uuid.Nil, false
+	}
+	return cityID, true
+}
+
+// globalValue returns the current global value of def
+func (h *CityHandler) globalValue(def settings.Definition) interface{} {
+	switch def.Type {
+	case settings.TypeInt:
+		return h.settings.Int(def.Key)
+	case settings.TypeFloat:
+		return h.settings.Float(def.Key)
+	case settings.TypeBool:
+		return h.settings.Bool(def.Key)
+	case settings.TypeDuration:
+		return h.settings.Duration(def.Key).String()
+	default:
+		return h.settings.String(def.Key)
+	}
+}
+
+// rawValue returns a JSON value as the text stored for it: strings unquoted,
+// numbers and booleans as written
+func rawValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// typedValue returns a stored value as JSON-friendly typed data; durations stay strings
+func typedValue(def settings.Definition, value string) interface{} {
+	parsed, err := def.Parse(value)
+	if err != nil {
+		return value
+	}
+	if d, ok := parsed.(time.Duration); ok {
+		return d.String()
+	}
+	return parsed
+}
+
+func toCityInput(req dtos.CityRequest) services.CityInput {
+	return services.CityInput{
+		Code:         req.Code,
+		Name:         req.Name,
+		NameAr:       req.NameAr,
+		Timezone:     req.Timezone,
+		Currency:     req.Currency,
+		MinorUnits:   req.MinorUnits,
+		FareRounding: req.FareRounding,
+		SupportPhone: req.SupportPhone,
+		Features:     models.Features(req.Features),
+		Active:       req.Active == nil || *req.Active,
+	}
+}
+
+func toCityResponse(city *models.City) dtos.CityResponse {
+	features := map[string]bool(city.Features)
+	if features == nil {
+		features = map[string]bool{}
+	}
+	return dtos.CityResponse{
+		ID:           city.ID,
+		Code:         city.Code,
+		Name:         city.Name,
+		NameAr:       city.NameAr,
+		Timezone:     city.Timezone,
+		Currency:     city.Currency,
+		MinorUnits:   city.MinorUnits,
+		SupportPhone: city.SupportPhone,
+		Features:     features,
+	}
+}
+
+func toAdminCityResponse(city *models.City) dtos.AdminCityResponse {
+	return dtos.AdminCityResponse{
+		CityResponse: toCityResponse(city),
+		FareRounding: city.FareRounding,
+		Active:       city.Active,
+		UpdatedBy:    city.UpdatedBy,
+		CreatedAt:    city.CreatedAt,
+		UpdatedAt:    city.UpdatedAt,
+	}
+}
+
+func toCityAdminResponse(admin *models.CityAdmin) dtos.CityAdminResponse {
+	return dtos.CityAdminResponse{
+		UserID:    admin.UserID,
+		CreatedBy: admin.CreatedBy,
+		CreatedAt: admin.CreatedAt,
+	}
+}
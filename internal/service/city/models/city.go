@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Features are a city's feature flags by name, e.g. {"wallet_payments": true}
+type Features map[string]bool
+
+// Value implements driver.Valuer
+func (f Features) Value() (driver.Value, error) {
+	if f == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(f)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (f *Features) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return fmt.Errorf("unsupported city features type %T", value)
+	}
+}
+
+// City is a market THEB operates in. Pricing and dispatch settings fall back to
+// the global settings unless the city overrides them.
+type City struct {
+	ID       uuid.UUID `gorm:"column:city_id;type:uuid;primaryKey" json:"city_id"`
+	Code     string    `gorm:"type:varchar(32);not null;uniqueIndex" json:"code"`
+	Name     string    `gorm:"type:varchar(100);not null" json:"name"`
+	NameAr   string    `gorm:"type:varchar(100)" json:"name_ar"`
+	Timezone string    `gorm:"type:varchar(64);not null" json:"timezone"`
+	Currency string    `gorm:"type:char(3);not null" json:"currency"`
+	// MinorUnits is how many minor units make one currency unit, e.g. 1000 fils per JOD
+	MinorUnits int64 `gorm:"not null" json:"minor_units"`
+	// FareRounding rounds fares to a multiple of this many minor units
+	FareRounding int64      `gorm:"not null" json:"fare_rounding"`
+	SupportPhone string     `gorm:"type:varchar(16)" json:"support_phone,omitempty"`
+	Features     Features   `gorm:"type:jsonb;not null;default:'{}'" json:"features"`
+	Active       bool       `gorm:"not null" json:"active"`
+	UpdatedBy    *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (City) TableName() string {
+	return "cities"
+}
+
+// RoundFare rounds amount, in minor units, to the city's fare rounding, halves up
+func (c City) RoundFare(amount int64) int64 {
+	if c.FareRounding <= 1 {
+		return amount
+	}
+	return (amount + c.FareRounding/2) / c.FareRounding * c.FareRounding
+}
+
+// Location returns the city's time zone, or UTC if it cannot be loaded
+func (c City) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// CitySetting overrides a global runtime setting in one city, e.g. base_fare
+type CitySetting struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	CityID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_city_settings_city_key" json:"city_id"`
+	Key       string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_city_settings_city_key" json:"key"`
+	Value     string     `gorm:"type:text;not null" json:"value"`
+	UpdatedBy *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (CitySetting) TableName() string {
+	return "city_settings"
+}
+
+// CityAdmin grants an admin user management of one city. Admins without any
+// grant manage every city.
+type CityAdmin struct {
+	CityID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"city_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (CityAdmin) TableName() string {
+	return "city_admins"
+}
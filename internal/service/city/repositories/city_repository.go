@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"theb-backend/internal/service/city/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CityRepository provides data access for cities, their setting overrides and admins
+type CityRepository struct {
+	db *gorm.DB
+}
+
+// NewCityRepository creates a new city repository
+func NewCityRepository(db *gorm.DB) *CityRepository {
+	return &CityRepository{db: db}
+}
+
+// Transaction runs fn inside a database transaction
+func (r *CityRepository) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
+}
+
+// All returns every city, oldest first
+func (r *CityRepository) All(ctx context.Context) ([]models.City, error) {
+	var cities []models.City
+	err := r.db.WithContext(ctx).Order("created_at").Find(&cities).Error
+	return cities, err
+}
+
+// Find returns a city by ID, or nil if it does not exist
+func (r *CityRepository) Find(ctx context.Context, id uuid.UUID) (*models.City, error) {
+	var city models.City
+	err := r.db.WithContext(ctx).Where("city_id = ?", id).First(&city).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &city, nil
+}
+
+// Lock loads a city by ID with a row lock held until tx ends, or nil if it does not exist
+func (r *CityRepository) Lock(tx *gorm.DB, id uuid.UUID) (*models.City, error) {
+	var city models.City
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("city_id = ?", id).First(&city).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &city, nil
+}
+
+// ExistsCode reports whether a city other than exceptID has code
+func (r *CityRepository) ExistsCode(tx *gorm.DB, code string, exceptID uuid.UUID) (bool, error) {
+	var count int64
+	err := tx.Model(&models.City{}).Where("code = ? AND city_id <> ?", code, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateIfNoneExist inserts city only when the table is empty, so instances booting
+// together seed the default city once. It reports whether the city was inserted.
+func (r *CityRepository) CreateIfNoneExist(ctx context.Context, city *models.City) (bool, error) {
+	var created bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.City{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(city)
+		created = result.RowsAffected > 0
+		return result.Error
+	})
+	return created, err
+}
+
+// Save inserts or updates a city inside tx
+func (r *CityRepository) Save(tx *gorm.DB, city *models.City) error {
+	return tx.Save(city).Error
+}
+
+// AllSettings returns every city setting override
+func (r *CityRepository) AllSettings(ctx context.Context) ([]models.CitySetting, error) {
+	var settings []models.CitySetting
+	err := r.db.WithContext(ctx).Order("key").Find(&settings).Error
+	return settings, err
+}
+
+// Settings returns the overrides of one city
+func (r *CityRepository) Settings(ctx context.Context, cityID uuid.UUID) ([]models.CitySetting, error) {
+	var settings []models.CitySetting
+	err := r.db.WithContext(ctx).Where("city_id = ?", cityID).Order("key").Find(&settings).Error
+	return settings, err
+}
+
+// SaveSetting inserts or updates an override by city and key
+func (r *CityRepository) SaveSetting(ctx context.Context, setting *models.CitySetting) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "city_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+	}).Create(setting).Error
+}
+
+// DeleteSetting removes an override, reporting whether there was one
+func (r *CityRepository) DeleteSetting(ctx context.Context, cityID uuid.UUID, key string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("city_id = ? AND key = ?", cityID, key).Delete(&models.CitySetting{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Admins returns the admins granted a city
+func (r *CityRepository) Admins(ctx context.Context, cityID uuid.UUID) ([]models.CityAdmin, error) {
+	var admins []models.CityAdmin
+	err := r.db.WithContext(ctx).Where("city_id = ?", cityID).Order("created_at").Find(&admins).Error
+	return admins, err
+}
+
+// ManagedCityIDs returns the cities an admin has been granted
+func (r *CityRepository) ManagedCityIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&models.CityAdmin{}).Where("user_id = ?", userID).Pluck("city_id", &ids).Error
+	return ids, err
+}
+
+// AddAdmin grants an admin a city; granting twice is a no-op
+func (r *CityRepository) AddAdmin(ctx context.Context, admin *models.CityAdmin) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(admin).Error
+}
+
+// RemoveAdmin revokes an admin's grant, reporting whether there was one
+func (r *CityRepository) RemoveAdmin(ctx context.Context, cityID, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Where("city_id = ? AND user_id = ?", cityID, userID).Delete(&models.CityAdmin{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,557 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/service/city/models"
+	"theb-backend/internal/service/city/repositories"
+	settings "theb-backend/internal/service/settings/services"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// invalidateChannel tells every instance to reload cities after a change
+const invalidateChannel = "cities:invalidate"
+
+var (
+	// ErrCityNotFound is returned when a city does not exist
+	ErrCityNotFound = errors.New("city not found")
+	// ErrDuplicateCode is returned when another city already has the code
+	ErrDuplicateCode = errors.New("city code already in use")
+	// ErrOverrideNotFound is returned when clearing a setting the city does not override
+	ErrOverrideNotFound = errors.New("city does not override this setting")
+	// ErrGrantNotFound is returned when revoking a city from an admin who was not granted it
+	ErrGrantNotFound = errors.New("admin does not manage this city")
+)
+
+// CityInput is a city as created or updated by an admin
+type CityInput struct {
+	Code         string
+	Name         string
+	NameAr       string
+	Timezone     string
+	Currency     string
+	MinorUnits   int64
+	FareRounding int64
+	SupportPhone string
+	Features     models.Features
+	Active       bool
+}
+
+// Scope is the set of cities an admin manages. Admins without any city grant
+// manage every city, so existing admins keep their access until they are scoped.
+type Scope struct {
+	All     bool
+	CityIDs []uuid.UUID
+}
+
+// Allows reports whether the scope covers cityID. Records without a city, such as
+// zones shared by every city, are covered only by an unscoped admin.
+func (s Scope) Allows(cityID *uuid.UUID) bool {
+	if s.All {
+		return true
+	}
+	return cityID != nil && slices.Contains(s.CityIDs, *cityID)
+}
+
+// CityService serves cities and their setting overrides from memory. Changes are
+// stored in cities and city_settings, and every instance reloads when one is made,
+// via Redis pub/sub when Redis is configured.
+type CityService struct {
+	repo     *repositories.CityRepository
+	settings *settings.SettingsService
+	redis    *redis.Client
+	defaults config.CityConfig
+
+	mu        sync.RWMutex
+	cities    map[uuid.UUID]models.City
+	overrides map[uuid.UUID]map[string]string
+	defaultID uuid.UUID
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewCityService creates a city service. defaults describes the city seeded on
+// first boot. redisClient may be nil.
+func NewCityService(repo *repositories.CityRepository, settingsService *settings.SettingsService, redisClient *redis.Client, defaults config.CityConfig) *CityService {
+	return &CityService{
+		repo:      repo,
+		settings:  settingsService,
+		redis:     redisClient,
+		defaults:  defaults,
+		cities:    make(map[uuid.UUID]models.City),
+		overrides: make(map[uuid.UUID]map[string]string),
+	}
+}
+
+// Start seeds the default city if there are none, loads the cities and listens
+// for changes made on other instances
+func (s *CityService) Start(ctx context.Context) error {
+	if err := s.seed(ctx); err != nil {
+		return err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return err
+	}
+	if s.redis == nil {
+		return nil
+	}
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	pubsub := s.redis.Subscribe(subCtx, invalidateChannel)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case _, ok := <-messages:
+				if !ok {
+					return
+				}
+				if err := s.Reload(subCtx); err != nil {
+					logger.Error("Failed to reload cities", map[string]interface{}{
+						"error": err.Error(),
+					})
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops listening for changes
+func (s *CityService) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// seed creates the configured default city when no city exists yet
+func (s *CityService) seed(ctx context.Context) error {
+	city := &models.City{
+		ID:           uuid.New(),
+		Code:         s.defaults.Code,
+		Name:         s.defaults.Name,
+		NameAr:       s.defaults.NameAr,
+		Timezone:     s.defaults.Timezone,
+		Currency:     s.defaults.Currency,
+		MinorUnits:   s.defaults.MinorUnits,
+		FareRounding: s.defaults.FareRounding,
+		SupportPhone: s.defaults.SupportPhone,
+		Features:     models.Features{},
+		Active:       true,
+	}
+	created, err := s.repo.CreateIfNoneExist(ctx, city)
+	if err != nil {
+		return fmt.Errorf("failed to seed default city: %w", err)
+	}
+	if created {
+		logger.Info("Default city created", map[string]interface{}{
+			"city_id": city.ID.String(),
+			"code":    city.Code,
+		})
+	}
+	return nil
+}
+
+// Reload replaces the cached cities and overrides with the stored ones. The
+// default city is the one with the configured code, else the oldest.
+func (s *CityService) Reload(ctx context.Context) error {
+	stored, err := s.repo.All(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load cities: %w", err)
+	}
+	storedSettings, err := s.repo.AllSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load city settings: %w", err)
+	}
+
+	cities := make(map[uuid.UUID]models.City, len(stored))
+	var defaultID uuid.UUID
+	for _, city := range stored {
+		cities[city.ID] = city
+		if defaultID == uuid.Nil || city.Code == s.defaults.Code {
+			defaultID = city.ID
+		}
+	}
+
+	overrides := make(map[uuid.UUID]map[string]string)
+	for _, setting := range storedSettings {
+		if overrides[setting.CityID] == nil {
+			overrides[setting.CityID] = make(map[string]string)
+		}
+		overrides[setting.CityID][setting.Key] = setting.Value
+	}
+
+	s.mu.Lock()
+	s.cities = cities
+	s.overrides = overrides
+	s.defaultID = defaultID
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Default returns the default city, which rides and captains outside any city's
+// service area belong to
+func (s *CityService) Default() models.City {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cities[s.defaultID]
+}
+
+// City returns a cached city by ID
+func (s *CityService) City(id uuid.UUID) (models.City, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	city, ok := s.cities[id]
+	return city, ok
+}
+
+// Active returns the active cities ordered by name
+func (s *CityService) Active() []models.City {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cities := make([]models.City, 0, len(s.cities))
+	for _, city := range s.cities {
+		if city.Active {
+			cities = append(cities, city)
+		}
+	}
+	sort.Slice(cities, func(i, j int) bool { return cities[i].Name < cities[j].Name })
+	return cities
+}
+
+// Enabled reports whether a feature flag is on in a city
+func (s *CityService) Enabled(cityID uuid.UUID, flag string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cities[cityID].Features[flag]
+}
+
+// Int returns an integer setting in a city
+func (s *CityService) Int(cityID uuid.UUID, key string) int64 {
+	if v, ok := s.override(cityID, key).(int64); ok {
+		return v
+	}
+	return s.settings.Int(key)
+}
+
+// Float returns a numeric setting in a city
+func (s *CityService) Float(cityID uuid.UUID, key string) float64 {
+	if v, ok := s.override(cityID, key).(float64); ok {
+		return v
+	}
+	return s.settings.Float(key)
+}
+
+// Bool returns a boolean setting in a city
+func (s *CityService) Bool(cityID uuid.UUID, key string) bool {
+	if v, ok := s.override(cityID, key).(bool); ok {
+		return v
+	}
+	return s.settings.Bool(key)
+}
+
+// Duration returns a duration setting in a city
+func (s *CityService) Duration(cityID uuid.UUID, key string) time.Duration {
+	if v, ok := s.override(cityID, key).(time.Duration); ok {
+		return v
+	}
+	return s.settings.Duration(key)
+}
+
+// String returns a string setting in a city
+func (s *CityService) String(cityID uuid.UUID, key string) string {
+	if v, ok := s.override(cityID, key).(string); ok {
+		return v
+	}
+	return s.settings.String(key)
+}
+
+// override returns the city's typed value for key, or nil when the city does not
+// override it or the stored value no longer fits the setting's definition
+func (s *CityService) override(cityID uuid.UUID, key string) interface{} {
+	s.mu.RLock()
+	value, ok := s.overrides[cityID][key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	def, err := s.settings.Lookup(key)
+	if err != nil {
+		return nil
+	}
+	parsed, err := def.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return parsed
+}
+
+// Scope returns the cities an admin manages
+func (s *CityService) Scope(ctx context.Context, adminID uuid.UUID) (Scope, error) {
+	ids, err := s.repo.ManagedCityIDs(ctx, adminID)
+	if err != nil {
+		return Scope{}, err
+	}
+	return Scope{All: len(ids) == 0, CityIDs: ids}, nil
+}
+
+// List returns the cities in scope, for the admin view
+func (s *CityService) List(ctx context.Context, scope Scope) ([]models.City, error) {
+	stored, err := s.repo.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cities := make([]models.City, 0, len(stored))
+	for _, city := range stored {
+		if scope.Allows(&city.ID) {
+			cities = append(cities, city)
+		}
+	}
+	return cities, nil
+}
+
+// Get returns one city
+func (s *CityService) Get(ctx context.Context, id uuid.UUID) (*models.City, error) {
+	city, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if city == nil {
+		return nil, ErrCityNotFound
+	}
+	return city, nil
+}
+
+// Create adds a city
+func (s *CityService) Create(ctx context.Context, adminID uuid.UUID, input CityInput) (*models.City, error) {
+	city := &models.City{ID: uuid.New()}
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		taken, err := s.repo.ExistsCode(tx, input.Code, city.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("%w: %s", ErrDuplicateCode, input.Code)
+		}
+
+		apply(city, adminID, input)
+		return s.repo.Save(tx, city)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, "City created", city.ID)
+	return city, nil
+}
+
+// Update replaces a city's details
+func (s *CityService) Update(ctx context.Context, adminID, id uuid.UUID, input CityInput) (*models.City, error) {
+	var city *models.City
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		city, err = s.repo.Lock(tx, id)
+		if err != nil {
+			return err
+		}
+		if city == nil {
+			return ErrCityNotFound
+		}
+
+		taken, err := s.repo.ExistsCode(tx, input.Code, city.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("%w: %s", ErrDuplicateCode, input.Code)
+		}
+
+		apply(city, adminID, input)
+		return s.repo.Save(tx, city)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, "City updated", city.ID)
+	return city, nil
+}
+
+// Overrides returns the settings a city overrides
+func (s *CityService) Overrides(ctx context.Context, cityID uuid.UUID) ([]models.CitySetting, error) {
+	if _, err := s.Get(ctx, cityID); err != nil {
+		return nil, err
+	}
+	return s.repo.Settings(ctx, cityID)
+}
+
+// SetOverride sets a city's value for a global setting, e.g. a higher base_fare
+func (s *CityService) SetOverride(ctx context.Context, adminID, cityID uuid.UUID, key, value string) (*models.CitySetting, error) {
+	def, err := s.settings.Lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err = def.Normalise(value)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Get(ctx, cityID); err != nil {
+		return nil, err
+	}
+
+	setting := &models.CitySetting{
+		ID:        uuid.New(),
+		CityID:    cityID,
+		Key:       key,
+		Value:     value,
+		UpdatedBy: &adminID,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.SaveSetting(ctx, setting); err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, "City setting changed", cityID)
+	return setting, nil
+}
+
+// ClearOverride makes a city use the global value of a setting again
+func (s *CityService) ClearOverride(ctx context.Context, cityID uuid.UUID, key string) error {
+	deleted, err := s.repo.DeleteSetting(ctx, cityID, key)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrOverrideNotFound
+	}
+
+	s.invalidate(ctx, "City setting cleared", cityID)
+	return nil
+}
+
+// Admins returns the admins granted a city
+func (s *CityService) Admins(ctx context.Context, cityID uuid.UUID) ([]models.CityAdmin, error) {
+	if _, err := s.Get(ctx, cityID); err != nil {
+		return nil, err
+	}
+	return s.repo.Admins(ctx, cityID)
+}
+
+// AddAdmin grants an admin user a city, scoping them to their granted cities
+func (s *CityService) AddAdmin(ctx context.Context, grantedBy, cityID, userID uuid.UUID) (*models.CityAdmin, error) {
+	if _, err := s.Get(ctx, cityID); err != nil {
+		return nil, err
+	}
+
+	admin := &models.CityAdmin{CityID: cityID, UserID: userID, CreatedBy: grantedBy}
+	if err := s.repo.AddAdmin(ctx, admin); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("City admin granted", map[string]interface{}{
+		"city_id":    cityID.String(),
+		"user_id":    userID.String(),
+		"granted_by": grantedBy.String(),
+	})
+	return admin, nil
+}
+
+// RemoveAdmin revokes an admin's grant for a city. An admin left without grants
+// manages every city again.
+func (s *CityService) RemoveAdmin(ctx context.Context, cityID, userID uuid.UUID) error {
+	removed, err := s.repo.RemoveAdmin(ctx, cityID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrGrantNotFound
+	}
+
+	logger.FromContext(ctx).Info("City admin revoked", map[string]interface{}{
+		"city_id": cityID.String(),
+		"user_id": userID.String(),
+	})
+	return nil
+}
+
+// apply copies an admin's input onto city
+func apply(city *models.City, adminID uuid.UUID, input CityInput) {
+	city.Code = input.Code
+	city.Name = input.Name
+	city.NameAr = input.NameAr
+	city.Timezone = input.Timezone
+	city.Currency = input.Currency
+	city.MinorUnits = input.MinorUnits
+	city.FareRounding = input.FareRounding
+	city.SupportPhone = input.SupportPhone
+	city.Features = input.Features
+	if city.Features == nil {
+		city.Features = models.Features{}
+	}
+	city.Active = input.Active
+	city.UpdatedBy = &adminID
+}
+
+// invalidate reloads this instance and tells the others to reload. Failures are
+// logged rather than returned, since the change itself is already committed.
+func (s *CityService) invalidate(ctx context.Context, message string, cityID uuid.UUID) {
+	log := logger.FromContext(ctx)
+
+	if err := s.Reload(ctx); err != nil {
+		log.Error("Failed to reload cities", map[string]interface{}{
+			"city_id": cityID.String(),
+			"error":   err.Error(),
+		})
+	}
+
+	if s.redis != nil {
+		if err := s.redis.Publish(ctx, invalidateChannel, cityID.String()).Err(); err != nil {
+			log.Error("Failed to publish cities invalidation", map[string]interface{}{
+				"city_id": cityID.String(),
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	log.Info(message, map[string]interface{}{
+		"city_id": cityID.String(),
+	})
+}
@@ -0,0 +1,46 @@
+package ledger
+
+import (
+	"theb-backend/internal/container"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/service/ledger/models"
+	"theb-backend/internal/service/ledger/repositories"
+	"theb-backend/internal/service/ledger/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Module wires the double-entry ledger. It has no endpoints of its own.
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "ledger" }
+
+// Requires returns no modules; ledger uses only the core services
+func (Module) Requires() []string { return nil }
+
+// Migrations returns the ledger tables
+func (Module) Migrations() []interface{} {
+	return []interface{}{&models.Account{}, &models.Transaction{}, &models.Entry{}}
+}
+
+// Register registers the ledger repository and service in the container
+func (Module) Register(ctn *container.Container) error {
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+
+	repo := repositories.NewLedgerRepository(db)
+	container.Supply(ctn, repo)
+	container.Supply(ctn, services.NewLedgerService(repo))
+
+	return nil
+}
+
+// Jobs returns no jobs
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) { return nil, nil }
+
+// Routes mounts nothing
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error { return nil }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account types
+const (
+	// AccountPassengerWallet holds prepaid passenger funds. It can never go negative.
+	AccountPassengerWallet = "passenger_wallet"
+	// AccountCaptainBalance is what the platform owes a captain; negative means the captain owes the platform.
+	AccountCaptainBalance = "captain_balance"
+	// AccountSystemAdjustments is the counter-account for admin credits.
+	AccountSystemAdjustments = "system_adjustments"
+	// AccountSystemRefunds is the counter-account for dispute refunds.
+	AccountSystemRefunds = "system_refunds"
+	// AccountSystemCommission accumulates platform commission on rides.
+	AccountSystemCommission = "system_commission"
+	// AccountSystemPayoutsPending holds captain cash-outs awaiting payment.
+	AccountSystemPayoutsPending = "system_payouts_pending"
+	// AccountSystemBank is the counter-account for money paid to or received from captains.
+	AccountSystemBank = "system_bank"
+	// AccountSystemCardClearing is the counter-account for card fares captured by the gateway.
+	AccountSystemCardClearing = "system_card_clearing"
+)
+
+// SystemOwnerID is the owner of platform-level accounts
+var SystemOwnerID = uuid.Nil
+
+// Account is a single balance in the ledger. Amounts are stored in fils (1 JOD = 1000 fils).
+type Account struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	OwnerID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_ledger_accounts_owner_type" json:"owner_id"`
+	Type          string    `gorm:"type:varchar(32);not null;uniqueIndex:idx_ledger_accounts_owner_type" json:"type"`
+	Currency      string    `gorm:"type:char(3);not null;default:JOD" json:"currency"`
+	Balance       int64     `gorm:"not null;default:0" json:"balance"`
+	AllowNegative bool      `gorm:"not null;default:false" json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Account) TableName() string {
+	return "ledger_accounts"
+}
+
+// AllowsNegative reports whether accounts of the given type may be overdrawn
+func AllowsNegative(accountType string) bool {
+	return accountType != AccountPassengerWallet
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transaction kinds
+const (
+	KindWalletCashTopUp = "wallet_cash_topup"
+	KindWalletCredit    = "wallet_admin_credit"
+	KindRideDebit       = "ride_wallet_debit"
+	KindRideRefund      = "ride_refund"
+	KindRideCardCapture = "ride_card_capture"
+
+	KindRideCommission       = "ride_commission"
+	KindCashOutHold          = "cashout_hold"
+	KindCashOutRelease       = "cashout_release"
+	KindCashOutPaid          = "cashout_paid"
+	KindCommissionSettlement = "commission_settlement"
+)
+
+// Transaction groups balanced entries that were posted together.
+// Reference is unique and makes postings idempotent.
+type Transaction struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Kind        string     `gorm:"type:varchar(40);not null;index" json:"kind"`
+	Reference   string     `gorm:"type:varchar(128);not null;uniqueIndex" json:"reference"`
+	Description string     `gorm:"type:varchar(255)" json:"description,omitempty"`
+	RideID      *uuid.UUID `gorm:"type:uuid;index" json:"ride_id,omitempty"`
+	CreatedBy   *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	Entries     []Entry    `gorm:"foreignKey:TransactionID" json:"entries,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (Transaction) TableName() string {
+	return "ledger_transactions"
+}
+
+// Entry is one side of a transaction against a single account
+type Entry struct {
+	ID            uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	TransactionID uuid.UUID    `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	AccountID     uuid.UUID    `gorm:"type:uuid;not null;index:idx_ledger_entries_account_created" json:"account_id"`
+	Amount        int64        `gorm:"not null" json:"amount"`
+	BalanceAfter  int64        `gorm:"not null" json:"balance_after"`
+	Transaction   *Transaction `gorm:"foreignKey:TransactionID" json:"transaction,omitempty"`
+	CreatedAt     time.Time    `gorm:"index:idx_ledger_entries_account_created" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (Entry) TableName() string {
+	return "ledger_entries"
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"theb-backend/internal/service/ledger/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LedgerRepository provides data access for ledger accounts and transactions.
+// Methods that take a *gorm.DB run inside the caller's transaction.
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+// NewLedgerRepository creates a new ledger repository
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// DB returns the underlying database handle
+func (r *LedgerRepository) DB() *gorm.DB {
+	return r.db
+}
+
+// EnsureAccount creates the account for owner and type if it does not exist yet
+func (r *LedgerRepository) EnsureAccount(tx *gorm.DB, ownerID uuid.UUID, accountType string) error {
+	account := models.Account{
+		ID:            uuid.New(),
+		OwnerID:       ownerID,
+		Type:          accountType,
+		Currency:      "JOD",
+		AllowNegative: models.AllowsNegative(accountType),
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&account).Error
+}
+
+// LockAccount loads an account with a row lock held until the transaction ends
+func (r *LedgerRepository) LockAccount(tx *gorm.DB, ownerID uuid.UUID, accountType string) (*models.Account, error) {
+	var account models.Account
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("owner_id = ? AND type = ?", ownerID, accountType).
+		First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// UpdateBalance persists a new account balance
+func (r *LedgerRepository) UpdateBalance(tx *gorm.DB, account *models.Account) error {
+	return tx.Model(account).Update("balance", account.Balance).Error
+}
+
+// FindTransactionByReference returns the transaction with the given reference, or nil
+func (r *LedgerRepository) FindTransactionByReference(tx *gorm.DB, reference string) (*models.Transaction, error) {
+	var txn models.Transaction
+	err := tx.Preload("Entries").Where("reference = ?", reference).First(&txn).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &txn, nil
+}
+
+// CreateTransaction inserts a transaction together with its entries
+func (r *LedgerRepository) CreateTransaction(tx *gorm.DB, txn *models.Transaction) error {
+	return tx.Create(txn).Error
+}
+
+// FindAccount returns the account for owner and type, or nil if it was never opened
+func (r *LedgerRepository) FindAccount(ctx context.Context, ownerID uuid.UUID, accountType string) (*models.Account, error) {
+	var account models.Account
+	err := r.db.WithContext(ctx).
+		Where("owner_id = ? AND type = ?", ownerID, accountType).
+		First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// ListEntries returns a page of entries for an account, newest first, and the total count
+func (r *LedgerRepository) ListEntries(ctx context.Context, accountID uuid.UUID, offset, limit int) ([]models.Entry, int64, error) {
+	var total int64
+	query := r.db.WithContext(ctx).Model(&models.Entry{}).Where("account_id = ?", accountID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.Entry
+	err := r.db.WithContext(ctx).
+		Preload("Transaction").
+		Where("account_id = ?", accountID).
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"theb-backend/internal/service/ledger/models"
+	"theb-backend/internal/service/ledger/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnbalanced is returned when posting lines do not sum to zero
+	ErrUnbalanced = errors.New("ledger posting is not balanced")
+	// ErrInsufficientFunds is returned when a posting would overdraw an account that cannot go negative
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrEmptyReference is returned when a posting has no idempotency reference
+	ErrEmptyReference = errors.New("ledger posting reference is required")
+	// ErrReferenceConflict is returned when a reference is posted again with different lines
+	ErrReferenceConflict = errors.New("ledger reference was already posted with different lines")
+)
+
+// Line moves Amount (in fils) into the account identified by OwnerID and AccountType.
+// Negative amounts move money out of the account.
+type Line struct {
+	OwnerID     uuid.UUID
+	AccountType string
+	Amount      int64
+}
+
+// Posting is a balanced set of lines recorded as one ledger transaction
+type Posting struct {
+	Kind        string
+	Reference   string
+	Description string
+	RideID      *uuid.UUID
+	CreatedBy   *uuid.UUID
+	Lines       []Line
+}
+
+// LedgerService records balanced money movements between accounts
+type LedgerService struct {
+	repo *repositories.LedgerRepository
+}
+
+// NewLedgerService creates a new ledger service
+func NewLedgerService(repo *repositories.LedgerRepository) *LedgerService {
+	return &LedgerService{repo: repo}
+}
+
+// Transaction runs fn inside a database transaction
+func (s *LedgerService) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return s.repo.DB().WithContext(ctx).Transaction(fn)
+}
+
+// Post records a posting inside tx. Accounts are created on first use and locked
+// in a fixed order so concurrent postings cannot deadlock. Posting the same
+// reference twice returns the original transaction without moving money again,
+// as long as it carries the same kind and lines; otherwise ErrReferenceConflict.
+// A posting with no lines moves nothing and only claims its reference, recording
+// a decision that a replay must not revisit.
+func (s *LedgerService) Post(tx *gorm.DB, posting Posting) (*models.Transaction, error) {
+	if posting.Reference == "" {
+		return nil, ErrEmptyReference
+	}
+
+	lines := mergeLines(posting.Lines)
+	var sum int64
+	for _, line := range lines {
+		sum += line.Amount
+	}
+	if sum != 0 || (len(lines) == 0 && len(posting.Lines) > 0) {
+		return nil, ErrUnbalanced
+	}
+
+	accounts := make([]*models.Account, len(lines))
+	for i, line := range lines {
+		if err := s.repo.EnsureAccount(tx, line.OwnerID, line.AccountType); err != nil {
+			return nil, fmt.Errorf("failed to open %s account: %w", line.AccountType, err)
+		}
+		account, err := s.repo.LockAccount(tx, line.OwnerID, line.AccountType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock %s account: %w", line.AccountType, err)
+		}
+		accounts[i] = account
+	}
+
+	// Checked after locking so a concurrent duplicate waits for the first one to commit
+	existing, err := s.repo.FindTransactionByReference(tx, posting.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if !samePosting(existing, posting.Kind, accounts, lines) {
+			return nil, fmt.Errorf("%w: %s", ErrReferenceConflict, posting.Reference)
+		}
+		return existing, nil
+	}
+
+	txn := &models.Transaction{
+		ID:          uuid.New(),
+		Kind:        posting.Kind,
+		Reference:   posting.Reference,
+		Description: posting.Description,
+		RideID:      posting.RideID,
+		CreatedBy:   posting.CreatedBy,
+	}
+
+	for i, line := range lines {
+		account := accounts[i]
+		account.Balance += line.Amount
+		if account.Balance < 0 && !account.AllowNegative {
+			return nil, ErrInsufficientFunds
+		}
+		if err := s.repo.UpdateBalance(tx, account); err != nil {
+			return nil, err
+		}
+
+		txn.Entries = append(txn.Entries, models.Entry{
+			ID:           uuid.New(),
+			AccountID:    account.ID,
+			Amount:       line.Amount,
+			BalanceAfter: account.Balance,
+		})
+	}
+
+	if err := s.repo.CreateTransaction(tx, txn); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// FindByReference returns the transaction posted under reference inside tx, or nil
+func (s *LedgerService) FindByReference(tx *gorm.DB, reference string) (*models.Transaction, error) {
+	return s.repo.FindTransactionByReference(tx, reference)
+}
+
+// Balance returns the current balance of an account; unopened accounts have a zero balance
+func (s *LedgerService) Balance(ctx context.Context, ownerID uuid.UUID, accountType string) (int64, error) {
+	account, err := s.repo.FindAccount(ctx, ownerID, accountType)
+	if err != nil {
+		return 0, err
+	}
+	if account == nil {
+		return 0, nil
+	}
+
+	return account.Balance, nil
+}
+
+// AccountRef identifies a ledger account by owner and type
+type AccountRef struct {
+	OwnerID     uuid.UUID
+	AccountType string
+}
+
+// LockBalances locks the given accounts inside tx, in the same order Post uses,
+// and returns their balances. Use it to read balances a posting depends on.
+func (s *LedgerService) LockBalances(tx *gorm.DB, refs ...AccountRef) (map[AccountRef]int64, error) {
+	lines := make([]Line, len(refs))
+	for i, ref := range refs {
+		// Non-zero placeholder amount so mergeLines keeps every account
+		lines[i] = Line{OwnerID: ref.OwnerID, AccountType: ref.AccountType, Amount: 1}
+	}
+
+	balances := make(map[AccountRef]int64, len(refs))
+	for _, line := range mergeLines(lines) {
+		if err := s.repo.EnsureAccount(tx, line.OwnerID, line.AccountType); err != nil {
+			return nil, err
+		}
+		account, err := s.repo.LockAccount(tx, line.OwnerID, line.AccountType)
+		if err != nil {
+			return nil, err
+		}
+		balances[AccountRef{OwnerID: line.OwnerID, AccountType: line.AccountType}] = account.Balance
+	}
+
+	return balances, nil
+}
+
+// Entries returns a page of entries for an account, newest first
+func (s *LedgerService) Entries(ctx context.Context, ownerID uuid.UUID, accountType string, offset, limit int) ([]models.Entry, int64, error) {
+	account, err := s.repo.FindAccount(ctx, ownerID, accountType)
+	if err != nil {
+		return nil, 0, err
+	}
+	if account == nil {
+		return []models.Entry{}, 0, nil
+	}
+
+	return s.repo.ListEntries(ctx, account.ID, offset, limit)
+}
+
+// samePosting reports whether txn moved the same amounts between the same
+// accounts as a posting of kind with the given merged lines
+func samePosting(txn *models.Transaction, kind string, accounts []*models.Account, lines []Line) bool {
+	if txn.Kind != kind || len(txn.Entries) != len(lines) {
+		return false
+	}
+
+	amounts := make(map[uuid.UUID]int64, len(lines))
+	for i, line := range lines {
+		amounts[accounts[i].ID] = line.Amount
+	}
+	for _, entry := range txn.Entries {
+		amount, ok := amounts[entry.AccountID]
+		if !ok || amount != entry.Amount {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeLines combines lines for the same account and sorts them into lock order
+func mergeLines(lines []Line) []Line {
+	type key struct {
+		owner       uuid.UUID
+		accountType string
+	}
+
+	totals := make(map[key]int64)
+	var keys []key
+	for _, line := range lines {
+		k := key{owner: line.OwnerID, accountType: line.AccountType}
+		if _, seen := totals[k]; !seen {
+			keys = append(keys, k)
+		}
+		totals[k] += line.Amount
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].accountType != keys[j].accountType {
+			return keys[i].accountType < keys[j].accountType
+		}
+		return keys[i].owner.String() < keys[j].owner.String()
+	})
+
+	merged := make([]Line, 0, len(keys))
+	for _, k := range keys {
+		if totals[k] == 0 {
+			continue
+		}
+		merged = append(merged, Line{OwnerID: k.owner, AccountType: k.accountType, Amount: totals[k]})
+	}
+
+	return merged
+}
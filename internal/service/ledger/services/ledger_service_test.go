@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"theb-backend/internal/service/ledger/models"
+
+	"github.com/google/uuid"
+)
+
+func TestSamePosting(t *testing.T) {
+	wallet := &models.Account{ID: uuid.New()}
+	captain := &models.Account{ID: uuid.New()}
+	passengerID, captainID := uuid.New(), uuid.New()
+
+	posted := &models.Transaction{
+		Kind: models.KindRideDebit,
+		Entries: []models.Entry{
+			{AccountID: wallet.ID, Amount: -500},
+			{AccountID: captain.ID, Amount: 500},
+		},
+	}
+	lines := func(amount int64) []Line {
+		return []Line{
+			{OwnerID: passengerID, AccountType: models.AccountPassengerWallet, Amount: -amount},
+			{OwnerID: captainID, AccountType: models.AccountCaptainBalance, Amount: amount},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		kind     string
+		accounts []*models.Account
+		lines    []Line
+		want     bool
+	}{
+		{name: "same lines", kind: models.KindRideDebit, accounts: []*models.Account{wallet, captain}, lines: lines(500), want: true},
+		{name: "different amount", kind: models.KindRideDebit, accounts: []*models.Account{wallet, captain}, lines: lines(700)},
+		{name: "different kind", kind: models.KindRideRefund, accounts: []*models.Account{wallet, captain}, lines: lines(500)},
+		{name: "different account", kind: models.KindRideDebit, accounts: []*models.Account{wallet, {ID: uuid.New()}}, lines: lines(500)},
+		{name: "extra line", kind: models.KindRideDebit, accounts: []*models.Account{wallet, captain, {ID: uuid.New()}}, lines: append(lines(500), Line{Amount: 1})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := samePosting(posted, tt.kind, tt.accounts, tt.lines); got != tt.want {
+				t.Errorf("samePosting() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RegisterDeviceRequest registers a device's Expo push token
+type RegisterDeviceRequest struct {
+	DeviceID string `json:"device_id" binding:"required,max=128"`
+	Token    string `json:"token" binding:"required,max=255"`
+	Platform string `json:"platform" binding:"required,oneof=ios android"`
+} // @name RegisterDeviceRequest
+
+// DeviceResponse is a registered push device
+type DeviceResponse struct {
+	ID         uuid.UUID `json:"id"`
+	DeviceID   string    `json:"device_id"`
+	Platform   string    `json:"platform"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+} // @name DeviceResponse
+
+// NotificationResponse is an inbox notification
+type NotificationResponse struct {
+	ID        uuid.UUID              `json:"notification_id"`
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body"`
+	Data      map[string]interface{} `json:"data"`
+	IsRead    bool                   `json:"is_read"`
+	ReadAt    *time.Time             `json:"read_at,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+} // @name NotificationResponse
+
+// NotificationListResponse is one page of the inbox
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
+	UnreadCount   int64                  `json:"unread_count"`
+} // @name NotificationListResponse
+
+// UnreadCountResponse is the number of unread notifications
+type UnreadCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+} // @name UnreadCountResponse
+
+// PreferencesRequest updates notification preferences
+type PreferencesRequest struct {
+	Locale string `json:"locale" binding:"required,oneof=ar en"`
+} // @name NotificationPreferencesRequest
+
+// PreferencesResponse is the user's notification preferences
+type PreferencesResponse struct {
+	Locale string `json:"locale"`
+} // @name NotificationPreferencesResponse
+
+// CreateTemplateRequest adds a new version of a notification template
+type CreateTemplateRequest struct {
+	Key    string `json:"key" binding:"required,max=64"`
+	Locale string `json:"locale" binding:"required,oneof=ar en"`
+	Type   string `json:"type" binding:"required,max=64"`
+	Title  string `json:"title" binding:"required,max=200"`
+	Body   string `json:"body" binding:"required,max=2000"`
+} // @name CreateNotificationTemplateRequest
+
+// TemplateResponse is one version of a notification template
+type TemplateResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Key       string     `json:"key"`
+	Locale    string     `json:"locale"`
+	Version   int        `json:"version"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+} // @name NotificationTemplateResponse
+
+// TemplateListResponse lists notification template versions
+type TemplateListResponse struct {
+	Templates []TemplateResponse `json:"templates"`
+} // @name NotificationTemplateListResponse
@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/events"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/realtime"
+	"theb-backend/internal/service/notification/expo"
+	"theb-backend/internal/service/notification/handlers"
+	"theb-backend/internal/service/notification/models"
+	"theb-backend/internal/service/notification/repositories"
+	"theb-backend/internal/service/notification/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Module wires the notification inbox, templates and Expo push delivery
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "notification" }
+
+// Requires returns no modules; notification uses only the core services
+func (Module) Requires() []string { return nil }
+
+// Migrations returns the notification tables
+func (Module) Migrations() []interface{} {
+	return []interface{}{
+		&models.Notification{},
+		&models.DeviceToken{},
+		&models.PushTicket{},
+		&models.Template{},
+		&models.Preference{},
+	}
+}
+
+// Register registers the Expo client, repositories, push dispatcher, template and
+// notification services and handler in the container and seeds the built-in templates.
+// The dispatcher starts and flushes with the container. Ride events are turned into
+// passenger notifications.
+func (Module) Register(ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	hub, err := container.Resolve[*realtime.Hub](ctn)
+	if err != nil {
+		return err
+	}
+	bus, err := container.Resolve[*events.Bus](ctn)
+	if err != nil {
+		return err
+	}
+
+	templateRepo := repositories.NewTemplateRepository(db)
+	templateService := services.NewTemplateService(templateRepo)
+	if err := templateService.Seed(context.Background()); err != nil {
+		return fmt.Errorf("failed to seed notification templates: %w", err)
+	}
+
+	baseURL := cfg.ExpoPush.BaseURL
+	var dispatcherOpts []container.Option
+	if cfg.ExpoPush.Fake {
+		fake, err := expo.NewFakeServer()
+		if err != nil {
+			return fmt.Errorf("failed to start fake Expo push server: %w", err)
+		}
+		baseURL = fake.URL()
+		container.Supply(ctn, fake, container.OnStop(func(ctx context.Context, fake *expo.FakeServer) error {
+			return fake.Close(ctx)
+		}))
+		// The dispatcher sends to the fake server, so it stops draining first
+		dispatcherOpts = append(dispatcherOpts, container.DependsOn[*expo.FakeServer]())
+		logger.Info("Using fake Expo push server", map[string]interface{}{
+			"url": baseURL,
+		})
+	}
+	client := expo.NewHTTPClient(baseURL, cfg.ExpoPush.Token)
+
+	deviceRepo := repositories.NewDeviceRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	dispatcher := services.NewPushDispatcher(cfg.ExpoPush, client, deviceRepo, notificationRepo)
+	service := services.NewNotificationService(deviceRepo, notificationRepo, templateRepo, templateService, dispatcher, hub)
+
+	container.Supply(ctn, client)
+	container.Supply(ctn, deviceRepo)
+	container.Supply(ctn, notificationRepo)
+	container.Supply(ctn, templateRepo)
+	container.Supply(ctn, templateService)
+	container.Supply(ctn, dispatcher, append(dispatcherOpts,
+		container.OnStart(func(ctx context.Context, dispatcher *services.PushDispatcher) error {
+			dispatcher.Start()
+			return nil
+		}),
+		container.OnStop(func(ctx context.Context, dispatcher *services.PushDispatcher) error {
+			return dispatcher.Stop(ctx)
+		}),
+	)...)
+	container.Supply(ctn, service)
+	container.Supply(ctn, handlers.NewNotificationHandler(service, templateService))
+
+	subscribe(bus, service)
+
+	return nil
+}
+
+// Jobs returns the push receipt poller
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return nil, err
+	}
+	dispatcher, err := container.Resolve[*services.PushDispatcher](ctn)
+	if err != nil {
+		return nil, err
+	}
+
+	receiptInterval := cfg.ExpoPush.ReceiptInterval
+	if receiptInterval <= 0 {
+		receiptInterval = 5 * time.Minute
+	}
+
+	return []jobs.CronJob{{
+		Name:     "push_receipts",
+		Schedule: "@every " + receiptInterval.String(),
+		Run:      dispatcher.CheckReceipts,
+	}}, nil
+}
+
+// subscribe notifies passengers about their rides from domain events. Notifications
+// are stored and pushed outside the event's transaction, so they are keyed on the
+// event ID to be sent once however often the event is redelivered.
+func subscribe(bus *events.Bus, service *services.NotificationService) {
+	events.On(bus, "notification.ride_matched", func(ctx context.Context, tx *gorm.DB, event events.RideMatched) error {
+		eventID, _ := events.EventID(ctx)
+		_, err := service.NotifyEvent(ctx, eventID, []uuid.UUID{event.PassengerID}, "ride_matched", map[string]interface{}{
+			"captain_name": event.CaptainName,
+			"vehicle":      event.Vehicle,
+		}, models.Data{"ride_id": event.RideID.String()})
+		return err
+	})
+
+	events.On(bus, "notification.ride_completed", func(ctx context.Context, tx *gorm.DB, event events.RideCompleted) error {
+		eventID, _ := events.EventID(ctx)
+		_, err := service.NotifyEvent(ctx, eventID, []uuid.UUID{event.PassengerID}, "ride_completed", map[string]interface{}{
+			"fare": fmt.Sprintf("%.3f", float64(event.Fare)/1000),
+		}, models.Data{"ride_id": event.RideID.String()})
+		return err
+	})
+}
+
+// Routes mounts the notification endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	handler, err := container.Resolve[*handlers.NotificationHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+
+	notifications := v1.Group("/notifications", auth)
+	{
+		notifications.GET("", handler.List)
+		notifications.GET("/unread-count", handler.UnreadCount)
+		notifications.POST("/read-all", handler.MarkAllRead)
+		notifications.POST("/:id/read", handler.MarkRead)
+		notifications.GET("/preferences", handler.GetPreferences)
+		notifications.PUT("/preferences", handler.UpdatePreferences)
+		notifications.POST("/devices", handler.RegisterDevice)
+		notifications.DELETE("/devices/:device_id", handler.UnregisterDevice)
+	}
+
+	admin := v1.Group("/admin/notification-templates", auth, middleware.RequireRole(middleware.RoleAdmin))
+	{
+		admin.GET("", handler.ListTemplates)
+		admin.POST("", handler.CreateTemplate)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package expo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"theb-backend/internal/tracing"
+)
+
+// DefaultBaseURL is the public Expo push API root
+const DefaultBaseURL = "https://exp.host/--/api/v2/push"
+
+// Limits imposed by the Expo push API
+const (
+	MaxMessagesPerRequest = 100
+	MaxReceiptsPerRequest = 1000
+)
+
+// Ticket and receipt statuses
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// Error codes reported in ticket and receipt details
+const (
+	ErrorDeviceNotRegistered = "DeviceNotRegistered"
+	ErrorMessageTooBig       = "MessageTooBig"
+	ErrorMessageRateExceeded = "MessageRateExceeded"
+	ErrorMismatchSenderID    = "MismatchSenderId"
+	ErrorInvalidCredentials  = "InvalidCredentials"
+)
+
+// ErrTransient marks failures that are worth retrying: network errors,
+// rate limiting and 5xx responses from Expo
+var ErrTransient = errors.New("transient expo push failure")
+
+// Message is a single push notification addressed to one Expo push token
+type Message struct {
+	To        string                 `json:"to"`
+	Title     string                 `json:"title,omitempty"`
+	Body      string                 `json:"body,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Sound     string                 `json:"sound,omitempty"`
+	Priority  string                 `json:"priority,omitempty"`
+	ChannelID string                 `json:"channelId,omitempty"`
+	TTL       int                    `json:"ttl,omitempty"`
+}
+
+// Details carries the machine-readable error code of a failed ticket or receipt
+type Details struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Ticket is Expo's immediate answer for one message, in request order
+type Ticket struct {
+	Status  string  `json:"status"`
+	ID      string  `json:"id,omitempty"`
+	Message string  `json:"message,omitempty"`
+	Details Details `json:"details,omitempty"`
+}
+
+// Receipt is the delivery outcome reported by Expo once the message reached APNs/FCM
+type Receipt struct {
+	Status  string  `json:"status"`
+	Message string  `json:"message,omitempty"`
+	Details Details `json:"details,omitempty"`
+}
+
+// Client sends push messages and fetches their receipts
+type Client interface {
+	// Send delivers up to MaxMessagesPerRequest messages and returns one ticket per message
+	Send(ctx context.Context, messages []Message) ([]Ticket, error)
+	// Receipts returns receipts for up to MaxReceiptsPerRequest ticket IDs.
+	// IDs whose receipt is not ready yet are absent from the map.
+	Receipts(ctx context.Context, ticketIDs []string) (map[string]Receipt, error)
+}
+
+// IsPushToken reports whether token looks like an Expo push token
+func IsPushToken(token string) bool {
+	return (strings.HasPrefix(token, "ExponentPushToken[") || strings.HasPrefix(token, "ExpoPushToken[")) &&
+		strings.HasSuffix(token, "]")
+}
+
+// HTTPClient talks to the Expo push API over HTTP
+type HTTPClient struct {
+	baseURL     string
+	accessToken string
+	http        *http.Client
+}
+
+// NewHTTPClient creates an Expo push client. accessToken is optional and only
+// needed when enhanced push security is enabled for the Expo project.
+func NewHTTPClient(baseURL, accessToken string) *HTTPClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &HTTPClient{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		accessToken: accessToken,
+		http:        &http.Client{Timeout: 15 * time.Second, Transport: tracing.Transport(nil)},
+	}
+}
+
+// Send posts messages to /send
+func (c *HTTPClient) Send(ctx context.Context, messages []Message) ([]Ticket, error) {
+	if len(messages) > MaxMessagesPerRequest {
+		return nil, fmt.Errorf("expo accepts at most %d messages per request, got %d", MaxMessagesPerRequest, len(messages))
+	}
+
+	var response struct {
+		Data []Ticket `json:"data"`
+	}
+	if err := c.post(ctx, "/send", messages, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Data) != len(messages) {
+		return nil, fmt.Errorf("%w: expected %d tickets, got %d", ErrTransient, len(messages), len(response.Data))
+	}
+
+	return response.Data, nil
+}
+
+// Receipts posts ticket IDs to /getReceipts
+func (c *HTTPClient) Receipts(ctx context.Context, ticketIDs []string) (map[string]Receipt, error) {
+	if len(ticketIDs) > MaxReceiptsPerRequest {
+		return nil, fmt.Errorf("expo accepts at most %d receipt IDs per request, got %d", MaxReceiptsPerRequest, len(ticketIDs))
+	}
+
+	var response struct {
+		Data map[string]Receipt `json:"data"`
+	}
+	if err := c.post(ctx, "/getReceipts", map[string][]string{"ids": ticketIDs}, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Data, nil
+}
+
+func (c *HTTPClient) post(ctx context.Context, path string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTransient, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTransient, err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return fmt.Errorf("%w: %s returned %d", ErrTransient, path, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("expo %s returned %d: %s", path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid expo response: %w", err)
+	}
+
+	return nil
+}
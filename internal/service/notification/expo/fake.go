@@ -0,0 +1,198 @@
+package expo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"theb-backend/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+// FakeServer is an in-process stand-in for the Expo push API, used in
+// development and wherever the real service cannot be reached. Point an
+// HTTPClient at URL() to exercise the full HTTP path without network access.
+type FakeServer struct {
+	server *http.Server
+	url    string
+
+	mu            sync.Mutex
+	messages      []Message
+	batches       []int
+	tickets       map[string]string
+	unregistered  map[string]bool
+	failures      int
+	failureStatus int
+}
+
+// NewFakeServer starts a fake Expo push API on a local port
+func NewFakeServer() (*FakeServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FakeServer{
+		url:          "http://" + listener.Addr().String(),
+		tickets:      make(map[string]string),
+		unregistered: make(map[string]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/send", f.handleSend)
+	mux.HandleFunc("/getReceipts", f.handleReceipts)
+	f.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := f.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Fake Expo push server stopped", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	return f, nil
+}
+
+// URL is the base URL to pass to NewHTTPClient
+func (f *FakeServer) URL() string {
+	return f.url
+}
+
+// Close shuts the server down, waiting for in-flight requests until ctx expires
+func (f *FakeServer) Close(ctx context.Context) error {
+	return f.server.Shutdown(ctx)
+}
+
+// Unregister makes token behave like an uninstalled app: new sends fail on the
+// ticket and receipts for earlier sends report DeviceNotRegistered
+func (f *FakeServer) Unregister(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unregistered[token] = true
+}
+
+// FailNext makes the next n requests answer 503 to simulate an Expo outage
+func (f *FakeServer) FailNext(n int) {
+	f.failNext(n, http.StatusServiceUnavailable)
+}
+
+// ThrottleNext makes the next n requests answer 429 to simulate rate limiting
+func (f *FakeServer) ThrottleNext(n int) {
+	f.failNext(n, http.StatusTooManyRequests)
+}
+
+// Messages returns every message accepted so far
+func (f *FakeServer) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.messages...)
+}
+
+// Batches returns the number of messages in each send request answered so far
+func (f *FakeServer) Batches() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.batches...)
+}
+
+func (f *FakeServer) failNext(n, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+	f.failureStatus = status
+}
+
+// failing consumes one simulated failure and answers it, if any are pending
+func (f *FakeServer) failing(w http.ResponseWriter) bool {
+	if f.failures == 0 {
+		return false
+	}
+	f.failures--
+	http.Error(w, http.StatusText(f.failureStatus), f.failureStatus)
+	return true
+}
+
+func (f *FakeServer) handleSend(w http.ResponseWriter, r *http.Request) {
+	var messages []Message
+	if err := json.NewDecoder(r.Body).Decode(&messages); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(messages) > MaxMessagesPerRequest {
+		http.Error(w, "too many messages", http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failing(w) {
+		return
+	}
+	f.batches = append(f.batches, len(messages))
+
+	tickets := make([]Ticket, 0, len(messages))
+	for _, message := range messages {
+		switch {
+		case !IsPushToken(message.To) || f.unregistered[message.To]:
+			tickets = append(tickets, Ticket{
+				Status:  StatusError,
+				Message: message.To + " is not a registered push notification recipient",
+				Details: Details{Error: ErrorDeviceNotRegistered},
+			})
+		default:
+			id := uuid.NewString()
+			f.tickets[id] = message.To
+			f.messages = append(f.messages, message)
+			tickets = append(tickets, Ticket{Status: StatusOK, ID: id})
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{"data": tickets})
+}
+
+func (f *FakeServer) handleReceipts(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failing(w) {
+		return
+	}
+
+	receipts := make(map[string]Receipt, len(request.IDs))
+	for _, id := range request.IDs {
+		token, ok := f.tickets[id]
+		if !ok {
+			continue
+		}
+		if f.unregistered[token] {
+			receipts[id] = Receipt{
+				Status:  StatusError,
+				Message: "The device cannot receive push notifications anymore",
+				Details: Details{Error: ErrorDeviceNotRegistered},
+			}
+			continue
+		}
+		receipts[id] = Receipt{Status: StatusOK}
+	}
+
+	writeJSON(w, map[string]interface{}{"data": receipts})
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
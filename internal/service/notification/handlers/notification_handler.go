@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/notification/dtos"
+	"theb-backend/internal/service/notification/models"
+	"theb-backend/internal/service/notification/services"
+	"theb-backend/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// NotificationHandler serves notification endpoints
+type NotificationHandler struct {
+	service   *services.NotificationService
+	templates *services.TemplateService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(service *services.NotificationService, templates *services.TemplateService) *NotificationHandler {
+	return &NotificationHandler{service: service, templates: templates}
+}
+
+// List returns the authenticated user's inbox
+// @Summary List notifications
+// @ID notification-list
+// @Tags Notification
+// @Security BearerAuth
+// @Produce json
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} dtos.NotificationListResponse
+// @Router /api/v1/notifications [get]
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	params, err := pagination.CursorFromQuery(c)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("cursor", "cursor")))
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), userID, params)
+	if err != nil {
+		h.handleError(c, "Failed to load notifications", err)
+		return
+	}
+
+	response := dtos.NotificationListResponse{
+		Notifications: make([]dtos.NotificationResponse, 0, len(page.Notifications)),
+		NextCursor:    page.NextCursor,
+		UnreadCount:   page.UnreadCount,
+	}
+	for _, notification := range page.Notifications {
+		response.Notifications = append(response.Notifications, notificationResponse(notification))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UnreadCount returns how many notifications the authenticated user has not read
+// @Summary Get unread notification count
+// @ID notification-unread-count
+// @Tags Notification
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dtos.UnreadCountResponse
+// @Router /api/v1/notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	count, err := h.service.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, "Failed to count notifications", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.UnreadCountResponse{UnreadCount: count})
+}
+
+// MarkRead marks one notification read
+// @Summary Mark a notification read
+// @ID notification-mark-read
+// @Tags Notification
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} dtos.UnreadCountResponse
+// @Router /api/v1/notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return
+	}
+
+	count, err := h.service.MarkRead(c.Request.Context(), userID, notificationID)
+	if err != nil {
+		h.handleError(c, "Failed to mark notification read", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.UnreadCountResponse{UnreadCount: count})
+}
+
+// MarkAllRead marks all of the authenticated user's notifications read
+// @Summary Mark all notifications read
+// @ID notification-mark-all-read
+// @Tags Notification
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dtos.UnreadCountResponse
+// @Router /api/v1/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	count, err := h.service.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, "Failed to mark notifications read", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.UnreadCountResponse{UnreadCount: count})
+}
+
+// GetPreferences returns the authenticated user's notification preferences
+// @Summary Get notification preferences
+// @ID notification-get-preferences
+// @Tags Notification
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dtos.PreferencesResponse
+// @Router /api/v1/notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	locale, err := h.service.Locale(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, "Failed to load notification preferences", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.PreferencesResponse{Locale: locale})
+}
+
+// UpdatePreferences sets the language notifications are rendered in
+// @Summary Update notification preferences
+// @ID notification-update-preferences
+// @Tags Notification
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.PreferencesRequest true "Preferred locale (ar or en)"
+// @Success 200 {object} dtos.PreferencesResponse
+// @Router /api/v1/notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	if err := h.service.SetLocale(c.Request.Context(), userID, req.Locale); err != nil {
+		h.handleError(c, "Failed to update notification preferences", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.PreferencesResponse{Locale: req.Locale})
+}
+
+// ListTemplates returns notification template versions
+// @Summary List notification templates (admin)
+// @ID notification-admin-list-templates
+// @Tags Notification
+// @Security BearerAuth
+// @Produce json
+// @Param key query string false "Only versions of this template key"
+// @Success 200 {object} dtos.TemplateListResponse
+// @Router /api/v1/admin/notification-templates [get]
+func (h *NotificationHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.templates.List(c.Request.Context(), c.Query("key"))
+	if err != nil {
+		h.handleError(c, "Failed to load notification templates", err)
+		return
+	}
+
+	response := dtos.TemplateListResponse{Templates: make([]dtos.TemplateResponse, 0, len(templates))}
+	for _, tpl := range templates {
+		response.Templates = append(response.Templates, templateResponse(tpl))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateTemplate publishes a new version of a notification template
+// @Summary Create a notification template version (admin)
+// @ID notification-admin-create-template
+// @Tags Notification
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.CreateTemplateRequest true "Template key, locale and text/template title and body"
+// @Success 201 {object} dtos.TemplateResponse
+// @Router /api/v1/admin/notification-templates [post]
+func (h *NotificationHandler) CreateTemplate(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	tpl, err := h.templates.CreateVersion(c.Request.Context(), adminID, req.Key, req.Locale, req.Type, req.Title, req.Body)
+	if err != nil {
+		h.handleError(c, "Failed to create notification template", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, templateResponse(*tpl))
+}
+
+// RegisterDevice stores the push token of the caller's device
+// @Summary Register a device for push notifications
+// @ID notification-register-device
+// @Tags Notification
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.RegisterDeviceRequest true "Device ID, Expo push token and platform"
+// @Success 200 {object} dtos.DeviceResponse
+// @Router /api/v1/notifications/devices [post]
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	device, err := h.service.RegisterDevice(c.Request.Context(), userID, req.DeviceID, req.Token, req.Platform)
+	if err != nil {
+		h.handleError(c, "Failed to register device", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.DeviceResponse{
+		ID:         device.ID,
+		DeviceID:   device.DeviceID,
+		Platform:   device.Platform,
+		LastSeenAt: device.LastSeenAt,
+	})
+}
+
+// UnregisterDevice stops push notifications to one of the caller's devices
+// @Summary Unregister a push device
+// @ID notification-unregister-device
+// @Tags Notification
+// @Security BearerAuth
+// @Param device_id path string true "Device ID"
+// @Success 204
+// @Router /api/v1/notifications/devices/{device_id} [delete]
+func (h *NotificationHandler) UnregisterDevice(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	if err := h.service.UnregisterDevice(c.Request.Context(), userID, c.Param("device_id")); err != nil {
+		h.handleError(c, "Failed to unregister device", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPushToken):
+		apierror.Abort(c, apierror.Wrap(apierror.CodePushTokenInvalid, err))
+	case errors.Is(err, services.ErrInvalidTemplate):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeTemplateInvalid, err))
+	case errors.Is(err, services.ErrUnsupportedLocale):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeLocaleUnsupported, err))
+	case errors.Is(err, services.ErrTemplateNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeTemplateNotFound, err))
+	case errors.Is(err, services.ErrDeviceNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeDeviceNotFound, err))
+	case errors.Is(err, services.ErrNotificationNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeNotificationNotFound, err))
+	default:
+		logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+			"error": err.Error(),
+		})
+		apierror.Abort(c, apierror.New(apierror.CodeInternal))
+	}
+}
+
+func notificationResponse(notification models.Notification) dtos.NotificationResponse {
+	data := notification.Data
+	if data == nil {
+		data = models.Data{}
+	}
+
+	return dtos.NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Data:      data,
+		IsRead:    notification.IsRead,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+func templateResponse(tpl models.Template) dtos.TemplateResponse {
+	return dtos.TemplateResponse{
+		ID:        tpl.ID,
+		Key:       tpl.Key,
+		Locale:    tpl.Locale,
+		Version:   tpl.Version,
+		Type:      tpl.Type,
+		Title:     tpl.Title,
+		Body:      tpl.Body,
+		CreatedBy: tpl.CreatedBy,
+		CreatedAt: tpl.CreatedAt,
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Device platforms
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+)
+
+// DeviceToken is an Expo push token registered by one of a user's devices
+type DeviceToken struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_push_devices_user_device" json:"user_id"`
+	DeviceID   string    `gorm:"type:varchar(128);not null;uniqueIndex:idx_push_devices_user_device" json:"device_id"`
+	Token      string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"token"`
+	Platform   string    `gorm:"type:varchar(16);not null" json:"platform"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (DeviceToken) TableName() string {
+	return "push_device_tokens"
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Data is free-form notification payload stored as JSON, e.g. {"ride_id": "..."}
+type Data map[string]interface{}
+
+// Value implements driver.Valuer
+func (d Data) Value() (driver.Value, error) {
+	if d == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(d)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (d *Data) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	default:
+		return fmt.Errorf("unsupported notification data type %T", value)
+	}
+}
+
+// Notification is a message shown in a user's in-app inbox and optionally pushed to their devices.
+// Templated notifications record the template version and locale they were rendered with.
+type Notification struct {
+	ID              uuid.UUID  `gorm:"column:notification_id;type:uuid;primaryKey" json:"notification_id"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;index:idx_notifications_user_created,priority:1;index:idx_notifications_user_unread,where:is_read = false" json:"user_id"`
+	Type            string     `gorm:"type:varchar(64);not null;default:'general'" json:"type"`
+	Title           string     `gorm:"type:varchar(200);not null" json:"title"`
+	Body            string     `gorm:"type:text;not null" json:"body"`
+	Data            Data       `gorm:"type:jsonb;not null;default:'{}'" json:"data"`
+	TemplateKey     string     `gorm:"type:varchar(64)" json:"template_key,omitempty"`
+	TemplateVersion int        `json:"template_version,omitempty"`
+	Locale          string     `gorm:"type:varchar(8)" json:"locale,omitempty"`
+	IsRead          bool       `gorm:"not null;default:false" json:"is_read"`
+	ReadAt          *time.Time `json:"read_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"index:idx_notifications_user_created,priority:2" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Push ticket statuses
+const (
+	TicketPending   = "pending"
+	TicketDelivered = "delivered"
+	TicketFailed    = "failed"
+	TicketExpired   = "expired"
+)
+
+// PushTicket tracks one push sent to Expo until its receipt has been checked
+type PushTicket struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	NotificationID uuid.UUID  `gorm:"type:uuid;not null;index" json:"notification_id"`
+	Token          string     `gorm:"type:varchar(255);not null" json:"token"`
+	TicketID       string     `gorm:"type:varchar(64);index" json:"ticket_id,omitempty"`
+	Status         string     `gorm:"type:varchar(16);not null;index:idx_push_tickets_status_created" json:"status"`
+	Error          string     `gorm:"type:varchar(64)" json:"error,omitempty"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	CheckedAt      *time.Time `json:"checked_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"index:idx_push_tickets_status_created" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (PushTicket) TableName() string {
+	return "push_tickets"
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supported notification locales
+const (
+	LocaleArabic  = "ar"
+	LocaleEnglish = "en"
+	// DefaultLocale is used for users without a preference
+	DefaultLocale = LocaleArabic
+)
+
+// Template is one version of a notification's title and body in one locale.
+// Title and Body use text/template syntax, e.g. "{{.captain_name}} is on the way".
+// The highest version of a key and locale is the one rendered.
+type Template struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Key       string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_notification_templates_version" json:"key"`
+	Locale    string     `gorm:"type:varchar(8);not null;uniqueIndex:idx_notification_templates_version" json:"locale"`
+	Version   int        `gorm:"not null;uniqueIndex:idx_notification_templates_version" json:"version"`
+	Type      string     `gorm:"type:varchar(64);not null" json:"type"`
+	Title     string     `gorm:"type:varchar(200);not null" json:"title"`
+	Body      string     `gorm:"type:text;not null" json:"body"`
+	CreatedBy *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (Template) TableName() string {
+	return "notification_templates"
+}
+
+// Preference holds a user's notification settings
+type Preference struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Locale    string    `gorm:"type:varchar(8);not null" json:"locale"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Preference) TableName() string {
+	return "notification_preferences"
+}
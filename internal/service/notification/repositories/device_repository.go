@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+
+	"theb-backend/internal/service/notification/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeviceRepository provides data access for push device tokens
+type DeviceRepository struct {
+	db *gorm.DB
+}
+
+// NewDeviceRepository creates a new device repository
+func NewDeviceRepository(db *gorm.DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+// Upsert stores the token for a user's device. A token belongs to one device at a
+// time, so any other row holding it (e.g. after a different user logs in) is removed.
+func (r *DeviceRepository) Upsert(ctx context.Context, device *models.DeviceToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token = ? AND NOT (user_id = ? AND device_id = ?)", device.Token, device.UserID, device.DeviceID).
+			Delete(&models.DeviceToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "device_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"token", "platform", "last_seen_at", "updated_at"}),
+		}).Create(device).Error
+	})
+}
+
+// Delete removes a user's device and reports whether it existed
+func (r *DeviceRepository) Delete(ctx context.Context, userID uuid.UUID, deviceID string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND device_id = ?", userID, deviceID).Delete(&models.DeviceToken{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteByTokens removes tokens Expo reported as no longer registered
+func (r *DeviceRepository) DeleteByTokens(ctx context.Context, tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("token IN ?", tokens).Delete(&models.DeviceToken{}).Error
+}
+
+// ListByUsers returns all devices registered by the given users
+func (r *DeviceRepository) ListByUsers(ctx context.Context, userIDs []uuid.UUID) ([]models.DeviceToken, error) {
+	var devices []models.DeviceToken
+	err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&devices).Error
+	return devices, err
+}
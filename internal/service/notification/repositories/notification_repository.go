@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"theb-backend/internal/service/notification/models"
+	"theb-backend/pkg/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository provides data access for notifications and push tickets
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create inserts notifications
+func (r *NotificationRepository) Create(ctx context.Context, notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&notifications).Error
+}
+
+// CreateMissing inserts the notifications whose ID is not stored yet and returns them
+func (r *NotificationRepository) CreateMissing(ctx context.Context, notifications []models.Notification) ([]models.Notification, error) {
+	created := make([]models.Notification, 0, len(notifications))
+	for i := range notifications {
+		result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&notifications[i])
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			created = append(created, notifications[i])
+		}
+	}
+	return created, nil
+}
+
+// List returns a user's notifications newest first, starting after the cursor when one is given
+func (r *NotificationRepository) List(ctx context.Context, userID uuid.UUID, after *pagination.Cursor, limit int) ([]models.Notification, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if after != nil {
+		query = query.Where("(created_at, notification_id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var notifications []models.Notification
+	err := query.Order("created_at DESC, notification_id DESC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+// UnreadCount returns how many of a user's notifications are unread
+func (r *NotificationRepository) UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of a user's notifications read and reports whether it exists.
+// Marking an already read notification is not an error.
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("notification_id = ? AND user_id = ?", id, userID).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": gorm.Expr("COALESCE(read_at, ?)", at),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkAllRead marks all of a user's unread notifications read and returns how many changed
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": at})
+	return result.RowsAffected, result.Error
+}
+
+// CreateTickets inserts push tickets
+func (r *NotificationRepository) CreateTickets(ctx context.Context, tickets []models.PushTicket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&tickets).Error
+}
+
+// PendingTickets returns tickets sent before sentBefore whose receipt has not been
+// checked yet, oldest first, starting after the cursor when one is given
+func (r *NotificationRepository) PendingTickets(ctx context.Context, after *pagination.Cursor, sentBefore time.Time, limit int) ([]models.PushTicket, error) {
+	query := r.db.WithContext(ctx).Where("status = ? AND created_at < ?", models.TicketPending, sentBefore)
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var tickets []models.PushTicket
+	err := query.Order("created_at, id").Limit(limit).Find(&tickets).Error
+	return tickets, err
+}
+
+// SaveTicket persists changes to a push ticket
+func (r *NotificationRepository) SaveTicket(ctx context.Context, ticket *models.PushTicket) error {
+	return r.db.WithContext(ctx).Save(ticket).Error
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"theb-backend/internal/service/notification/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TemplateRepository provides data access for notification templates and user preferences
+type TemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewTemplateRepository creates a new template repository
+func NewTemplateRepository(db *gorm.DB) *TemplateRepository {
+	return &TemplateRepository{db: db}
+}
+
+// Seed inserts template versions that do not exist yet
+func (r *TemplateRepository) Seed(ctx context.Context, templates []models.Template) error {
+	if len(templates) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&templates).Error
+}
+
+// Latest returns the highest version of a template in a locale, or nil if there is none
+func (r *TemplateRepository) Latest(ctx context.Context, key, locale string) (*models.Template, error) {
+	var template models.Template
+	err := r.db.WithContext(ctx).
+		Where("key = ? AND locale = ?", key, locale).
+		Order("version DESC").
+		First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// List returns all versions of all templates, or of one key when key is not empty
+func (r *TemplateRepository) List(ctx context.Context, key string) ([]models.Template, error) {
+	query := r.db.WithContext(ctx)
+	if key != "" {
+		query = query.Where("key = ?", key)
+	}
+
+	var templates []models.Template
+	err := query.Order("key, locale, version DESC").Find(&templates).Error
+	return templates, err
+}
+
+// CreateVersion stores template as the next version of its key and locale
+func (r *TemplateRepository) CreateVersion(ctx context.Context, template *models.Template) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current int
+		if err := tx.Model(&models.Template{}).
+			Where("key = ? AND locale = ?", template.Key, template.Locale).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&current).Error; err != nil {
+			return err
+		}
+
+		template.Version = current + 1
+		return tx.Create(template).Error
+	})
+}
+
+// FindLocales returns the preferred locale of each user that has one
+func (r *TemplateRepository) FindLocales(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	var preferences []models.Preference
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+
+	locales := make(map[uuid.UUID]string, len(preferences))
+	for _, preference := range preferences {
+		locales[preference.UserID] = preference.Locale
+	}
+
+	return locales, nil
+}
+
+// SavePreference creates or updates a user's preferences
+func (r *TemplateRepository) SavePreference(ctx context.Context, preference *models.Preference) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"locale", "updated_at"}),
+	}).Create(preference).Error
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"theb-backend/internal/logger"
+	"theb-backend/internal/realtime"
+	"theb-backend/internal/service/notification/expo"
+	"theb-backend/internal/service/notification/models"
+	"theb-backend/internal/service/notification/repositories"
+	"theb-backend/pkg/pagination"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidPushToken is returned when a device registers something that is not an Expo push token
+	ErrInvalidPushToken = errors.New("invalid expo push token")
+	// ErrDeviceNotFound is returned when unregistering an unknown device
+	ErrDeviceNotFound = errors.New("device not found")
+	// ErrNotificationNotFound is returned when a notification does not exist or belongs to another user
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// TypeGeneral is the notification type used when none is given
+const TypeGeneral = "general"
+
+// Realtime event types sent over the user's WebSocket
+const (
+	EventNotificationCreated = "notification.created"
+	EventNotificationsRead   = "notification.read"
+)
+
+// Message is the content of a notification sent to one or more users
+type Message struct {
+	Type  string
+	Title string
+	Body  string
+	Data  models.Data
+}
+
+// Page is one page of a user's inbox
+type Page struct {
+	Notifications []models.Notification
+	NextCursor    string
+	UnreadCount   int64
+}
+
+// NotificationService stores inbox notifications, pushes them to users' devices
+// and delivers them in real time to connected WebSockets
+type NotificationService struct {
+	devices       *repositories.DeviceRepository
+	notifications *repositories.NotificationRepository
+	preferences   *repositories.TemplateRepository
+	templates     *TemplateService
+	dispatcher    *PushDispatcher
+	hub           *realtime.Hub
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(
+	devices *repositories.DeviceRepository,
+	notifications *repositories.NotificationRepository,
+	preferences *repositories.TemplateRepository,
+	templates *TemplateService,
+	dispatcher *PushDispatcher,
+	hub *realtime.Hub,
+) *NotificationService {
+	return &NotificationService{
+		devices:       devices,
+		notifications: notifications,
+		preferences:   preferences,
+		templates:     templates,
+		dispatcher:    dispatcher,
+		hub:           hub,
+	}
+}
+
+// RegisterDevice stores or refreshes the push token of a user's device
+func (s *NotificationService) RegisterDevice(ctx context.Context, userID uuid.UUID, deviceID, token, platform string) (*models.DeviceToken, error) {
+	if !expo.IsPushToken(token) {
+		return nil, ErrInvalidPushToken
+	}
+
+	device := &models.DeviceToken{
+		ID:         uuid.New(),
+		UserID:     userID,
+		DeviceID:   deviceID,
+		Token:      token,
+		Platform:   platform,
+		LastSeenAt: time.Now().UTC(),
+	}
+	if err := s.devices.Upsert(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// UnregisterDevice removes a device, e.g. on logout
+func (s *NotificationService) UnregisterDevice(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	deleted, err := s.devices.Delete(ctx, userID, deviceID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// Locale returns the user's preferred notification locale
+func (s *NotificationService) Locale(ctx context.Context, userID uuid.UUID) (string, error) {
+	locales, err := s.preferences.FindLocales(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return "", err
+	}
+	if locale, ok := locales[userID]; ok {
+		return locale, nil
+	}
+
+	return models.DefaultLocale, nil
+}
+
+// SetLocale stores the user's preferred notification locale
+func (s *NotificationService) SetLocale(ctx context.Context, userID uuid.UUID, locale string) error {
+	if !IsSupportedLocale(locale) {
+		return ErrUnsupportedLocale
+	}
+
+	return s.preferences.SavePreference(ctx, &models.Preference{
+		UserID:    userID,
+		Locale:    locale,
+		UpdatedAt: time.Now().UTC(),
+	})
+}
+
+// Notify stores a notification in the user's inbox and delivers it
+func (s *NotificationService) Notify(ctx context.Context, userID uuid.UUID, message Message) (*models.Notification, error) {
+	notifications, err := s.NotifyMany(ctx, []uuid.UUID{userID}, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifications[0], nil
+}
+
+// NotifyMany stores the same notification in each user's inbox and delivers it
+func (s *NotificationService) NotifyMany(ctx context.Context, userIDs []uuid.UUID, message Message) ([]models.Notification, error) {
+	if message.Type == "" {
+		message.Type = TypeGeneral
+	}
+
+	notifications := make([]models.Notification, len(userIDs))
+	for i, userID := range userIDs {
+		notifications[i] = models.Notification{
+			ID:     uuid.New(),
+			UserID: userID,
+			Type:   message.Type,
+			Title:  message.Title,
+			Body:   message.Body,
+			Data:   message.Data,
+		}
+	}
+
+	if err := s.notifications.Create(ctx, notifications); err != nil {
+		return nil, err
+	}
+
+	s.deliver(ctx, notifications)
+	return notifications, nil
+}
+
+// NotifyTemplate renders template key in each user's preferred locale, stores the
+// result in their inbox and delivers it. params fill the template placeholders.
+func (s *NotificationService) NotifyTemplate(ctx context.Context, userIDs []uuid.UUID, key string, params map[string]interface{}, data models.Data) ([]models.Notification, error) {
+	notifications, err := s.render(ctx, userIDs, key, params, data, func(uuid.UUID) uuid.UUID { return uuid.New() })
+	if err != nil {
+		return nil, err
+	}
+	if err := s.notifications.Create(ctx, notifications); err != nil {
+		return nil, err
+	}
+
+	s.deliver(ctx, notifications)
+	return notifications, nil
+}
+
+// NotifyEvent is NotifyTemplate for a domain event. Each notification's ID derives
+// from the event and the user, so a redelivered event is neither stored nor pushed
+// again; only the notifications stored by this call are returned.
+func (s *NotificationService) NotifyEvent(ctx context.Context, eventID uuid.UUID, userIDs []uuid.UUID, key string, params map[string]interface{}, data models.Data) ([]models.Notification, error) {
+	notifications, err := s.render(ctx, userIDs, key, params, data, func(userID uuid.UUID) uuid.UUID {
+		return uuid.NewSHA1(eventID, userID[:])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.notifications.CreateMissing(ctx, notifications)
+	if err != nil {
+		return nil, err
+	}
+
+	s.deliver(ctx, created)
+	return created, nil
+}
+
+// render builds each user's notification from template key in their preferred
+// locale, with IDs from newID
+func (s *NotificationService) render(ctx context.Context, userIDs []uuid.UUID, key string, params map[string]interface{}, data models.Data, newID func(userID uuid.UUID) uuid.UUID) ([]models.Notification, error) {
+	locales, err := s.preferences.FindLocales(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make(map[string]*Rendered, 2)
+	notifications := make([]models.Notification, len(userIDs))
+	for i, userID := range userIDs {
+		locale, ok := locales[userID]
+		if !ok {
+			locale = models.DefaultLocale
+		}
+
+		content, ok := rendered[locale]
+		if !ok {
+			content, err = s.templates.Render(ctx, key, locale, params)
+			if err != nil {
+				return nil, err
+			}
+			rendered[locale] = content
+		}
+
+		notifications[i] = models.Notification{
+			ID:              newID(userID),
+			UserID:          userID,
+			Type:            content.Type,
+			Title:           content.Title,
+			Body:            content.Body,
+			Data:            data,
+			TemplateKey:     key,
+			TemplateVersion: content.Version,
+			Locale:          content.Locale,
+		}
+	}
+
+	return notifications, nil
+}
+
+// List returns a page of the user's inbox, newest first
+func (s *NotificationService) List(ctx context.Context, userID uuid.UUID, params pagination.CursorParams) (*Page, error) {
+	notifications, err := s.notifications.List(ctx, userID, params.After, params.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.notifications.UnreadCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Notifications: notifications, UnreadCount: unread}
+	if len(notifications) > params.Limit {
+		page.Notifications = notifications[:params.Limit]
+		last := page.Notifications[params.Limit-1]
+		page.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	return page, nil
+}
+
+// UnreadCount returns how many of the user's notifications are unread
+func (s *NotificationService) UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.notifications.UnreadCount(ctx, userID)
+}
+
+// MarkRead marks one notification read and returns the new unread count
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID uuid.UUID) (int64, error) {
+	found, err := s.notifications.MarkRead(ctx, userID, notificationID, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, ErrNotificationNotFound
+	}
+
+	return s.syncUnread(ctx, userID, []uuid.UUID{notificationID})
+}
+
+// MarkAllRead marks every notification of the user read and returns the new unread count
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if _, err := s.notifications.MarkAllRead(ctx, userID, time.Now().UTC()); err != nil {
+		return 0, err
+	}
+
+	return s.syncUnread(ctx, userID, nil)
+}
+
+// syncUnread tells the user's other connected devices about read changes.
+// An empty ids list means everything was marked read.
+func (s *NotificationService) syncUnread(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	unread, err := s.notifications.UnreadCount(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.publish(ctx, userID, realtime.Event{
+		Type: EventNotificationsRead,
+		Data: map[string]interface{}{
+			"notification_ids": ids,
+			"all":              len(ids) == 0,
+			"unread_count":     unread,
+		},
+	})
+
+	return unread, nil
+}
+
+// deliver sends stored notifications to connected WebSockets and queues pushes.
+// The inbox rows are the source of truth: delivery failures are logged, not returned.
+func (s *NotificationService) deliver(ctx context.Context, notifications []models.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(notifications))
+	byUser := make(map[uuid.UUID]*models.Notification, len(notifications))
+	for i := range notifications {
+		notification := &notifications[i]
+		userIDs = append(userIDs, notification.UserID)
+		byUser[notification.UserID] = notification
+
+		unread, err := s.notifications.UnreadCount(ctx, notification.UserID)
+		if err != nil {
+			logger.Warn("Failed to count unread notifications", map[string]interface{}{
+				"error":   err.Error(),
+				"user_id": notification.UserID.String(),
+			})
+		}
+		s.publish(ctx, notification.UserID, realtime.Event{
+			Type: EventNotificationCreated,
+			Data: map[string]interface{}{
+				"notification": notification,
+				"unread_count": unread,
+			},
+		})
+	}
+
+	devices, err := s.devices.ListByUsers(ctx, userIDs)
+	if err != nil {
+		logger.Error("Failed to load push devices", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	jobs := make([]pushJob, 0, len(devices))
+	for _, device := range devices {
+		notification := byUser[device.UserID]
+		data := models.Data{"notification_id": notification.ID.String(), "type": notification.Type}
+		for key, value := range notification.Data {
+			data[key] = value
+		}
+
+		jobs = append(jobs, pushJob{
+			notificationID: notification.ID,
+			message: expo.Message{
+				To:       device.Token,
+				Title:    notification.Title,
+				Body:     notification.Body,
+				Data:     data,
+				Sound:    "default",
+				Priority: "high",
+			},
+		})
+	}
+
+	if err := s.dispatcher.enqueue(ctx, jobs); err != nil {
+		logger.Warn("Failed to queue push notifications", map[string]interface{}{
+			"error":   err.Error(),
+			"devices": len(jobs),
+		})
+	}
+}
+
+func (s *NotificationService) publish(ctx context.Context, userID uuid.UUID, event realtime.Event) {
+	if err := s.hub.Send(ctx, userID, event); err != nil {
+		logger.Warn("Failed to publish realtime notification event", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": userID.String(),
+			"type":    event.Type,
+		})
+	}
+}
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/service/notification/expo"
+	"theb-backend/internal/service/notification/models"
+	"theb-backend/internal/service/notification/repositories"
+	"theb-backend/pkg/pagination"
+
+	"github.com/google/uuid"
+)
+
+// ErrDispatcherStopped is returned when pushes are queued after shutdown began
+var ErrDispatcherStopped = errors.New("push dispatcher stopped")
+
+const (
+	// queueSize bounds pushes waiting to be batched
+	queueSize = 1000
+	// maxBackoff caps the delay between retries
+	maxBackoff = time.Minute
+	// receiptRetention is how long Expo keeps receipts; older tickets are expired
+	receiptRetention = 24 * time.Hour
+	// deliveryTimeout bounds one Expo request and the bookkeeping around it
+	deliveryTimeout = 30 * time.Second
+)
+
+// deviceStore removes device tokens Expo no longer accepts
+type deviceStore interface {
+	DeleteByTokens(ctx context.Context, tokens []string) error
+}
+
+// ticketStore records push tickets and their receipts
+type ticketStore interface {
+	CreateTickets(ctx context.Context, tickets []models.PushTicket) error
+	PendingTickets(ctx context.Context, after *pagination.Cursor, sentBefore time.Time, limit int) ([]models.PushTicket, error)
+	SaveTicket(ctx context.Context, ticket *models.PushTicket) error
+}
+
+// pushJob is one message waiting to be sent to one device
+type pushJob struct {
+	notificationID uuid.UUID
+	message        expo.Message
+}
+
+// PushDispatcher batches pushes to Expo in the background and retries transient
+// failures with exponential backoff. Receipts are polled by CheckReceipts.
+// Tokens Expo reports as DeviceNotRegistered are removed.
+type PushDispatcher struct {
+	cfg           config.ExpoPushConfig
+	client        expo.Client
+	devices       deviceStore
+	notifications ticketStore
+
+	queue    chan pushJob
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewPushDispatcher creates a push dispatcher. Call Start to begin sending.
+func NewPushDispatcher(cfg config.ExpoPushConfig, client expo.Client, devices *repositories.DeviceRepository, notifications *repositories.NotificationRepository) *PushDispatcher {
+	return newPushDispatcher(cfg, client, devices, notifications)
+}
+
+func newPushDispatcher(cfg config.ExpoPushConfig, client expo.Client, devices deviceStore, notifications ticketStore) *PushDispatcher {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Second
+	}
+
+	return &PushDispatcher{
+		cfg:           cfg,
+		client:        client,
+		devices:       devices,
+		notifications: notifications,
+		queue:         make(chan pushJob, queueSize),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start launches the sender
+func (d *PushDispatcher) Start() {
+	d.wg.Add(1)
+	go d.sendLoop()
+}
+
+// Stop flushes queued pushes and waits for the workers to exit or ctx to expire
+func (d *PushDispatcher) Stop(ctx context.Context) error {
+	d.stopOnce.Do(func() { close(d.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue queues messages for the sender, blocking while the queue is full
+func (d *PushDispatcher) enqueue(ctx context.Context, jobs []pushJob) error {
+	for _, job := range jobs {
+		select {
+		case <-d.stop:
+			return ErrDispatcherStopped
+		default:
+		}
+
+		select {
+		case d.queue <- job:
+		case <-d.stop:
+			return ErrDispatcherStopped
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// sendLoop collects jobs into batches of up to expo.MaxMessagesPerRequest and
+// flushes a batch when it is full or BatchWindow after its first job arrived
+func (d *PushDispatcher) sendLoop() {
+	defer d.wg.Done()
+
+	batch := make([]pushJob, 0, expo.MaxMessagesPerRequest)
+	var flush <-chan time.Time
+
+	send := func() {
+		if len(batch) > 0 {
+			d.deliver(batch)
+			batch = make([]pushJob, 0, expo.MaxMessagesPerRequest)
+		}
+		flush = nil
+	}
+
+	for {
+		select {
+		case job := <-d.queue:
+			batch = append(batch, job)
+			if len(batch) == expo.MaxMessagesPerRequest {
+				send()
+			} else if flush == nil {
+				flush = time.After(d.cfg.BatchWindow)
+			}
+		case <-flush:
+			send()
+		case <-d.stop:
+			for {
+				select {
+				case job := <-d.queue:
+					batch = append(batch, job)
+					if len(batch) == expo.MaxMessagesPerRequest {
+						send()
+					}
+				default:
+					send()
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends one batch, retrying transient failures and rate-limited messages
+func (d *PushDispatcher) deliver(batch []pushJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout*time.Duration(d.cfg.MaxAttempts))
+	defer cancel()
+
+	tickets := make([]models.PushTicket, 0, len(batch))
+	var unregistered []string
+
+	pending := batch
+	for attempt := 1; len(pending) > 0; attempt++ {
+		messages := make([]expo.Message, len(pending))
+		for i, job := range pending {
+			messages[i] = job.message
+		}
+
+		sendCtx, sendCancel := context.WithTimeout(ctx, deliveryTimeout)
+		results, err := d.client.Send(sendCtx, messages)
+		sendCancel()
+		if err != nil {
+			if !errors.Is(err, expo.ErrTransient) || attempt >= d.cfg.MaxAttempts {
+				logger.Error("Failed to send push notifications", map[string]interface{}{
+					"error":    err.Error(),
+					"messages": len(pending),
+					"attempts": attempt,
+				})
+				tickets = append(tickets, failedTickets(pending, attempt, "send_failed")...)
+				break
+			}
+			if !d.wait(attempt) {
+				tickets = append(tickets, failedTickets(pending, attempt, "shutdown")...)
+				break
+			}
+			continue
+		}
+
+		var retry []pushJob
+		for i, result := range results {
+			job := pending[i]
+			ticket := models.PushTicket{
+				ID:             uuid.New(),
+				NotificationID: job.notificationID,
+				Token:          job.message.To,
+				Attempts:       attempt,
+			}
+
+			switch {
+			case result.Status == expo.StatusOK:
+				ticket.TicketID = result.ID
+				ticket.Status = models.TicketPending
+			case result.Details.Error == expo.ErrorMessageRateExceeded && attempt < d.cfg.MaxAttempts:
+				retry = append(retry, job)
+				continue
+			default:
+				ticket.Status = models.TicketFailed
+				ticket.Error = errorCode(result.Details.Error)
+				if result.Details.Error == expo.ErrorDeviceNotRegistered {
+					unregistered = append(unregistered, job.message.To)
+				}
+			}
+			tickets = append(tickets, ticket)
+		}
+
+		pending = retry
+		if len(pending) > 0 && !d.wait(attempt) {
+			tickets = append(tickets, failedTickets(pending, attempt, "shutdown")...)
+			break
+		}
+	}
+
+	if err := d.notifications.CreateTickets(ctx, tickets); err != nil {
+		logger.Error("Failed to save push tickets", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	d.removeTokens(ctx, unregistered)
+}
+
+// CheckReceipts fetches receipts for tickets older than ReceiptDelay, oldest first.
+// Pages follow a (created_at, id) cursor, so tickets saved in the same instant
+// are not skipped. It runs as a scheduled job.
+func (d *PushDispatcher) CheckReceipts(ctx context.Context) error {
+	now := time.Now().UTC()
+	sentBefore := now.Add(-d.cfg.ReceiptDelay)
+	var after *pagination.Cursor
+
+	for {
+		tickets, err := d.notifications.PendingTickets(ctx, after, sentBefore, expo.MaxReceiptsPerRequest)
+		if err != nil {
+			return err
+		}
+		if len(tickets) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(tickets))
+		for i, ticket := range tickets {
+			ids[i] = ticket.TicketID
+		}
+
+		receipts, err := d.client.Receipts(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		var unregistered []string
+		for i := range tickets {
+			ticket := &tickets[i]
+			receipt, ok := receipts[ticket.TicketID]
+			switch {
+			case ok && receipt.Status == expo.StatusOK:
+				ticket.Status = models.TicketDelivered
+			case ok:
+				ticket.Status = models.TicketFailed
+				ticket.Error = errorCode(receipt.Details.Error)
+				if receipt.Details.Error == expo.ErrorDeviceNotRegistered {
+					unregistered = append(unregistered, ticket.Token)
+				}
+			case now.Sub(ticket.CreatedAt) > receiptRetention:
+				ticket.Status = models.TicketExpired
+			default:
+				// Receipt not ready yet; check again on the next run
+				continue
+			}
+
+			ticket.CheckedAt = &now
+			if err := d.notifications.SaveTicket(ctx, ticket); err != nil {
+				logger.Error("Failed to update push ticket", map[string]interface{}{
+					"error":     err.Error(),
+					"ticket_id": ticket.TicketID,
+				})
+			}
+		}
+		d.removeTokens(ctx, unregistered)
+
+		if len(tickets) < expo.MaxReceiptsPerRequest {
+			return nil
+		}
+		last := tickets[len(tickets)-1]
+		after = &pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// removeTokens deletes device tokens Expo no longer accepts
+func (d *PushDispatcher) removeTokens(ctx context.Context, tokens []string) {
+	if len(tokens) == 0 {
+		return
+	}
+
+	if err := d.devices.DeleteByTokens(ctx, tokens); err != nil {
+		logger.Error("Failed to remove unregistered push tokens", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	logger.Info("Removed unregistered push tokens", map[string]interface{}{
+		"count": len(tokens),
+	})
+}
+
+// wait sleeps for the backoff of the given attempt and reports false if shutdown began
+func (d *PushDispatcher) wait(attempt int) bool {
+	backoff := d.cfg.RetryBackoff << (attempt - 1)
+	if backoff <= 0 || backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-d.stop:
+		return false
+	}
+}
+
+func failedTickets(jobs []pushJob, attempts int, code string) []models.PushTicket {
+	tickets := make([]models.PushTicket, len(jobs))
+	for i, job := range jobs {
+		tickets[i] = models.PushTicket{
+			ID:             uuid.New(),
+			NotificationID: job.notificationID,
+			Token:          job.message.To,
+			Status:         models.TicketFailed,
+			Error:          code,
+			Attempts:       attempts,
+		}
+	}
+	return tickets
+}
+
+func errorCode(code string) string {
+	if code == "" {
+		return "unknown"
+	}
+	return code
+}
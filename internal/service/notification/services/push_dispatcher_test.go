@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/service/notification/expo"
+	"theb-backend/internal/service/notification/models"
+	"theb-backend/pkg/pagination"
+
+	"github.com/google/uuid"
+)
+
+// memoryStore keeps tickets and removed tokens in memory. Every ticket gets the
+// same creation time, as a batch saved in one insert would.
+type memoryStore struct {
+	mu        sync.Mutex
+	createdAt time.Time
+	tickets   []models.PushTicket
+	removed   []string
+}
+
+func (s *memoryStore) DeleteByTokens(ctx context.Context, tokens []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removed = append(s.removed, tokens...)
+	return nil
+}
+
+func (s *memoryStore) CreateTickets(ctx context.Context, tickets []models.PushTicket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ticket := range tickets {
+		ticket.CreatedAt = s.createdAt
+		s.tickets = append(s.tickets, ticket)
+	}
+	return nil
+}
+
+func (s *memoryStore) PendingTickets(ctx context.Context, after *pagination.Cursor, sentBefore time.Time, limit int) ([]models.PushTicket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []models.PushTicket
+	for _, ticket := range s.tickets {
+		if ticket.Status != models.TicketPending || !ticket.CreatedAt.Before(sentBefore) {
+			continue
+		}
+		if after != nil && !cursorBefore(after.CreatedAt, after.ID, ticket.CreatedAt, ticket.ID) {
+			continue
+		}
+		pending = append(pending, ticket)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return cursorBefore(pending[i].CreatedAt, pending[i].ID, pending[j].CreatedAt, pending[j].ID)
+	})
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending, nil
+}
+
+func (s *memoryStore) SaveTicket(ctx context.Context, ticket *models.PushTicket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.tickets {
+		if s.tickets[i].ID == ticket.ID {
+			s.tickets[i] = *ticket
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tickets)
+}
+
+func (s *memoryStore) statuses() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int)
+	for _, ticket := range s.tickets {
+		counts[ticket.Status]++
+	}
+	return counts
+}
+
+// cursorBefore orders tickets by (created_at, id) as Postgres compares row values
+func cursorBefore(at time.Time, id uuid.UUID, otherAt time.Time, otherID uuid.UUID) bool {
+	if !at.Equal(otherAt) {
+		return at.Before(otherAt)
+	}
+	return id.String() < otherID.String()
+}
+
+func pushToken(i int) string {
+	return fmt.Sprintf("ExponentPushToken[device-%d]", i)
+}
+
+// newTestDispatcher starts a dispatcher against a fake Expo server
+func newTestDispatcher(t *testing.T, maxAttempts int) (*PushDispatcher, *expo.FakeServer, *memoryStore) {
+	t.Helper()
+
+	fake, err := expo.NewFakeServer()
+	if err != nil {
+		t.Fatalf("NewFakeServer() error = %v", err)
+	}
+	t.Cleanup(func() { _ = fake.Close(context.Background()) })
+
+	store := &memoryStore{createdAt: time.Now().UTC().Add(-time.Minute)}
+	cfg := config.ExpoPushConfig{
+		BatchWindow:  50 * time.Millisecond,
+		MaxAttempts:  maxAttempts,
+		RetryBackoff: time.Millisecond,
+	}
+
+	return newPushDispatcher(cfg, expo.NewHTTPClient(fake.URL(), ""), store, store), fake, store
+}
+
+// push queues one message per token and waits until every message has a ticket.
+// Stopping earlier would cut retries short.
+func push(t *testing.T, d *PushDispatcher, tokens ...string) {
+	t.Helper()
+
+	jobs := make([]pushJob, len(tokens))
+	for i, token := range tokens {
+		jobs[i] = pushJob{notificationID: uuid.New(), message: expo.Message{To: token, Title: "Ride matched"}}
+	}
+
+	d.Start()
+	if err := d.enqueue(context.Background(), jobs); err != nil {
+		t.Fatalf("enqueue() error = %v", err)
+	}
+
+	store := d.notifications.(*memoryStore)
+	deadline := time.Now().Add(10 * time.Second)
+	for store.count() < len(tokens) {
+		if time.Now().After(deadline) {
+			t.Fatalf("tickets = %d after 10s, want %d", store.count(), len(tokens))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
+
+func TestPushDispatcherBatches(t *testing.T) {
+	d, fake, store := newTestDispatcher(t, 1)
+
+	tokens := make([]string, 250)
+	for i := range tokens {
+		tokens[i] = pushToken(i)
+	}
+	push(t, d, tokens...)
+
+	batches := fake.Batches()
+	want := []int{100, 100, 50}
+	if fmt.Sprint(batches) != fmt.Sprint(want) {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+	if got := store.statuses()[models.TicketPending]; got != len(tokens) {
+		t.Errorf("pending tickets = %d, want %d", got, len(tokens))
+	}
+}
+
+func TestPushDispatcherRemovesUnregisteredTokens(t *testing.T) {
+	d, fake, store := newTestDispatcher(t, 1)
+	fake.Unregister(pushToken(1))
+
+	push(t, d, pushToken(0), pushToken(1))
+
+	if len(store.removed) != 1 || store.removed[0] != pushToken(1) {
+		t.Errorf("removed tokens = %v, want [%s]", store.removed, pushToken(1))
+	}
+	statuses := store.statuses()
+	if statuses[models.TicketPending] != 1 || statuses[models.TicketFailed] != 1 {
+		t.Errorf("ticket statuses = %v, want one pending and one failed", statuses)
+	}
+}
+
+func TestPushDispatcherRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		fail        func(fake *expo.FakeServer)
+		maxAttempts int
+		status      string
+		attempts    int
+	}{
+		{name: "5xx then success", fail: func(fake *expo.FakeServer) { fake.FailNext(2) }, maxAttempts: 3, status: models.TicketPending, attempts: 3},
+		{name: "429 then success", fail: func(fake *expo.FakeServer) { fake.ThrottleNext(1) }, maxAttempts: 3, status: models.TicketPending, attempts: 2},
+		{name: "outage outlasts the attempts", fail: func(fake *expo.FakeServer) { fake.FailNext(5) }, maxAttempts: 2, status: models.TicketFailed, attempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, fake, store := newTestDispatcher(t, tt.maxAttempts)
+			tt.fail(fake)
+
+			push(t, d, pushToken(0))
+
+			if len(store.tickets) != 1 {
+				t.Fatalf("tickets = %d, want 1", len(store.tickets))
+			}
+			ticket := store.tickets[0]
+			if ticket.Status != tt.status || ticket.Attempts != tt.attempts {
+				t.Errorf("ticket = %s after %d attempts, want %s after %d", ticket.Status, ticket.Attempts, tt.status, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestPushDispatcherCheckReceipts(t *testing.T) {
+	d, fake, store := newTestDispatcher(t, 1)
+
+	// More tickets than one receipt request holds, all saved in the same instant
+	tokens := make([]string, expo.MaxReceiptsPerRequest+5)
+	for i := range tokens {
+		tokens[i] = pushToken(i)
+	}
+	push(t, d, tokens...)
+	fake.Unregister(pushToken(0))
+
+	if err := d.CheckReceipts(context.Background()); err != nil {
+		t.Fatalf("CheckReceipts() error = %v", err)
+	}
+
+	statuses := store.statuses()
+	if statuses[models.TicketDelivered] != len(tokens)-1 || statuses[models.TicketFailed] != 1 || statuses[models.TicketPending] != 0 {
+		t.Errorf("ticket statuses = %v, want %d delivered and 1 failed", statuses, len(tokens)-1)
+	}
+	if len(store.removed) != 1 || store.removed[0] != pushToken(0) {
+		t.Errorf("removed tokens = %v, want [%s]", store.removed, pushToken(0))
+	}
+}
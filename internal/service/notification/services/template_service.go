@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"theb-backend/internal/service/notification/models"
+	"theb-backend/internal/service/notification/repositories"
+	"theb-backend/internal/service/notification/templates"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTemplateNotFound is returned when no version of a template exists in any usable locale
+	ErrTemplateNotFound = errors.New("notification template not found")
+	// ErrInvalidTemplate is returned when a title or body does not parse
+	ErrInvalidTemplate = errors.New("invalid notification template")
+	// ErrUnsupportedLocale is returned for locales other than ar and en
+	ErrUnsupportedLocale = errors.New("unsupported locale")
+)
+
+// Rendered is a template rendered for one locale
+type Rendered struct {
+	Type    string
+	Title   string
+	Body    string
+	Version int
+	Locale  string
+}
+
+// TemplateService renders versioned notification templates
+type TemplateService struct {
+	repo *repositories.TemplateRepository
+}
+
+// NewTemplateService creates a new template service
+func NewTemplateService(repo *repositories.TemplateRepository) *TemplateService {
+	return &TemplateService{repo: repo}
+}
+
+// IsSupportedLocale reports whether templates can be written in locale
+func IsSupportedLocale(locale string) bool {
+	return locale == models.LocaleArabic || locale == models.LocaleEnglish
+}
+
+// Seed inserts the built-in template versions that are not in the database yet
+func (s *TemplateService) Seed(ctx context.Context) error {
+	seeds, err := templates.Defaults()
+	if err != nil {
+		return fmt.Errorf("failed to load default notification templates: %w", err)
+	}
+
+	rows := make([]models.Template, 0, len(seeds))
+	for _, seed := range seeds {
+		if _, _, err := parse(seed.Title, seed.Body); err != nil {
+			return fmt.Errorf("default template %s/%s v%d: %w", seed.Key, seed.Locale, seed.Version, err)
+		}
+		rows = append(rows, models.Template{
+			ID:      uuid.New(),
+			Key:     seed.Key,
+			Locale:  seed.Locale,
+			Version: seed.Version,
+			Type:    seed.Type,
+			Title:   seed.Title,
+			Body:    seed.Body,
+		})
+	}
+
+	return s.repo.Seed(ctx, rows)
+}
+
+// Render renders the latest version of key in locale, falling back to the
+// default locale and then English when the template is not translated
+func (s *TemplateService) Render(ctx context.Context, key, locale string, params map[string]interface{}) (*Rendered, error) {
+	for _, candidate := range []string{locale, models.DefaultLocale, models.LocaleEnglish} {
+		if candidate == "" {
+			continue
+		}
+
+		tpl, err := s.repo.Latest(ctx, key, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if tpl == nil {
+			continue
+		}
+
+		title, body, err := parse(tpl.Title, tpl.Body)
+		if err != nil {
+			return nil, fmt.Errorf("template %s/%s v%d: %w", key, candidate, tpl.Version, err)
+		}
+
+		rendered := &Rendered{Type: tpl.Type, Version: tpl.Version, Locale: candidate}
+		if rendered.Title, err = execute(title, params); err != nil {
+			return nil, fmt.Errorf("template %s/%s v%d: %w", key, candidate, tpl.Version, err)
+		}
+		if rendered.Body, err = execute(body, params); err != nil {
+			return nil, fmt.Errorf("template %s/%s v%d: %w", key, candidate, tpl.Version, err)
+		}
+
+		return rendered, nil
+	}
+
+	return nil, ErrTemplateNotFound
+}
+
+// List returns all template versions, optionally for one key
+func (s *TemplateService) List(ctx context.Context, key string) ([]models.Template, error) {
+	return s.repo.List(ctx, key)
+}
+
+// CreateVersion adds a new version of a template; it is used for all notifications sent afterwards
+func (s *TemplateService) CreateVersion(ctx context.Context, adminID uuid.UUID, key, locale, notificationType, title, body string) (*models.Template, error) {
+	if !IsSupportedLocale(locale) {
+		return nil, ErrUnsupportedLocale
+	}
+	if _, _, err := parse(title, body); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	tpl := &models.Template{
+		ID:        uuid.New(),
+		Key:       key,
+		Locale:    locale,
+		Type:      notificationType,
+		Title:     title,
+		Body:      body,
+		CreatedBy: &adminID,
+	}
+	if err := s.repo.CreateVersion(ctx, tpl); err != nil {
+		return nil, err
+	}
+
+	return tpl, nil
+}
+
+func parse(title, body string) (*template.Template, *template.Template, error) {
+	titleTpl, err := template.New("title").Option("missingkey=error").Parse(title)
+	if err != nil {
+		return nil, nil, err
+	}
+	bodyTpl, err := template.New("body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return titleTpl, bodyTpl, nil
+}
+
+func execute(tpl *template.Template, params map[string]interface{}) (string, error) {
+	var out strings.Builder
+	if err := tpl.Execute(&out, params); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
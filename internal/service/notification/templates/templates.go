@@ -0,0 +1,29 @@
+package templates
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+//go:embed defaults.json
+var defaults []byte
+
+// Seed is a template version shipped with the application
+type Seed struct {
+	Key     string `json:"key"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	Locale  string `json:"locale"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+}
+
+// Defaults returns the built-in template versions. They are inserted on startup
+// if missing; newer versions are added through the admin API without a deploy.
+func Defaults() ([]Seed, error) {
+	var seeds []Seed
+	if err := json.Unmarshal(defaults, &seeds); err != nil {
+		return nil, err
+	}
+	return seeds, nil
+}
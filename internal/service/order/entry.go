@@ -0,0 +1,79 @@
+package order
+
+import (
+	"context"
+
+	"theb-backend/internal/container"
+	"theb-backend/internal/events"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/metrics"
+	"theb-backend/internal/service/order/models"
+	"theb-backend/internal/service/order/repositories"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// statuses are reported by the rides gauge even when no ride is in them
+var statuses = []string{
+	models.StatusRequested,
+	models.StatusMatched,
+	models.StatusOnTheWay,
+	models.StatusInProgress,
+	models.StatusCompleted,
+	models.StatusCanceled,
+}
+
+// Module wires ride storage. The ride endpoints are served by the ride module.
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "order" }
+
+// Requires returns no modules; order uses only the core services
+func (Module) Requires() []string { return nil }
+
+// Migrations returns the ride tables
+func (Module) Migrations() []interface{} {
+	return []interface{}{&models.Ride{}}
+}
+
+// Register registers the ride repository in the container, exposes rides by status
+// and records dispatch time-to-match from matched rides
+func (Module) Register(ctn *container.Container) error {
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	bus, err := container.Resolve[*events.Bus](ctn)
+	if err != nil {
+		return err
+	}
+
+	repo := repositories.NewRideRepository(db)
+	container.Supply(ctn, repo)
+
+	if err := metrics.RegisterRideCounts(statuses, repo.CountByStatus); err != nil {
+		return err
+	}
+
+	// Measured from the request to when the match was recorded, not when the event
+	// arrived, so relay delays do not skew it. The event carries both times, so the
+	// handler reads nothing; a redelivery after a failed commit observes it again.
+	bus.Subscribe("order.dispatch_metrics", events.TypeRideMatched, func(ctx context.Context, tx *gorm.DB, env events.Envelope) error {
+		event, err := events.Decode[events.RideMatched](env)
+		if err != nil {
+			return err
+		}
+		metrics.DispatchTimeToMatch.Observe(env.OccurredAt.Sub(event.RequestedAt).Seconds())
+		return nil
+	})
+
+	return nil
+}
+
+// Jobs returns no jobs
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) { return nil, nil }
+
+// Routes mounts nothing
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error { return nil }
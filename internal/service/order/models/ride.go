@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ride statuses
+const (
+	StatusRequested  = "requested"
+	StatusMatched    = "matched"
+	StatusOnTheWay   = "on_the_way"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusCanceled   = "canceled"
+)
+
+// Ride is a passenger's ride request and its lifecycle. Fares are in fils.
+type Ride struct {
+	ID          uuid.UUID  `gorm:"column:ride_id;type:uuid;primaryKey" json:"ride_id"`
+	PassengerID uuid.UUID  `gorm:"type:uuid;not null;index" json:"passenger_id"`
+	CaptainID   *uuid.UUID `gorm:"type:uuid;index" json:"captain_id,omitempty"`
+	// CityID is the city serving the pickup, which prices and dispatches the ride
+	CityID       *uuid.UUID `gorm:"type:uuid;index" json:"city_id,omitempty"`
+	PickupLat    float64    `gorm:"not null" json:"pickup_lat"`
+	PickupLng    float64    `gorm:"not null" json:"pickup_lng"`
+	DropoffLat   float64    `gorm:"not null" json:"dropoff_lat"`
+	DropoffLng   float64    `gorm:"not null" json:"dropoff_lng"`
+	Status       string     `gorm:"type:varchar(16);not null;index" json:"status"`
+	FareEstimate int64      `gorm:"not null;default:0" json:"fare_estimate"`
+	FareFinal    *int64     `json:"fare_final,omitempty"`
+	// PaymentMethod is cash, wallet or card, as chosen when requesting the ride
+	PaymentMethod string     `gorm:"type:varchar(16);not null;default:'cash'" json:"payment_method"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Ride) TableName() string {
+	return "rides"
+}
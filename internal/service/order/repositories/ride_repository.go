@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"theb-backend/internal/service/order/models"
+	"theb-backend/pkg/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RideRepository provides data access for rides
+type RideRepository struct {
+	db *gorm.DB
+}
+
+// NewRideRepository creates a new ride repository
+func NewRideRepository(db *gorm.DB) *RideRepository {
+	return &RideRepository{db: db}
+}
+
+// Transaction runs fn in a database transaction
+func (r *RideRepository) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
+}
+
+// Create inserts a ride
+func (r *RideRepository) Create(tx *gorm.DB, ride *models.Ride) error {
+	return tx.Create(ride).Error
+}
+
+// Save updates a ride
+func (r *RideRepository) Save(tx *gorm.DB, ride *models.Ride) error {
+	return tx.Save(ride).Error
+}
+
+// Lock loads a ride by ID with a row lock held until tx ends, or nil if it does not exist
+func (r *RideRepository) Lock(tx *gorm.DB, id uuid.UUID) (*models.Ride, error) {
+	var ride models.Ride
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("ride_id = ?", id).First(&ride).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ride, nil
+}
+
+// History returns the rides a passenger took or a captain drove, newest first,
+// starting after the cursor when one is given. captainID is nil for users who
+// are not captains.
+func (r *RideRepository) History(ctx context.Context, passengerID uuid.UUID, captainID *uuid.UUID, after *pagination.Cursor, limit int) ([]models.Ride, error) {
+	query := r.db.WithContext(ctx)
+	if captainID != nil {
+		query = query.Where("(passenger_id = ? OR captain_id = ?)", passengerID, *captainID)
+	} else {
+		query = query.Where("passenger_id = ?", passengerID)
+	}
+	if after != nil {
+		query = query.Where("(created_at, ride_id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var rides []models.Ride
+	err := query.Order("created_at DESC, ride_id DESC").Limit(limit).Find(&rides).Error
+	return rides, err
+}
+
+// FindByID returns a ride by ID, or nil if it does not exist
+func (r *RideRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Ride, error) {
+	var ride models.Ride
+	err := r.db.WithContext(ctx).Where("ride_id = ?", id).First(&ride).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ride, nil
+}
+
+// HasPassenger reports whether the user has requested any ride
+func (r *RideRepository) HasPassenger(ctx context.Context, passengerID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Ride{}).
+		Where("passenger_id = ?", passengerID).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// OpenRequests returns rides still waiting for a captain that were requested at or after since
+func (r *RideRepository) OpenRequests(ctx context.Context, since time.Time) ([]models.Ride, error) {
+	var rides []models.Ride
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND created_at >= ?", models.StatusRequested, since).
+		Find(&rides).Error
+	return rides, err
+}
+
+// BusyCaptainIDs returns the captains currently assigned to a ride
+func (r *RideRepository) BusyCaptainIDs(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Model(&models.Ride{}).
+		Where("captain_id IS NOT NULL AND status IN ?", []string{models.StatusMatched, models.StatusOnTheWay, models.StatusInProgress}).
+		Distinct().
+		Pluck("captain_id", &ids).Error
+	return ids, err
+}
+
+// CountByStatus returns the number of rides in each status
+func (r *RideRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&models.Ride{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
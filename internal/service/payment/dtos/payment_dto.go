@@ -0,0 +1,28 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentResponse is a ride's card payment. Amounts are in fils.
+type PaymentResponse struct {
+	ID               uuid.UUID `json:"payment_id"`
+	RideID           uuid.UUID `json:"ride_id"`
+	Method           string    `json:"method"`
+	Status           string    `json:"status"`
+	Amount           int64     `json:"amount"`
+	AuthorizedAmount int64     `json:"authorized_amount"`
+	RefundedAmount   int64     `json:"refunded_amount"`
+	FailureReason    string    `json:"failure_reason,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+} // @name PaymentResponse
+
+// RefundRequest refunds a disputed card ride to the passenger's card. Amount is in fils.
+type RefundRequest struct {
+	RideID string `json:"ride_id" binding:"required,uuid"`
+	Amount int64  `json:"amount" binding:"required,gt=0"`
+	Reason string `json:"reason" binding:"required,max=255"`
+} // @name PaymentRefundRequest
@@ -0,0 +1,157 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/events"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	ledger "theb-backend/internal/service/ledger/services"
+	"theb-backend/internal/service/payment/gateway"
+	"theb-backend/internal/service/payment/handlers"
+	"theb-backend/internal/service/payment/models"
+	"theb-backend/internal/service/payment/repositories"
+	"theb-backend/internal/service/payment/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Module wires card payments through the configured gateway
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "payment" }
+
+// Requires returns the modules whose services payment resolves
+func (Module) Requires() []string { return []string{"ledger"} }
+
+// Migrations returns the payment tables
+func (Module) Migrations() []interface{} {
+	return []interface{}{&models.Payment{}, &models.WebhookEvent{}}
+}
+
+// Register registers the payment gateway, repository, service and handler in the container
+// and schedules the capture of card payments of completed rides
+func (Module) Register(ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	outbox, err := container.Resolve[*events.Outbox](ctn)
+	if err != nil {
+		return err
+	}
+	bus, err := container.Resolve[*events.Bus](ctn)
+	if err != nil {
+		return err
+	}
+	ledgerService, err := container.Resolve[*ledger.LedgerService](ctn)
+	if err != nil {
+		return err
+	}
+
+	gw, err := newGateway(cfg.Payments)
+	if err != nil {
+		return err
+	}
+
+	repo := repositories.NewPaymentRepository(db)
+	service := services.NewCardPaymentService(gw, repo, ledgerService, outbox, cfg.Payments.AuthorizationBuffer)
+
+	container.Supply(ctn, gw)
+	container.Supply(ctn, repo)
+	container.Supply(ctn, service)
+	container.Supply(ctn, handlers.NewPaymentHandler(service, gw))
+
+	// Only the capture is scheduled in the event's transaction, so it commits once
+	// with the processed event; CapturePending calls the gateway outside it
+	events.On(bus, "payment.capture", func(ctx context.Context, tx *gorm.DB, event events.RideCompleted) error {
+		if event.PaymentMethod != models.MethodCard {
+			return nil
+		}
+
+		err := service.ScheduleCapture(tx, event.RideID, event.CaptainID, event.CaptainUserID, event.Fare)
+		switch {
+		case errors.Is(err, services.ErrPaymentNotFound), errors.Is(err, services.ErrInvalidPaymentState):
+			// Nothing is left to capture, e.g. the card was declined or the hold
+			// voided, and retrying cannot change that
+			logger.FromContext(ctx).Warn("Skipping card capture of completed ride", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return nil
+		case errors.Is(err, services.ErrNotAuthorized), errors.Is(err, services.ErrCaptureExceedsAuthorization), errors.Is(err, services.ErrInvalidAmount):
+			// Retrying cannot fix these either, but the passenger still owes the fare
+			logger.FromContext(ctx).Error("Card capture needs manual review", map[string]interface{}{
+				"error":   err.Error(),
+				"ride_id": event.RideID.String(),
+			})
+			return service.FlagCapture(tx, event.RideID, err)
+		}
+		return err
+	})
+
+	return nil
+}
+
+// Jobs returns the capture job, which charges the card of completed rides
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) {
+	service, err := container.Resolve[*services.CardPaymentService](ctn)
+	if err != nil {
+		return nil, err
+	}
+
+	return []jobs.CronJob{{
+		Name:     "payments_capture",
+		Schedule: "@every 30s",
+		Run:      service.CapturePending,
+	}}, nil
+}
+
+// Routes mounts the payment and card refund admin endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	handler, err := container.Resolve[*handlers.PaymentHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+
+	payments := v1.Group("/payments")
+	{
+		payments.POST("/webhooks/:gateway", handler.Webhook)
+		payments.GET("/:id", auth, handler.GetPayment)
+
+		// Approves any challenge without authentication, so never outside development
+		if handler.MockEnabled() && cfg.App.Env != "production" {
+			payments.POST("/mock/3ds/:reference", handler.CompleteMock3DS)
+		}
+	}
+
+	v1.POST("/admin/payments/refunds", auth, middleware.RequireRole(middleware.RoleAdmin), handler.Refund)
+
+	return nil
+}
+
+// newGateway builds the configured payment gateway
+func newGateway(cfg config.PaymentsConfig) (gateway.PaymentGateway, error) {
+	switch cfg.Gateway {
+	case "", "mock":
+		return gateway.NewMockGateway(cfg.WebhookSecret, cfg.Mock.WebhookURL, cfg.Mock.WebhookDelay), nil
+	default:
+		return nil, fmt.Errorf("unsupported payment gateway: %s", cfg.Gateway)
+	}
+}
@@ -0,0 +1,77 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+)
+
+// Authorization statuses reported by a gateway
+const (
+	StatusAuthorized     = "authorized"
+	StatusRequiresAction = "requires_action"
+	StatusPending        = "pending"
+	StatusDeclined       = "declined"
+	StatusCaptured       = "captured"
+	StatusVoided         = "voided"
+	StatusRefunded       = "refunded"
+)
+
+// Webhook event types
+const (
+	EventAuthorizationSucceeded = "authorization.succeeded"
+	EventAuthorizationFailed    = "authorization.failed"
+	EventCaptureSucceeded       = "capture.succeeded"
+	EventRefundSucceeded        = "refund.succeeded"
+)
+
+var (
+	// ErrInvalidSignature is returned when a webhook signature does not match
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrUnknownPayment is returned when the gateway has no record of a payment reference
+	ErrUnknownPayment = errors.New("unknown payment reference")
+	// ErrInvalidState is returned when an operation is not allowed in the payment's current state
+	ErrInvalidState = errors.New("operation not allowed in current payment state")
+)
+
+// AuthorizeRequest reserves funds on a card. Amount is in fils.
+type AuthorizeRequest struct {
+	IdempotencyKey string
+	CardToken      string
+	Amount         int64
+	Currency       string
+	Description    string
+}
+
+// Result is the gateway's answer to an operation
+type Result struct {
+	Reference     string
+	Status        string
+	Amount        int64
+	DeclineReason string
+	// RedirectURL is set when Status is StatusRequiresAction (3-D Secure)
+	RedirectURL string
+}
+
+// WebhookEvent is an asynchronous notification from the gateway
+type WebhookEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Reference string `json:"reference"`
+	Amount    int64  `json:"amount"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// PaymentGateway is implemented by card payment processors
+type PaymentGateway interface {
+	// Name identifies the gateway in webhook URLs and stored payments
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, reference string, amount int64) (*Result, error)
+	Void(ctx context.Context, reference string) (*Result, error)
+	// Refund returns part of a captured amount; repeating idempotencyKey returns the first refund
+	Refund(ctx context.Context, reference string, amount int64, idempotencyKey string) (*Result, error)
+	// SignatureHeader is the HTTP header carrying the webhook signature
+	SignatureHeader() string
+	// VerifyWebhook checks the signature and parses the event payload
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
@@ -0,0 +1,280 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"theb-backend/internal/logger"
+	"theb-backend/internal/tracing"
+
+	"github.com/google/uuid"
+)
+
+// Card tokens understood by the mock gateway. Any other token is approved.
+const (
+	MockTokenSuccess  = "tok_success"
+	MockTokenDecline  = "tok_decline"
+	MockToken3DS      = "tok_3ds"
+	MockTokenDelayed  = "tok_delayed"
+	MockSignatureName = "X-Mock-Signature"
+)
+
+type mockPayment struct {
+	status     string
+	authorized int64
+	captured   int64
+	refunded   int64
+}
+
+// MockGateway simulates a card processor for local development. It keeps
+// payments in memory and delivers signed webhooks to WebhookURL after WebhookDelay.
+type MockGateway struct {
+	secret       []byte
+	webhookURL   string
+	webhookDelay time.Duration
+	client       *http.Client
+
+	mu          sync.Mutex
+	payments    map[string]*mockPayment
+	idempotency map[string]string
+	refunds     map[string]*Result
+}
+
+// NewMockGateway creates a mock gateway that signs webhooks with secret
+func NewMockGateway(secret, webhookURL string, webhookDelay time.Duration) *MockGateway {
+	return &MockGateway{
+		secret:       []byte(secret),
+		webhookURL:   webhookURL,
+		webhookDelay: webhookDelay,
+		client:       &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)},
+		payments:     make(map[string]*mockPayment),
+		idempotency:  make(map[string]string),
+		refunds:      make(map[string]*Result),
+	}
+}
+
+// Name identifies the gateway
+func (g *MockGateway) Name() string {
+	return "mock"
+}
+
+// SignatureHeader is the header carrying the webhook signature
+func (g *MockGateway) SignatureHeader() string {
+	return MockSignatureName
+}
+
+// Authorize simulates an authorization whose outcome depends on the card token
+func (g *MockGateway) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if reference, ok := g.idempotency[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return g.resultLocked(reference), nil
+	}
+
+	reference := "mock_" + uuid.NewString()
+	payment := &mockPayment{authorized: req.Amount}
+	g.payments[reference] = payment
+	if req.IdempotencyKey != "" {
+		g.idempotency[req.IdempotencyKey] = reference
+	}
+
+	result := &Result{Reference: reference, Amount: req.Amount}
+	switch req.CardToken {
+	case MockTokenDecline:
+		payment.status = StatusDeclined
+		result.DeclineReason = "card_declined"
+	case MockToken3DS:
+		payment.status = StatusRequiresAction
+		result.RedirectURL = "/api/v1/payments/mock/3ds/" + reference
+	case MockTokenDelayed:
+		payment.status = StatusPending
+		g.sendWebhook(WebhookEvent{Type: EventAuthorizationSucceeded, Reference: reference, Amount: req.Amount})
+	default:
+		payment.status = StatusAuthorized
+	}
+	result.Status = payment.status
+
+	return result, nil
+}
+
+// Complete3DS finishes a pending 3-D Secure challenge and delivers the outcome as a webhook
+func (g *MockGateway) Complete3DS(reference string, approve bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[reference]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if payment.status != StatusRequiresAction {
+		return ErrInvalidState
+	}
+
+	event := WebhookEvent{Reference: reference, Amount: payment.authorized}
+	if approve {
+		payment.status = StatusPending
+		event.Type = EventAuthorizationSucceeded
+	} else {
+		payment.status = StatusDeclined
+		event.Type = EventAuthorizationFailed
+		event.Reason = "3ds_failed"
+	}
+	g.sendWebhook(event)
+
+	return nil
+}
+
+// Capture settles up to the authorized amount
+func (g *MockGateway) Capture(ctx context.Context, reference string, amount int64) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[reference]
+	if !ok {
+		return nil, ErrUnknownPayment
+	}
+	if payment.status == StatusCaptured && payment.captured == amount {
+		return g.resultLocked(reference), nil
+	}
+	if payment.status != StatusAuthorized || amount > payment.authorized {
+		return nil, ErrInvalidState
+	}
+
+	payment.status = StatusCaptured
+	payment.captured = amount
+	g.sendWebhook(WebhookEvent{Type: EventCaptureSucceeded, Reference: reference, Amount: amount})
+
+	return g.resultLocked(reference), nil
+}
+
+// Void releases an uncaptured authorization
+func (g *MockGateway) Void(ctx context.Context, reference string) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[reference]
+	if !ok {
+		return nil, ErrUnknownPayment
+	}
+	switch payment.status {
+	case StatusVoided:
+	case StatusAuthorized, StatusPending, StatusRequiresAction:
+		payment.status = StatusVoided
+	default:
+		return nil, ErrInvalidState
+	}
+
+	return g.resultLocked(reference), nil
+}
+
+// Refund returns part or all of a captured amount
+func (g *MockGateway) Refund(ctx context.Context, reference string, amount int64, idempotencyKey string) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if result, ok := g.refunds[idempotencyKey]; ok && idempotencyKey != "" {
+		return result, nil
+	}
+
+	payment, ok := g.payments[reference]
+	if !ok {
+		return nil, ErrUnknownPayment
+	}
+	if (payment.status != StatusCaptured && payment.status != StatusRefunded) || amount > payment.captured-payment.refunded {
+		return nil, ErrInvalidState
+	}
+
+	payment.refunded += amount
+	if payment.refunded == payment.captured {
+		payment.status = StatusRefunded
+	}
+	g.sendWebhook(WebhookEvent{Type: EventRefundSucceeded, Reference: reference, Amount: amount})
+
+	result := &Result{Reference: reference, Status: StatusRefunded, Amount: amount}
+	if idempotencyKey != "" {
+		g.refunds[idempotencyKey] = result
+	}
+
+	return result, nil
+}
+
+// VerifyWebhook checks the HMAC-SHA256 signature and parses the event
+func (g *MockGateway) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	expected := g.sign(payload)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	return &event, nil
+}
+
+func (g *MockGateway) resultLocked(reference string) *Result {
+	payment := g.payments[reference]
+	amount := payment.authorized
+	if payment.captured > 0 {
+		amount = payment.captured
+	}
+	return &Result{Reference: reference, Status: payment.status, Amount: amount}
+}
+
+func (g *MockGateway) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendWebhook delivers an event asynchronously after the configured delay.
+// Authorizations that arrive by webhook take effect just before it is posted,
+// so the payment stays pending until then, as with a real gateway.
+func (g *MockGateway) sendWebhook(event WebhookEvent) {
+	event.ID = "evt_" + uuid.NewString()
+
+	go func() {
+		time.Sleep(g.webhookDelay)
+
+		if event.Type == EventAuthorizationSucceeded {
+			g.mu.Lock()
+			if payment := g.payments[event.Reference]; payment.status == StatusPending {
+				payment.status = StatusAuthorized
+			}
+			g.mu.Unlock()
+		}
+
+		if g.webhookURL == "" {
+			return
+		}
+
+		payload, _ := json.Marshal(event)
+		req, err := http.NewRequest(http.MethodPost, g.webhookURL, bytes.NewReader(payload))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(MockSignatureName, g.sign(payload))
+
+		resp, err := g.client.Do(req)
+		if err != nil {
+			logger.Warn("Mock gateway webhook delivery failed", map[string]interface{}{
+				"event_id": event.ID,
+				"error":    err.Error(),
+			})
+			return
+		}
+		resp.Body.Close()
+	}()
+}
@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMockAuthorize(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		status string
+	}{
+		{name: "success authorizes at once", token: MockTokenSuccess, status: StatusAuthorized},
+		{name: "decline", token: MockTokenDecline, status: StatusDeclined},
+		{name: "3ds needs the challenge", token: MockToken3DS, status: StatusRequiresAction},
+		{name: "delayed stays pending until its webhook", token: MockTokenDelayed, status: StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewMockGateway("secret", "", time.Hour)
+
+			result, err := g.Authorize(context.Background(), AuthorizeRequest{CardToken: tt.token, Amount: 1500})
+			if err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+			if result.Status != tt.status {
+				t.Errorf("Authorize() status = %s, want %s", result.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestMockAuthorizeDelayed(t *testing.T) {
+	g := NewMockGateway("secret", "", 20*time.Millisecond)
+	ctx := context.Background()
+
+	result, err := g.Authorize(ctx, AuthorizeRequest{IdempotencyKey: "ride-1", CardToken: MockTokenDelayed, Amount: 1500})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if result.Status != StatusPending {
+		t.Fatalf("Authorize() status = %s, want %s", result.Status, StatusPending)
+	}
+	if _, err := g.Capture(ctx, result.Reference, 1500); err == nil {
+		t.Fatal("Capture() before the webhook succeeded, want an error")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		again, err := g.Authorize(ctx, AuthorizeRequest{IdempotencyKey: "ride-1", CardToken: MockTokenDelayed, Amount: 1500})
+		if err != nil {
+			t.Fatalf("Authorize() retry error = %v", err)
+		}
+		if again.Reference != result.Reference {
+			t.Fatalf("Authorize() retry reference = %s, want %s", again.Reference, result.Reference)
+		}
+		if again.Status == StatusAuthorized {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("payment still %s after the webhook delay", again.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := g.Capture(ctx, result.Reference, 1500); err != nil {
+		t.Errorf("Capture() after the webhook error = %v", err)
+	}
+}
+
+func TestMockRefundIdempotency(t *testing.T) {
+	g := NewMockGateway("secret", "", time.Hour)
+	ctx := context.Background()
+
+	result, err := g.Authorize(ctx, AuthorizeRequest{CardToken: MockTokenSuccess, Amount: 1500})
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if _, err := g.Capture(ctx, result.Reference, 1000); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := g.Refund(ctx, result.Reference, 600, "ride_refund:1"); err != nil {
+			t.Fatalf("Refund() attempt %d error = %v", i+1, err)
+		}
+	}
+
+	// A retried key refunds once, so 400 of the 1000 captured is still refundable
+	if _, err := g.Refund(ctx, result.Reference, 400, "ride_refund:2"); err != nil {
+		t.Errorf("Refund() of the remainder error = %v", err)
+	}
+	if _, err := g.Refund(ctx, result.Reference, 1, "ride_refund:3"); err == nil {
+		t.Error("Refund() beyond the captured amount succeeded, want an error")
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/payment/dtos"
+	"theb-backend/internal/service/payment/gateway"
+	"theb-backend/internal/service/payment/models"
+	"theb-backend/internal/service/payment/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxWebhookBody limits webhook payloads read into memory
+const maxWebhookBody = 64 << 10
+
+// PaymentHandler serves payment status and gateway webhook endpoints
+type PaymentHandler struct {
+	service *services.CardPaymentService
+	gateway gateway.PaymentGateway
+	mock    *gateway.MockGateway
+}
+
+// NewPaymentHandler creates a new payment handler. mock is nil unless the mock gateway is in use.
+func NewPaymentHandler(service *services.CardPaymentService, gw gateway.PaymentGateway) *PaymentHandler {
+	mock, _ := gw.(*gateway.MockGateway)
+	return &PaymentHandler{
+		service: service,
+		gateway: gw,
+		mock:    mock,
+	}
+}
+
+// GetPayment returns a payment belonging to the authenticated passenger
+// @Summary Get payment status
+// @ID payment-get
+// @Tags Payment
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} dtos.PaymentResponse
+// @Router /api/v1/payments/{id} [get]
+func (h *PaymentHandler) GetPayment(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return
+	}
+
+	payment, err := h.service.Get(c.Request.Context(), id)
+	if errors.Is(err, services.ErrPaymentNotFound) || (err == nil && payment.PassengerID != userID) {
+		apierror.Abort(c, apierror.New(apierror.CodePaymentNotFound))
+		return
+	}
+	if err != nil {
+		h.internalError(c, "Failed to load payment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toResponse(payment))
+}
+
+// Refund returns part or all of a captured card ride to the passenger's card
+// @Summary Refund a card ride (admin)
+// @ID payment-admin-refund
+// @Tags Payment
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.RefundRequest true "Ride, amount in fils and reason"
+// @Success 200 {object} dtos.PaymentResponse
+// @Router /api/v1/admin/payments/refunds [post]
+func (h *PaymentHandler) Refund(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	payment, err := h.service.RefundRide(c.Request.Context(), adminID, uuid.MustParse(req.RideID), req.Amount, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPaymentNotFound):
+			apierror.Abort(c, apierror.Wrap(apierror.CodePaymentNotFound, err))
+		case errors.Is(err, services.ErrRefundExceedsPayment):
+			apierror.Abort(c, apierror.Wrap(apierror.CodePaymentRefundExceeds, err))
+		case errors.Is(err, services.ErrInvalidPaymentState), errors.Is(err, gateway.ErrInvalidState):
+			apierror.Abort(c, apierror.Wrap(apierror.CodePaymentInvalidState, err))
+		default:
+			h.internalError(c, "Failed to refund card payment", err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toResponse(payment))
+}
+
+// Webhook receives asynchronous events from the payment gateway
+// @Summary Payment gateway webhook
+// @ID payment-webhook
+// @Tags Payment
+// @Accept json
+// @Param gateway path string true "Gateway name"
+// @Success 204
+// @Router /api/v1/payments/webhooks/{gateway} [post]
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	if c.Param("gateway") != h.gateway.Name() {
+		apierror.Abort(c, apierror.New(apierror.CodePaymentGatewayUnknown))
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		apierror.Abort(c, apierror.Wrap(apierror.CodeBadRequest, err))
+		return
+	}
+
+	signature := c.GetHeader(h.gateway.SignatureHeader())
+	if err := h.service.HandleWebhook(c.Request.Context(), payload, signature); err != nil {
+		switch {
+		case errors.Is(err, gateway.ErrInvalidSignature):
+			apierror.Abort(c, apierror.Wrap(apierror.CodePaymentInvalidSignature, err))
+		case errors.Is(err, services.ErrPaymentNotFound):
+			// Not acknowledged so the gateway retries once the payment is stored
+			apierror.Abort(c, apierror.Wrap(apierror.CodePaymentNotFound, err))
+		default:
+			h.internalError(c, "Failed to process payment webhook", err)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CompleteMock3DS finishes a simulated 3-D Secure challenge. Only mounted with the mock gateway.
+// @Summary Complete mock 3-D Secure challenge
+// @ID payment-mock-3ds
+// @Tags Payment
+// @Param reference path string true "Gateway reference"
+// @Param approve query bool false "Approve the challenge" default(true)
+// @Success 204
+// @Router /api/v1/payments/mock/3ds/{reference} [post]
+func (h *PaymentHandler) CompleteMock3DS(c *gin.Context) {
+	if h.mock == nil {
+		apierror.Abort(c, apierror.New(apierror.CodePaymentMockDisabled))
+		return
+	}
+
+	approve := c.DefaultQuery("approve", "true") == "true"
+	if err := h.mock.Complete3DS(c.Param("reference"), approve); err != nil {
+		switch {
+		case errors.Is(err, gateway.ErrUnknownPayment):
+			apierror.Abort(c, apierror.Wrap(apierror.CodePaymentNotFound, err))
+		default:
+			apierror.Abort(c, apierror.Wrap(apierror.CodePaymentInvalidState, err))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MockEnabled reports whether the mock gateway endpoints should be mounted
+func (h *PaymentHandler) MockEnabled() bool {
+	return h.mock != nil
+}
+
+func (h *PaymentHandler) internalError(c *gin.Context, message string, err error) {
+	logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+		"error": err.Error(),
+	})
+	apierror.Abort(c, apierror.New(apierror.CodeInternal))
+}
+
+func toResponse(payment *models.Payment) dtos.PaymentResponse {
+	return dtos.PaymentResponse{
+		ID:               payment.ID,
+		RideID:           payment.RideID,
+		Method:           payment.Method,
+		Status:           payment.Status,
+		Amount:           payment.Amount,
+		AuthorizedAmount: payment.AuthorizedAmount,
+		RefundedAmount:   payment.RefundedAmount,
+		FailureReason:    payment.FailureReason,
+		CreatedAt:        payment.CreatedAt,
+		UpdatedAt:        payment.UpdatedAt,
+	}
+}
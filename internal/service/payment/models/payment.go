@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payment methods
+const (
+	MethodCash   = "cash"
+	MethodWallet = "wallet"
+	MethodCard   = "card"
+)
+
+// Payment statuses
+const (
+	StatusPending        = "pending"
+	StatusRequiresAction = "requires_action"
+	StatusAuthorized     = "authorized"
+	StatusCapturePending = "capture_pending"
+	StatusCaptureFailed  = "capture_failed"
+	StatusPaid           = "paid"
+	StatusFailed         = "failed"
+	StatusVoided         = "voided"
+	StatusRefunded       = "refunded"
+)
+
+// Payment is a card payment for a ride. Amounts are in fils.
+type Payment struct {
+	ID               uuid.UUID  `gorm:"column:payment_id;type:uuid;primaryKey" json:"payment_id"`
+	RideID           uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"ride_id"`
+	PassengerID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"passenger_id"`
+	CaptainID        *uuid.UUID `gorm:"type:uuid;index" json:"captain_id,omitempty"`
+	CaptainUserID    *uuid.UUID `gorm:"type:uuid" json:"-"`
+	Amount           int64      `gorm:"not null;default:0" json:"amount"`
+	AuthorizedAmount int64      `gorm:"not null;default:0" json:"authorized_amount"`
+	CaptureAmount    int64      `gorm:"not null;default:0" json:"-"`
+	RefundedAmount   int64      `gorm:"not null;default:0" json:"refunded_amount"`
+	Method           string     `gorm:"type:varchar(16);not null" json:"method"`
+	Status           string     `gorm:"type:varchar(16);not null;index" json:"status"`
+	Gateway          string     `gorm:"type:varchar(32);not null" json:"gateway"`
+	GatewayRef       string     `gorm:"type:varchar(128);uniqueIndex" json:"-"`
+	FailureReason    string     `gorm:"type:varchar(255)" json:"failure_reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Payment) TableName() string {
+	return "payments"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEvent records a processed gateway webhook so redeliveries are ignored
+type WebhookEvent struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Gateway     string    `gorm:"type:varchar(32);not null;uniqueIndex:idx_payment_webhook_events_gateway_event"`
+	EventID     string    `gorm:"type:varchar(128);not null;uniqueIndex:idx_payment_webhook_events_gateway_event"`
+	Type        string    `gorm:"type:varchar(64);not null"`
+	Reference   string    `gorm:"type:varchar(128);index"`
+	ProcessedAt time.Time `gorm:"not null"`
+}
+
+// TableName overrides the default table name
+func (WebhookEvent) TableName() string {
+	return "payment_webhook_events"
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"theb-backend/internal/service/payment/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentRepository provides data access for payments and webhook events
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepository creates a new payment repository
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// Transaction runs fn inside a database transaction
+func (r *PaymentRepository) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
+}
+
+// Create inserts a new payment
+func (r *PaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	return r.db.WithContext(ctx).Create(payment).Error
+}
+
+// Save persists changes to a payment, inside tx when given
+func (r *PaymentRepository) Save(tx *gorm.DB, payment *models.Payment) error {
+	return tx.Save(payment).Error
+}
+
+// FindByID returns a payment by ID, or nil if it does not exist
+func (r *PaymentRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
+	return r.first(r.db.WithContext(ctx).Where("payment_id = ?", id))
+}
+
+// LockByRideID loads a ride's payment with a row lock held until tx ends, or nil if none exists
+func (r *PaymentRepository) LockByRideID(tx *gorm.DB, rideID uuid.UUID) (*models.Payment, error) {
+	return r.first(tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("ride_id = ?", rideID))
+}
+
+// LockByGatewayRef loads a payment by gateway reference with a row lock held until tx ends
+func (r *PaymentRepository) LockByGatewayRef(tx *gorm.DB, gateway, reference string) (*models.Payment, error) {
+	return r.first(tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("gateway = ? AND gateway_ref = ?", gateway, reference))
+}
+
+// FindByRideID returns a ride's payment, or nil if none exists
+func (r *PaymentRepository) FindByRideID(ctx context.Context, rideID uuid.UUID) (*models.Payment, error) {
+	return r.first(r.db.WithContext(ctx).Where("ride_id = ?", rideID))
+}
+
+// FindByStatus returns up to limit payments in a status, least recently updated first
+func (r *PaymentRepository) FindByStatus(ctx context.Context, status string, limit int) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&payments).Error
+	return payments, err
+}
+
+// RecordWebhookEvent stores an event inside tx and reports whether it was new
+func (r *PaymentRepository) RecordWebhookEvent(tx *gorm.DB, event *models.WebhookEvent) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PaymentRepository) first(query *gorm.DB) (*models.Payment, error) {
+	var payment models.Payment
+	err := query.First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
@@ -0,0 +1,497 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"theb-backend/internal/events"
+	"theb-backend/internal/logger"
+	ledgermodels "theb-backend/internal/service/ledger/models"
+	ledger "theb-backend/internal/service/ledger/services"
+	"theb-backend/internal/service/payment/gateway"
+	"theb-backend/internal/service/payment/models"
+	"theb-backend/internal/service/payment/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidAmount is returned for zero or negative amounts
+	ErrInvalidAmount = errors.New("amount must be positive")
+	// ErrPaymentNotFound is returned when a ride or reference has no card payment
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrCardDeclined is returned when the gateway declines an authorization
+	ErrCardDeclined = errors.New("card was declined")
+	// ErrNotAuthorized is returned when capturing a payment that is not authorized yet
+	ErrNotAuthorized = errors.New("payment is not authorized")
+	// ErrCaptureExceedsAuthorization is returned when the final fare is above the authorized amount
+	ErrCaptureExceedsAuthorization = errors.New("final fare exceeds authorized amount")
+	// ErrInvalidPaymentState is returned when an operation is not allowed in the payment's state
+	ErrInvalidPaymentState = errors.New("operation not allowed in current payment state")
+	// ErrRefundExceedsPayment is returned when a refund is larger than the captured amount
+	ErrRefundExceedsPayment = errors.New("refund exceeds captured amount")
+)
+
+// captureBatch bounds the scheduled captures charged per CapturePending run
+const captureBatch = 100
+
+// AuthorizeRideInput is the data needed to hold funds when a ride is requested
+type AuthorizeRideInput struct {
+	RideID       uuid.UUID
+	PassengerID  uuid.UUID
+	CardToken    string
+	FareEstimate int64
+}
+
+// CardPaymentService authorizes card payments at ride request and captures them at completion
+type CardPaymentService struct {
+	gateway gateway.PaymentGateway
+	repo    *repositories.PaymentRepository
+	ledger  *ledger.LedgerService
+	outbox  *events.Outbox
+	buffer  float64
+}
+
+// NewCardPaymentService creates a new card payment service. buffer is the fraction added
+// to the fare estimate when authorizing so small fare increases can still be captured.
+func NewCardPaymentService(gw gateway.PaymentGateway, repo *repositories.PaymentRepository, ledgerService *ledger.LedgerService, outbox *events.Outbox, buffer float64) *CardPaymentService {
+	return &CardPaymentService{
+		gateway: gw,
+		repo:    repo,
+		ledger:  ledgerService,
+		outbox:  outbox,
+		buffer:  buffer,
+	}
+}
+
+// Get returns a payment by ID
+func (s *CardPaymentService) Get(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
+	payment, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, ErrPaymentNotFound
+	}
+
+	return payment, nil
+}
+
+// AuthorizeRide holds the estimated fare plus buffer on the passenger's card.
+// Repeating the call for the same ride returns the existing payment.
+// A declined card returns the failed payment together with ErrCardDeclined.
+func (s *CardPaymentService) AuthorizeRide(ctx context.Context, input AuthorizeRideInput) (*models.Payment, error) {
+	if input.FareEstimate <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	existing, err := s.repo.FindByRideID(ctx, input.RideID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	amount := int64(math.Ceil(float64(input.FareEstimate) * (1 + s.buffer)))
+	result, err := s.gateway.Authorize(ctx, gateway.AuthorizeRequest{
+		IdempotencyKey: "ride_auth:" + input.RideID.String(),
+		CardToken:      input.CardToken,
+		Amount:         amount,
+		Currency:       "JOD",
+		Description:    "THEB ride " + input.RideID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &models.Payment{
+		ID:               uuid.New(),
+		RideID:           input.RideID,
+		PassengerID:      input.PassengerID,
+		AuthorizedAmount: result.Amount,
+		Method:           models.MethodCard,
+		Status:           statusFromGateway(result.Status),
+		Gateway:          s.gateway.Name(),
+		GatewayRef:       result.Reference,
+		FailureReason:    result.DeclineReason,
+	}
+	if err := s.repo.Create(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	if payment.Status == models.StatusFailed {
+		return payment, ErrCardDeclined
+	}
+
+	return payment, nil
+}
+
+// ScheduleCapture records a completed ride's final fare and captain on its payment
+// in tx, so they commit with the caller's own changes; the gateway is charged later
+// by CapturePending, outside any transaction. Scheduling a ride again for the same
+// fare is a no-op. ErrNotAuthorized and ErrCaptureExceedsAuthorization cannot be
+// fixed by retrying; FlagCapture records them for review.
+func (s *CardPaymentService) ScheduleCapture(tx *gorm.DB, rideID, captainID, captainUserID uuid.UUID, finalFare int64) error {
+	if finalFare <= 0 {
+		return ErrInvalidAmount
+	}
+
+	payment, err := s.repo.LockByRideID(tx, rideID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return ErrPaymentNotFound
+	}
+
+	switch payment.Status {
+	case models.StatusCapturePending, models.StatusPaid:
+		if payment.CaptureAmount == finalFare {
+			return nil
+		}
+		return ErrInvalidPaymentState
+	case models.StatusPending, models.StatusRequiresAction:
+		return ErrNotAuthorized
+	case models.StatusAuthorized:
+	default:
+		return ErrInvalidPaymentState
+	}
+	if finalFare > payment.AuthorizedAmount {
+		return ErrCaptureExceedsAuthorization
+	}
+
+	payment.CaptainID = &captainID
+	payment.CaptainUserID = &captainUserID
+	payment.CaptureAmount = finalFare
+	payment.Status = models.StatusCapturePending
+	return s.repo.Save(tx, payment)
+}
+
+// FlagCapture marks a ride's payment capture_failed in tx with the reason it cannot
+// be captured, for ops to settle by hand
+func (s *CardPaymentService) FlagCapture(tx *gorm.DB, rideID uuid.UUID, reason error) error {
+	payment, err := s.repo.LockByRideID(tx, rideID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return ErrPaymentNotFound
+	}
+
+	payment.Status = models.StatusCaptureFailed
+	payment.FailureReason = reason.Error()
+	return s.repo.Save(tx, payment)
+}
+
+// CapturePending charges the gateway for payments scheduled by ScheduleCapture.
+// A capture that fails is tried again on the next run, unless the gateway rejects
+// it outright, in which case the payment is flagged.
+func (s *CardPaymentService) CapturePending(ctx context.Context) error {
+	pending, err := s.repo.FindByStatus(ctx, models.StatusCapturePending, captureBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range pending {
+		_, captureErr := s.CaptureRide(ctx, payment.RideID)
+		if captureErr == nil {
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"error":      captureErr.Error(),
+			"payment_id": payment.ID.String(),
+			"ride_id":    payment.RideID.String(),
+		}
+		if !errors.Is(captureErr, gateway.ErrInvalidState) && !errors.Is(captureErr, gateway.ErrUnknownPayment) {
+			logger.FromContext(ctx).Warn("Card capture failed, will retry", fields)
+			continue
+		}
+
+		logger.FromContext(ctx).Error("Card capture needs manual review", fields)
+		err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+			return s.FlagCapture(tx, payment.RideID, captureErr)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CaptureRide charges a ride's scheduled fare against its authorization. The
+// captain was recorded when the capture was scheduled, so a capture.succeeded
+// webhook that arrives first settles a payment that already names them and
+// credits their balance. Capturing a paid ride again returns the payment.
+func (s *CardPaymentService) CaptureRide(ctx context.Context, rideID uuid.UUID) (*models.Payment, error) {
+	return s.call(ctx, rideID, func(payment *models.Payment) (bool, error) {
+		switch payment.Status {
+		case models.StatusPaid:
+			return false, nil
+		case models.StatusCapturePending:
+			return true, nil
+		default:
+			return false, ErrInvalidPaymentState
+		}
+	}, func(payment *models.Payment) error {
+		_, err := s.gateway.Capture(ctx, payment.GatewayRef, payment.CaptureAmount)
+		return err
+	}, func(tx *gorm.DB, payment *models.Payment) error {
+		if payment.Status != models.StatusCapturePending {
+			return nil
+		}
+
+		payment.Status = models.StatusPaid
+		payment.Amount = payment.CaptureAmount
+		return s.recordSettled(tx, payment)
+	})
+}
+
+// VoidRide releases the authorization of a cancelled ride
+func (s *CardPaymentService) VoidRide(ctx context.Context, rideID uuid.UUID) (*models.Payment, error) {
+	return s.call(ctx, rideID, func(payment *models.Payment) (bool, error) {
+		switch payment.Status {
+		case models.StatusVoided, models.StatusFailed:
+			return false, nil
+		case models.StatusPending, models.StatusRequiresAction, models.StatusAuthorized:
+			return true, nil
+		default:
+			return false, ErrInvalidPaymentState
+		}
+	}, func(payment *models.Payment) error {
+		_, err := s.gateway.Void(ctx, payment.GatewayRef)
+		return err
+	}, func(tx *gorm.DB, payment *models.Payment) error {
+		payment.Status = models.StatusVoided
+		return nil
+	})
+}
+
+// RefundRide returns part or all of a captured ride payment to the card after a
+// dispute. Each ride can be refunded once: the amount is reserved under the row
+// lock before the gateway is called with a key per ride, so a retry of a refund
+// whose result was not recorded repeats the same gateway refund instead of
+// adding another. The refund is charged to the platform's refunds account.
+func (s *CardPaymentService) RefundRide(ctx context.Context, adminID, rideID uuid.UUID, amount int64, reason string) (*models.Payment, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	return s.call(ctx, rideID, func(payment *models.Payment) (bool, error) {
+		if payment.RefundedAmount > 0 {
+			if payment.RefundedAmount != amount {
+				return false, ErrInvalidPaymentState
+			}
+			return true, nil
+		}
+		if payment.Status != models.StatusPaid {
+			return false, ErrInvalidPaymentState
+		}
+		if amount > payment.Amount {
+			return false, fmt.Errorf("%w: captured %d", ErrRefundExceedsPayment, payment.Amount)
+		}
+
+		payment.RefundedAmount = amount
+		return true, nil
+	}, func(payment *models.Payment) error {
+		_, err := s.gateway.Refund(ctx, payment.GatewayRef, amount, "ride_refund:"+rideID.String())
+		return err
+	}, func(tx *gorm.DB, payment *models.Payment) error {
+		// Checked again under the lock that records the result
+		if payment.RefundedAmount != amount || amount > payment.Amount {
+			return ErrInvalidPaymentState
+		}
+
+		if payment.RefundedAmount == payment.Amount {
+			payment.Status = models.StatusRefunded
+		}
+		_, err := s.ledger.Post(tx, ledger.Posting{
+			Kind:        ledgermodels.KindRideRefund,
+			Reference:   "ride_card_refund:" + rideID.String(),
+			Description: reason,
+			RideID:      &rideID,
+			CreatedBy:   &adminID,
+			Lines: []ledger.Line{
+				{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemCardClearing, Amount: amount},
+				{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemRefunds, Amount: -amount},
+			},
+		})
+		return err
+	})
+}
+
+// HandleWebhook verifies and applies a gateway event. Each event is applied at most
+// once; redeliveries are acknowledged without changing anything.
+func (s *CardPaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.gateway.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		isNew, err := s.repo.RecordWebhookEvent(tx, &models.WebhookEvent{
+			ID:          uuid.New(),
+			Gateway:     s.gateway.Name(),
+			EventID:     event.ID,
+			Type:        event.Type,
+			Reference:   event.Reference,
+			ProcessedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		if !isNew {
+			logger.Debug("Duplicate payment webhook ignored", map[string]interface{}{
+				"event_id": event.ID,
+			})
+			return nil
+		}
+
+		payment, err := s.repo.LockByGatewayRef(tx, s.gateway.Name(), event.Reference)
+		if err != nil {
+			return err
+		}
+		if payment == nil {
+			// Rolls back the event record so the gateway's retry is processed
+			return ErrPaymentNotFound
+		}
+
+		switch event.Type {
+		case gateway.EventAuthorizationSucceeded:
+			if payment.Status == models.StatusPending || payment.Status == models.StatusRequiresAction {
+				payment.Status = models.StatusAuthorized
+			}
+		case gateway.EventAuthorizationFailed:
+			if payment.Status == models.StatusPending || payment.Status == models.StatusRequiresAction {
+				payment.Status = models.StatusFailed
+				payment.FailureReason = event.Reason
+			}
+		case gateway.EventCaptureSucceeded:
+			// ScheduleCapture recorded the captain before the gateway was called
+			if payment.Status == models.StatusCapturePending {
+				payment.Status = models.StatusPaid
+				payment.Amount = event.Amount
+				if err := s.recordSettled(tx, payment); err != nil {
+					return err
+				}
+			}
+		case gateway.EventRefundSucceeded:
+			// Refunds are recorded synchronously by RefundRide
+		default:
+			logger.Warn("Unhandled payment webhook type", map[string]interface{}{
+				"event_id": event.ID,
+				"type":     event.Type,
+			})
+		}
+
+		return s.repo.Save(tx, payment)
+	})
+}
+
+// update locks the ride's payment, applies fn inside the same transaction and saves the result
+func (s *CardPaymentService) update(ctx context.Context, rideID uuid.UUID, fn func(tx *gorm.DB, payment *models.Payment) error) (*models.Payment, error) {
+	var payment *models.Payment
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		payment, err = s.repo.LockByRideID(tx, rideID)
+		if err != nil {
+			return err
+		}
+		if payment == nil {
+			return ErrPaymentNotFound
+		}
+
+		if err := fn(tx, payment); err != nil {
+			return err
+		}
+
+		return s.repo.Save(tx, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// call runs a gateway operation on the ride's payment without holding its row lock
+// over the network. prepare checks and updates the locked payment and reports
+// whether the call is still needed; apply records the call's result in a second
+// transaction, by which time a webhook may already have moved the payment on.
+func (s *CardPaymentService) call(ctx context.Context, rideID uuid.UUID, prepare func(payment *models.Payment) (bool, error), call func(payment *models.Payment) error, apply func(tx *gorm.DB, payment *models.Payment) error) (*models.Payment, error) {
+	needed := false
+	payment, err := s.update(ctx, rideID, func(tx *gorm.DB, payment *models.Payment) error {
+		var err error
+		needed, err = prepare(payment)
+		return err
+	})
+	if err != nil || !needed {
+		return payment, err
+	}
+
+	if err := call(payment); err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, rideID, apply)
+}
+
+// recordSettled credits the captured fare to the captain and adds a PaymentSettled
+// event to the outbox in tx, so both commit with the paid status
+func (s *CardPaymentService) recordSettled(tx *gorm.DB, payment *models.Payment) error {
+	if payment.CaptainUserID == nil {
+		return fmt.Errorf("captured payment %s has no captain", payment.ID)
+	}
+	if _, err := s.ledger.Post(tx, capturePosting(payment)); err != nil {
+		return err
+	}
+
+	return s.outbox.Add(tx, payment.RideID, events.PaymentSettled{
+		PaymentID:   payment.ID,
+		RideID:      payment.RideID,
+		PassengerID: payment.PassengerID,
+		Amount:      payment.Amount,
+		Method:      payment.Method,
+	})
+}
+
+// capturePosting moves a captured fare from the card clearing account, where the
+// gateway's settlement lands, to the captain's balance. Commission is charged
+// separately when the ride completes, as for every payment method.
+func capturePosting(payment *models.Payment) ledger.Posting {
+	return ledger.Posting{
+		Kind:        ledgermodels.KindRideCardCapture,
+		Reference:   "ride_card_capture:" + payment.RideID.String(),
+		Description: "Card fare",
+		RideID:      &payment.RideID,
+		Lines: []ledger.Line{
+			{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemCardClearing, Amount: -payment.Amount},
+			{OwnerID: *payment.CaptainUserID, AccountType: ledgermodels.AccountCaptainBalance, Amount: payment.Amount},
+		},
+	}
+}
+
+func statusFromGateway(status string) string {
+	switch status {
+	case gateway.StatusAuthorized:
+		return models.StatusAuthorized
+	case gateway.StatusRequiresAction:
+		return models.StatusRequiresAction
+	case gateway.StatusDeclined:
+		return models.StatusFailed
+	case gateway.StatusCaptured:
+		return models.StatusPaid
+	case gateway.StatusVoided:
+		return models.StatusVoided
+	case gateway.StatusRefunded:
+		return models.StatusRefunded
+	default:
+		return models.StatusPending
+	}
+}
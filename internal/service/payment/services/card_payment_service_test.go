@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	ledgermodels "theb-backend/internal/service/ledger/models"
+	"theb-backend/internal/service/payment/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCapturePosting(t *testing.T) {
+	captainUserID := uuid.New()
+
+	tests := []struct {
+		name     string
+		captured int64
+	}{
+		{name: "fare within estimate", captured: 2500},
+		{name: "fare below estimate", captured: 1800},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &models.Payment{
+				ID:            uuid.New(),
+				RideID:        uuid.New(),
+				CaptainUserID: &captainUserID,
+				Amount:        tt.captured,
+				Status:        models.StatusPaid,
+			}
+
+			posting := capturePosting(payment)
+			if posting.Reference != "ride_card_capture:"+payment.RideID.String() {
+				t.Errorf("Reference = %q, want one per ride", posting.Reference)
+			}
+
+			balances := make(map[string]int64)
+			for _, line := range posting.Lines {
+				balances[line.OwnerID.String()+"/"+line.AccountType] += line.Amount
+			}
+
+			captain := balances[captainUserID.String()+"/"+ledgermodels.AccountCaptainBalance]
+			if captain != tt.captured {
+				t.Errorf("captain balance after completion = %d, want the captured fare %d", captain, tt.captured)
+			}
+			clearing := balances[ledgermodels.SystemOwnerID.String()+"/"+ledgermodels.AccountSystemCardClearing]
+			if clearing != -tt.captured {
+				t.Errorf("card clearing balance = %d, want %d", clearing, -tt.captured)
+			}
+		})
+	}
+}
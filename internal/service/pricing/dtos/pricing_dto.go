@@ -0,0 +1,64 @@
+package dtos
+
+import (
+	"time"
+
+	"theb-backend/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+// EstimateRequest asks for a fare quote between two points
+type EstimateRequest struct {
+	Pickup  geo.Point `json:"pickup"`
+	Dropoff geo.Point `json:"dropoff"`
+} // @name EstimateRequest
+
+// QuoteResponse is a fare estimate. Amounts are in the city's minor units, e.g. fils.
+type QuoteResponse struct {
+	CityID          uuid.UUID `json:"city_id"`
+	Currency        string    `json:"currency"`
+	DistanceKm      float64   `json:"distance_km"`
+	DurationMinutes int       `json:"duration_minutes"`
+	BaseFare        int64     `json:"base_fare"`
+	DistanceFare    int64     `json:"distance_fare"`
+	TimeFare        int64     `json:"time_fare"`
+	// MeteredFare is base, distance and time fares, raised to the minimum fare
+	MeteredFare int64 `json:"metered_fare"`
+	// SurgeMultiplier is 1 without surge; it applies to the metered fare only
+	SurgeMultiplier  float64    `json:"surge_multiplier"`
+	SurgeZoneID      *uuid.UUID `json:"surge_zone_id,omitempty"`
+	SurgeAmount      int64      `json:"surge_amount"`
+	PickupSurcharge  int64      `json:"pickup_surcharge"`
+	DropoffSurcharge int64      `json:"dropoff_surcharge"`
+	Fare             int64      `json:"fare"`
+} // @name QuoteResponse
+
+// HeatmapResponse is a GeoJSON feature collection of zones whose properties carry
+// their surge: id, name, multiplier, open_requests and available_captains
+type HeatmapResponse struct {
+	Type       string        `json:"type"`
+	Features   []geo.Feature `json:"features"`
+	ComputedAt *time.Time    `json:"computed_at,omitempty"`
+} // @name HeatmapResponse
+
+// ZoneSurgeResponse is a zone's surge with the readings behind it
+type ZoneSurgeResponse struct {
+	ZoneID     uuid.UUID `json:"zone_id"`
+	ZoneName   string    `json:"zone_name"`
+	CityID     uuid.UUID `json:"city_id"`
+	Demand     int       `json:"demand"`
+	Supply     int       `json:"supply"`
+	Ratio      float64   `json:"ratio"`
+	Target     float64   `json:"target"`
+	Smoothed   float64   `json:"smoothed"`
+	Multiplier float64   `json:"multiplier"`
+	Cap        float64   `json:"cap"`
+	Disabled   bool      `json:"disabled"`
+	ComputedAt time.Time `json:"computed_at"`
+} // @name ZoneSurgeResponse
+
+// ZoneSurgeListResponse lists the surge of every zone an admin manages
+type ZoneSurgeListResponse struct {
+	Zones []ZoneSurgeResponse `json:"zones"`
+} // @name ZoneSurgeListResponse
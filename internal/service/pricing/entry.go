@@ -0,0 +1,115 @@
+package pricing
+
+import (
+	"context"
+	"time"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/metrics"
+	"theb-backend/internal/middleware"
+	captains "theb-backend/internal/service/captain/services"
+	cities "theb-backend/internal/service/city/services"
+	rides "theb-backend/internal/service/order/repositories"
+	"theb-backend/internal/service/pricing/handlers"
+	"theb-backend/internal/service/pricing/services"
+	zones "theb-backend/internal/service/zone/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// surgeSchedule is the default schedule of the surge job
+const surgeSchedule = "@every 30s"
+
+// Module wires fare estimation and surge pricing
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "pricing" }
+
+// Requires returns the modules whose services pricing resolves
+func (Module) Requires() []string { return []string{"city", "zone", "captain", "order"} }
+
+// Migrations returns no tables; surge lives in Redis and rates in settings
+func (Module) Migrations() []interface{} { return nil }
+
+// Register registers the surge and fare services and the pricing handler in the
+// container and exposes surge multipliers as a metric
+func (Module) Register(ctn *container.Container) error {
+	redisClient, err := container.Resolve[*redis.Client](ctn)
+	if err != nil {
+		return err
+	}
+	cityService, err := container.Resolve[*cities.CityService](ctn)
+	if err != nil {
+		return err
+	}
+	zoneService, err := container.Resolve[*zones.ZoneService](ctn)
+	if err != nil {
+		return err
+	}
+	presenceService, err := container.Resolve[*captains.PresenceService](ctn)
+	if err != nil {
+		return err
+	}
+	rideRepo, err := container.Resolve[*rides.RideRepository](ctn)
+	if err != nil {
+		return err
+	}
+	scheduler, err := container.Resolve[*jobs.Scheduler](ctn)
+	if err != nil {
+		return err
+	}
+
+	// A surge is quoted for a few runs of the job, so it must know how often that is
+	interval := scheduler.Interval("surge_compute", surgeSchedule)
+	surgeService := services.NewSurgeService(zoneService, cityService, rideRepo, presenceService, redisClient, interval)
+	fareService := services.NewFareService(zoneService, cityService, surgeService)
+
+	container.Supply(ctn, surgeService)
+	container.Supply(ctn, fareService)
+	container.Supply(ctn, handlers.NewPricingHandler(fareService, surgeService, zoneService, cityService))
+
+	return metrics.RegisterSurgeMultipliers(surgeService.Multipliers)
+}
+
+// Jobs returns the surge job, which recomputes every zone's multiplier. Its interval
+// is overridden under jobs.schedules.surge_compute; a failed run is not retried,
+// since the next one follows shortly.
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) {
+	surgeService, err := container.Resolve[*services.SurgeService](ctn)
+	if err != nil {
+		return nil, err
+	}
+
+	return []jobs.CronJob{{
+		Name:     "surge_compute",
+		Schedule: surgeSchedule,
+		Options:  jobs.Options{Timeout: 20 * time.Second, MaxAttempts: 1},
+		Run: func(ctx context.Context) error {
+			return surgeService.Compute(ctx)
+		},
+	}}, nil
+}
+
+// Routes mounts the fare estimate, captain heatmap and admin surge endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	handler, err := container.Resolve[*handlers.PricingHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+
+	v1.POST("/payments/calculate", auth, handler.Estimate)
+	v1.GET("/captains/surge/heatmap", auth, middleware.RequireRole(middleware.RoleCaptain, middleware.RoleBoth), handler.Heatmap)
+	v1.GET("/admin/surge", auth, middleware.RequireRole(middleware.RoleAdmin), handler.AdminList)
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	cities "theb-backend/internal/service/city/services"
+	"theb-backend/internal/service/pricing/dtos"
+	"theb-backend/internal/service/pricing/services"
+	zones "theb-backend/internal/service/zone/services"
+	"theb-backend/pkg/geo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PricingHandler serves fare estimates, the captain surge heatmap and the admin surge view
+type PricingHandler struct {
+	fares  *services.FareService
+	surge  *services.SurgeService
+	zones  *zones.ZoneService
+	cities *cities.CityService
+}
+
+// NewPricingHandler creates a new pricing handler
+func NewPricingHandler(fareService *services.FareService, surgeService *services.SurgeService, zoneService *zones.ZoneService, cityService *cities.CityService) *PricingHandler {
+	return &PricingHandler{
+		fares:  fareService,
+		surge:  surgeService,
+		zones:  zoneService,
+		cities: cityService,
+	}
+}
+
+// Estimate quotes a ride, with the surge multiplier applied and shown
+// @Summary Estimate a fare
+// @ID payments-calculate
+// @Tags Payment
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.EstimateRequest true "Pickup and drop-off"
+// @Success 200 {object} dtos.QuoteResponse
+// @Router /api/v1/payments/calculate [post]
+func (h *PricingHandler) Estimate(c *gin.Context) {
+	var req dtos.EstimateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	quote, err := h.fares.Estimate(c.Request.Context(), req.Pickup, req.Dropoff)
+	if err != nil {
+		h.handleError(c, "Failed to estimate fare", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.QuoteResponse{
+		CityID:           quote.CityID,
+		Currency:         quote.Currency,
+		DistanceKm:       quote.DistanceKm,
+		DurationMinutes:  quote.DurationMinutes,
+		BaseFare:         quote.BaseFare,
+		DistanceFare:     quote.DistanceFare,
+		TimeFare:         quote.TimeFare,
+		MeteredFare:      quote.MeteredFare,
+		SurgeMultiplier:  quote.SurgeMultiplier,
+		SurgeZoneID:      quote.SurgeZoneID,
+		SurgeAmount:      quote.SurgeAmount,
+		PickupSurcharge:  quote.Surcharges.Pickup,
+		DropoffSurcharge: quote.Surcharges.Dropoff,
+		Fare:             quote.Fare,
+	})
+}
+
+// Heatmap returns the zones with their surge and demand, so captains can move to
+// where riders are waiting
+// @Summary Get the surge heatmap
+// @ID captains-surge-heatmap
+// @Tags Surge
+// @Security BearerAuth
+// @Produce json
+// @Param city_id query string false "Only zones of this city"
+// @Success 200 {object} dtos.HeatmapResponse
+// @Router /api/v1/captains/surge/heatmap [get]
+func (h *PricingHandler) Heatmap(c *gin.Context) {
+	cityID, ok := cityQuery(c)
+	if !ok {
+		return
+	}
+
+	state, err := h.surge.State(c.Request.Context())
+	if err != nil {
+		h.handleError(c, "Failed to load surge", err)
+		return
+	}
+
+	response := dtos.HeatmapResponse{Type: "FeatureCollection", Features: []geo.Feature{}}
+	if !state.ComputedAt.IsZero() {
+		response.ComputedAt = &state.ComputedAt
+	}
+	for _, zone := range h.zones.Zones() {
+		surge, found := state.Zones[zone.ID]
+		if !found || (cityID != nil && surge.CityID != *cityID) {
+			continue
+		}
+		response.Features = append(response.Features, geo.Feature{
+			Type: "Feature",
+			Properties: map[string]interface{}{
+				"id":                 zone.ID,
+				"name":               zone.Name,
+				"multiplier":         surge.Multiplier,
+				"open_requests":      surge.Demand,
+				"available_captains": surge.Supply,
+			},
+			Geometry: geo.Geometry(zone.Geometry),
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminList returns the surge of every zone in the cities the admin manages, with
+// the readings behind it. Surge is capped or disabled per zone through the zone's
+// surge_cap and surge_disabled properties.
+// @Summary List zone surge (admin)
+// @ID admin-surge-list
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param city_id query string false "Only zones of this city"
+// @Success 200 {object} dtos.ZoneSurgeListResponse
+// @Router /api/v1/admin/surge [get]
+func (h *PricingHandler) AdminList(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+	cityID, ok := cityQuery(c)
+	if !ok {
+		return
+	}
+
+	scope, err := h.cities.Scope(c.Request.Context(), adminID)
+	if err != nil {
+		h.handleError(c, "Failed to load admin cities", err)
+		return
+	}
+	if cityID != nil && !scope.Allows(cityID) {
+		apierror.Abort(c, apierror.New(apierror.CodeCityAccessDenied))
+		return
+	}
+
+	state, err := h.surge.State(c.Request.Context())
+	if err != nil {
+		h.handleError(c, "Failed to load surge", err)
+		return
+	}
+
+	response := dtos.ZoneSurgeListResponse{Zones: []dtos.ZoneSurgeResponse{}}
+	for _, zone := range h.zones.Zones() {
+		surge, found := state.Zones[zone.ID]
+		if !found || !scope.Allows(&surge.CityID) || (cityID != nil && surge.CityID != *cityID) {
+			continue
+		}
+		response.Zones = append(response.Zones, toZoneSurgeResponse(surge))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PricingHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, zones.ErrPickupOutsideServiceArea):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRidePickupOutOfArea, err))
+	case errors.Is(err, zones.ErrDropoffOutsideServiceArea):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideDropoffOutOfArea, err))
+	case errors.Is(err, zones.ErrRestrictedArea):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideRestrictedArea, err))
+	default:
+		logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+			"error": err.Error(),
+		})
+		apierror.Abort(c, apierror.New(apierror.CodeInternal))
+	}
+}
+
+// cityQuery parses the optional city_id query parameter
+func cityQuery(c *gin.Context) (*uuid.UUID, bool) {
+	raw := c.Query("city_id")
+	if raw == "" {
+		return nil, true
+	}
+
+	cityID, err := uuid.Parse(raw)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("city_id", "uuid")))
+		return nil, false
+	}
+	return &cityID, true
+}
+
+func toZoneSurgeResponse(surge services.ZoneSurge) dtos.ZoneSurgeResponse {
+	return dtos.ZoneSurgeResponse{
+		ZoneID:     surge.ZoneID,
+		ZoneName:   surge.ZoneName,
+		CityID:     surge.CityID,
+		Demand:     surge.Demand,
+		Supply:     surge.Supply,
+		Ratio:      surge.Ratio,
+		Target:     surge.Target,
+		Smoothed:   surge.Smoothed,
+		Multiplier: surge.Multiplier,
+		Cap:        surge.Cap,
+		Disabled:   surge.Disabled,
+		ComputedAt: surge.ComputedAt,
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	cities "theb-backend/internal/service/city/services"
+	zones "theb-backend/internal/service/zone/services"
+	"theb-backend/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+// Quote is a fare estimate for a ride. Amounts are in the city's minor units.
+type Quote struct {
+	CityID          uuid.UUID
+	Currency        string
+	DistanceKm      float64
+	DurationMinutes int
+	BaseFare        int64
+	DistanceFare    int64
+	TimeFare        int64
+	// MeteredFare is base, distance and time fares, raised to the minimum fare
+	MeteredFare int64
+	// SurgeMultiplier applies to the metered fare only; SurgeZoneID is nil without surge
+	SurgeMultiplier float64
+	SurgeZoneID     *uuid.UUID
+	SurgeAmount     int64
+	Surcharges      zones.Surcharges
+	// Fare is the surged metered fare plus surcharges, rounded to the city's fare rounding
+	Fare int64
+}
+
+// FareService estimates fares from the pickup city's rates, the zone surcharges
+// and the pickup zone's surge
+type FareService struct {
+	zones  *zones.ZoneService
+	cities *cities.CityService
+	surge  *SurgeService
+}
+
+// NewFareService creates a fare service
+func NewFareService(zoneService *zones.ZoneService, cityService *cities.CityService, surge *SurgeService) *FareService {
+	return &FareService{
+		zones:  zoneService,
+		cities: cityService,
+		surge:  surge,
+	}
+}
+
+// Estimate quotes a ride between two points. Without routing, the distance is the
+// straight line stretched by the city's route factor and the time follows from its
+// average speed. The ride must be allowed by the zones.
+func (s *FareService) Estimate(ctx context.Context, pickup, dropoff geo.Point) (*Quote, error) {
+	if err := s.zones.CheckRide(ctx, pickup, &dropoff); err != nil {
+		return nil, err
+	}
+
+	cityID := s.zones.CityAt(pickup)
+	city, ok := s.cities.City(cityID)
+	if !ok {
+		city = s.cities.Default()
+	}
+
+	distanceKm := geo.DistanceKm(pickup, dropoff) * s.cities.Float(city.ID, "route_factor")
+	minutes := distanceKm / s.cities.Float(city.ID, "average_speed_kmh") * 60
+
+	quote := &Quote{
+		CityID:          city.ID,
+		Currency:        city.Currency,
+		DistanceKm:      math.Round(distanceKm*100) / 100,
+		DurationMinutes: int(math.Ceil(minutes)),
+		BaseFare:        s.cities.Int(city.ID, "base_fare"),
+		DistanceFare:    int64(math.Round(distanceKm * float64(s.cities.Int(city.ID, "per_km_rate")))),
+		TimeFare:        int64(math.Round(minutes * float64(s.cities.Int(city.ID, "per_minute_rate")))),
+		Surcharges:      s.zones.Surcharges(pickup, dropoff),
+	}
+
+	quote.MeteredFare = quote.BaseFare + quote.DistanceFare + quote.TimeFare
+	if minimum := s.cities.Int(city.ID, "minimum_fare"); quote.MeteredFare < minimum {
+		quote.MeteredFare = minimum
+	}
+
+	surge := s.surge.At(ctx, pickup)
+	quote.SurgeMultiplier = surge.Multiplier
+	quote.SurgeZoneID = surge.ZoneID
+	quote.SurgeAmount = int64(math.Round(float64(quote.MeteredFare)*surge.Multiplier)) - quote.MeteredFare
+
+	quote.Fare = city.RoundFare(quote.MeteredFare + quote.SurgeAmount + quote.Surcharges.Total())
+
+	return quote, nil
+}
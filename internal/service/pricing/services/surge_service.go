@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"theb-backend/internal/logger"
+	captains "theb-backend/internal/service/captain/services"
+	cities "theb-backend/internal/service/city/services"
+	rides "theb-backend/internal/service/order/repositories"
+	zonemodels "theb-backend/internal/service/zone/models"
+	zones "theb-backend/internal/service/zone/services"
+	"theb-backend/pkg/geo"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const (
+	// stateKey holds the latest surge of every zone, written by the compute job
+	stateKey = "surge:zones"
+	// staleIntervals is how many compute intervals a computed surge is quoted for;
+	// if the job stops running, fares return to normal rather than staying surged
+	staleIntervals = 3
+)
+
+// ZoneSurge is a zone's surge as of one computation. Demand is open ride requests
+// picked up in the zone and Supply is available captains in it.
+type ZoneSurge struct {
+	ZoneID   uuid.UUID `json:"zone_id"`
+	ZoneName string    `json:"zone_name"`
+	CityID   uuid.UUID `json:"city_id"`
+	Demand   int       `json:"demand"`
+	Supply   int       `json:"supply"`
+	Ratio    float64   `json:"ratio"`
+	// Target is the multiplier the ratio maps to, within the cap
+	Target float64 `json:"target"`
+	// Smoothed moves towards Target a step per computation
+	Smoothed float64 `json:"smoothed"`
+	// Multiplier is what fares are quoted with; it follows Smoothed only once
+	// the two differ by the hysteresis
+	Multiplier float64   `json:"multiplier"`
+	Cap        float64   `json:"cap"`
+	Disabled   bool      `json:"disabled"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// State is the surge of every zone as of one computation
+type State struct {
+	Zones      map[uuid.UUID]ZoneSurge `json:"zones"`
+	ComputedAt time.Time               `json:"computed_at"`
+}
+
+// Surge is the multiplier for a pickup point. ZoneID is nil when no zone surges there.
+type Surge struct {
+	Multiplier float64
+	ZoneID     *uuid.UUID
+}
+
+// SurgeService computes surge multipliers per zone from the ratio of open ride
+// requests to available captains, and quotes them for pickup points. The compute
+// job runs on one replica at a time and shares its result through Redis when
+// Redis is configured; otherwise the result is kept in memory.
+type SurgeService struct {
+	zones    *zones.ZoneService
+	cities   *cities.CityService
+	rides    *rides.RideRepository
+	presence *captains.PresenceService
+	redis    *redis.Client
+	// staleAfter is how long a computed surge is quoted
+	staleAfter time.Duration
+
+	mu    sync.RWMutex
+	state State
+}
+
+// NewSurgeService creates a surge service whose Compute runs every interval.
+// redisClient may be nil.
+func NewSurgeService(zoneService *zones.ZoneService, cityService *cities.CityService, rideRepo *rides.RideRepository, presence *captains.PresenceService, redisClient *redis.Client, interval time.Duration) *SurgeService {
+	return &SurgeService{
+		zones:      zoneService,
+		cities:     cityService,
+		rides:      rideRepo,
+		presence:   presence,
+		redis:      redisClient,
+		staleAfter: staleIntervals * interval,
+	}
+}
+
+// Compute counts demand and supply in every zone and moves each zone's multiplier
+// towards its target
+func (s *SurgeService) Compute(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	var surgeZones []zonemodels.Zone
+	window := time.Duration(0)
+	for _, zone := range s.zones.Zones() {
+		if zone.Kind == zonemodels.KindRestricted {
+			continue
+		}
+		surgeZones = append(surgeZones, zone)
+		if w := s.cities.Duration(s.cityOf(zone), "surge_demand_window"); w > window {
+			window = w
+		}
+	}
+
+	demand := make(map[uuid.UUID]int)
+	supply := make(map[uuid.UUID]int)
+	if len(surgeZones) > 0 {
+		if err := s.countDemand(ctx, now, window, demand); err != nil {
+			return err
+		}
+		if err := s.countSupply(ctx, supply); err != nil {
+			return err
+		}
+	}
+
+	previous, err := s.State(ctx)
+	if err != nil {
+		// Start over from no surge rather than skip the run
+		logger.FromContext(ctx).Warn("Failed to load previous surge", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	next := State{Zones: make(map[uuid.UUID]ZoneSurge, len(surgeZones)), ComputedAt: now}
+	for _, zone := range surgeZones {
+		prev, ok := previous.Zones[zone.ID]
+		if !ok {
+			prev = ZoneSurge{Smoothed: 1, Multiplier: 1}
+		}
+		next.Zones[zone.ID] = s.step(zone, prev, demand[zone.ID], supply[zone.ID], now)
+	}
+
+	return s.save(ctx, next)
+}
+
+// countDemand assigns recent open ride requests to the zone of their pickup. Each
+// city counts requests within its own demand window.
+func (s *SurgeService) countDemand(ctx context.Context, now time.Time, window time.Duration, demand map[uuid.UUID]int) error {
+	open, err := s.rides.OpenRequests(ctx, now.Add(-window))
+	if err != nil {
+		return err
+	}
+
+	for _, ride := range open {
+		zone, ok := s.zoneAt(geo.Point{Lat: ride.PickupLat, Lng: ride.PickupLng})
+		if !ok {
+			continue
+		}
+		if ride.CreatedAt.Before(now.Add(-s.cities.Duration(s.cityOf(zone), "surge_demand_window"))) {
+			continue
+		}
+		demand[zone.ID]++
+	}
+	return nil
+}
+
+// countSupply assigns present captains who are not on a ride to the zone they are in
+func (s *SurgeService) countSupply(ctx context.Context, supply map[uuid.UUID]int) error {
+	present, err := s.presence.Available(ctx)
+	if err != nil {
+		return err
+	}
+	busyIDs, err := s.rides.BusyCaptainIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	busy := make(map[uuid.UUID]bool, len(busyIDs))
+	for _, id := range busyIDs {
+		busy[id] = true
+	}
+
+	for _, captain := range present {
+		if busy[captain.CaptainID] {
+			continue
+		}
+		if zone, ok := s.zoneAt(captain.Point); ok {
+			supply[zone.ID]++
+		}
+	}
+	return nil
+}
+
+// step computes a zone's next surge from its previous one. The ratio maps linearly
+// to a target above the threshold, capped by the city's maximum and the zone's cap.
+// The smoothed multiplier moves part of the way towards the target, and the quoted
+// multiplier only follows it in steps of at least the hysteresis, so fares do not
+// flicker with every new request. Disabling surge or lowering the cap resets or
+// clamps the multiplier at once.
+func (s *SurgeService) step(zone zonemodels.Zone, prev ZoneSurge, demand, supply int, now time.Time) ZoneSurge {
+	cityID := s.cityOf(zone)
+	limit := s.limit(zone)
+
+	next := ZoneSurge{
+		ZoneID:     zone.ID,
+		ZoneName:   zone.Name,
+		CityID:     cityID,
+		Demand:     demand,
+		Supply:     supply,
+		Ratio:      round(float64(demand)/math.Max(float64(supply), 1), 2),
+		Cap:        limit,
+		Disabled:   s.disabled(zone),
+		ComputedAt: now,
+	}
+	if next.Disabled {
+		next.Target, next.Smoothed, next.Multiplier = 1, 1, 1
+		return next
+	}
+
+	threshold := s.cities.Float(cityID, "surge_threshold")
+	sensitivity := s.cities.Float(cityID, "surge_sensitivity")
+	next.Target = round(math.Min(1+math.Max(0, next.Ratio-threshold)*sensitivity, limit), 2)
+
+	alpha := s.cities.Float(cityID, "surge_smoothing")
+	next.Smoothed = round(prev.Smoothed+alpha*(next.Target-prev.Smoothed), 2)
+	if math.Abs(next.Smoothed-next.Target) < 0.05 {
+		// Smoothing only approaches the target; snap once close so the multiplier can settle
+		next.Smoothed = next.Target
+	}
+
+	next.Multiplier = prev.Multiplier
+	if round(math.Abs(next.Smoothed-prev.Multiplier), 2) >= s.cities.Float(cityID, "surge_hysteresis") {
+		next.Multiplier = round(next.Smoothed, 1)
+	}
+	next.Multiplier = math.Max(1, math.Min(next.Multiplier, limit))
+
+	return next
+}
+
+// At returns the surge for a pickup at point: that of the highest-priority zone
+// containing it. The zone's current cap and switch apply to the last computed
+// multiplier, so an admin disabling or capping surge takes effect on the next quote.
+// A surge that cannot be read, or was computed too long ago, is quoted as none.
+func (s *SurgeService) At(ctx context.Context, point geo.Point) Surge {
+	zone, ok := s.zoneAt(point)
+	if !ok || s.disabled(zone) {
+		return Surge{Multiplier: 1}
+	}
+
+	state, err := s.load(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to load surge, quoting without it", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return Surge{Multiplier: 1}
+	}
+	return s.quote(ctx, zone, state, time.Now())
+}
+
+// quote returns a zone's surge from state, or none when state is older than
+// staleAfter at now
+func (s *SurgeService) quote(ctx context.Context, zone zonemodels.Zone, state State, now time.Time) Surge {
+	surge, ok := state.Zones[zone.ID]
+	if !ok {
+		return Surge{Multiplier: 1}
+	}
+	if s.stale(state, now) {
+		logger.FromContext(ctx).Warn("Surge is stale, quoting without it", map[string]interface{}{
+			"zone_id":     zone.ID.String(),
+			"computed_at": state.ComputedAt,
+		})
+		return Surge{Multiplier: 1}
+	}
+	multiplier := math.Min(surge.Multiplier, s.limit(zone))
+	if multiplier <= 1 {
+		return Surge{Multiplier: 1}
+	}
+	return Surge{Multiplier: multiplier, ZoneID: &surge.ZoneID}
+}
+
+// State returns the latest computed surge, or an empty state when it is missing or stale
+func (s *SurgeService) State(ctx context.Context) (State, error) {
+	state, err := s.load(ctx)
+	if err != nil || s.stale(state, time.Now()) {
+		return State{}, err
+	}
+	return state, nil
+}
+
+// stale reports whether state was computed more than staleAfter before now
+func (s *SurgeService) stale(state State, now time.Time) bool {
+	return now.Sub(state.ComputedAt) > s.staleAfter
+}
+
+// load returns the latest computed surge however old, or an empty state when it is missing
+func (s *SurgeService) load(ctx context.Context) (State, error) {
+	if s.redis == nil {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.state, nil
+	}
+
+	data, err := s.redis.Get(ctx, stateKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, err
+	}
+	return state, nil
+}
+
+// Multipliers returns the quoted multiplier by zone name, for metrics
+func (s *SurgeService) Multipliers(ctx context.Context) (map[string]float64, error) {
+	state, err := s.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	multipliers := make(map[string]float64, len(state.Zones))
+	for _, surge := range state.Zones {
+		multipliers[surge.ZoneName] = surge.Multiplier
+	}
+	return multipliers, nil
+}
+
+// save stores a computed state for every replica to quote
+func (s *SurgeService) save(ctx context.Context, state State) error {
+	if s.redis == nil {
+		s.mu.Lock()
+		s.state = state
+		s.mu.Unlock()
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(ctx, stateKey, data, s.staleAfter).Err()
+}
+
+// zoneAt returns the highest-priority zone containing point that surge applies to
+func (s *SurgeService) zoneAt(point geo.Point) (zonemodels.Zone, bool) {
+	for _, zone := range s.zones.Locate(point) {
+		if zone.Kind != zonemodels.KindRestricted {
+			return zone, true
+		}
+	}
+	return zonemodels.Zone{}, false
+}
+
+// limit returns the highest multiplier a zone may surge to: the city's maximum,
+// or the zone's cap when lower
+func (s *SurgeService) limit(zone zonemodels.Zone) float64 {
+	limit := s.cities.Float(s.cityOf(zone), "surge_max_multiplier")
+	if zone.SurgeCap != nil && *zone.SurgeCap < limit {
+		limit = *zone.SurgeCap
+	}
+	return limit
+}
+
+// disabled reports whether surge is off in a zone or its city
+func (s *SurgeService) disabled(zone zonemodels.Zone) bool {
+	return zone.SurgeDisabled || !s.cities.Bool(s.cityOf(zone), "surge_enabled")
+}
+
+// cityOf returns the city whose settings apply to a zone
+func (s *SurgeService) cityOf(zone zonemodels.Zone) uuid.UUID {
+	if zone.CityID != nil {
+		return *zone.CityID
+	}
+	return s.cities.Default().ID
+}
+
+// round rounds v to the given number of decimal places
+func round(v float64, places int) float64 {
+	scale := math.Pow10(places)
+	return math.Round(v*scale) / scale
+}
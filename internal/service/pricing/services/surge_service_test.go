@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"theb-backend/internal/config"
+	cities "theb-backend/internal/service/city/services"
+	settings "theb-backend/internal/service/settings/services"
+	zonemodels "theb-backend/internal/service/zone/models"
+
+	"github.com/google/uuid"
+)
+
+// newTestSurgeService returns a surge service reading the default settings: a
+// maximum of 2, threshold 1, sensitivity 0.5, smoothing 0.3 and hysteresis 0.1
+func newTestSurgeService() *SurgeService {
+	settingsService := settings.NewSettingsService(nil, nil)
+	return &SurgeService{
+		cities:     cities.NewCityService(nil, settingsService, nil, config.CityConfig{}),
+		staleAfter: staleIntervals * 30 * time.Second,
+	}
+}
+
+func TestSurgeStep(t *testing.T) {
+	s := newTestSurgeService()
+	capped := 1.5
+
+	tests := []struct {
+		name           string
+		zone           zonemodels.Zone
+		prev           ZoneSurge
+		demand, supply int
+
+		wantRatio      float64
+		wantTarget     float64
+		wantSmoothed   float64
+		wantMultiplier float64
+		wantCap        float64
+		wantDisabled   bool
+	}{
+		{
+			name:           "no demand stays at 1",
+			prev:           ZoneSurge{Smoothed: 1, Multiplier: 1},
+			demand:         0,
+			supply:         5,
+			wantRatio:      0,
+			wantTarget:     1,
+			wantSmoothed:   1,
+			wantMultiplier: 1,
+			wantCap:        2,
+		},
+		{
+			name:           "smoothing moves part of the way to the target",
+			prev:           ZoneSurge{Smoothed: 1, Multiplier: 1},
+			demand:         5,
+			supply:         1,
+			wantRatio:      5,
+			wantTarget:     2,
+			wantSmoothed:   1.3,
+			wantMultiplier: 1.3,
+			wantCap:        2,
+		},
+		{
+			name:           "no captains counts as one",
+			prev:           ZoneSurge{Smoothed: 1, Multiplier: 1},
+			demand:         3,
+			supply:         0,
+			wantRatio:      3,
+			wantTarget:     2,
+			wantSmoothed:   1.3,
+			wantMultiplier: 1.3,
+			wantCap:        2,
+		},
+		{
+			name:           "smoothed snaps to a close target",
+			prev:           ZoneSurge{Smoothed: 1.97, Multiplier: 1.9},
+			demand:         10,
+			supply:         1,
+			wantRatio:      10,
+			wantTarget:     2,
+			wantSmoothed:   2,
+			wantMultiplier: 2,
+			wantCap:        2,
+		},
+		{
+			name:           "hysteresis holds the multiplier for a small change",
+			prev:           ZoneSurge{Smoothed: 1.2, Multiplier: 1.2},
+			demand:         3,
+			supply:         2,
+			wantRatio:      1.5,
+			wantTarget:     1.25,
+			wantSmoothed:   1.25,
+			wantMultiplier: 1.2,
+			wantCap:        2,
+		},
+		{
+			name:           "hysteresis lets a large enough change through, rounded",
+			prev:           ZoneSurge{Smoothed: 1.3, Multiplier: 1.2},
+			demand:         4,
+			supply:         2,
+			wantRatio:      2,
+			wantTarget:     1.5,
+			wantSmoothed:   1.36,
+			wantMultiplier: 1.4,
+			wantCap:        2,
+		},
+		{
+			name:           "multiplier falls back as demand drops",
+			prev:           ZoneSurge{Smoothed: 2, Multiplier: 2},
+			demand:         0,
+			supply:         4,
+			wantRatio:      0,
+			wantTarget:     1,
+			wantSmoothed:   1.7,
+			wantMultiplier: 1.7,
+			wantCap:        2,
+		},
+		{
+			name:           "zone cap limits the target",
+			zone:           zonemodels.Zone{SurgeCap: &capped},
+			prev:           ZoneSurge{Smoothed: 1, Multiplier: 1},
+			demand:         10,
+			supply:         1,
+			wantRatio:      10,
+			wantTarget:     1.5,
+			wantSmoothed:   1.15,
+			wantMultiplier: 1.2,
+			wantCap:        1.5,
+		},
+		{
+			name:           "lowered cap clamps the multiplier at once",
+			zone:           zonemodels.Zone{SurgeCap: &capped},
+			prev:           ZoneSurge{Smoothed: 1.8, Multiplier: 1.8},
+			demand:         10,
+			supply:         1,
+			wantRatio:      10,
+			wantTarget:     1.5,
+			wantSmoothed:   1.71,
+			wantMultiplier: 1.5,
+			wantCap:        1.5,
+		},
+		{
+			name:           "disabled zone resets to 1",
+			zone:           zonemodels.Zone{SurgeDisabled: true},
+			prev:           ZoneSurge{Smoothed: 1.8, Multiplier: 1.8},
+			demand:         10,
+			supply:         1,
+			wantRatio:      10,
+			wantTarget:     1,
+			wantSmoothed:   1,
+			wantMultiplier: 1,
+			wantCap:        2,
+			wantDisabled:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.zone.ID = uuid.New()
+			now := time.Now().UTC()
+
+			got := s.step(tt.zone, tt.prev, tt.demand, tt.supply, now)
+
+			if got.ZoneID != tt.zone.ID || !got.ComputedAt.Equal(now) {
+				t.Errorf("zone/computed at = %s/%s, want %s/%s", got.ZoneID, got.ComputedAt, tt.zone.ID, now)
+			}
+			if got.Demand != tt.demand || got.Supply != tt.supply {
+				t.Errorf("demand/supply = %d/%d, want %d/%d", got.Demand, got.Supply, tt.demand, tt.supply)
+			}
+			if got.Ratio != tt.wantRatio {
+				t.Errorf("ratio = %v, want %v", got.Ratio, tt.wantRatio)
+			}
+			if got.Target != tt.wantTarget {
+				t.Errorf("target = %v, want %v", got.Target, tt.wantTarget)
+			}
+			if got.Smoothed != tt.wantSmoothed {
+				t.Errorf("smoothed = %v, want %v", got.Smoothed, tt.wantSmoothed)
+			}
+			if got.Multiplier != tt.wantMultiplier {
+				t.Errorf("multiplier = %v, want %v", got.Multiplier, tt.wantMultiplier)
+			}
+			if got.Cap != tt.wantCap {
+				t.Errorf("cap = %v, want %v", got.Cap, tt.wantCap)
+			}
+			if got.Disabled != tt.wantDisabled {
+				t.Errorf("disabled = %v, want %v", got.Disabled, tt.wantDisabled)
+			}
+		})
+	}
+}
+
+func TestSurgeQuoteStale(t *testing.T) {
+	s := newTestSurgeService()
+	zone := zonemodels.Zone{ID: uuid.New()}
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name           string
+		computedAt     time.Time
+		wantMultiplier float64
+	}{
+		{name: "just computed", computedAt: now, wantMultiplier: 1.6},
+		{name: "within a few compute intervals", computedAt: now.Add(-80 * time.Second), wantMultiplier: 1.6},
+		{name: "past a few compute intervals", computedAt: now.Add(-2 * time.Minute), wantMultiplier: 1},
+		{name: "never computed", wantMultiplier: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := State{
+				Zones:      map[uuid.UUID]ZoneSurge{zone.ID: {ZoneID: zone.ID, Multiplier: 1.6, ComputedAt: tt.computedAt}},
+				ComputedAt: tt.computedAt,
+			}
+
+			got := s.quote(context.Background(), zone, state, now)
+			if got.Multiplier != tt.wantMultiplier {
+				t.Errorf("multiplier = %v, want %v", got.Multiplier, tt.wantMultiplier)
+			}
+			if surged := got.ZoneID != nil; surged != (tt.wantMultiplier > 1) {
+				t.Errorf("zone set = %v, want %v", surged, tt.wantMultiplier > 1)
+			}
+
+			s.state = state
+			loaded, err := s.State(context.Background())
+			if err != nil {
+				t.Fatalf("State() error = %v", err)
+			}
+			if stale := len(loaded.Zones) == 0; stale != (tt.wantMultiplier == 1) {
+				t.Errorf("State() dropped = %v, want %v", stale, tt.wantMultiplier == 1)
+			}
+		})
+	}
+}
+
+func TestSurgeLimit(t *testing.T) {
+	s := newTestSurgeService()
+	low, high := 1.4, 3.0
+
+	tests := []struct {
+		name         string
+		zone         zonemodels.Zone
+		wantLimit    float64
+		wantDisabled bool
+	}{
+		{name: "city maximum without a cap", zone: zonemodels.Zone{}, wantLimit: 2},
+		{name: "lower zone cap applies", zone: zonemodels.Zone{SurgeCap: &low}, wantLimit: 1.4},
+		{name: "higher zone cap does not raise the maximum", zone: zonemodels.Zone{SurgeCap: &high}, wantLimit: 2},
+		{name: "disabled zone", zone: zonemodels.Zone{SurgeDisabled: true}, wantLimit: 2, wantDisabled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.limit(tt.zone); got != tt.wantLimit {
+				t.Errorf("limit = %v, want %v", got, tt.wantLimit)
+			}
+			if got := s.disabled(tt.zone); got != tt.wantDisabled {
+				t.Errorf("disabled = %v, want %v", got, tt.wantDisabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SubmitRatingRequest rates the other side of a completed ride
+type SubmitRatingRequest struct {
+	RideID      string   `json:"ride_id" binding:"required,uuid"`
+	RatingValue int      `json:"rating_value" binding:"required,min=1,max=5"`
+	Review      string   `json:"review" binding:"omitempty,max=500"`
+	Tags        []string `json:"tags" binding:"omitempty,max=5,dive,max=32"`
+} // @name SubmitRatingRequest
+
+// RatingResponse is a submitted rating
+type RatingResponse struct {
+	ID          uuid.UUID `json:"rating_id"`
+	RideID      uuid.UUID `json:"ride_id"`
+	RaterRole   string    `json:"rater_role"`
+	RatingValue int       `json:"rating_value"`
+	Review      string    `json:"review,omitempty"`
+	Tags        []string  `json:"tags"`
+	Timestamp   time.Time `json:"timestamp"`
+} // @name RatingResponse
+
+// RatingSummaryResponse is a user's rolling rating in one role
+type RatingSummaryResponse struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Role          string    `json:"role"`
+	Average       float64   `json:"average"`
+	WindowCount   int       `json:"window_count"`
+	LifetimeCount int64     `json:"lifetime_count"`
+} // @name RatingSummaryResponse
+
+// ResolveReviewCaseRequest closes an ops review case
+type ResolveReviewCaseRequest struct {
+	Note string `json:"note" binding:"required,max=500"`
+} // @name ResolveReviewCaseRequest
+
+// ReviewCaseResponse is an ops review case opened by a low rating
+type ReviewCaseResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	RatingID       uuid.UUID  `json:"rating_id"`
+	RideID         uuid.UUID  `json:"ride_id"`
+	SubjectUserID  uuid.UUID  `json:"subject_user_id"`
+	SubjectRole    string     `json:"subject_role"`
+	RatingValue    int        `json:"rating_value"`
+	Status         string     `json:"status"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+} // @name ReviewCaseResponse
+
+// ReviewCaseListResponse is a page of review cases
+type ReviewCaseListResponse struct {
+	Cases   []ReviewCaseResponse `json:"cases"`
+	Page    int                  `json:"page"`
+	PerPage int                  `json:"per_page"`
+	Total   int64                `json:"total"`
+} // @name ReviewCaseListResponse
@@ -0,0 +1,91 @@
+package rating
+
+import (
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/middleware"
+	captainrepos "theb-backend/internal/service/captain/repositories"
+	orderrepos "theb-backend/internal/service/order/repositories"
+	"theb-backend/internal/service/rating/handlers"
+	"theb-backend/internal/service/rating/models"
+	"theb-backend/internal/service/rating/repositories"
+	"theb-backend/internal/service/rating/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Module wires two-way ride ratings and ops review cases
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "rating" }
+
+// Requires returns the modules whose services rating resolves
+func (Module) Requires() []string { return []string{"order", "captain"} }
+
+// Migrations returns the rating tables
+func (Module) Migrations() []interface{} {
+	return []interface{}{&models.Rating{}, &models.RatingAggregate{}, &models.ReviewCase{}}
+}
+
+// Register registers the rating repository, service and handler in the container
+func (Module) Register(ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	rideRepo, err := container.Resolve[*orderrepos.RideRepository](ctn)
+	if err != nil {
+		return err
+	}
+	captainRepo, err := container.Resolve[*captainrepos.CaptainRepository](ctn)
+	if err != nil {
+		return err
+	}
+
+	repo := repositories.NewRatingRepository(db)
+	service := services.NewRatingService(cfg.Ratings, repo, rideRepo, captainRepo)
+
+	container.Supply(ctn, repo)
+	container.Supply(ctn, service)
+	container.Supply(ctn, handlers.NewRatingHandler(service))
+
+	return nil
+}
+
+// Jobs returns no jobs
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) { return nil, nil }
+
+// Routes mounts the rating endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	handler, err := container.Resolve[*handlers.RatingHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+
+	ratings := v1.Group("/ratings", auth)
+	{
+		ratings.POST("", handler.Submit)
+		ratings.GET("/users/:user_id", handler.Summary)
+	}
+
+	admin := v1.Group("/admin/rating-reviews", auth, middleware.RequireRole(middleware.RoleAdmin))
+	{
+		admin.GET("", handler.ListReviewCases)
+		admin.POST("/:id/resolve", handler.ResolveReviewCase)
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/rating/dtos"
+	"theb-backend/internal/service/rating/models"
+	"theb-backend/internal/service/rating/services"
+	"theb-backend/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RatingHandler serves rating endpoints
+type RatingHandler struct {
+	service *services.RatingService
+}
+
+// NewRatingHandler creates a new rating handler
+func NewRatingHandler(service *services.RatingService) *RatingHandler {
+	return &RatingHandler{service: service}
+}
+
+// Submit rates the other side of a completed ride
+// @Summary Submit a ride rating
+// @ID rating-submit
+// @Tags Rating
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.SubmitRatingRequest true "Ride, rating and feedback"
+// @Success 201 {object} dtos.RatingResponse
+// @Router /api/v1/ratings [post]
+func (h *RatingHandler) Submit(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.SubmitRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	rating, err := h.service.Submit(c.Request.Context(), userID, services.SubmitInput{
+		RideID: uuid.MustParse(req.RideID),
+		Value:  req.RatingValue,
+		Review: req.Review,
+		Tags:   req.Tags,
+	})
+	if err != nil {
+		h.handleError(c, "Failed to submit rating", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.RatingResponse{
+		ID:          rating.ID,
+		RideID:      rating.RideID,
+		RaterRole:   rating.RaterRole,
+		RatingValue: rating.RatingValue,
+		Review:      rating.Review,
+		Tags:        rating.Tags,
+		Timestamp:   rating.CreatedAt,
+	})
+}
+
+// Summary returns a user's rolling rating as captain or passenger
+// @Summary Get a user's rating summary
+// @ID rating-summary
+// @Tags Rating
+// @Security BearerAuth
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param role query string false "captain or passenger" default(captain)
+// @Success 200 {object} dtos.RatingSummaryResponse
+// @Router /api/v1/ratings/users/{user_id} [get]
+func (h *RatingHandler) Summary(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("user_id", "uuid")))
+		return
+	}
+
+	role := c.DefaultQuery("role", models.RaterCaptain)
+	if role != models.RaterCaptain && role != models.RaterPassenger {
+		apierror.Abort(c, apierror.Validation(apierror.FieldError{
+			Field: "role",
+			Rule:  "oneof",
+			Param: models.RaterCaptain + " " + models.RaterPassenger,
+		}))
+		return
+	}
+
+	aggregate, err := h.service.Summary(c.Request.Context(), userID, role)
+	if err != nil {
+		h.handleError(c, "Failed to load rating summary", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.RatingSummaryResponse{
+		UserID:        aggregate.UserID,
+		Role:          aggregate.Role,
+		Average:       aggregate.Average,
+		WindowCount:   aggregate.WindowCount,
+		LifetimeCount: aggregate.LifetimeCount,
+	})
+}
+
+// ListReviewCases returns low-rating review cases for ops
+// @Summary List rating review cases (admin)
+// @ID admin-rating-review-list
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "open or resolved"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} dtos.ReviewCaseListResponse
+// @Router /api/v1/admin/rating-reviews [get]
+func (h *RatingHandler) ListReviewCases(c *gin.Context) {
+	params := pagination.FromQuery(c)
+	cases, total, err := h.service.ListReviewCases(c.Request.Context(), c.Query("status"), params)
+	if err != nil {
+		h.handleError(c, "Failed to load review cases", err)
+		return
+	}
+
+	response := dtos.ReviewCaseListResponse{
+		Cases:   make([]dtos.ReviewCaseResponse, 0, len(cases)),
+		Page:    params.Page,
+		PerPage: params.PerPage,
+		Total:   total,
+	}
+	for i := range cases {
+		response.Cases = append(response.Cases, toReviewCaseResponse(&cases[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResolveReviewCase closes a review case
+// @Summary Resolve a rating review case (admin)
+// @ID admin-rating-review-resolve
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Review case ID"
+// @Param request body dtos.ResolveReviewCaseRequest true "Resolution note"
+// @Success 200 {object} dtos.ReviewCaseResponse
+// @Router /api/v1/admin/rating-reviews/{id}/resolve [post]
+func (h *RatingHandler) ResolveReviewCase(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	caseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return
+	}
+
+	var req dtos.ResolveReviewCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	reviewCase, err := h.service.ResolveReviewCase(c.Request.Context(), adminID, caseID, req.Note)
+	if err != nil {
+		h.handleError(c, "Failed to resolve review case", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toReviewCaseResponse(reviewCase))
+}
+
+func (h *RatingHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidRating):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRatingInvalidValue, err))
+	case errors.Is(err, services.ErrInvalidTag):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRatingInvalidTag, err))
+	case errors.Is(err, services.ErrNotRideParticipant):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRatingNotParticipant, err))
+	case errors.Is(err, services.ErrRideNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideNotFound, err))
+	case errors.Is(err, services.ErrReviewCaseNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeReviewCaseNotFound, err))
+	case errors.Is(err, services.ErrAlreadyRated):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRatingAlreadySubmitted, err))
+	case errors.Is(err, services.ErrRideNotCompleted):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideNotCompleted, err))
+	case errors.Is(err, services.ErrRatingWindowClosed):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRatingWindowClosed, err))
+	case errors.Is(err, services.ErrReviewCaseResolved):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeReviewCaseResolved, err))
+	default:
+		logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+			"error": err.Error(),
+		})
+		apierror.Abort(c, apierror.New(apierror.CodeInternal))
+	}
+}
+
+func toReviewCaseResponse(reviewCase *models.ReviewCase) dtos.ReviewCaseResponse {
+	return dtos.ReviewCaseResponse{
+		ID:             reviewCase.ID,
+		RatingID:       reviewCase.RatingID,
+		RideID:         reviewCase.RideID,
+		SubjectUserID:  reviewCase.SubjectUserID,
+		SubjectRole:    reviewCase.SubjectRole,
+		RatingValue:    reviewCase.RatingValue,
+		Status:         reviewCase.Status,
+		ResolutionNote: reviewCase.ResolutionNote,
+		ResolvedAt:     reviewCase.ResolvedAt,
+		CreatedAt:      reviewCase.CreatedAt,
+	}
+}
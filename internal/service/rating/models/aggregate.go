@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RatingAggregate is the rolling score of a user in one role (captain or passenger)
+type RatingAggregate struct {
+	UserID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Role          string    `gorm:"type:varchar(16);primaryKey" json:"role"`
+	Average       float64   `gorm:"not null;default:0" json:"average"`
+	WindowCount   int       `gorm:"not null;default:0" json:"window_count"`
+	LifetimeCount int64     `gorm:"not null;default:0" json:"lifetime_count"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (RatingAggregate) TableName() string {
+	return "rating_aggregates"
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rater roles
+const (
+	RaterPassenger = "passenger"
+	RaterCaptain   = "captain"
+)
+
+// Tags is a list of feedback tags stored as JSON
+type Tags []string
+
+// Value implements driver.Valuer
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(t)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (t *Tags) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("unsupported tags type %T", value)
+	}
+}
+
+// Rating is one side's feedback on a completed ride
+type Rating struct {
+	ID          uuid.UUID `gorm:"column:rating_id;type:uuid;primaryKey" json:"rating_id"`
+	RideID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_ratings_ride_rater" json:"ride_id"`
+	PassengerID uuid.UUID `gorm:"type:uuid;not null;index" json:"passenger_id"`
+	CaptainID   uuid.UUID `gorm:"type:uuid;not null;index" json:"captain_id"`
+	RaterRole   string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_ratings_ride_rater" json:"rater_role"`
+	RaterID     uuid.UUID `gorm:"type:uuid;not null" json:"rater_id"`
+	RateeID     uuid.UUID `gorm:"type:uuid;not null;index:idx_ratings_ratee_created" json:"ratee_id"`
+	RatingValue int       `gorm:"not null;check:rating_value BETWEEN 1 AND 5" json:"rating_value"`
+	Review      string    `gorm:"type:varchar(500)" json:"review,omitempty"`
+	Tags        Tags      `gorm:"type:jsonb;not null;default:'[]'" json:"tags"`
+	CreatedAt   time.Time `gorm:"column:timestamp;index:idx_ratings_ratee_created" json:"timestamp"`
+}
+
+// TableName overrides the default table name
+func (Rating) TableName() string {
+	return "ratings"
+}
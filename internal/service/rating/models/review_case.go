@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review case statuses
+const (
+	CaseOpen     = "open"
+	CaseResolved = "resolved"
+)
+
+// ReviewCase is an ops follow-up opened automatically for a low rating
+type ReviewCase struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	RatingID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"rating_id"`
+	RideID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"ride_id"`
+	SubjectUserID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"subject_user_id"`
+	SubjectRole    string     `gorm:"type:varchar(16);not null" json:"subject_role"`
+	RatingValue    int        `gorm:"not null" json:"rating_value"`
+	Status         string     `gorm:"type:varchar(16);not null;index" json:"status"`
+	ResolutionNote string     `gorm:"type:varchar(500)" json:"resolution_note,omitempty"`
+	ResolvedBy     *uuid.UUID `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (ReviewCase) TableName() string {
+	return "rating_review_cases"
+}
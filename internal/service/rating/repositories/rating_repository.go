@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"theb-backend/internal/service/rating/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RatingRepository provides data access for ratings, aggregates and review cases
+type RatingRepository struct {
+	db *gorm.DB
+}
+
+// NewRatingRepository creates a new rating repository
+func NewRatingRepository(db *gorm.DB) *RatingRepository {
+	return &RatingRepository{db: db}
+}
+
+// Transaction runs fn inside a database transaction
+func (r *RatingRepository) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
+}
+
+// Create inserts a rating inside tx and reports false if this side already rated the ride
+func (r *RatingRepository) Create(tx *gorm.DB, rating *models.Rating) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(rating)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// RecalculateAggregate recomputes the rolling average of the last n ratings received
+// by a user from raterRole. The aggregate row is locked so concurrent ratings serialise.
+func (r *RatingRepository) RecalculateAggregate(tx *gorm.DB, userID uuid.UUID, role, raterRole string, n int) (*models.RatingAggregate, error) {
+	aggregate := models.RatingAggregate{UserID: userID, Role: role}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&aggregate).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND role = ?", userID, role).
+		First(&aggregate).Error; err != nil {
+		return nil, err
+	}
+
+	var window struct {
+		Average float64
+		Count   int
+	}
+	recent := tx.Model(&models.Rating{}).
+		Select("rating_value").
+		Where("ratee_id = ? AND rater_role = ?", userID, raterRole).
+		Order(`"timestamp" DESC`).
+		Limit(n)
+	if err := tx.Table("(?) AS recent", recent).
+		Select("COALESCE(AVG(rating_value), 0) AS average, COUNT(*) AS count").
+		Scan(&window).Error; err != nil {
+		return nil, err
+	}
+
+	var lifetime int64
+	if err := tx.Model(&models.Rating{}).
+		Where("ratee_id = ? AND rater_role = ?", userID, raterRole).
+		Count(&lifetime).Error; err != nil {
+		return nil, err
+	}
+
+	aggregate.Average = window.Average
+	aggregate.WindowCount = window.Count
+	aggregate.LifetimeCount = lifetime
+	aggregate.UpdatedAt = time.Now().UTC()
+
+	return &aggregate, tx.Save(&aggregate).Error
+}
+
+// CreateReviewCase inserts a review case inside tx
+func (r *RatingRepository) CreateReviewCase(tx *gorm.DB, reviewCase *models.ReviewCase) error {
+	return tx.Create(reviewCase).Error
+}
+
+// FindAggregate returns a user's aggregate for a role, or nil if they have not been rated
+func (r *RatingRepository) FindAggregate(ctx context.Context, userID uuid.UUID, role string) (*models.RatingAggregate, error) {
+	var aggregate models.RatingAggregate
+	err := r.db.WithContext(ctx).Where("user_id = ? AND role = ?", userID, role).First(&aggregate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &aggregate, nil
+}
+
+// FindAggregates returns aggregates for several users in one role
+func (r *RatingRepository) FindAggregates(ctx context.Context, userIDs []uuid.UUID, role string) ([]models.RatingAggregate, error) {
+	var aggregates []models.RatingAggregate
+	err := r.db.WithContext(ctx).Where("user_id IN ? AND role = ?", userIDs, role).Find(&aggregates).Error
+	return aggregates, err
+}
+
+// ListReviewCases returns a page of review cases, newest first, optionally filtered by status
+func (r *RatingRepository) ListReviewCases(ctx context.Context, status string, offset, limit int) ([]models.ReviewCase, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.ReviewCase{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var cases []models.ReviewCase
+	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&cases).Error
+	return cases, total, err
+}
+
+// LockReviewCase loads a review case with a row lock held until tx ends, or nil if it does not exist
+func (r *RatingRepository) LockReviewCase(tx *gorm.DB, id uuid.UUID) (*models.ReviewCase, error) {
+	var reviewCase models.ReviewCase
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&reviewCase).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &reviewCase, nil
+}
+
+// SaveReviewCase persists changes to a review case inside tx
+func (r *RatingRepository) SaveReviewCase(tx *gorm.DB, reviewCase *models.ReviewCase) error {
+	return tx.Save(reviewCase).Error
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"theb-backend/internal/config"
+	captainrepos "theb-backend/internal/service/captain/repositories"
+	ordermodels "theb-backend/internal/service/order/models"
+	orderrepos "theb-backend/internal/service/order/repositories"
+	"theb-backend/internal/service/rating/models"
+	"theb-backend/internal/service/rating/repositories"
+	"theb-backend/pkg/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRideNotFound is returned when the rated ride does not exist
+	ErrRideNotFound = errors.New("ride not found")
+	// ErrRideNotCompleted is returned when rating a ride that has not completed
+	ErrRideNotCompleted = errors.New("ride is not completed")
+	// ErrRatingWindowClosed is returned when the rating window after completion has passed
+	ErrRatingWindowClosed = errors.New("rating window has closed")
+	// ErrNotRideParticipant is returned when the rater was not the ride's passenger or captain
+	ErrNotRideParticipant = errors.New("only the ride's passenger or captain can rate it")
+	// ErrAlreadyRated is returned when this side already rated the ride
+	ErrAlreadyRated = errors.New("ride already rated")
+	// ErrInvalidRating is returned for values outside 1–5
+	ErrInvalidRating = errors.New("rating value must be between 1 and 5")
+	// ErrInvalidTag is returned for tags that do not apply to the rated side
+	ErrInvalidTag = errors.New("invalid feedback tag")
+	// ErrReviewCaseNotFound is returned when a review case does not exist
+	ErrReviewCaseNotFound = errors.New("review case not found")
+	// ErrReviewCaseResolved is returned when resolving a case twice
+	ErrReviewCaseResolved = errors.New("review case already resolved")
+)
+
+// allowedTags lists the feedback tags each rater role may use
+var allowedTags = map[string]map[string]bool{
+	models.RaterPassenger: {
+		"cleanliness":  true,
+		"driving":      true,
+		"route":        true,
+		"punctuality":  true,
+		"friendliness": true,
+	},
+	models.RaterCaptain: {
+		"punctuality": true,
+		"politeness":  true,
+		"cleanliness": true,
+	},
+}
+
+// SubmitInput is a rating submitted by one side of a ride
+type SubmitInput struct {
+	RideID uuid.UUID
+	Value  int
+	Review string
+	Tags   []string
+}
+
+// RatingService handles two-way ride ratings, rolling aggregates and low-rating review cases
+type RatingService struct {
+	cfg      config.RatingsConfig
+	repo     *repositories.RatingRepository
+	rides    *orderrepos.RideRepository
+	captains *captainrepos.CaptainRepository
+}
+
+// NewRatingService creates a new rating service
+func NewRatingService(cfg config.RatingsConfig, repo *repositories.RatingRepository, rides *orderrepos.RideRepository, captains *captainrepos.CaptainRepository) *RatingService {
+	return &RatingService{
+		cfg:      cfg,
+		repo:     repo,
+		rides:    rides,
+		captains: captains,
+	}
+}
+
+// Submit records a rating from raterID for a completed ride and updates the
+// ratee's rolling average. Ratings below the review threshold open a review case.
+func (s *RatingService) Submit(ctx context.Context, raterID uuid.UUID, input SubmitInput) (*models.Rating, error) {
+	if input.Value < 1 || input.Value > 5 {
+		return nil, ErrInvalidRating
+	}
+
+	ride, err := s.rides.FindByID(ctx, input.RideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride == nil {
+		return nil, ErrRideNotFound
+	}
+	if ride.Status != ordermodels.StatusCompleted || ride.CompletedAt == nil || ride.CaptainID == nil {
+		return nil, ErrRideNotCompleted
+	}
+	if time.Now().After(ride.CompletedAt.Add(s.cfg.Window)) {
+		return nil, ErrRatingWindowClosed
+	}
+
+	captain, err := s.captains.FindByID(ctx, *ride.CaptainID)
+	if err != nil {
+		return nil, err
+	}
+	if captain == nil {
+		return nil, ErrRideNotFound
+	}
+
+	rating := &models.Rating{
+		ID:          uuid.New(),
+		RideID:      ride.ID,
+		PassengerID: ride.PassengerID,
+		CaptainID:   captain.ID,
+		RaterID:     raterID,
+		RatingValue: input.Value,
+		Review:      input.Review,
+	}
+
+	var rateeRole string
+	switch raterID {
+	case ride.PassengerID:
+		rating.RaterRole = models.RaterPassenger
+		rating.RateeID = captain.UserID
+		rateeRole = models.RaterCaptain
+	case captain.UserID:
+		rating.RaterRole = models.RaterCaptain
+		rating.RateeID = ride.PassengerID
+		rateeRole = models.RaterPassenger
+	default:
+		return nil, ErrNotRideParticipant
+	}
+
+	tags, err := normaliseTags(rating.RaterRole, input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	rating.Tags = tags
+
+	err = s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		created, err := s.repo.Create(tx, rating)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrAlreadyRated
+		}
+
+		if _, err := s.repo.RecalculateAggregate(tx, rating.RateeID, rateeRole, rating.RaterRole, s.cfg.AverageOverRides); err != nil {
+			return err
+		}
+
+		if rating.RatingValue >= s.cfg.ReviewThreshold {
+			return nil
+		}
+
+		return s.repo.CreateReviewCase(tx, &models.ReviewCase{
+			ID:            uuid.New(),
+			RatingID:      rating.ID,
+			RideID:        rating.RideID,
+			SubjectUserID: rating.RateeID,
+			SubjectRole:   rateeRole,
+			RatingValue:   rating.RatingValue,
+			Status:        models.CaseOpen,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rating, nil
+}
+
+// Summary returns a user's aggregate in a role; unrated users get an empty aggregate
+func (s *RatingService) Summary(ctx context.Context, userID uuid.UUID, role string) (*models.RatingAggregate, error) {
+	aggregate, err := s.repo.FindAggregate(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if aggregate == nil {
+		return &models.RatingAggregate{UserID: userID, Role: role}, nil
+	}
+
+	return aggregate, nil
+}
+
+// CaptainScores returns the rolling average for each rated captain user, for dispatch ranking.
+// Captains without ratings are absent from the map.
+func (s *RatingService) CaptainScores(ctx context.Context, captainUserIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	scores := make(map[uuid.UUID]float64, len(captainUserIDs))
+	if len(captainUserIDs) == 0 {
+		return scores, nil
+	}
+
+	aggregates, err := s.repo.FindAggregates(ctx, captainUserIDs, models.RaterCaptain)
+	if err != nil {
+		return nil, err
+	}
+	for _, aggregate := range aggregates {
+		if aggregate.WindowCount > 0 {
+			scores[aggregate.UserID] = aggregate.Average
+		}
+	}
+
+	return scores, nil
+}
+
+// ListReviewCases returns a page of review cases for ops
+func (s *RatingService) ListReviewCases(ctx context.Context, status string, params pagination.Params) ([]models.ReviewCase, int64, error) {
+	return s.repo.ListReviewCases(ctx, status, params.Offset(), params.PerPage)
+}
+
+// ResolveReviewCase closes a review case with a note
+func (s *RatingService) ResolveReviewCase(ctx context.Context, adminID, caseID uuid.UUID, note string) (*models.ReviewCase, error) {
+	var reviewCase *models.ReviewCase
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		reviewCase, err = s.repo.LockReviewCase(tx, caseID)
+		if err != nil {
+			return err
+		}
+		if reviewCase == nil {
+			return ErrReviewCaseNotFound
+		}
+		if reviewCase.Status == models.CaseResolved {
+			return ErrReviewCaseResolved
+		}
+
+		now := time.Now().UTC()
+		reviewCase.Status = models.CaseResolved
+		reviewCase.ResolutionNote = note
+		reviewCase.ResolvedBy = &adminID
+		reviewCase.ResolvedAt = &now
+
+		return s.repo.SaveReviewCase(tx, reviewCase)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reviewCase, nil
+}
+
+// normaliseTags checks tags against the rater's allowed set and removes duplicates
+func normaliseTags(raterRole string, tags []string) (models.Tags, error) {
+	seen := make(map[string]bool, len(tags))
+	normalised := make(models.Tags, 0, len(tags))
+	for _, tag := range tags {
+		if !allowedTags[raterRole][tag] {
+			return nil, ErrInvalidTag
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalised = append(normalised, tag)
+	}
+
+	return normalised, nil
+}
@@ -0,0 +1,45 @@
+package dtos
+
+import (
+	"time"
+
+	"theb-backend/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+// RequestRideRequest asks for a ride between two points. The payment method
+// defaults to cash; card rides need a card token.
+type RequestRideRequest struct {
+	Pickup        geo.Point `json:"pickup"`
+	Dropoff       geo.Point `json:"dropoff"`
+	PaymentMethod string    `json:"payment_method" binding:"omitempty,oneof=cash wallet card"`
+	CardToken     string    `json:"card_token" binding:"required_if=PaymentMethod card,max=255"`
+} // @name RequestRideRequest
+
+// UpdateRideStatusRequest moves a ride to its next status
+type UpdateRideStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=on_the_way in_progress completed canceled"`
+} // @name UpdateRideStatusRequest
+
+// RideResponse is a ride. Fares are in fils.
+type RideResponse struct {
+	ID            uuid.UUID  `json:"ride_id"`
+	PassengerID   uuid.UUID  `json:"passenger_id"`
+	CaptainID     *uuid.UUID `json:"captain_id,omitempty"`
+	CityID        *uuid.UUID `json:"city_id,omitempty"`
+	Pickup        geo.Point  `json:"pickup"`
+	Dropoff       geo.Point  `json:"dropoff"`
+	Status        string     `json:"status"`
+	PaymentMethod string     `json:"payment_method"`
+	FareEstimate  int64      `json:"fare_estimate"`
+	FareFinal     *int64     `json:"fare_final,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+} // @name RideResponse
+
+// RideHistoryResponse is one page of a user's rides
+type RideHistoryResponse struct {
+	Rides      []RideResponse `json:"rides"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+} // @name RideHistoryResponse
@@ -0,0 +1,150 @@
+package ride
+
+import (
+	"context"
+	"encoding/json"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/events"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/realtime"
+	captainrepos "theb-backend/internal/service/captain/repositories"
+	captains "theb-backend/internal/service/captain/services"
+	orderrepos "theb-backend/internal/service/order/repositories"
+	payments "theb-backend/internal/service/payment/services"
+	pricing "theb-backend/internal/service/pricing/services"
+	ratings "theb-backend/internal/service/rating/services"
+	"theb-backend/internal/service/ride/handlers"
+	"theb-backend/internal/service/ride/models"
+	"theb-backend/internal/service/ride/repositories"
+	"theb-backend/internal/service/ride/services"
+	zones "theb-backend/internal/service/zone/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Module wires the ride lifecycle on top of the rides the order module stores.
+// It is separate from order because requests are quoted by pricing, which itself
+// reads rides for surge.
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "ride" }
+
+// Requires returns the modules whose services ride resolves
+func (Module) Requires() []string {
+	return []string{"captain", "payment", "order", "pricing", "zone", "rating"}
+}
+
+// Migrations returns the ride offer table; rides are migrated by the order module
+func (Module) Migrations() []interface{} { return []interface{}{&models.Offer{}} }
+
+// Register registers the ride and dispatch services and the handler in the container,
+// offers requested rides to captains and moves unanswered offers on
+func (Module) Register(ctn *container.Container) error {
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	rideRepo, err := container.Resolve[*orderrepos.RideRepository](ctn)
+	if err != nil {
+		return err
+	}
+	captainRepo, err := container.Resolve[*captainrepos.CaptainRepository](ctn)
+	if err != nil {
+		return err
+	}
+	presenceService, err := container.Resolve[*captains.PresenceService](ctn)
+	if err != nil {
+		return err
+	}
+	zoneService, err := container.Resolve[*zones.ZoneService](ctn)
+	if err != nil {
+		return err
+	}
+	ratingService, err := container.Resolve[*ratings.RatingService](ctn)
+	if err != nil {
+		return err
+	}
+	hub, err := container.Resolve[*realtime.Hub](ctn)
+	if err != nil {
+		return err
+	}
+	scheduler, err := container.Resolve[*jobs.Scheduler](ctn)
+	if err != nil {
+		return err
+	}
+	bus, err := container.Resolve[*events.Bus](ctn)
+	if err != nil {
+		return err
+	}
+	fareService, err := container.Resolve[*pricing.FareService](ctn)
+	if err != nil {
+		return err
+	}
+	cardPayments, err := container.Resolve[*payments.CardPaymentService](ctn)
+	if err != nil {
+		return err
+	}
+	outbox, err := container.Resolve[*events.Outbox](ctn)
+	if err != nil {
+		return err
+	}
+
+	offerRepo := repositories.NewOfferRepository(db)
+	service := services.NewRideService(rideRepo, offerRepo, captainRepo, fareService, cardPayments, outbox)
+	dispatchService := services.NewDispatchService(rideRepo, offerRepo, captainRepo, presenceService, zoneService, ratingService, hub, scheduler)
+
+	container.Supply(ctn, offerRepo)
+	container.Supply(ctn, service)
+	container.Supply(ctn, dispatchService)
+	container.Supply(ctn, handlers.NewRideHandler(service, dispatchService))
+
+	// The first offer is made in the request event's transaction, so it is made once
+	events.On(bus, "ride.dispatch", func(ctx context.Context, tx *gorm.DB, event events.RideRequested) error {
+		return dispatchService.Offer(ctx, tx, event.RideID)
+	})
+
+	return scheduler.Handle(jobs.TaskHandler{
+		Name: services.OfferExpiryTask,
+		Run: func(ctx context.Context, payload json.RawMessage) error {
+			var expiry services.OfferExpiry
+			if err := json.Unmarshal(payload, &expiry); err != nil {
+				return err
+			}
+			return dispatchService.Expire(ctx, expiry.OfferID)
+		},
+	})
+}
+
+// Jobs returns no jobs
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) { return nil, nil }
+
+// Routes mounts the ride endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	handler, err := container.Resolve[*handlers.RideHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+
+	orders := v1.Group("/orders", auth)
+	{
+		orders.POST("/request", handler.Request)
+		orders.GET("/history", handler.History)
+		orders.GET("/:id", handler.Get)
+		orders.POST("/:id/accept", middleware.RequireRole(middleware.RoleCaptain, middleware.RoleBoth), handler.Accept)
+		orders.POST("/:id/decline", middleware.RequireRole(middleware.RoleCaptain, middleware.RoleBoth), handler.Decline)
+		orders.PUT("/:id/status", handler.UpdateStatus)
+	}
+
+	return nil
+}
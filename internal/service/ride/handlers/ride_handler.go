@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	captains "theb-backend/internal/service/captain/services"
+	"theb-backend/internal/service/order/models"
+	paymentmodels "theb-backend/internal/service/payment/models"
+	payments "theb-backend/internal/service/payment/services"
+	"theb-backend/internal/service/ride/dtos"
+	"theb-backend/internal/service/ride/services"
+	zones "theb-backend/internal/service/zone/services"
+	"theb-backend/pkg/geo"
+	"theb-backend/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RideHandler serves the ride request, offer, status and history endpoints
+type RideHandler struct {
+	service  *services.RideService
+	dispatch *services.DispatchService
+}
+
+// NewRideHandler creates a new ride handler
+func NewRideHandler(service *services.RideService, dispatch *services.DispatchService) *RideHandler {
+	return &RideHandler{service: service, dispatch: dispatch}
+}
+
+// Request creates a ride for the authenticated passenger at the quoted fare
+// @Summary Request a ride
+// @ID orders-request
+// @Tags Order
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.RequestRideRequest true "Pickup, drop-off and payment method"
+// @Success 201 {object} dtos.RideResponse
+// @Router /api/v1/orders/request [post]
+func (h *RideHandler) Request(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.RequestRideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	method := req.PaymentMethod
+	if method == "" {
+		method = paymentmodels.MethodCash
+	}
+
+	ride, err := h.service.Request(c.Request.Context(), userID, services.RequestInput{
+		Pickup:        req.Pickup,
+		Dropoff:       req.Dropoff,
+		PaymentMethod: method,
+		CardToken:     req.CardToken,
+	})
+	if err != nil {
+		h.handleError(c, "Failed to request ride", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toRideResponse(ride))
+}
+
+// Accept assigns a requested ride to the authenticated captain
+// @Summary Accept a ride
+// @ID orders-accept
+// @Tags Order
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Ride ID"
+// @Success 200 {object} dtos.RideResponse
+// @Router /api/v1/orders/{id}/accept [post]
+func (h *RideHandler) Accept(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	rideID, ok := rideParam(c)
+	if !ok {
+		return
+	}
+
+	ride, err := h.service.Accept(c.Request.Context(), userID, middleware.CurrentUserName(c), rideID)
+	if err != nil {
+		h.handleError(c, "Failed to accept ride", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRideResponse(ride))
+}
+
+// Decline turns down the authenticated captain's offer of a ride, which is then offered to the next captain
+// @Summary Decline a ride offer
+// @ID orders-decline
+// @Tags Order
+// @Security BearerAuth
+// @Param id path string true "Ride ID"
+// @Success 204
+// @Router /api/v1/orders/{id}/decline [post]
+func (h *RideHandler) Decline(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	rideID, ok := rideParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.dispatch.Decline(c.Request.Context(), userID, rideID); err != nil {
+		h.handleError(c, "Failed to decline ride", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateStatus moves a ride to its next status. Passengers may only cancel.
+// @Summary Update a ride's status
+// @ID orders-status
+// @Tags Order
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Ride ID"
+// @Param request body dtos.UpdateRideStatusRequest true "New status"
+// @Success 200 {object} dtos.RideResponse
+// @Router /api/v1/orders/{id}/status [put]
+func (h *RideHandler) UpdateStatus(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	rideID, ok := rideParam(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.UpdateRideStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	ride, err := h.service.UpdateStatus(c.Request.Context(), userID, rideID, req.Status)
+	if err != nil {
+		h.handleError(c, "Failed to update ride status", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRideResponse(ride))
+}
+
+// Get returns one of the authenticated user's rides
+// @Summary Get a ride
+// @ID orders-get
+// @Tags Order
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Ride ID"
+// @Success 200 {object} dtos.RideResponse
+// @Router /api/v1/orders/{id} [get]
+func (h *RideHandler) Get(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	rideID, ok := rideParam(c)
+	if !ok {
+		return
+	}
+
+	ride, err := h.service.Get(c.Request.Context(), userID, rideID)
+	if err != nil {
+		h.handleError(c, "Failed to load ride", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRideResponse(ride))
+}
+
+// History returns the rides the authenticated user took or drove, newest first
+// @Summary List ride history
+// @ID orders-history
+// @Tags Order
+// @Security BearerAuth
+// @Produce json
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} dtos.RideHistoryResponse
+// @Router /api/v1/orders/history [get]
+func (h *RideHandler) History(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	params, err := pagination.CursorFromQuery(c)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("cursor", "cursor")))
+		return
+	}
+
+	page, err := h.service.History(c.Request.Context(), userID, params)
+	if err != nil {
+		h.handleError(c, "Failed to load ride history", err)
+		return
+	}
+
+	response := dtos.RideHistoryResponse{
+		Rides:      make([]dtos.RideResponse, 0, len(page.Rides)),
+		NextCursor: page.NextCursor,
+	}
+	for i := range page.Rides {
+		response.Rides = append(response.Rides, toRideResponse(&page.Rides[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *RideHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, zones.ErrPickupOutsideServiceArea):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRidePickupOutOfArea, err))
+	case errors.Is(err, zones.ErrDropoffOutsideServiceArea):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideDropoffOutOfArea, err))
+	case errors.Is(err, zones.ErrRestrictedArea):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideRestrictedArea, err))
+	case errors.Is(err, services.ErrRideNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideNotFound, err))
+	case errors.Is(err, services.ErrInvalidTransition):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideInvalidTransition, err))
+	case errors.Is(err, services.ErrNotRideParticipant):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideNotParticipant, err))
+	case errors.Is(err, services.ErrCaptainOnly):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideCaptainOnly, err))
+	case errors.Is(err, services.ErrOfferedElsewhere):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideOfferedElsewhere, err))
+	case errors.Is(err, services.ErrNoPendingOffer):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideNoPendingOffer, err))
+	case errors.Is(err, payments.ErrCardDeclined):
+		apierror.Abort(c, apierror.Wrap(apierror.CodePaymentCardDeclined, err))
+	case errors.Is(err, payments.ErrNotAuthorized):
+		apierror.Abort(c, apierror.Wrap(apierror.CodePaymentNotAuthorized, err))
+	case errors.Is(err, captains.ErrCaptainNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeCaptainNotFound, err))
+	case errors.Is(err, captains.ErrCaptainOffline):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideCaptainOffline, err))
+	default:
+		logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+			"error": err.Error(),
+		})
+		apierror.Abort(c, apierror.New(apierror.CodeInternal))
+	}
+}
+
+// rideParam parses the ride ID path parameter
+func rideParam(c *gin.Context) (uuid.UUID, bool) {
+	rideID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return uuid.Nil, false
+	}
+	return rideID, true
+}
+
+func toRideResponse(ride *models.Ride) dtos.RideResponse {
+	return dtos.RideResponse{
+		ID:            ride.ID,
+		PassengerID:   ride.PassengerID,
+		CaptainID:     ride.CaptainID,
+		CityID:        ride.CityID,
+		Pickup:        geo.Point{Lat: ride.PickupLat, Lng: ride.PickupLng},
+		Dropoff:       geo.Point{Lat: ride.DropoffLat, Lng: ride.DropoffLng},
+		Status:        ride.Status,
+		PaymentMethod: ride.PaymentMethod,
+		FareEstimate:  ride.FareEstimate,
+		FareFinal:     ride.FareFinal,
+		CompletedAt:   ride.CompletedAt,
+		CreatedAt:     ride.CreatedAt,
+	}
+}
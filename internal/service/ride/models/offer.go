@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Offer statuses
+const (
+	OfferPending  = "pending"
+	OfferAccepted = "accepted"
+	OfferDeclined = "declined"
+	OfferExpired  = "expired"
+)
+
+// Offer is a requested ride offered to one captain until they answer or it expires.
+// A ride has at most one pending offer at a time.
+type Offer struct {
+	ID            uuid.UUID `gorm:"column:offer_id;type:uuid;primaryKey" json:"offer_id"`
+	RideID        uuid.UUID `gorm:"type:uuid;not null;index" json:"ride_id"`
+	CaptainID     uuid.UUID `gorm:"type:uuid;not null;index" json:"captain_id"`
+	CaptainUserID uuid.UUID `gorm:"type:uuid;not null" json:"captain_user_id"`
+	Status        string    `gorm:"type:varchar(16);not null" json:"status"`
+	ExpiresAt     time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Offer) TableName() string {
+	return "ride_offers"
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"errors"
+
+	"theb-backend/internal/service/ride/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OfferRepository provides data access for ride offers
+type OfferRepository struct {
+	db *gorm.DB
+}
+
+// NewOfferRepository creates a new offer repository
+func NewOfferRepository(db *gorm.DB) *OfferRepository {
+	return &OfferRepository{db: db}
+}
+
+// Create inserts an offer
+func (r *OfferRepository) Create(tx *gorm.DB, offer *models.Offer) error {
+	return tx.Create(offer).Error
+}
+
+// Save updates an offer
+func (r *OfferRepository) Save(tx *gorm.DB, offer *models.Offer) error {
+	return tx.Save(offer).Error
+}
+
+// Find returns an offer by ID, or nil if it does not exist
+func (r *OfferRepository) Find(tx *gorm.DB, id uuid.UUID) (*models.Offer, error) {
+	return r.first(tx.Where("offer_id = ?", id))
+}
+
+// LockPending loads a ride's pending offer with a row lock held until tx ends, or nil if none is pending
+func (r *OfferRepository) LockPending(tx *gorm.DB, rideID uuid.UUID) (*models.Offer, error) {
+	return r.first(tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("ride_id = ? AND status = ?", rideID, models.OfferPending))
+}
+
+// OfferedCaptainIDs returns the captains a ride has already been offered to
+func (r *OfferRepository) OfferedCaptainIDs(tx *gorm.DB, rideID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := tx.Model(&models.Offer{}).Where("ride_id = ?", rideID).Pluck("captain_id", &ids).Error
+	return ids, err
+}
+
+// PendingCaptainIDs returns the captains with an offer they have not answered yet
+func (r *OfferRepository) PendingCaptainIDs(tx *gorm.DB) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := tx.Model(&models.Offer{}).Where("status = ?", models.OfferPending).Pluck("captain_id", &ids).Error
+	return ids, err
+}
+
+func (r *OfferRepository) first(query *gorm.DB) (*models.Offer, error) {
+	var offer models.Offer
+	err := query.First(&offer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &offer, nil
+}
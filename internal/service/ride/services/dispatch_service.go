@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/metrics"
+	"theb-backend/internal/realtime"
+	captainrepos "theb-backend/internal/service/captain/repositories"
+	captains "theb-backend/internal/service/captain/services"
+	ordermodels "theb-backend/internal/service/order/models"
+	orderrepos "theb-backend/internal/service/order/repositories"
+	ratings "theb-backend/internal/service/rating/services"
+	"theb-backend/internal/service/ride/models"
+	"theb-backend/internal/service/ride/repositories"
+	zones "theb-backend/internal/service/zone/services"
+	"theb-backend/pkg/geo"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OfferExpiryTask is the delayed task that moves an unanswered offer on to the next captain
+const OfferExpiryTask = "ride_offer_expiry"
+
+const (
+	// ratingWeight is the share of a captain's distance added for each star their
+	// rolling average is below five
+	ratingWeight = 0.2
+	// unratedScore ranks captains who have no ratings yet
+	unratedScore = 4.5
+)
+
+// ErrNoPendingOffer is returned when a captain declines a ride they have no pending offer for
+var ErrNoPendingOffer = errors.New("no pending offer for this ride")
+
+// OfferExpiry is the payload of OfferExpiryTask
+type OfferExpiry struct {
+	OfferID uuid.UUID `json:"offer_id"`
+}
+
+// candidate is a captain who may be offered a ride
+type candidate struct {
+	CaptainID  uuid.UUID
+	UserID     uuid.UUID
+	DistanceKm float64
+	Score      float64
+}
+
+// cost is the candidate's distance, lengthened for a rating below five
+func (c candidate) cost() float64 {
+	return c.DistanceKm * (1 + ratingWeight*(5-c.Score))
+}
+
+// rank orders candidates by cost, nearest first among equals
+func rank(candidates []candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i].cost(), candidates[j].cost()
+		if ci != cj {
+			return ci < cj
+		}
+		return candidates[i].DistanceKm < candidates[j].DistanceKm
+	})
+}
+
+// DispatchService offers requested rides to captains one at a time. Captains within
+// the pickup zone's search radius are ranked by distance and rating; the best one
+// gets the offer until they decline it or its timeout passes, and then the next.
+// A ride no captain can be offered stays open for any captain to accept.
+type DispatchService struct {
+	rides     *orderrepos.RideRepository
+	offers    *repositories.OfferRepository
+	captains  *captainrepos.CaptainRepository
+	presence  *captains.PresenceService
+	zones     *zones.ZoneService
+	ratings   *ratings.RatingService
+	hub       *realtime.Hub
+	scheduler *jobs.Scheduler
+}
+
+// NewDispatchService creates a new dispatch service
+func NewDispatchService(rides *orderrepos.RideRepository, offers *repositories.OfferRepository, captainRepo *captainrepos.CaptainRepository, presence *captains.PresenceService, zoneService *zones.ZoneService, ratingService *ratings.RatingService, hub *realtime.Hub, scheduler *jobs.Scheduler) *DispatchService {
+	return &DispatchService{
+		rides:     rides,
+		offers:    offers,
+		captains:  captainRepo,
+		presence:  presence,
+		zones:     zoneService,
+		ratings:   ratingService,
+		hub:       hub,
+		scheduler: scheduler,
+	}
+}
+
+// Offer offers a requested ride in tx to the best-ranked captain who has not been
+// offered it yet. A ride that is no longer requested or already has a pending offer
+// is left alone, so a redelivered request is not offered twice. The offer's expiry
+// is queued and the captain told before tx commits; both are keyed on the offer, so
+// an offer that rolls back expires without effect.
+func (s *DispatchService) Offer(ctx context.Context, tx *gorm.DB, rideID uuid.UUID) error {
+	ride, err := s.rides.Lock(tx, rideID)
+	if err != nil {
+		return err
+	}
+	if ride == nil || ride.Status != ordermodels.StatusRequested {
+		return nil
+	}
+	pending, err := s.offers.LockPending(tx, rideID)
+	if err != nil || pending != nil {
+		return err
+	}
+
+	pickup := geo.Point{Lat: ride.PickupLat, Lng: ride.PickupLng}
+	params := s.zones.Dispatch(pickup)
+	candidates, err := s.candidates(ctx, tx, rideID, pickup, params)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		logger.FromContext(ctx).Info("No captain to offer the ride to", map[string]interface{}{
+			"ride_id": rideID.String(),
+		})
+		return nil
+	}
+
+	best := candidates[0]
+	offer := &models.Offer{
+		ID:            uuid.New(),
+		RideID:        rideID,
+		CaptainID:     best.CaptainID,
+		CaptainUserID: best.UserID,
+		Status:        models.OfferPending,
+		ExpiresAt:     time.Now().UTC().Add(params.OfferTimeout),
+	}
+	if err := s.offers.Create(tx, offer); err != nil {
+		return err
+	}
+	if err := s.scheduler.Enqueue(ctx, OfferExpiryTask, OfferExpiry{OfferID: offer.ID}, offer.ExpiresAt); err != nil {
+		return err
+	}
+
+	s.send(ctx, ride, offer)
+	return nil
+}
+
+// Expire closes an offer its captain did not answer in time and offers the ride on
+func (s *DispatchService) Expire(ctx context.Context, offerID uuid.UUID) error {
+	var expired bool
+	err := s.rides.Transaction(ctx, func(tx *gorm.DB) error {
+		offer, err := s.offers.Find(tx, offerID)
+		if err != nil || offer == nil {
+			return err
+		}
+
+		// The ride is locked before its offer, as in Offer and Accept
+		if _, err := s.rides.Lock(tx, offer.RideID); err != nil {
+			return err
+		}
+		pending, err := s.offers.LockPending(tx, offer.RideID)
+		if err != nil || pending == nil || pending.ID != offerID {
+			return err
+		}
+
+		pending.Status = models.OfferExpired
+		if err := s.offers.Save(tx, pending); err != nil {
+			return err
+		}
+		expired = true
+		return s.Offer(ctx, tx, offer.RideID)
+	})
+	if err != nil {
+		return err
+	}
+	if expired {
+		metrics.DispatchOffers.WithLabelValues(metrics.OfferExpired).Inc()
+	}
+	return nil
+}
+
+// Decline records that the captain with userID turned down their offer of a ride
+// and offers it on
+func (s *DispatchService) Decline(ctx context.Context, userID, rideID uuid.UUID) error {
+	captain, err := s.captains.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if captain == nil {
+		return captains.ErrCaptainNotFound
+	}
+
+	err = s.rides.Transaction(ctx, func(tx *gorm.DB) error {
+		ride, err := s.rides.Lock(tx, rideID)
+		if err != nil {
+			return err
+		}
+		if ride == nil {
+			return ErrRideNotFound
+		}
+		pending, err := s.offers.LockPending(tx, rideID)
+		if err != nil {
+			return err
+		}
+		if pending == nil || pending.CaptainID != captain.ID {
+			return ErrNoPendingOffer
+		}
+
+		pending.Status = models.OfferDeclined
+		if err := s.offers.Save(tx, pending); err != nil {
+			return err
+		}
+		return s.Offer(ctx, tx, rideID)
+	})
+	if err != nil {
+		return err
+	}
+	metrics.DispatchOffers.WithLabelValues(metrics.OfferDeclined).Inc()
+	return nil
+}
+
+// candidates returns the present, online captains in the pickup's city and search
+// radius that are free and have not been offered the ride, best first
+func (s *DispatchService) candidates(ctx context.Context, tx *gorm.DB, rideID uuid.UUID, pickup geo.Point, params zones.DispatchParams) ([]candidate, error) {
+	present, err := s.presence.Available(ctx)
+	if err != nil {
+		return nil, err
+	}
+	offered, err := s.offers.OfferedCaptainIDs(tx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	waiting, err := s.offers.PendingCaptainIDs(tx)
+	if err != nil {
+		return nil, err
+	}
+	busy, err := s.rides.BusyCaptainIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[uuid.UUID]bool, len(offered)+len(waiting)+len(busy))
+	for _, ids := range [][]uuid.UUID{offered, waiting, busy} {
+		for _, id := range ids {
+			skip[id] = true
+		}
+	}
+
+	distances := make(map[uuid.UUID]float64)
+	var ids []uuid.UUID
+	for _, p := range present {
+		if skip[p.CaptainID] {
+			continue
+		}
+		distance := geo.DistanceKm(pickup, p.Point)
+		if distance > params.SearchRadiusKm {
+			continue
+		}
+		distances[p.CaptainID] = distance
+		ids = append(ids, p.CaptainID)
+	}
+
+	online, err := s.captains.FindOnline(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uuid.UUID, 0, len(online))
+	for _, captain := range online {
+		userIDs = append(userIDs, captain.UserID)
+	}
+	scores, err := s.ratings.CaptainScores(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]candidate, 0, len(online))
+	for _, captain := range online {
+		// Captains assigned to a city are only offered rides there
+		if captain.CityID != nil && *captain.CityID != params.CityID {
+			continue
+		}
+		score, ok := scores[captain.UserID]
+		if !ok {
+			score = unratedScore
+		}
+		candidates = append(candidates, candidate{
+			CaptainID:  captain.ID,
+			UserID:     captain.UserID,
+			DistanceKm: distances[captain.ID],
+			Score:      score,
+		})
+	}
+
+	rank(candidates)
+	return candidates, nil
+}
+
+// send tells the captain about their offer. It is not retried: if the captain
+// misses it, the offer expires and the ride moves on to the next captain.
+func (s *DispatchService) send(ctx context.Context, ride *ordermodels.Ride, offer *models.Offer) {
+	err := s.hub.Send(ctx, offer.CaptainUserID, realtime.Event{
+		Type: "ride_offer",
+		Data: map[string]interface{}{
+			"offer_id":      offer.ID,
+			"ride_id":       ride.ID,
+			"pickup":        geo.Point{Lat: ride.PickupLat, Lng: ride.PickupLng},
+			"dropoff":       geo.Point{Lat: ride.DropoffLat, Lng: ride.DropoffLng},
+			"fare_estimate": ride.FareEstimate,
+			"expires_at":    offer.ExpiresAt,
+		},
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to send ride offer", map[string]interface{}{
+			"error":    err.Error(),
+			"ride_id":  ride.ID.String(),
+			"offer_id": offer.ID.String(),
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRank(t *testing.T) {
+	near, far := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name       string
+		candidates []candidate
+		want       uuid.UUID
+	}{
+		{
+			name: "nearest first at equal scores",
+			candidates: []candidate{
+				{CaptainID: far, DistanceKm: 3, Score: 4.8},
+				{CaptainID: near, DistanceKm: 1, Score: 4.8},
+			},
+			want: near,
+		},
+		{
+			name: "a much better rated captain a little further away goes first",
+			candidates: []candidate{
+				{CaptainID: near, DistanceKm: 1, Score: 2},
+				{CaptainID: far, DistanceKm: 1.3, Score: 5},
+			},
+			want: far,
+		},
+		{
+			name: "distance outweighs a small rating gap",
+			candidates: []candidate{
+				{CaptainID: far, DistanceKm: 4, Score: 5},
+				{CaptainID: near, DistanceKm: 1, Score: 4},
+			},
+			want: near,
+		},
+		{
+			name: "an unrated captain ranks as the unrated score",
+			candidates: []candidate{
+				{CaptainID: far, DistanceKm: 1, Score: 4},
+				{CaptainID: near, DistanceKm: 1, Score: unratedScore},
+			},
+			want: near,
+		},
+		{
+			name: "a captain at the pickup goes first whatever their rating",
+			candidates: []candidate{
+				{CaptainID: far, DistanceKm: 0.5, Score: 5},
+				{CaptainID: near, DistanceKm: 0, Score: 1},
+			},
+			want: near,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank(tt.candidates)
+			if got := tt.candidates[0].CaptainID; got != tt.want {
+				t.Errorf("rank() put %s first, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"theb-backend/internal/events"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/metrics"
+	captainmodels "theb-backend/internal/service/captain/models"
+	captainrepos "theb-backend/internal/service/captain/repositories"
+	captains "theb-backend/internal/service/captain/services"
+	"theb-backend/internal/service/order/models"
+	"theb-backend/internal/service/order/repositories"
+	paymentmodels "theb-backend/internal/service/payment/models"
+	payments "theb-backend/internal/service/payment/services"
+	pricing "theb-backend/internal/service/pricing/services"
+	ridemodels "theb-backend/internal/service/ride/models"
+	riderepos "theb-backend/internal/service/ride/repositories"
+	"theb-backend/pkg/geo"
+	"theb-backend/pkg/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRideNotFound is returned when a ride does not exist
+	ErrRideNotFound = errors.New("ride not found")
+	// ErrInvalidTransition is returned when a ride cannot move to the requested status
+	ErrInvalidTransition = errors.New("invalid ride status transition")
+	// ErrNotRideParticipant is returned when a user other than the ride's passenger or captain reads or changes it
+	ErrNotRideParticipant = errors.New("only the ride's passenger or captain can access it")
+	// ErrCaptainOnly is returned when the passenger moves a ride to a status only its captain may set
+	ErrCaptainOnly = errors.New("only the ride's captain can move it to that status")
+	// ErrOfferedElsewhere is returned when a captain accepts a ride that is offered to another captain
+	ErrOfferedElsewhere = errors.New("ride is offered to another captain")
+)
+
+// transitions lists the statuses each status may move to through UpdateStatus.
+// Requested rides are matched through Accept.
+var transitions = map[string][]string{
+	models.StatusRequested:  {models.StatusCanceled},
+	models.StatusMatched:    {models.StatusOnTheWay, models.StatusCanceled},
+	models.StatusOnTheWay:   {models.StatusInProgress, models.StatusCanceled},
+	models.StatusInProgress: {models.StatusCompleted},
+}
+
+// RequestInput is a passenger's ride request. CardToken is required for card rides.
+type RequestInput struct {
+	Pickup        geo.Point
+	Dropoff       geo.Point
+	PaymentMethod string
+	CardToken     string
+}
+
+// Page is one page of a user's ride history
+type Page struct {
+	Rides      []models.Ride
+	NextCursor string
+}
+
+// RideService runs the ride lifecycle: passengers request rides, captains accept
+// them and move them through their statuses. Every change records its domain
+// event in the same transaction.
+type RideService struct {
+	rides    *repositories.RideRepository
+	offers   *riderepos.OfferRepository
+	captains *captainrepos.CaptainRepository
+	fares    *pricing.FareService
+	payments *payments.CardPaymentService
+	outbox   *events.Outbox
+}
+
+// NewRideService creates a new ride service
+func NewRideService(rides *repositories.RideRepository, offers *riderepos.OfferRepository, captainRepo *captainrepos.CaptainRepository, fares *pricing.FareService, cardPayments *payments.CardPaymentService, outbox *events.Outbox) *RideService {
+	return &RideService{
+		rides:    rides,
+		offers:   offers,
+		captains: captainRepo,
+		fares:    fares,
+		payments: cardPayments,
+		outbox:   outbox,
+	}
+}
+
+// Request quotes and creates a ride for a passenger. The ride must be allowed by
+// the zones; its estimate is the quoted fare. A card ride's fare is authorized
+// first, under the ride's ID, so a declined card or one that is not authorized
+// at once leaves no ride behind.
+func (s *RideService) Request(ctx context.Context, passengerID uuid.UUID, input RequestInput) (*models.Ride, error) {
+	quote, err := s.fares.Estimate(ctx, input.Pickup, input.Dropoff)
+	if err != nil {
+		return nil, err
+	}
+
+	ride := &models.Ride{
+		ID:            uuid.New(),
+		PassengerID:   passengerID,
+		PickupLat:     input.Pickup.Lat,
+		PickupLng:     input.Pickup.Lng,
+		DropoffLat:    input.Dropoff.Lat,
+		DropoffLng:    input.Dropoff.Lng,
+		Status:        models.StatusRequested,
+		FareEstimate:  quote.Fare,
+		PaymentMethod: input.PaymentMethod,
+	}
+	if quote.CityID != uuid.Nil {
+		ride.CityID = &quote.CityID
+	}
+
+	card := ride.PaymentMethod == paymentmodels.MethodCard
+	if card {
+		payment, err := s.payments.AuthorizeRide(ctx, payments.AuthorizeRideInput{
+			RideID:       ride.ID,
+			PassengerID:  passengerID,
+			CardToken:    input.CardToken,
+			FareEstimate: ride.FareEstimate,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// A hold still waiting on 3-D Secure or the gateway may never be
+		// capturable, so the ride is not offered to captains on it
+		if payment.Status != paymentmodels.StatusAuthorized {
+			s.void(ctx, ride.ID)
+			return nil, payments.ErrNotAuthorized
+		}
+	}
+
+	err = s.rides.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := s.rides.Create(tx, ride); err != nil {
+			return err
+		}
+		return s.outbox.Add(tx, ride.ID, events.RideRequested{
+			RideID:       ride.ID,
+			PassengerID:  ride.PassengerID,
+			PickupLat:    ride.PickupLat,
+			PickupLng:    ride.PickupLng,
+			DropoffLat:   ride.DropoffLat,
+			DropoffLng:   ride.DropoffLng,
+			FareEstimate: ride.FareEstimate,
+		})
+	})
+	if err != nil {
+		if card {
+			s.void(ctx, ride.ID)
+		}
+		return nil, err
+	}
+
+	return ride, nil
+}
+
+// Accept assigns a requested ride to the captain with userID. Only online captains
+// accept rides. A ride with a pending offer is matched only to the captain it is
+// offered to; one that could not be offered goes to the first captain who accepts.
+// captainName is shown to the passenger.
+func (s *RideService) Accept(ctx context.Context, userID uuid.UUID, captainName string, rideID uuid.UUID) (*models.Ride, error) {
+	captain, err := s.captains.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if captain == nil {
+		return nil, captains.ErrCaptainNotFound
+	}
+	if !captain.IsOnline {
+		return nil, captains.ErrCaptainOffline
+	}
+
+	var ride *models.Ride
+	var offered bool
+	err = s.rides.Transaction(ctx, func(tx *gorm.DB) error {
+		ride, err = s.rides.Lock(tx, rideID)
+		if err != nil {
+			return err
+		}
+		if ride == nil {
+			return ErrRideNotFound
+		}
+		if ride.Status != models.StatusRequested {
+			return fmt.Errorf("%w: ride is %s", ErrInvalidTransition, ride.Status)
+		}
+
+		offer, err := s.offers.LockPending(tx, rideID)
+		if err != nil {
+			return err
+		}
+		if offer != nil {
+			if offer.CaptainID != captain.ID {
+				return ErrOfferedElsewhere
+			}
+			offer.Status = ridemodels.OfferAccepted
+			if err := s.offers.Save(tx, offer); err != nil {
+				return err
+			}
+			offered = true
+		}
+
+		ride.CaptainID = &captain.ID
+		ride.Status = models.StatusMatched
+		if err := s.rides.Save(tx, ride); err != nil {
+			return err
+		}
+
+		return s.outbox.Add(tx, ride.ID, events.RideMatched{
+			RideID:        ride.ID,
+			PassengerID:   ride.PassengerID,
+			CaptainID:     captain.ID,
+			CaptainUserID: captain.UserID,
+			CaptainName:   captainName,
+			Vehicle:       vehicle(captain),
+			RequestedAt:   ride.CreatedAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if offered {
+		metrics.DispatchOffers.WithLabelValues(metrics.OfferAccepted).Inc()
+	}
+
+	return ride, nil
+}
+
+// UpdateStatus moves a ride on through its lifecycle. The passenger may only cancel
+// it, before it starts; every other change is made by its captain. A completed
+// ride's final fare is its estimate, since trips are not metered yet. Cancelling a
+// card ride releases its authorization.
+func (s *RideService) UpdateStatus(ctx context.Context, userID, rideID uuid.UUID, status string) (*models.Ride, error) {
+	captain, err := s.captains.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ride *models.Ride
+	err = s.rides.Transaction(ctx, func(tx *gorm.DB) error {
+		ride, err = s.rides.Lock(tx, rideID)
+		if err != nil {
+			return err
+		}
+		if ride == nil {
+			return ErrRideNotFound
+		}
+
+		isCaptain := captain != nil && ride.CaptainID != nil && *ride.CaptainID == captain.ID
+		if ride.PassengerID != userID && !isCaptain {
+			return ErrNotRideParticipant
+		}
+		if !canTransition(ride.Status, status) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, ride.Status, status)
+		}
+		if !isCaptain && status != models.StatusCanceled {
+			return ErrCaptainOnly
+		}
+
+		ride.Status = status
+		if status != models.StatusCompleted {
+			return s.rides.Save(tx, ride)
+		}
+
+		fare := ride.FareEstimate
+		now := time.Now().UTC()
+		ride.FareFinal = &fare
+		ride.CompletedAt = &now
+		if err := s.rides.Save(tx, ride); err != nil {
+			return err
+		}
+
+		return s.outbox.Add(tx, ride.ID, events.RideCompleted{
+			RideID:        ride.ID,
+			PassengerID:   ride.PassengerID,
+			CaptainID:     captain.ID,
+			CaptainUserID: captain.UserID,
+			Fare:          fare,
+			PaymentMethod: ride.PaymentMethod,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ride.Status == models.StatusCanceled && ride.PaymentMethod == paymentmodels.MethodCard {
+		s.void(ctx, ride.ID)
+	}
+
+	return ride, nil
+}
+
+// Get returns a ride to its passenger or captain
+func (s *RideService) Get(ctx context.Context, userID, rideID uuid.UUID) (*models.Ride, error) {
+	ride, err := s.rides.FindByID(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride == nil {
+		return nil, ErrRideNotFound
+	}
+	if ride.PassengerID == userID {
+		return ride, nil
+	}
+
+	captain, err := s.captains.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if captain == nil || ride.CaptainID == nil || *ride.CaptainID != captain.ID {
+		return nil, ErrNotRideParticipant
+	}
+
+	return ride, nil
+}
+
+// History returns a page of the rides the user took or drove, newest first
+func (s *RideService) History(ctx context.Context, userID uuid.UUID, params pagination.CursorParams) (*Page, error) {
+	captain, err := s.captains.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var captainID *uuid.UUID
+	if captain != nil {
+		captainID = &captain.ID
+	}
+
+	rides, err := s.rides.History(ctx, userID, captainID, params.After, params.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Rides: rides}
+	if len(rides) > params.Limit {
+		page.Rides = rides[:params.Limit]
+		last := page.Rides[params.Limit-1]
+		page.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	return page, nil
+}
+
+// void releases a card ride's authorization after the ride is cancelled or could
+// not be created. It runs outside the ride's transaction, since it calls the
+// gateway; a failure is logged for follow-up rather than undoing the ride change.
+func (s *RideService) void(ctx context.Context, rideID uuid.UUID) {
+	if _, err := s.payments.VoidRide(ctx, rideID); err != nil {
+		logger.FromContext(ctx).Error("Failed to void card authorization", map[string]interface{}{
+			"error":   err.Error(),
+			"ride_id": rideID.String(),
+		})
+	}
+}
+
+// canTransition reports whether a ride may move from one status to another
+func canTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// vehicle describes a captain's car to the passenger, e.g. "Toyota Corolla"
+func vehicle(captain *captainmodels.Captain) string {
+	if captain.VehicleModel != "" {
+		return captain.VehicleModel
+	}
+	return captain.VehicleType
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+
+	"theb-backend/internal/service/order/models"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     bool
+	}{
+		{name: "requested rides can be canceled", from: models.StatusRequested, to: models.StatusCanceled, want: true},
+		{name: "requested rides are matched only by accepting", from: models.StatusRequested, to: models.StatusMatched},
+		{name: "matched captain sets off", from: models.StatusMatched, to: models.StatusOnTheWay, want: true},
+		{name: "matched rides cannot skip to in progress", from: models.StatusMatched, to: models.StatusInProgress},
+		{name: "pickup starts the trip", from: models.StatusOnTheWay, to: models.StatusInProgress, want: true},
+		{name: "on the way can be canceled", from: models.StatusOnTheWay, to: models.StatusCanceled, want: true},
+		{name: "trip completes", from: models.StatusInProgress, to: models.StatusCompleted, want: true},
+		{name: "started trips cannot be canceled", from: models.StatusInProgress, to: models.StatusCanceled},
+		{name: "completed rides are final", from: models.StatusCompleted, to: models.StatusCanceled},
+		{name: "canceled rides are final", from: models.StatusCanceled, to: models.StatusOnTheWay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("canTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UpdateSettingRequest sets a value now. Value is a JSON number, boolean or string
+// matching the setting's type; durations are strings such as "30s".
+type UpdateSettingRequest struct {
+	Value  json.RawMessage `json:"value" binding:"required" swaggertype:"string"`
+	Reason string          `json:"reason" binding:"required,max=500"`
+} // @name UpdateSettingRequest
+
+// ScheduleSettingRequest sets a value from EffectiveAt on
+type ScheduleSettingRequest struct {
+	Value       json.RawMessage `json:"value" binding:"required" swaggertype:"string"`
+	EffectiveAt time.Time       `json:"effective_at" binding:"required"`
+	Reason      string          `json:"reason" binding:"required,max=500"`
+} // @name ScheduleSettingRequest
+
+// SettingResponse is a setting's schema and current value
+type SettingResponse struct {
+	Key         string      `json:"key"`
+	Type        string      `json:"type"`
+	Value       interface{} `json:"value"`
+	Default     interface{} `json:"default"`
+	Min         *float64    `json:"min,omitempty"`
+	Max         *float64    `json:"max,omitempty"`
+	Description string      `json:"description"`
+	IsDefault   bool        `json:"is_default"`
+	UpdatedBy   *uuid.UUID  `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time  `json:"updated_at,omitempty"`
+} // @name SettingResponse
+
+// SettingListResponse lists every setting
+type SettingListResponse struct {
+	Settings []SettingResponse `json:"settings"`
+} // @name SettingListResponse
+
+// SettingDetailResponse is a setting with its pending scheduled changes
+type SettingDetailResponse struct {
+	SettingResponse
+	Scheduled []ScheduledChangeResponse `json:"scheduled"`
+} // @name SettingDetailResponse
+
+// ScheduledChangeResponse is a change applied at EffectiveAt
+type ScheduledChangeResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Key         string     `json:"key"`
+	Value       string     `json:"value"`
+	EffectiveAt time.Time  `json:"effective_at"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason,omitempty"`
+	CreatedBy   uuid.UUID  `json:"created_by"`
+	Error       string     `json:"error,omitempty"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+} // @name ScheduledChangeResponse
+
+// SettingChangeResponse is one audit trail entry
+type SettingChangeResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	OldValue   string     `json:"old_value"`
+	NewValue   string     `json:"new_value"`
+	Source     string     `json:"source"`
+	ChangedBy  uuid.UUID  `json:"changed_by"`
+	ScheduleID *uuid.UUID `json:"schedule_id,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+} // @name SettingChangeResponse
+
+// SettingHistoryResponse is a page of a setting's audit trail
+type SettingHistoryResponse struct {
+	Changes []SettingChangeResponse `json:"changes"`
+	Page    int                     `json:"page"`
+	PerPage int                     `json:"per_page"`
+	Total   int64                   `json:"total"`
+} // @name SettingHistoryResponse
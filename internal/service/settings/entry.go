@@ -0,0 +1,99 @@
+package settings
+
+import (
+	"context"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/settings/handlers"
+	"theb-backend/internal/service/settings/models"
+	"theb-backend/internal/service/settings/repositories"
+	"theb-backend/internal/service/settings/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// Module wires runtime-reloadable settings such as tariffs
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "settings" }
+
+// Requires returns no modules; settings uses only the core services
+func (Module) Requires() []string { return nil }
+
+// Migrations returns the settings tables
+func (Module) Migrations() []interface{} {
+	return []interface{}{&models.AppSetting{}, &models.SettingChange{}, &models.ScheduledChange{}}
+}
+
+// Register registers the settings repository, service and handler in the container.
+// Settings are loaded when the container starts, before other modules' workers.
+func (Module) Register(ctn *container.Container) error {
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	redisClient, err := container.Resolve[*redis.Client](ctn)
+	if err != nil {
+		return err
+	}
+
+	repo := repositories.NewSettingsRepository(db)
+	service := services.NewSettingsService(repo, redisClient)
+
+	container.Supply(ctn, repo)
+	container.Supply(ctn, service,
+		container.OnStart(func(ctx context.Context, service *services.SettingsService) error {
+			return service.Start(ctx)
+		}),
+		container.OnStop(func(ctx context.Context, service *services.SettingsService) error {
+			return service.Stop(ctx)
+		}),
+	)
+	container.Supply(ctn, handlers.NewSettingsHandler(service))
+
+	return nil
+}
+
+// Jobs returns the job applying scheduled setting changes
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) {
+	service, err := container.Resolve[*services.SettingsService](ctn)
+	if err != nil {
+		return nil, err
+	}
+
+	return []jobs.CronJob{{
+		Name:     "settings_apply_scheduled",
+		Schedule: "@every 1m",
+		Run:      service.ApplyDue,
+	}}, nil
+}
+
+// Routes mounts the admin settings endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	handler, err := container.Resolve[*handlers.SettingsHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	admin := v1.Group("/admin/settings", middleware.AuthMiddleware(cfg.JWT.Secret), middleware.RequireRole(middleware.RoleAdmin))
+	{
+		admin.GET("", handler.List)
+		admin.GET("/:key", handler.Get)
+		admin.PUT("/:key", handler.Update)
+		admin.GET("/:key/history", handler.History)
+		admin.POST("/:key/schedules", handler.Schedule)
+		admin.POST("/schedules/:id/cancel", handler.CancelSchedule)
+	}
+
+	return nil
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	"theb-backend/internal/service/settings/dtos"
+	"theb-backend/internal/service/settings/models"
+	"theb-backend/internal/service/settings/services"
+	"theb-backend/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SettingsHandler serves the admin settings endpoints
+type SettingsHandler struct {
+	service *services.SettingsService
+}
+
+// NewSettingsHandler creates a new settings handler
+func NewSettingsHandler(service *services.SettingsService) *SettingsHandler {
+	return &SettingsHandler{service: service}
+}
+
+// List returns every setting with its schema and current value
+// @Summary List runtime settings (admin)
+// @ID admin-settings-list
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dtos.SettingListResponse
+// @Router /api/v1/admin/settings [get]
+func (h *SettingsHandler) List(c *gin.Context) {
+	settings, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, "Failed to load settings", err)
+		return
+	}
+
+	response := dtos.SettingListResponse{Settings: make([]dtos.SettingResponse, 0, len(settings))}
+	for _, setting := range settings {
+		response.Settings = append(response.Settings, toSettingResponse(setting))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get returns one setting and its pending scheduled changes
+// @Summary Get a runtime setting (admin)
+// @ID admin-settings-get
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param key path string true "Setting key"
+// @Success 200 {object} dtos.SettingDetailResponse
+// @Router /api/v1/admin/settings/{key} [get]
+func (h *SettingsHandler) Get(c *gin.Context) {
+	setting, schedules, err := h.service.Get(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		h.handleError(c, "Failed to load setting", err)
+		return
+	}
+
+	response := dtos.SettingDetailResponse{
+		SettingResponse: toSettingResponse(*setting),
+		Scheduled:       make([]dtos.ScheduledChangeResponse, 0, len(schedules)),
+	}
+	for i := range schedules {
+		response.Scheduled = append(response.Scheduled, toScheduledChangeResponse(&schedules[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Update changes a setting now
+// @Summary Update a runtime setting (admin)
+// @ID admin-settings-update
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param key path string true "Setting key"
+// @Param request body dtos.UpdateSettingRequest true "New value and reason"
+// @Success 200 {object} dtos.SettingResponse
+// @Router /api/v1/admin/settings/{key} [put]
+func (h *SettingsHandler) Update(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.UpdateSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	setting, err := h.service.Update(c.Request.Context(), adminID, c.Param("key"), rawValue(req.Value), req.Reason)
+	if err != nil {
+		h.handleError(c, "Failed to update setting", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSettingResponse(*setting))
+}
+
+// History returns the audit trail of a setting
+// @Summary List a setting's change history (admin)
+// @ID admin-settings-history
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param key path string true "Setting key"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} dtos.SettingHistoryResponse
+// @Router /api/v1/admin/settings/{key}/history [get]
+func (h *SettingsHandler) History(c *gin.Context) {
+	params := pagination.FromQuery(c)
+	changes, total, err := h.service.History(c.Request.Context(), c.Param("key"), params)
+	if err != nil {
+		h.handleError(c, "Failed to load setting history", err)
+		return
+	}
+
+	response := dtos.SettingHistoryResponse{
+		Changes: make([]dtos.SettingChangeResponse, 0, len(changes)),
+		Page:    params.Page,
+		PerPage: params.PerPage,
+		Total:   total,
+	}
+	for _, change := range changes {
+		response.Changes = append(response.Changes, dtos.SettingChangeResponse{
+			ID:         change.ID,
+			OldValue:   change.OldValue,
+			NewValue:   change.NewValue,
+			Source:     change.Source,
+			ChangedBy:  change.ChangedBy,
+			ScheduleID: change.ScheduleID,
+			Reason:     change.Reason,
+			CreatedAt:  change.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Schedule stores a change that takes effect later, e.g. a new tariff
+// @Summary Schedule a setting change (admin)
+// @ID admin-settings-schedule
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param key path string true "Setting key"
+// @Param request body dtos.ScheduleSettingRequest true "Value, effective time and reason"
+// @Success 201 {object} dtos.ScheduledChangeResponse
+// @Router /api/v1/admin/settings/{key}/schedules [post]
+func (h *SettingsHandler) Schedule(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.ScheduleSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	schedule, err := h.service.Schedule(c.Request.Context(), adminID, c.Param("key"), rawValue(req.Value), req.EffectiveAt, req.Reason)
+	if err != nil {
+		h.handleError(c, "Failed to schedule setting change", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toScheduledChangeResponse(schedule))
+}
+
+// CancelSchedule cancels a pending scheduled change
+// @Summary Cancel a scheduled setting change (admin)
+// @ID admin-settings-schedule-cancel
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Scheduled change ID"
+// @Success 200 {object} dtos.ScheduledChangeResponse
+// @Router /api/v1/admin/settings/schedules/{id}/cancel [post]
+func (h *SettingsHandler) CancelSchedule(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return
+	}
+
+	schedule, err := h.service.CancelSchedule(c.Request.Context(), adminID, scheduleID)
+	if err != nil {
+		h.handleError(c, "Failed to cancel scheduled change", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toScheduledChangeResponse(schedule))
+}
+
+func (h *SettingsHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidValue):
+		// The cause says which bound or type the value broke, which admins need
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettingInvalidValue, err).WithDetails(apierror.FieldError{
+			Field:   "value",
+			Rule:    "setting",
+			Message: err.Error(),
+		}))
+	case errors.Is(err, services.ErrScheduleInPast):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettingScheduleInPast, err))
+	case errors.Is(err, services.ErrUnknownSetting):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettingNotFound, err))
+	case errors.Is(err, services.ErrScheduleNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettingScheduleNotFound, err))
+	case errors.Is(err, services.ErrScheduleNotPending):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeSettingScheduleNotPending, err))
+	default:
+		logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+			"error": err.Error(),
+		})
+		apierror.Abort(c, apierror.New(apierror.CodeInternal))
+	}
+}
+
+// rawValue returns a JSON value as the text stored for it: strings unquoted,
+// numbers and booleans as written
+func rawValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// typedValue returns a stored value as JSON-friendly typed data; durations stay strings
+func typedValue(def services.Definition, value string) interface{} {
+	parsed, err := def.Parse(value)
+	if err != nil {
+		return value
+	}
+	if d, ok := parsed.(time.Duration); ok {
+		return d.String()
+	}
+	return parsed
+}
+
+func toSettingResponse(setting services.Setting) dtos.SettingResponse {
+	return dtos.SettingResponse{
+		Key:         setting.Key,
+		Type:        setting.Type,
+		Value:       typedValue(setting.Definition, setting.Value),
+		Default:     typedValue(setting.Definition, setting.Default),
+		Min:         setting.Min,
+		Max:         setting.Max,
+		Description: setting.Description,
+		IsDefault:   setting.IsDefault,
+		UpdatedBy:   setting.UpdatedBy,
+		UpdatedAt:   setting.UpdatedAt,
+	}
+}
+
+func toScheduledChangeResponse(schedule *models.ScheduledChange) dtos.ScheduledChangeResponse {
+	return dtos.ScheduledChangeResponse{
+		ID:          schedule.ID,
+		Key:         schedule.Key,
+		Value:       schedule.Value,
+		EffectiveAt: schedule.EffectiveAt,
+		Status:      schedule.Status,
+		Reason:      schedule.Reason,
+		CreatedBy:   schedule.CreatedBy,
+		Error:       schedule.Error,
+		AppliedAt:   schedule.AppliedAt,
+		CancelledAt: schedule.CancelledAt,
+		CreatedAt:   schedule.CreatedAt,
+	}
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Change sources recorded in the audit trail
+const (
+	SourceAdmin    = "admin"
+	SourceSchedule = "schedule"
+)
+
+// Scheduled change statuses
+const (
+	SchedulePending   = "pending"
+	ScheduleApplied   = "applied"
+	ScheduleCancelled = "cancelled"
+	// ScheduleFailed marks a change whose value no longer fits the setting's schema when due
+	ScheduleFailed = "failed"
+)
+
+// AppSetting is a runtime setting overriding its schema default
+type AppSetting struct {
+	ID        uuid.UUID  `gorm:"column:setting_id;type:uuid;primaryKey" json:"setting_id"`
+	Key       string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"key"`
+	Value     string     `gorm:"type:text;not null" json:"value"`
+	UpdatedBy *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (AppSetting) TableName() string {
+	return "app_settings"
+}
+
+// SettingChange is one entry of the settings audit trail
+type SettingChange struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Key        string     `gorm:"type:varchar(64);not null;index:idx_setting_changes_key_created" json:"key"`
+	OldValue   string     `gorm:"type:text;not null" json:"old_value"`
+	NewValue   string     `gorm:"type:text;not null" json:"new_value"`
+	Source     string     `gorm:"type:varchar(16);not null" json:"source"`
+	ChangedBy  uuid.UUID  `gorm:"type:uuid;not null;index" json:"changed_by"`
+	ScheduleID *uuid.UUID `gorm:"type:uuid" json:"schedule_id,omitempty"`
+	Reason     string     `gorm:"type:varchar(500)" json:"reason,omitempty"`
+	CreatedAt  time.Time  `gorm:"index:idx_setting_changes_key_created" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (SettingChange) TableName() string {
+	return "app_setting_changes"
+}
+
+// ScheduledChange sets a value when EffectiveAt is reached, e.g. a new tariff
+type ScheduledChange struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Key         string     `gorm:"type:varchar(64);not null;index" json:"key"`
+	Value       string     `gorm:"type:text;not null" json:"value"`
+	EffectiveAt time.Time  `gorm:"not null;index:idx_setting_schedules_due" json:"effective_at"`
+	Status      string     `gorm:"type:varchar(16);not null;index:idx_setting_schedules_due" json:"status"`
+	Reason      string     `gorm:"type:varchar(500)" json:"reason,omitempty"`
+	CreatedBy   uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CancelledBy *uuid.UUID `gorm:"type:uuid" json:"cancelled_by,omitempty"`
+	Error       string     `gorm:"type:varchar(500)" json:"error,omitempty"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (ScheduledChange) TableName() string {
+	return "app_setting_schedules"
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"theb-backend/internal/service/settings/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SettingsRepository provides data access for settings, their audit trail and scheduled changes
+type SettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewSettingsRepository creates a new settings repository
+func NewSettingsRepository(db *gorm.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// Transaction runs fn inside a database transaction
+func (r *SettingsRepository) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
+}
+
+// All returns every stored setting
+func (r *SettingsRepository) All(ctx context.Context) ([]models.AppSetting, error) {
+	var settings []models.AppSetting
+	err := r.db.WithContext(ctx).Order("key").Find(&settings).Error
+	return settings, err
+}
+
+// Find returns the stored setting for key, or nil if it was never changed from its default
+func (r *SettingsRepository) Find(ctx context.Context, key string) (*models.AppSetting, error) {
+	var setting models.AppSetting
+	err := r.db.WithContext(ctx).Where("key = ?", key).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// Lock loads the stored setting for key with a row lock held until tx ends, or nil if there is none
+func (r *SettingsRepository) Lock(tx *gorm.DB, key string) (*models.AppSetting, error) {
+	var setting models.AppSetting
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("key = ?", key).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// Save inserts or updates the setting by key inside tx
+func (r *SettingsRepository) Save(tx *gorm.DB, setting *models.AppSetting) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+	}).Create(setting).Error
+}
+
+// CreateChange appends an audit trail entry inside tx
+func (r *SettingsRepository) CreateChange(tx *gorm.DB, change *models.SettingChange) error {
+	return tx.Create(change).Error
+}
+
+// ListChanges returns a page of the audit trail of key, newest first
+func (r *SettingsRepository) ListChanges(ctx context.Context, key string, offset, limit int) ([]models.SettingChange, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.SettingChange{}).Where("key = ?", key)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var changes []models.SettingChange
+	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&changes).Error
+	return changes, total, err
+}
+
+// CreateSchedule inserts a scheduled change
+func (r *SettingsRepository) CreateSchedule(ctx context.Context, schedule *models.ScheduledChange) error {
+	return r.db.WithContext(ctx).Create(schedule).Error
+}
+
+// ListSchedules returns the scheduled changes of key in the given status, soonest first.
+// An empty status returns all of them.
+func (r *SettingsRepository) ListSchedules(ctx context.Context, key, status string) ([]models.ScheduledChange, error) {
+	query := r.db.WithContext(ctx).Where("key = ?", key)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var schedules []models.ScheduledChange
+	err := query.Order("effective_at").Find(&schedules).Error
+	return schedules, err
+}
+
+// LockSchedule loads a scheduled change with a row lock held until tx ends, or nil if it does not exist
+func (r *SettingsRepository) LockSchedule(tx *gorm.DB, id uuid.UUID) (*models.ScheduledChange, error) {
+	var schedule models.ScheduledChange
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&schedule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// LockDueSchedules locks up to limit pending changes effective at or before now, oldest
+// first. Rows locked by another transaction are skipped.
+func (r *SettingsRepository) LockDueSchedules(tx *gorm.DB, now time.Time, limit int) ([]models.ScheduledChange, error) {
+	var schedules []models.ScheduledChange
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND effective_at <= ?", models.SchedulePending, now).
+		Order("effective_at").
+		Limit(limit).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// SaveSchedule updates a scheduled change inside tx
+func (r *SettingsRepository) SaveSchedule(tx *gorm.DB, schedule *models.ScheduledChange) error {
+	return tx.Save(schedule).Error
+}
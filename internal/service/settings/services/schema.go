@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Setting value types
+const (
+	TypeInt      = "int"
+	TypeFloat    = "float"
+	TypeBool     = "bool"
+	TypeString   = "string"
+	TypeDuration = "duration"
+)
+
+// Definition is the schema of one setting. Min and Max bound numeric values;
+// durations are bounded in seconds.
+type Definition struct {
+	Key         string
+	Type        string
+	Default     string
+	Min         *float64
+	Max         *float64
+	Description string
+}
+
+// bound returns a pointer for Definition.Min and Max
+func bound(v float64) *float64 {
+	return &v
+}
+
+// Defaults are the settings every deployment has. Money amounts are in fils (1 JOD = 1000 fils).
+var Defaults = []Definition{
+	{Key: "base_fare", Type: TypeInt, Default: "500", Min: bound(0), Max: bound(20000), Description: "Flag-down fare in fils"},
+	{Key: "per_km_rate", Type: TypeInt, Default: "250", Min: bound(0), Max: bound(5000), Description: "Fare per kilometre in fils"},
+	{Key: "per_minute_rate", Type: TypeInt, Default: "50", Min: bound(0), Max: bound(1000), Description: "Fare per minute of ride time in fils"},
+	{Key: "minimum_fare", Type: TypeInt, Default: "1000", Min: bound(0), Max: bound(50000), Description: "Lowest fare charged for a ride in fils"},
+	{Key: "cancellation_fee", Type: TypeInt, Default: "500", Min: bound(0), Max: bound(10000), Description: "Fee for passenger cancellations after a captain accepted, in fils"},
+	{Key: "search_radius_km", Type: TypeFloat, Default: "3", Min: bound(0.5), Max: bound(20), Description: "Radius searched for available captains"},
+	{Key: "offer_timeout", Type: TypeDuration, Default: "20s", Min: bound(5), Max: bound(120), Description: "How long a captain has to accept a ride offer"},
+	{Key: "route_factor", Type: TypeFloat, Default: "1.3", Min: bound(1), Max: bound(3), Description: "Ratio of road distance to straight-line distance used in fare estimates"},
+	{Key: "average_speed_kmh", Type: TypeFloat, Default: "30", Min: bound(5), Max: bound(120), Description: "Average driving speed used to estimate ride time"},
+	{Key: "surge_enabled", Type: TypeBool, Default: "true", Description: "Whether fares surge when requests outnumber captains"},
+	{Key: "surge_max_multiplier", Type: TypeFloat, Default: "2", Min: bound(1), Max: bound(5), Description: "Highest surge multiplier; zones may cap it lower"},
+	{Key: "surge_threshold", Type: TypeFloat, Default: "1", Min: bound(0), Max: bound(10), Description: "Open requests per available captain above which fares surge"},
+	{Key: "surge_sensitivity", Type: TypeFloat, Default: "0.5", Min: bound(0), Max: bound(5), Description: "Multiplier added per request per captain above the threshold"},
+	{Key: "surge_smoothing", Type: TypeFloat, Default: "0.3", Min: bound(0.05), Max: bound(1), Description: "Weight of the latest reading in the smoothed multiplier; 1 turns smoothing off"},
+	{Key: "surge_hysteresis", Type: TypeFloat, Default: "0.1", Min: bound(0), Max: bound(1), Description: "Smallest change in the smoothed multiplier that changes the quoted one"},
+	{Key: "surge_demand_window", Type: TypeDuration, Default: "10m", Min: bound(60), Max: bound(3600), Description: "How recent an open ride request must be to count as demand"},
+}
+
+// Normalise parses a value for this setting, checks its bounds and returns it in
+// canonical form, e.g. "1m0s" for "60s"
+func (d Definition) Normalise(value string) (string, error) {
+	parsed, err := d.Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := parsed.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Duration:
+		return v.String(), nil
+	default:
+		return value, nil
+	}
+}
+
+// Parse converts a stored value to its typed form and checks its bounds
+func (d Definition) Parse(value string) (interface{}, error) {
+	value = strings.TrimSpace(value)
+
+	switch d.Type {
+	case TypeInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be an integer", ErrInvalidValue, d.Key)
+		}
+		return n, d.checkBounds(float64(n))
+	case TypeFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidValue, d.Key)
+		}
+		return f, d.checkBounds(f)
+	case TypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be true or false", ErrInvalidValue, d.Key)
+		}
+		return b, nil
+	case TypeDuration:
+		dur, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a duration such as 30s or 5m", ErrInvalidValue, d.Key)
+		}
+		return dur, d.checkBounds(dur.Seconds())
+	default:
+		return value, nil
+	}
+}
+
+func (d Definition) checkBounds(v float64) error {
+	if d.Min != nil && v < *d.Min {
+		return fmt.Errorf("%w: %s must be at least %v", ErrInvalidValue, d.Key, *d.Min)
+	}
+	if d.Max != nil && v > *d.Max {
+		return fmt.Errorf("%w: %s must be at most %v", ErrInvalidValue, d.Key, *d.Max)
+	}
+	return nil
+}
@@ -0,0 +1,538 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"theb-backend/internal/logger"
+	"theb-backend/internal/service/settings/models"
+	"theb-backend/internal/service/settings/repositories"
+	"theb-backend/pkg/pagination"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// invalidateChannel tells every instance to reload settings after a change
+const invalidateChannel = "settings:invalidate"
+
+// dueBatch bounds the scheduled changes applied per run
+const dueBatch = 100
+
+var (
+	// ErrUnknownSetting is returned for keys without a definition
+	ErrUnknownSetting = errors.New("unknown setting")
+	// ErrDuplicateSetting is returned when a key is defined twice
+	ErrDuplicateSetting = errors.New("setting already defined")
+	// ErrInvalidValue is returned for values of the wrong type or out of bounds
+	ErrInvalidValue = errors.New("invalid setting value")
+	// ErrScheduleInPast is returned when scheduling a change for a time that has passed
+	ErrScheduleInPast = errors.New("effective time must be in the future")
+	// ErrScheduleNotFound is returned when a scheduled change does not exist
+	ErrScheduleNotFound = errors.New("scheduled change not found")
+	// ErrScheduleNotPending is returned when cancelling a change already applied or cancelled
+	ErrScheduleNotPending = errors.New("scheduled change is no longer pending")
+)
+
+// Setting is a definition with its current value
+type Setting struct {
+	Definition
+	Value     string
+	IsDefault bool
+	UpdatedBy *uuid.UUID
+	UpdatedAt *time.Time
+}
+
+// SettingsService serves typed runtime settings from memory. Changes are stored in
+// app_settings with an audit trail, and every instance reloads when one is made,
+// via Redis pub/sub when Redis is configured.
+type SettingsService struct {
+	repo  *repositories.SettingsRepository
+	redis *redis.Client
+
+	mu     sync.RWMutex
+	defs   map[string]Definition
+	keys   []string
+	values map[string]string
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewSettingsService creates a settings service with the default definitions. redisClient may be nil.
+func NewSettingsService(repo *repositories.SettingsRepository, redisClient *redis.Client) *SettingsService {
+	s := &SettingsService{
+		repo:   repo,
+		redis:  redisClient,
+		defs:   make(map[string]Definition),
+		values: make(map[string]string),
+	}
+	if err := s.Define(Defaults...); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Define adds setting definitions. Modules define their settings while registering.
+func (s *SettingsService) Define(defs ...Definition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, def := range defs {
+		if _, exists := s.defs[def.Key]; exists {
+			return fmt.Errorf("%w: %s", ErrDuplicateSetting, def.Key)
+		}
+		if _, err := def.Parse(def.Default); err != nil {
+			return fmt.Errorf("invalid default for %s: %w", def.Key, err)
+		}
+		s.defs[def.Key] = def
+		s.keys = append(s.keys, def.Key)
+	}
+	return nil
+}
+
+// Start loads the settings and listens for changes made on other instances
+func (s *SettingsService) Start(ctx context.Context) error {
+	if err := s.Reload(ctx); err != nil {
+		return err
+	}
+	if s.redis == nil {
+		return nil
+	}
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	pubsub := s.redis.Subscribe(subCtx, invalidateChannel)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case _, ok := <-messages:
+				if !ok {
+					return
+				}
+				if err := s.Reload(subCtx); err != nil {
+					logger.Error("Failed to reload settings", map[string]interface{}{
+						"error": err.Error(),
+					})
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops listening for changes
+func (s *SettingsService) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reload replaces the cached values with the stored ones. Stored values that no
+// longer fit their definition are ignored, so the default applies.
+func (s *SettingsService) Reload(ctx context.Context) error {
+	stored, err := s.repo.All(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make(map[string]string, len(stored))
+	for _, setting := range stored {
+		def, ok := s.defs[setting.Key]
+		if !ok {
+			continue
+		}
+		if _, err := def.Parse(setting.Value); err != nil {
+			logger.Warn("Ignoring invalid stored setting", map[string]interface{}{
+				"key":   setting.Key,
+				"error": err.Error(),
+			})
+			continue
+		}
+		values[setting.Key] = setting.Value
+	}
+	s.values = values
+
+	return nil
+}
+
+// Int returns an integer setting
+func (s *SettingsService) Int(key string) int64 {
+	v, _ := s.typed(key, TypeInt).(int64)
+	return v
+}
+
+// Float returns a numeric setting
+func (s *SettingsService) Float(key string) float64 {
+	v, _ := s.typed(key, TypeFloat).(float64)
+	return v
+}
+
+// Bool returns a boolean setting
+func (s *SettingsService) Bool(key string) bool {
+	v, _ := s.typed(key, TypeBool).(bool)
+	return v
+}
+
+// Duration returns a duration setting
+func (s *SettingsService) Duration(key string) time.Duration {
+	v, _ := s.typed(key, TypeDuration).(time.Duration)
+	return v
+}
+
+// String returns a string setting
+func (s *SettingsService) String(key string) string {
+	v, _ := s.typed(key, TypeString).(string)
+	return v
+}
+
+// typed returns the cached value of key, or its default. Reading an undefined key
+// or with the wrong type is a programming error; it is logged and yields nil.
+func (s *SettingsService) typed(key, typ string) interface{} {
+	s.mu.RLock()
+	def, ok := s.defs[key]
+	value, stored := s.values[key]
+	s.mu.RUnlock()
+
+	if !ok || def.Type != typ {
+		logger.Error("Setting read with unknown key or wrong type", map[string]interface{}{
+			"key":  key,
+			"type": typ,
+		})
+		return nil
+	}
+	if !stored {
+		value = def.Default
+	}
+
+	parsed, err := def.Parse(value)
+	if err != nil {
+		parsed, _ = def.Parse(def.Default)
+	}
+	return parsed
+}
+
+// Lookup returns the definition of key, for modules that store their own overrides of a setting
+func (s *SettingsService) Lookup(key string) (Definition, error) {
+	return s.definition(key)
+}
+
+// List returns every setting with its stored value, for the admin view
+func (s *SettingsService) List(ctx context.Context) ([]Setting, error) {
+	stored, err := s.repo.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]models.AppSetting, len(stored))
+	for _, setting := range stored {
+		byKey[setting.Key] = setting
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	settings := make([]Setting, 0, len(s.keys))
+	for _, key := range s.keys {
+		var row *models.AppSetting
+		if setting, ok := byKey[key]; ok {
+			row = &setting
+		}
+		settings = append(settings, toSetting(s.defs[key], row))
+	}
+	return settings, nil
+}
+
+// Get returns one setting and its pending scheduled changes
+func (s *SettingsService) Get(ctx context.Context, key string) (*Setting, []models.ScheduledChange, error) {
+	def, err := s.definition(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	row, err := s.repo.Find(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	schedules, err := s.repo.ListSchedules(ctx, key, models.SchedulePending)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	setting := toSetting(def, row)
+	return &setting, schedules, nil
+}
+
+// Update sets a value now and records who changed it and why
+func (s *SettingsService) Update(ctx context.Context, adminID uuid.UUID, key, value, reason string) (*Setting, error) {
+	def, err := s.definition(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err = def.Normalise(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved *models.AppSetting
+	err = s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		saved, err = s.apply(tx, def, value, models.SettingChange{
+			Source:    models.SourceAdmin,
+			ChangedBy: adminID,
+			Reason:    reason,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, key)
+
+	setting := toSetting(def, saved)
+	return &setting, nil
+}
+
+// History returns a page of the audit trail of key
+func (s *SettingsService) History(ctx context.Context, key string, params pagination.Params) ([]models.SettingChange, int64, error) {
+	if _, err := s.definition(key); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListChanges(ctx, key, params.Offset(), params.PerPage)
+}
+
+// Schedule stores a change applied once effectiveAt is reached
+func (s *SettingsService) Schedule(ctx context.Context, adminID uuid.UUID, key, value string, effectiveAt time.Time, reason string) (*models.ScheduledChange, error) {
+	def, err := s.definition(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err = def.Normalise(value)
+	if err != nil {
+		return nil, err
+	}
+	if !effectiveAt.After(time.Now()) {
+		return nil, ErrScheduleInPast
+	}
+
+	schedule := &models.ScheduledChange{
+		ID:          uuid.New(),
+		Key:         key,
+		Value:       value,
+		EffectiveAt: effectiveAt.UTC(),
+		Status:      models.SchedulePending,
+		Reason:      reason,
+		CreatedBy:   adminID,
+	}
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("Setting change scheduled", map[string]interface{}{
+		"key":          key,
+		"value":        value,
+		"effective_at": schedule.EffectiveAt,
+		"schedule_id":  schedule.ID.String(),
+	})
+
+	return schedule, nil
+}
+
+// CancelSchedule cancels a pending scheduled change
+func (s *SettingsService) CancelSchedule(ctx context.Context, adminID, scheduleID uuid.UUID) (*models.ScheduledChange, error) {
+	var schedule *models.ScheduledChange
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		schedule, err = s.repo.LockSchedule(tx, scheduleID)
+		if err != nil {
+			return err
+		}
+		if schedule == nil {
+			return ErrScheduleNotFound
+		}
+		if schedule.Status != models.SchedulePending {
+			return ErrScheduleNotPending
+		}
+
+		now := time.Now().UTC()
+		schedule.Status = models.ScheduleCancelled
+		schedule.CancelledBy = &adminID
+		schedule.CancelledAt = &now
+		return s.repo.SaveSchedule(tx, schedule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// ApplyDue applies every scheduled change whose effective time has passed, in
+// effective order. A change whose value no longer fits its definition is marked failed.
+func (s *SettingsService) ApplyDue(ctx context.Context) error {
+	var applied []string
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		due, err := s.repo.LockDueSchedules(tx, time.Now().UTC(), dueBatch)
+		if err != nil {
+			return err
+		}
+
+		for i := range due {
+			schedule := &due[i]
+			now := time.Now().UTC()
+
+			def, err := s.definition(schedule.Key)
+			if err == nil {
+				_, err = def.Parse(schedule.Value)
+			}
+			if err != nil {
+				schedule.Status = models.ScheduleFailed
+				schedule.Error = err.Error()
+				logger.FromContext(ctx).Error("Scheduled setting change failed", map[string]interface{}{
+					"key":         schedule.Key,
+					"schedule_id": schedule.ID.String(),
+					"error":       err.Error(),
+				})
+			} else {
+				scheduleID := schedule.ID
+				if _, err := s.apply(tx, def, schedule.Value, models.SettingChange{
+					Source:     models.SourceSchedule,
+					ChangedBy:  schedule.CreatedBy,
+					ScheduleID: &scheduleID,
+					Reason:     schedule.Reason,
+				}); err != nil {
+					return err
+				}
+				schedule.Status = models.ScheduleApplied
+				schedule.AppliedAt = &now
+				applied = append(applied, schedule.Key)
+			}
+
+			if err := s.repo.SaveSchedule(tx, schedule); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range applied {
+		s.invalidate(ctx, key)
+	}
+	return nil
+}
+
+// apply stores value for def inside tx and appends the audit entry described by change
+func (s *SettingsService) apply(tx *gorm.DB, def Definition, value string, change models.SettingChange) (*models.AppSetting, error) {
+	current, err := s.repo.Lock(tx, def.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	oldValue := def.Default
+	setting := &models.AppSetting{ID: uuid.New(), Key: def.Key}
+	if current != nil {
+		oldValue = current.Value
+		setting = current
+	}
+
+	changedBy := change.ChangedBy
+	setting.Value = value
+	setting.UpdatedBy = &changedBy
+	setting.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Save(tx, setting); err != nil {
+		return nil, err
+	}
+
+	change.ID = uuid.New()
+	change.Key = def.Key
+	change.OldValue = oldValue
+	change.NewValue = value
+	if err := s.repo.CreateChange(tx, &change); err != nil {
+		return nil, err
+	}
+
+	return setting, nil
+}
+
+// invalidate reloads this instance and tells the others to reload. Failures are
+// logged rather than returned, since the change itself is already committed.
+func (s *SettingsService) invalidate(ctx context.Context, key string) {
+	log := logger.FromContext(ctx)
+
+	if err := s.Reload(ctx); err != nil {
+		log.Error("Failed to reload settings", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+	}
+
+	if s.redis != nil {
+		if err := s.redis.Publish(ctx, invalidateChannel, key).Err(); err != nil {
+			log.Error("Failed to publish settings invalidation", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+		}
+	}
+
+	log.Info("Setting changed", map[string]interface{}{
+		"key": key,
+	})
+}
+
+// definition returns the definition of key
+func (s *SettingsService) definition(key string) (Definition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	def, ok := s.defs[key]
+	if !ok {
+		return Definition{}, fmt.Errorf("%w: %s", ErrUnknownSetting, key)
+	}
+	return def, nil
+}
+
+// toSetting combines a definition with its stored row, which is nil for defaults
+func toSetting(def Definition, row *models.AppSetting) Setting {
+	setting := Setting{Definition: def, Value: def.Default, IsDefault: true}
+	if row != nil {
+		setting.Value = row.Value
+		setting.IsDefault = false
+		setting.UpdatedBy = row.UpdatedBy
+		updatedAt := row.UpdatedAt
+		setting.UpdatedAt = &updatedAt
+	}
+	return setting
+}
@@ -0,0 +1,63 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CashTopUpRequest is sent by a captain who collected extra cash for a passenger's wallet
+type CashTopUpRequest struct {
+	PassengerID string `json:"passenger_id" binding:"required,uuid"`
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Reference   string `json:"reference" binding:"omitempty,max=64"`
+} // @name CashTopUpRequest
+
+// AdminCreditRequest credits a user's wallet from the admin dashboard
+type AdminCreditRequest struct {
+	Amount    int64  `json:"amount" binding:"required,gt=0"`
+	Reason    string `json:"reason" binding:"required,max=255"`
+	Reference string `json:"reference" binding:"omitempty,max=64"`
+} // @name AdminCreditRequest
+
+// RefundRequest refunds a disputed ride to the passenger's wallet
+type RefundRequest struct {
+	RideID string `json:"ride_id" binding:"required,uuid"`
+	Amount int64  `json:"amount" binding:"required,gt=0"`
+	Reason string `json:"reason" binding:"required,max=255"`
+} // @name RefundRequest
+
+// BalanceResponse is the wallet balance in fils
+type BalanceResponse struct {
+	Balance  int64  `json:"balance"`
+	Currency string `json:"currency"`
+} // @name WalletBalanceResponse
+
+// TransactionResponse is a single wallet movement
+type TransactionResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Kind          string     `json:"kind"`
+	Amount        int64      `json:"amount"`
+	BalanceAfter  int64      `json:"balance_after"`
+	Description   string     `json:"description,omitempty"`
+	RideID        *uuid.UUID `json:"ride_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+} // @name WalletTransactionResponse
+
+// TransactionListResponse is a page of wallet movements
+type TransactionListResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	Page         int                   `json:"page"`
+	PerPage      int                   `json:"per_page"`
+	Total        int64                 `json:"total"`
+} // @name WalletTransactionListResponse
+
+// PostingResponse confirms a wallet movement
+type PostingResponse struct {
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Kind          string     `json:"kind"`
+	Reference     string     `json:"reference"`
+	RideID        *uuid.UUID `json:"ride_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+} // @name WalletPostingResponse
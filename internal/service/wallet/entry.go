@@ -0,0 +1,101 @@
+package wallet
+
+import (
+	"context"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/events"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/middleware"
+	ledger "theb-backend/internal/service/ledger/services"
+	orderrepos "theb-backend/internal/service/order/repositories"
+	paymentmodels "theb-backend/internal/service/payment/models"
+	"theb-backend/internal/service/wallet/handlers"
+	"theb-backend/internal/service/wallet/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Module wires passenger wallets on top of the ledger
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "wallet" }
+
+// Requires returns the modules whose services wallet resolves
+func (Module) Requires() []string { return []string{"ledger", "order"} }
+
+// Migrations returns no tables; balances live in the ledger
+func (Module) Migrations() []interface{} { return nil }
+
+// Register registers the wallet service and handler in the container and
+// charges completed wallet rides
+func (Module) Register(ctn *container.Container) error {
+	ledgerService, err := container.Resolve[*ledger.LedgerService](ctn)
+	if err != nil {
+		return err
+	}
+	rideRepo, err := container.Resolve[*orderrepos.RideRepository](ctn)
+	if err != nil {
+		return err
+	}
+	bus, err := container.Resolve[*events.Bus](ctn)
+	if err != nil {
+		return err
+	}
+
+	service := services.NewWalletService(ledgerService, rideRepo)
+	container.Supply(ctn, service)
+	container.Supply(ctn, handlers.NewWalletHandler(service))
+
+	// The debit posts in the event's transaction, so it commits exactly once.
+	// Whatever the wallet does not cover is collected in cash.
+	events.On(bus, "wallet.ride_debit", func(ctx context.Context, tx *gorm.DB, event events.RideCompleted) error {
+		if event.PaymentMethod != paymentmodels.MethodWallet {
+			return nil
+		}
+		_, err := service.DebitRide(tx, services.RideDebit{
+			RideID:        event.RideID,
+			PassengerID:   event.PassengerID,
+			CaptainUserID: event.CaptainUserID,
+			Fare:          event.Fare,
+		})
+		return err
+	})
+
+	return nil
+}
+
+// Jobs returns no jobs
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) { return nil, nil }
+
+// Routes mounts the wallet endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	handler, err := container.Resolve[*handlers.WalletHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+
+	wallet := v1.Group("/wallet", auth)
+	{
+		wallet.GET("", handler.GetBalance)
+		wallet.GET("/transactions", handler.ListTransactions)
+		wallet.POST("/topups/cash", middleware.RequireRole(middleware.RoleCaptain, middleware.RoleBoth), handler.CashTopUp)
+	}
+
+	admin := v1.Group("/admin/wallets", auth, middleware.RequireRole(middleware.RoleAdmin))
+	{
+		admin.POST("/:user_id/credit", handler.AdminCredit)
+		admin.POST("/:user_id/refunds", handler.Refund)
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	ledgermodels "theb-backend/internal/service/ledger/models"
+	ledger "theb-backend/internal/service/ledger/services"
+	"theb-backend/internal/service/wallet/dtos"
+	"theb-backend/internal/service/wallet/services"
+	"theb-backend/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WalletHandler serves wallet endpoints
+type WalletHandler struct {
+	service *services.WalletService
+}
+
+// NewWalletHandler creates a new wallet handler
+func NewWalletHandler(service *services.WalletService) *WalletHandler {
+	return &WalletHandler{service: service}
+}
+
+// GetBalance returns the authenticated user's wallet balance
+// @Summary Get wallet balance
+// @ID wallet-balance
+// @Tags Wallet
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dtos.BalanceResponse
+// @Router /api/v1/wallet [get]
+func (h *WalletHandler) GetBalance(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	balance, err := h.service.Balance(c.Request.Context(), userID)
+	if err != nil {
+		h.internalError(c, "Failed to load wallet balance", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.BalanceResponse{Balance: balance, Currency: "JOD"})
+}
+
+// ListTransactions returns the authenticated user's wallet history
+// @Summary List wallet transactions
+// @ID wallet-transactions
+// @Tags Wallet
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} dtos.TransactionListResponse
+// @Router /api/v1/wallet/transactions [get]
+func (h *WalletHandler) ListTransactions(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	params := pagination.FromQuery(c)
+	entries, total, err := h.service.Transactions(c.Request.Context(), userID, params)
+	if err != nil {
+		h.internalError(c, "Failed to load wallet transactions", err)
+		return
+	}
+
+	response := dtos.TransactionListResponse{
+		Transactions: make([]dtos.TransactionResponse, 0, len(entries)),
+		Page:         params.Page,
+		PerPage:      params.PerPage,
+		Total:        total,
+	}
+	for _, entry := range entries {
+		item := dtos.TransactionResponse{
+			ID:            entry.ID,
+			TransactionID: entry.TransactionID,
+			Amount:        entry.Amount,
+			BalanceAfter:  entry.BalanceAfter,
+			CreatedAt:     entry.CreatedAt,
+		}
+		if entry.Transaction != nil {
+			item.Kind = entry.Transaction.Kind
+			item.Description = entry.Transaction.Description
+			item.RideID = entry.Transaction.RideID
+		}
+		response.Transactions = append(response.Transactions, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CashTopUp credits a passenger's wallet with cash handed to a captain
+// @Summary Top up a passenger wallet with cash (captain)
+// @ID wallet-cash-topup
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.CashTopUpRequest true "Passenger and amount in fils"
+// @Success 201 {object} dtos.PostingResponse
+// @Router /api/v1/wallet/topups/cash [post]
+func (h *WalletHandler) CashTopUp(c *gin.Context) {
+	captainID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	var req dtos.CashTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	txn, err := h.service.CashTopUp(c.Request.Context(), captainID, uuid.MustParse(req.PassengerID), req.Amount, req.Reference)
+	if err != nil {
+		h.handleError(c, "Failed to top up wallet", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, postingResponse(txn))
+}
+
+// AdminCredit credits a user's wallet from the admin dashboard
+// @Summary Credit a user wallet (admin)
+// @ID wallet-admin-credit
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body dtos.AdminCreditRequest true "Amount in fils and reason"
+// @Success 201 {object} dtos.PostingResponse
+// @Router /api/v1/admin/wallets/{user_id}/credit [post]
+func (h *WalletHandler) AdminCredit(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("user_id", "uuid")))
+		return
+	}
+
+	var req dtos.AdminCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	txn, err := h.service.AdminCredit(c.Request.Context(), adminID, userID, req.Amount, req.Reason, req.Reference)
+	if err != nil {
+		h.handleError(c, "Failed to credit wallet", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, postingResponse(txn))
+}
+
+// Refund refunds a disputed ride to the passenger's wallet
+// @Summary Refund a ride to a passenger wallet (admin)
+// @ID wallet-admin-refund
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user_id path string true "Passenger user ID"
+// @Param request body dtos.RefundRequest true "Ride, amount in fils and reason"
+// @Success 201 {object} dtos.PostingResponse
+// @Router /api/v1/admin/wallets/{user_id}/refunds [post]
+func (h *WalletHandler) Refund(c *gin.Context) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return
+	}
+
+	passengerID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("user_id", "uuid")))
+		return
+	}
+
+	var req dtos.RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	txn, err := h.service.Refund(c.Request.Context(), adminID, passengerID, uuid.MustParse(req.RideID), req.Amount, req.Reason)
+	if err != nil {
+		h.handleError(c, "Failed to refund ride", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, postingResponse(txn))
+}
+
+func (h *WalletHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAmount):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeAmountInvalid, err))
+	case errors.Is(err, services.ErrSelfTopUp):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeWalletSelfTopUp, err))
+	case errors.Is(err, services.ErrPassengerNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeWalletPassengerNotFound, err))
+	case errors.Is(err, services.ErrRideNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideNotFound, err))
+	case errors.Is(err, services.ErrRideNotCompleted):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeRideNotCompleted, err))
+	case errors.Is(err, services.ErrRefundExceedsFare):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeWalletRefundExceedsFare, err))
+	case errors.Is(err, ledger.ErrInsufficientFunds):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeWalletInsufficientFunds, err))
+	case errors.Is(err, ledger.ErrReferenceConflict):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeConflict, err))
+	default:
+		h.internalError(c, message, err)
+	}
+}
+
+func (h *WalletHandler) internalError(c *gin.Context, message string, err error) {
+	logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+		"error": err.Error(),
+	})
+	apierror.Abort(c, apierror.New(apierror.CodeInternal))
+}
+
+func postingResponse(txn *ledgermodels.Transaction) dtos.PostingResponse {
+	return dtos.PostingResponse{
+		TransactionID: txn.ID,
+		Kind:          txn.Kind,
+		Reference:     txn.Reference,
+		RideID:        txn.RideID,
+		CreatedAt:     txn.CreatedAt,
+	}
+}
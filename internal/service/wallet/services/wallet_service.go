@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ledgermodels "theb-backend/internal/service/ledger/models"
+	ledger "theb-backend/internal/service/ledger/services"
+	orderrepos "theb-backend/internal/service/order/repositories"
+	"theb-backend/pkg/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidAmount is returned for zero or negative amounts
+	ErrInvalidAmount = errors.New("amount must be positive")
+	// ErrSelfTopUp is returned when a captain tries to top up their own wallet
+	ErrSelfTopUp = errors.New("captains cannot top up their own wallet")
+	// ErrPassengerNotFound is returned when topping up a user who has never requested a ride
+	ErrPassengerNotFound = errors.New("passenger not found")
+	// ErrRideNotFound is returned when a refunded ride does not exist or was not the passenger's
+	ErrRideNotFound = errors.New("ride not found")
+	// ErrRideNotCompleted is returned when refunding a ride that was never charged
+	ErrRideNotCompleted = errors.New("ride is not completed")
+	// ErrRefundExceedsFare is returned when a refund is larger than the ride's fare
+	ErrRefundExceedsFare = errors.New("refund exceeds the ride fare")
+)
+
+// SplitPayment describes how a ride fare was paid
+type SplitPayment struct {
+	WalletAmount int64
+	CashAmount   int64
+}
+
+// RideDebit describes a completed ride to be charged to the passenger's wallet
+type RideDebit struct {
+	RideID      uuid.UUID
+	PassengerID uuid.UUID
+	// CaptainUserID owns the captain balance the wallet share is paid into
+	CaptainUserID uuid.UUID
+	Fare          int64
+}
+
+// WalletService manages passenger wallets on top of the ledger. All amounts are in fils.
+type WalletService struct {
+	ledger *ledger.LedgerService
+	rides  *orderrepos.RideRepository
+}
+
+// NewWalletService creates a new wallet service
+func NewWalletService(ledgerService *ledger.LedgerService, rides *orderrepos.RideRepository) *WalletService {
+	return &WalletService{ledger: ledgerService, rides: rides}
+}
+
+// Balance returns the user's wallet balance
+func (s *WalletService) Balance(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.ledger.Balance(ctx, userID, ledgermodels.AccountPassengerWallet)
+}
+
+// Transactions returns a page of wallet entries, newest first, and the total count
+func (s *WalletService) Transactions(ctx context.Context, userID uuid.UUID, params pagination.Params) ([]ledgermodels.Entry, int64, error) {
+	return s.ledger.Entries(ctx, userID, ledgermodels.AccountPassengerWallet, params.Offset(), params.PerPage)
+}
+
+// CashTopUp credits a passenger's wallet with extra cash collected by a captain.
+// The captain now holds platform money, so their balance is reduced by the same amount.
+// The passenger must have requested a ride, so a mistyped ID cannot strand the cash.
+// Retrying with the same reference does not credit the wallet twice.
+func (s *WalletService) CashTopUp(ctx context.Context, captainID, passengerID uuid.UUID, amount int64, reference string) (*ledgermodels.Transaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if captainID == passengerID {
+		return nil, ErrSelfTopUp
+	}
+	if reference == "" {
+		reference = uuid.NewString()
+	}
+
+	exists, err := s.rides.HasPassenger(ctx, passengerID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrPassengerNotFound
+	}
+
+	return s.post(ctx, ledger.Posting{
+		Kind:        ledgermodels.KindWalletCashTopUp,
+		Reference:   fmt.Sprintf("cash_topup:%s:%s", captainID, reference),
+		Description: "Cash top-up collected by captain",
+		CreatedBy:   &captainID,
+		Lines: []ledger.Line{
+			{OwnerID: passengerID, AccountType: ledgermodels.AccountPassengerWallet, Amount: amount},
+			{OwnerID: captainID, AccountType: ledgermodels.AccountCaptainBalance, Amount: -amount},
+		},
+	})
+}
+
+// AdminCredit credits a user's wallet on behalf of the platform
+func (s *WalletService) AdminCredit(ctx context.Context, adminID, userID uuid.UUID, amount int64, reason, reference string) (*ledgermodels.Transaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if reference == "" {
+		reference = uuid.NewString()
+	}
+
+	return s.post(ctx, ledger.Posting{
+		Kind:        ledgermodels.KindWalletCredit,
+		Reference:   fmt.Sprintf("admin_credit:%s:%s", adminID, reference),
+		Description: reason,
+		CreatedBy:   &adminID,
+		Lines: []ledger.Line{
+			{OwnerID: userID, AccountType: ledgermodels.AccountPassengerWallet, Amount: amount},
+			{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemAdjustments, Amount: -amount},
+		},
+	})
+}
+
+// DebitRide charges as much of the fare as the wallet covers, inside tx, and
+// returns the remainder to be collected in cash. An empty wallet is recorded with
+// a posting that moves nothing, so calling it again for the same ride returns the
+// original split without charging, even if the wallet was topped up since.
+func (s *WalletService) DebitRide(tx *gorm.DB, debit RideDebit) (SplitPayment, error) {
+	if debit.Fare <= 0 {
+		return SplitPayment{}, ErrInvalidAmount
+	}
+
+	reference := "ride_debit:" + debit.RideID.String()
+	walletRef := ledger.AccountRef{OwnerID: debit.PassengerID, AccountType: ledgermodels.AccountPassengerWallet}
+
+	balances, err := s.ledger.LockBalances(tx,
+		walletRef,
+		ledger.AccountRef{OwnerID: debit.CaptainUserID, AccountType: ledgermodels.AccountCaptainBalance},
+	)
+	if err != nil {
+		return SplitPayment{}, err
+	}
+
+	// Checked before the balance so a replay reports the original split
+	txn, err := s.ledger.FindByReference(tx, reference)
+	if err != nil {
+		return SplitPayment{}, err
+	}
+	if txn == nil {
+		txn, err = s.ledger.Post(tx, debitPosting(reference, debit, balances[walletRef]))
+		if err != nil {
+			return SplitPayment{}, err
+		}
+	}
+
+	var walletAmount int64
+	for _, entry := range txn.Entries {
+		if entry.Amount < 0 {
+			walletAmount = -entry.Amount
+		}
+	}
+
+	return SplitPayment{WalletAmount: walletAmount, CashAmount: debit.Fare - walletAmount}, nil
+}
+
+// debitPosting charges as much of the fare as balance covers. With nothing to
+// charge it has no lines and only records the decision under reference.
+func debitPosting(reference string, debit RideDebit, balance int64) ledger.Posting {
+	walletAmount := min(balance, debit.Fare)
+	if walletAmount <= 0 {
+		return ledger.Posting{
+			Kind:        ledgermodels.KindRideDebit,
+			Reference:   reference,
+			Description: "Ride fare paid in cash, wallet empty",
+			RideID:      &debit.RideID,
+		}
+	}
+
+	return ledger.Posting{
+		Kind:        ledgermodels.KindRideDebit,
+		Reference:   reference,
+		Description: "Ride fare paid from wallet",
+		RideID:      &debit.RideID,
+		Lines: []ledger.Line{
+			{OwnerID: debit.PassengerID, AccountType: ledgermodels.AccountPassengerWallet, Amount: -walletAmount},
+			{OwnerID: debit.CaptainUserID, AccountType: ledgermodels.AccountCaptainBalance, Amount: walletAmount},
+		},
+	}
+}
+
+// Refund credits a passenger's wallet after a ride dispute. Each ride can be
+// refunded once, by at most its final fare.
+func (s *WalletService) Refund(ctx context.Context, adminID, passengerID, rideID uuid.UUID, amount int64, reason string) (*ledgermodels.Transaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	ride, err := s.rides.FindByID(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride == nil || ride.PassengerID != passengerID {
+		return nil, ErrRideNotFound
+	}
+	if ride.FareFinal == nil {
+		return nil, ErrRideNotCompleted
+	}
+	if amount > *ride.FareFinal {
+		return nil, fmt.Errorf("%w: fare is %d", ErrRefundExceedsFare, *ride.FareFinal)
+	}
+
+	return s.post(ctx, ledger.Posting{
+		Kind:        ledgermodels.KindRideRefund,
+		Reference:   "ride_refund:" + rideID.String(),
+		Description: reason,
+		RideID:      &rideID,
+		CreatedBy:   &adminID,
+		Lines: []ledger.Line{
+			{OwnerID: passengerID, AccountType: ledgermodels.AccountPassengerWallet, Amount: amount},
+			{OwnerID: ledgermodels.SystemOwnerID, AccountType: ledgermodels.AccountSystemRefunds, Amount: -amount},
+		},
+	})
+}
+
+func (s *WalletService) post(ctx context.Context, posting ledger.Posting) (*ledgermodels.Transaction, error) {
+	var txn *ledgermodels.Transaction
+	err := s.ledger.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		txn, err = s.ledger.Post(tx, posting)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
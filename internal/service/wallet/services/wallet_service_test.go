@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	ledgermodels "theb-backend/internal/service/ledger/models"
+
+	"github.com/google/uuid"
+)
+
+func TestDebitPosting(t *testing.T) {
+	debit := RideDebit{RideID: uuid.New(), PassengerID: uuid.New(), CaptainUserID: uuid.New(), Fare: 3000}
+
+	tests := []struct {
+		name       string
+		balance    int64
+		wantWallet int64
+	}{
+		{name: "wallet covers the fare", balance: 5000, wantWallet: 3000},
+		{name: "wallet covers part of the fare", balance: 1200, wantWallet: 1200},
+		{name: "empty wallet", balance: 0, wantWallet: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posting := debitPosting("ride_debit:"+debit.RideID.String(), debit, tt.balance)
+			if posting.Kind != ledgermodels.KindRideDebit || posting.Reference != "ride_debit:"+debit.RideID.String() {
+				t.Errorf("kind/reference = %s/%s, want a ride debit under the ride's reference", posting.Kind, posting.Reference)
+			}
+
+			var wallet, captain int64
+			for _, line := range posting.Lines {
+				switch {
+				case line.OwnerID == debit.PassengerID && line.AccountType == ledgermodels.AccountPassengerWallet:
+					wallet -= line.Amount
+				case line.OwnerID == debit.CaptainUserID && line.AccountType == ledgermodels.AccountCaptainBalance:
+					captain += line.Amount
+				default:
+					t.Errorf("unexpected line %+v", line)
+				}
+			}
+			if wallet != tt.wantWallet || captain != tt.wantWallet {
+				t.Errorf("wallet debited %d and captain credited %d, want %d", wallet, captain, tt.wantWallet)
+			}
+			if tt.wantWallet == 0 && len(posting.Lines) != 0 {
+				t.Errorf("lines = %v, want none so the posting only claims the reference", posting.Lines)
+			}
+		})
+	}
+}
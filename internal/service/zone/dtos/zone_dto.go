@@ -0,0 +1,94 @@
+package dtos
+
+import (
+	"time"
+
+	"theb-backend/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+// ZoneProperties are a zone's rules, sent as the properties of a GeoJSON feature.
+// Surcharges are in fils.
+type ZoneProperties struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Kind string `json:"kind" binding:"required,oneof=service_area airport university restricted"`
+	// CityID is the city the zone belongs to; omit it for a zone that applies in every city
+	CityID              *uuid.UUID `json:"city_id"`
+	PickupSurcharge     int64      `json:"pickup_surcharge" binding:"min=0,max=20000"`
+	DropoffSurcharge    int64      `json:"dropoff_surcharge" binding:"min=0,max=20000"`
+	SearchRadiusKm      *float64   `json:"search_radius_km" binding:"omitempty,min=0.5,max=20"`
+	OfferTimeoutSeconds *int       `json:"offer_timeout_seconds" binding:"omitempty,min=5,max=120"`
+	// SurgeCap caps the zone's surge multiplier below the city's maximum
+	SurgeCap      *float64 `json:"surge_cap" binding:"omitempty,min=1,max=5"`
+	SurgeDisabled bool     `json:"surge_disabled"`
+	Priority      int      `json:"priority" binding:"min=0,max=1000"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+} // @name ZoneProperties
+
+// ZoneFeature is a zone as a GeoJSON feature with a Polygon or MultiPolygon
+// geometry in [lng, lat] order
+type ZoneFeature struct {
+	Type       string         `json:"type" binding:"required,eq=Feature"`
+	Properties ZoneProperties `json:"properties"`
+	Geometry   geo.Geometry   `json:"geometry" swaggertype:"object"`
+} // @name ZoneFeature
+
+// ImportZonesRequest is a GeoJSON feature collection of zones, created or updated by name
+type ImportZonesRequest struct {
+	Type     string        `json:"type" binding:"required,eq=FeatureCollection"`
+	Features []ZoneFeature `json:"features" binding:"required,min=1,max=200,dive"`
+} // @name ImportZonesRequest
+
+// ZoneResponse is a zone with its geometry and rules
+type ZoneResponse struct {
+	ID                  uuid.UUID    `json:"id"`
+	Name                string       `json:"name"`
+	Kind                string       `json:"kind"`
+	CityID              *uuid.UUID   `json:"city_id,omitempty"`
+	Geometry            geo.Geometry `json:"geometry" swaggertype:"object"`
+	Bounds              geo.BBox     `json:"bounds"`
+	PickupSurcharge     int64        `json:"pickup_surcharge"`
+	DropoffSurcharge    int64        `json:"dropoff_surcharge"`
+	SearchRadiusKm      *float64     `json:"search_radius_km,omitempty"`
+	OfferTimeoutSeconds *int         `json:"offer_timeout_seconds,omitempty"`
+	SurgeCap            *float64     `json:"surge_cap,omitempty"`
+	SurgeDisabled       bool         `json:"surge_disabled"`
+	Priority            int          `json:"priority"`
+	Active              bool         `json:"active"`
+	UpdatedBy           *uuid.UUID   `json:"updated_by,omitempty"`
+	CreatedAt           time.Time    `json:"created_at"`
+	UpdatedAt           time.Time    `json:"updated_at"`
+} // @name ZoneResponse
+
+// ZoneListResponse lists zones
+type ZoneListResponse struct {
+	Zones []ZoneResponse `json:"zones"`
+} // @name ZoneListResponse
+
+// CheckRideRequest asks whether a ride may be requested from Pickup, and to Dropoff when given
+type CheckRideRequest struct {
+	Pickup  geo.Point  `json:"pickup"`
+	Dropoff *geo.Point `json:"dropoff"`
+} // @name CheckRideRequest
+
+// ZoneSummary names a zone a point is in
+type ZoneSummary struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Kind string    `json:"kind"`
+} // @name ZoneSummary
+
+// CheckRideResponse says whether the ride is allowed and, if not, why. Surcharges are in fils.
+type CheckRideResponse struct {
+	Allowed bool `json:"allowed"`
+	// CityID is the city serving the pickup
+	CityID           uuid.UUID     `json:"city_id"`
+	Reason           string        `json:"reason,omitempty"`
+	Message          string        `json:"message,omitempty"`
+	PickupZones      []ZoneSummary `json:"pickup_zones"`
+	DropoffZones     []ZoneSummary `json:"dropoff_zones,omitempty"`
+	PickupSurcharge  int64         `json:"pickup_surcharge"`
+	DropoffSurcharge int64         `json:"dropoff_surcharge"`
+} // @name CheckRideResponse
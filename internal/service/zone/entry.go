@@ -0,0 +1,112 @@
+package zone
+
+import (
+	"context"
+
+	"theb-backend/internal/config"
+	"theb-backend/internal/container"
+	"theb-backend/internal/jobs"
+	"theb-backend/internal/middleware"
+	cities "theb-backend/internal/service/city/services"
+	"theb-backend/internal/service/zone/handlers"
+	"theb-backend/internal/service/zone/models"
+	"theb-backend/internal/service/zone/repositories"
+	"theb-backend/internal/service/zone/services"
+	"theb-backend/internal/validation"
+	"theb-backend/pkg/geo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// Module wires geo zones: the service area, airports, universities and restricted areas
+type Module struct{}
+
+// Name returns the module name used in config
+func (Module) Name() string { return "zone" }
+
+// Requires returns the modules whose services zone resolves
+func (Module) Requires() []string { return []string{"city"} }
+
+// Migrations returns the zones table
+func (Module) Migrations() []interface{} {
+	return []interface{}{&models.Zone{}}
+}
+
+// Register registers the zone repository, service and handler in the container.
+// Zones are loaded when the container starts; until a service_area zone exists,
+// the service area from config applies.
+func (Module) Register(ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	db, err := container.Resolve[*gorm.DB](ctn)
+	if err != nil {
+		return err
+	}
+	redisClient, err := container.Resolve[*redis.Client](ctn)
+	if err != nil {
+		return err
+	}
+	cityService, err := container.Resolve[*cities.CityService](ctn)
+	if err != nil {
+		return err
+	}
+
+	var fallback validation.Area
+	if len(cfg.Validation.ServiceArea) > 0 {
+		fallback = geo.Polygon(cfg.Validation.ServiceArea)
+	}
+
+	repo := repositories.NewZoneRepository(db)
+	service := services.NewZoneService(repo, cityService, redisClient, fallback)
+
+	container.Supply(ctn, repo)
+	container.Supply(ctn, service,
+		container.OnStart(func(ctx context.Context, service *services.ZoneService) error {
+			return service.Start(ctx)
+		}),
+		container.OnStop(func(ctx context.Context, service *services.ZoneService) error {
+			return service.Stop(ctx)
+		}),
+	)
+	container.Supply(ctn, handlers.NewZoneHandler(service, cityService))
+
+	return nil
+}
+
+// Jobs returns no jobs
+func (Module) Jobs(ctn *container.Container) ([]jobs.CronJob, error) { return nil, nil }
+
+// Routes mounts the zone lookup and admin zone endpoints on the v1 API group
+func (Module) Routes(v1, ws *gin.RouterGroup, ctn *container.Container) error {
+	cfg, err := container.Resolve[*config.Config](ctn)
+	if err != nil {
+		return err
+	}
+	handler, err := container.Resolve[*handlers.ZoneHandler](ctn)
+	if err != nil {
+		return err
+	}
+
+	auth := middleware.AuthMiddleware(cfg.JWT.Secret)
+
+	zones := v1.Group("/zones", auth)
+	{
+		zones.GET("/service-area", handler.ServiceArea)
+		zones.POST("/check", handler.Check)
+	}
+
+	admin := v1.Group("/admin/zones", auth, middleware.RequireRole(middleware.RoleAdmin))
+	{
+		admin.GET("", handler.List)
+		admin.POST("", handler.Create)
+		admin.POST("/import", handler.Import)
+		admin.GET("/:id", handler.Get)
+		admin.PUT("/:id", handler.Update)
+	}
+
+	return nil
+}
@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/logger"
+	"theb-backend/internal/middleware"
+	cities "theb-backend/internal/service/city/services"
+	"theb-backend/internal/service/zone/dtos"
+	"theb-backend/internal/service/zone/models"
+	"theb-backend/internal/service/zone/services"
+	"theb-backend/pkg/geo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ZoneHandler serves the zone lookup and admin zone endpoints
+type ZoneHandler struct {
+	service *services.ZoneService
+	cities  *cities.CityService
+}
+
+// NewZoneHandler creates a new zone handler
+func NewZoneHandler(service *services.ZoneService, cityService *cities.CityService) *ZoneHandler {
+	return &ZoneHandler{service: service, cities: cityService}
+}
+
+// ServiceArea returns the service area for drawing on the map
+// @Summary Get the service area
+// @ID zones-service-area
+// @Tags Zone
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} geo.FeatureCollection
+// @Router /api/v1/zones/service-area [get]
+func (h *ZoneHandler) ServiceArea(c *gin.Context) {
+	zones := h.service.ServiceArea()
+
+	collection := geo.FeatureCollection{Type: "FeatureCollection", Features: make([]geo.Feature, 0, len(zones))}
+	for _, zone := range zones {
+		collection.Features = append(collection.Features, geo.Feature{
+			Type:       "Feature",
+			Properties: map[string]interface{}{"id": zone.ID, "name": zone.Name},
+			Geometry:   geo.Geometry(zone.Geometry),
+		})
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// Check says whether a ride may be requested between two points, so the app can
+// explain an out-of-area location before the passenger books
+// @Summary Check a pickup and drop-off against the zones
+// @ID zones-check
+// @Tags Zone
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.CheckRideRequest true "Pickup and optional drop-off"
+// @Success 200 {object} dtos.CheckRideResponse
+// @Router /api/v1/zones/check [post]
+func (h *ZoneHandler) Check(c *gin.Context) {
+	var req dtos.CheckRideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	response := dtos.CheckRideResponse{
+		Allowed:     true,
+		CityID:      h.service.CityAt(req.Pickup),
+		PickupZones: toZoneSummaries(h.service.Locate(req.Pickup)),
+	}
+	dropoff := req.Pickup
+	if req.Dropoff != nil {
+		dropoff = *req.Dropoff
+		response.DropoffZones = toZoneSummaries(h.service.Locate(dropoff))
+	}
+
+	if err := h.service.CheckRide(c.Request.Context(), req.Pickup, req.Dropoff); err != nil {
+		code, ok := rideCode(err)
+		if !ok {
+			h.handleError(c, "Failed to check ride", err)
+			return
+		}
+		response.Allowed = false
+		response.Reason = string(code)
+		response.Message = code.Message(apierror.NegotiateLocale(c.GetHeader("Accept-Language")))
+	} else {
+		surcharges := h.service.Surcharges(req.Pickup, dropoff)
+		response.PickupSurcharge = surcharges.Pickup
+		if req.Dropoff != nil {
+			response.DropoffSurcharge = surcharges.Dropoff
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// List returns the zones in the cities the admin manages, optionally of one kind
+// @Summary List zones (admin)
+// @ID admin-zones-list
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param kind query string false "Zone kind" Enums(service_area, airport, university, restricted)
+// @Success 200 {object} dtos.ZoneListResponse
+// @Router /api/v1/admin/zones [get]
+func (h *ZoneHandler) List(c *gin.Context) {
+	_, scope, ok := h.authorise(c)
+	if !ok {
+		return
+	}
+
+	zones, err := h.service.List(c.Request.Context(), scope, c.Query("kind"))
+	if err != nil {
+		h.handleError(c, "Failed to load zones", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toZoneListResponse(zones))
+}
+
+// Get returns one zone
+// @Summary Get a zone (admin)
+// @ID admin-zones-get
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Success 200 {object} dtos.ZoneResponse
+// @Router /api/v1/admin/zones/{id} [get]
+func (h *ZoneHandler) Get(c *gin.Context) {
+	zoneID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return
+	}
+	_, scope, ok := h.authorise(c)
+	if !ok {
+		return
+	}
+
+	zone, err := h.service.Get(c.Request.Context(), zoneID)
+	if err != nil {
+		h.handleError(c, "Failed to load zone", err)
+		return
+	}
+	if !scope.Allows(zone.CityID) {
+		apierror.Abort(c, apierror.New(apierror.CodeCityAccessDenied))
+		return
+	}
+
+	c.JSON(http.StatusOK, toZoneResponse(zone))
+}
+
+// Create adds a zone from a GeoJSON feature
+// @Summary Create a zone (admin)
+// @ID admin-zones-create
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.ZoneFeature true "Zone as a GeoJSON feature"
+// @Success 201 {object} dtos.ZoneResponse
+// @Router /api/v1/admin/zones [post]
+func (h *ZoneHandler) Create(c *gin.Context) {
+	adminID, scope, ok := h.authorise(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ZoneFeature
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+	input, apiErr := toZoneInput(req, "geometry")
+	if apiErr != nil {
+		apierror.Abort(c, apiErr)
+		return
+	}
+	if !scope.Allows(input.CityID) {
+		apierror.Abort(c, apierror.New(apierror.CodeCityAccessDenied))
+		return
+	}
+
+	zone, err := h.service.Create(c.Request.Context(), adminID, input)
+	if err != nil {
+		h.handleError(c, "Failed to create zone", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toZoneResponse(zone))
+}
+
+// Update replaces a zone's geometry and rules
+// @Summary Update a zone (admin)
+// @ID admin-zones-update
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Zone ID"
+// @Param request body dtos.ZoneFeature true "Zone as a GeoJSON feature"
+// @Success 200 {object} dtos.ZoneResponse
+// @Router /api/v1/admin/zones/{id} [put]
+func (h *ZoneHandler) Update(c *gin.Context) {
+	zoneID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Validation(apierror.Field("id", "uuid")))
+		return
+	}
+	adminID, scope, ok := h.authorise(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ZoneFeature
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+	input, apiErr := toZoneInput(req, "geometry")
+	if apiErr != nil {
+		apierror.Abort(c, apiErr)
+		return
+	}
+
+	// The admin must manage both the zone's current city and the one it moves to
+	current, err := h.service.Get(c.Request.Context(), zoneID)
+	if err != nil {
+		h.handleError(c, "Failed to update zone", err)
+		return
+	}
+	if !scope.Allows(current.CityID) || !scope.Allows(input.CityID) {
+		apierror.Abort(c, apierror.New(apierror.CodeCityAccessDenied))
+		return
+	}
+
+	zone, err := h.service.Update(c.Request.Context(), adminID, zoneID, input)
+	if err != nil {
+		h.handleError(c, "Failed to update zone", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toZoneResponse(zone))
+}
+
+// Import creates or updates zones by name from a GeoJSON feature collection. Every
+// feature is checked before any is saved.
+// @Summary Import zones from GeoJSON (admin)
+// @ID admin-zones-import
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dtos.ImportZonesRequest true "GeoJSON feature collection"
+// @Success 200 {object} dtos.ZoneListResponse
+// @Router /api/v1/admin/zones/import [post]
+func (h *ZoneHandler) Import(c *gin.Context) {
+	adminID, scope, ok := h.authorise(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ImportZonesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.AbortBinding(c, err)
+		return
+	}
+
+	inputs := make([]services.ZoneInput, 0, len(req.Features))
+	for i, feature := range req.Features {
+		input, apiErr := toZoneInput(feature, fmt.Sprintf("features[%d].geometry", i))
+		if apiErr != nil {
+			apierror.Abort(c, apiErr)
+			return
+		}
+		if !scope.Allows(input.CityID) {
+			apierror.Abort(c, apierror.New(apierror.CodeCityAccessDenied))
+			return
+		}
+		inputs = append(inputs, input)
+	}
+
+	zones, err := h.service.Import(c.Request.Context(), adminID, inputs)
+	if err != nil {
+		h.handleError(c, "Failed to import zones", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toZoneListResponse(zones))
+}
+
+// authorise resolves the admin and the cities they manage
+func (h *ZoneHandler) authorise(c *gin.Context) (uuid.UUID, cities.Scope, bool) {
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeAuthTokenInvalid))
+		return uuid.Nil, cities.Scope{}, false
+	}
+
+	scope, err := h.cities.Scope(c.Request.Context(), adminID)
+	if err != nil {
+		h.handleError(c, "Failed to load admin cities", err)
+		return uuid.Nil, cities.Scope{}, false
+	}
+	return adminID, scope, true
+}
+
+func (h *ZoneHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrZoneNotFound):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeZoneNotFound, err))
+	case errors.Is(err, services.ErrDuplicateName):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeZoneNameTaken, err))
+	case errors.Is(err, services.ErrUnknownCity):
+		apierror.Abort(c, apierror.Wrap(apierror.CodeCityNotFound, err))
+	case errors.Is(err, services.ErrInvalidSurgeCap):
+		apierror.Abort(c, apierror.Validation(apierror.FieldError{Field: "properties.surge_cap", Rule: "min", Param: "1"}))
+	default:
+		if code, ok := rideCode(err); ok {
+			apierror.Abort(c, apierror.Wrap(code, err))
+			return
+		}
+		logger.FromContext(c.Request.Context()).Error(message, map[string]interface{}{
+			"error": err.Error(),
+		})
+		apierror.Abort(c, apierror.New(apierror.CodeInternal))
+	}
+}
+
+// rideCode returns the code for a ride rejected by the zones
+func rideCode(err error) (apierror.Code, bool) {
+	switch {
+	case errors.Is(err, services.ErrPickupOutsideServiceArea):
+		return apierror.CodeRidePickupOutOfArea, true
+	case errors.Is(err, services.ErrDropoffOutsideServiceArea):
+		return apierror.CodeRideDropoffOutOfArea, true
+	case errors.Is(err, services.ErrRestrictedArea):
+		return apierror.CodeRideRestrictedArea, true
+	default:
+		return "", false
+	}
+}
+
+// toZoneInput parses a feature's geometry, reporting a bad one against field
+func toZoneInput(feature dtos.ZoneFeature, field string) (services.ZoneInput, *apierror.Error) {
+	shape, err := geo.ParseGeometry(feature.Geometry)
+	if err != nil {
+		return services.ZoneInput{}, apierror.Wrap(apierror.CodeZoneInvalidGeometry, err).WithDetails(apierror.FieldError{
+			Field:   field,
+			Rule:    "geometry",
+			Message: err.Error(),
+		})
+	}
+
+	props := feature.Properties
+	active := props.Active == nil || *props.Active
+	return services.ZoneInput{
+		Name:                props.Name,
+		Kind:                props.Kind,
+		CityID:              props.CityID,
+		Shape:               shape,
+		PickupSurcharge:     props.PickupSurcharge,
+		DropoffSurcharge:    props.DropoffSurcharge,
+		SearchRadiusKm:      props.SearchRadiusKm,
+		OfferTimeoutSeconds: props.OfferTimeoutSeconds,
+		SurgeCap:            props.SurgeCap,
+		SurgeDisabled:       props.SurgeDisabled,
+		Priority:            props.Priority,
+		Active:              active,
+	}, nil
+}
+
+func toZoneSummaries(zones []models.Zone) []dtos.ZoneSummary {
+	summaries := make([]dtos.ZoneSummary, 0, len(zones))
+	for _, zone := range zones {
+		summaries = append(summaries, dtos.ZoneSummary{ID: zone.ID, Name: zone.Name, Kind: zone.Kind})
+	}
+	return summaries
+}
+
+func toZoneListResponse(zones []models.Zone) dtos.ZoneListResponse {
+	response := dtos.ZoneListResponse{Zones: make([]dtos.ZoneResponse, 0, len(zones))}
+	for i := range zones {
+		response.Zones = append(response.Zones, toZoneResponse(&zones[i]))
+	}
+	return response
+}
+
+func toZoneResponse(zone *models.Zone) dtos.ZoneResponse {
+	return dtos.ZoneResponse{
+		ID:                  zone.ID,
+		Name:                zone.Name,
+		Kind:                zone.Kind,
+		CityID:              zone.CityID,
+		Geometry:            geo.Geometry(zone.Geometry),
+		Bounds:              geo.BBox{MinLat: zone.MinLat, MinLng: zone.MinLng, MaxLat: zone.MaxLat, MaxLng: zone.MaxLng},
+		PickupSurcharge:     zone.PickupSurcharge,
+		DropoffSurcharge:    zone.DropoffSurcharge,
+		SearchRadiusKm:      zone.SearchRadiusKm,
+		OfferTimeoutSeconds: zone.OfferTimeoutSeconds,
+		SurgeCap:            zone.SurgeCap,
+		SurgeDisabled:       zone.SurgeDisabled,
+		Priority:            zone.Priority,
+		Active:              zone.Active,
+		UpdatedBy:           zone.UpdatedBy,
+		CreatedAt:           zone.CreatedAt,
+		UpdatedAt:           zone.UpdatedAt,
+	}
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"theb-backend/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+// Zone kinds
+const (
+	// KindServiceArea is where rides may be requested
+	KindServiceArea = "service_area"
+	KindAirport     = "airport"
+	KindUniversity  = "university"
+	// KindRestricted is where pickups and drop-offs are not allowed, e.g. military areas
+	KindRestricted = "restricted"
+)
+
+// Kinds lists every zone kind
+var Kinds = []string{KindServiceArea, KindAirport, KindUniversity, KindRestricted}
+
+// Geometry is a zone's GeoJSON Polygon or MultiPolygon stored as JSON
+type Geometry geo.Geometry
+
+// Value implements driver.Valuer
+func (g Geometry) Value() (driver.Value, error) {
+	data, err := json.Marshal(geo.Geometry(g))
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (g *Geometry) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, (*geo.Geometry)(g))
+	case string:
+		return json.Unmarshal([]byte(v), (*geo.Geometry)(g))
+	default:
+		return fmt.Errorf("unsupported zone geometry type %T", value)
+	}
+}
+
+// Zone is a named area with its own ride rules. The bounding box is stored
+// alongside the geometry so lookups can skip zones far from a point.
+type Zone struct {
+	ID   uuid.UUID `gorm:"column:zone_id;type:uuid;primaryKey" json:"zone_id"`
+	Name string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+	Kind string    `gorm:"type:varchar(32);not null;index" json:"kind"`
+	// CityID is the city the zone belongs to; nil applies it in every city,
+	// e.g. a restricted area on a road between cities
+	CityID   *uuid.UUID `gorm:"type:uuid;index" json:"city_id,omitempty"`
+	Geometry Geometry   `gorm:"type:jsonb;not null" json:"geometry"`
+	MinLat   float64    `gorm:"not null" json:"min_lat"`
+	MinLng   float64    `gorm:"not null" json:"min_lng"`
+	MaxLat   float64    `gorm:"not null" json:"max_lat"`
+	MaxLng   float64    `gorm:"not null" json:"max_lng"`
+	// Surcharges in fils added to fares starting or ending in the zone
+	PickupSurcharge  int64 `gorm:"not null;default:0" json:"pickup_surcharge"`
+	DropoffSurcharge int64 `gorm:"not null;default:0" json:"dropoff_surcharge"`
+	// Dispatch overrides for pickups in the zone; nil uses the city's setting
+	SearchRadiusKm      *float64 `json:"search_radius_km,omitempty"`
+	OfferTimeoutSeconds *int     `json:"offer_timeout_seconds,omitempty"`
+	// SurgeCap caps the surge multiplier below the city's maximum; SurgeDisabled turns surge off
+	SurgeCap      *float64 `json:"surge_cap,omitempty"`
+	SurgeDisabled bool     `gorm:"not null;default:false" json:"surge_disabled"`
+	// Priority decides which zone's rules apply where zones overlap; higher wins
+	Priority  int        `gorm:"not null;default:0" json:"priority"`
+	Active    bool       `gorm:"not null" json:"active"`
+	UpdatedBy *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Zone) TableName() string {
+	return "zones"
+}
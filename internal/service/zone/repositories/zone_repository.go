@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"theb-backend/internal/service/zone/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ZoneRepository provides data access for zones
+type ZoneRepository struct {
+	db *gorm.DB
+}
+
+// NewZoneRepository creates a new zone repository
+func NewZoneRepository(db *gorm.DB) *ZoneRepository {
+	return &ZoneRepository{db: db}
+}
+
+// Transaction runs fn inside a database transaction
+func (r *ZoneRepository) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
+}
+
+// Active returns every active zone, highest priority first
+func (r *ZoneRepository) Active(ctx context.Context) ([]models.Zone, error) {
+	var zones []models.Zone
+	err := r.db.WithContext(ctx).Where("active = ?", true).Order("priority DESC, name").Find(&zones).Error
+	return zones, err
+}
+
+// List returns zones of kind, or of every kind when it is empty, ordered by name
+func (r *ZoneRepository) List(ctx context.Context, kind string) ([]models.Zone, error) {
+	query := r.db.WithContext(ctx)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var zones []models.Zone
+	err := query.Order("name").Find(&zones).Error
+	return zones, err
+}
+
+// Find returns a zone by ID, or nil if it does not exist
+func (r *ZoneRepository) Find(ctx context.Context, id uuid.UUID) (*models.Zone, error) {
+	var zone models.Zone
+	err := r.db.WithContext(ctx).Where("zone_id = ?", id).First(&zone).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// Lock loads a zone by ID with a row lock held until tx ends, or nil if it does not exist
+func (r *ZoneRepository) Lock(tx *gorm.DB, id uuid.UUID) (*models.Zone, error) {
+	var zone models.Zone
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("zone_id = ?", id).First(&zone).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// LockByName loads a zone by name with a row lock held until tx ends, or nil if there is none
+func (r *ZoneRepository) LockByName(tx *gorm.DB, name string) (*models.Zone, error) {
+	var zone models.Zone
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", name).First(&zone).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// Save inserts or updates a zone inside tx
+func (r *ZoneRepository) Save(tx *gorm.DB, zone *models.Zone) error {
+	return tx.Save(zone).Error
+}
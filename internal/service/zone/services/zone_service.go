@@ -0,0 +1,581 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"theb-backend/internal/logger"
+	cities "theb-backend/internal/service/city/services"
+	"theb-backend/internal/service/zone/models"
+	"theb-backend/internal/service/zone/repositories"
+	"theb-backend/internal/validation"
+	"theb-backend/pkg/geo"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// invalidateChannel tells every instance to reload zones after a change
+const invalidateChannel = "zones:invalidate"
+
+var (
+	// ErrZoneNotFound is returned when a zone does not exist
+	ErrZoneNotFound = errors.New("zone not found")
+	// ErrDuplicateName is returned when another zone already has the name
+	ErrDuplicateName = errors.New("zone name already in use")
+	// ErrPickupOutsideServiceArea is returned for rides starting where THEB does not operate
+	ErrPickupOutsideServiceArea = errors.New("pickup is outside the service area")
+	// ErrDropoffOutsideServiceArea is returned for rides ending outside the service area and airports
+	ErrDropoffOutsideServiceArea = errors.New("drop-off is outside the service area")
+	// ErrRestrictedArea is returned for pickups or drop-offs inside a restricted zone
+	ErrRestrictedArea = errors.New("location is in a restricted area")
+	// ErrUnknownCity is returned for zones assigned to a city that does not exist
+	ErrUnknownCity = errors.New("zone city does not exist")
+	// ErrInvalidSurgeCap is returned for a surge cap below 1, which would discount fares
+	ErrInvalidSurgeCap = errors.New("surge cap must be at least 1")
+)
+
+// ZoneInput is a zone as uploaded by an admin, with its geometry already parsed
+type ZoneInput struct {
+	Name                string
+	Kind                string
+	CityID              *uuid.UUID
+	Shape               geo.Shape
+	PickupSurcharge     int64
+	DropoffSurcharge    int64
+	SearchRadiusKm      *float64
+	OfferTimeoutSeconds *int
+	SurgeCap            *float64
+	SurgeDisabled       bool
+	Priority            int
+	Active              bool
+}
+
+// Surcharges are the zone charges in fils for a ride. A zero ID means no zone charged that end.
+type Surcharges struct {
+	Pickup        int64
+	PickupZoneID  uuid.UUID
+	Dropoff       int64
+	DropoffZoneID uuid.UUID
+}
+
+// Total returns the sum of both surcharges
+func (s Surcharges) Total() int64 {
+	return s.Pickup + s.Dropoff
+}
+
+// DispatchParams are the matching parameters for a pickup point
+type DispatchParams struct {
+	CityID         uuid.UUID
+	SearchRadiusKm float64
+	OfferTimeout   time.Duration
+	// ZoneID is the zone that overrode a global setting, if any
+	ZoneID *uuid.UUID
+}
+
+// indexedZone is an active zone with its parsed shape
+type indexedZone struct {
+	zone   models.Zone
+	shape  geo.Shape
+	bounds geo.BBox
+}
+
+func (z *indexedZone) contains(point geo.Point) bool {
+	return z.bounds.Contains(point) && z.shape.Contains(point)
+}
+
+// ZoneService answers which zones a point is in from an in-memory index of the
+// active zones. Every instance reloads the index when a zone changes, via Redis
+// pub/sub when Redis is configured.
+//
+// The service is also the request validators' service area: once any service_area
+// zone is active it replaces the polygon from config.
+type ZoneService struct {
+	repo     *repositories.ZoneRepository
+	cities   *cities.CityService
+	redis    *redis.Client
+	fallback validation.Area
+
+	mu    sync.RWMutex
+	zones []*indexedZone
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewZoneService creates a zone service. fallback is the service area used while no
+// service_area zone is active; nil allows anywhere. redisClient may be nil.
+func NewZoneService(repo *repositories.ZoneRepository, cityService *cities.CityService, redisClient *redis.Client, fallback validation.Area) *ZoneService {
+	return &ZoneService{
+		repo:     repo,
+		cities:   cityService,
+		redis:    redisClient,
+		fallback: fallback,
+	}
+}
+
+// Start loads the zones, installs the service area and listens for changes made on other instances
+func (s *ZoneService) Start(ctx context.Context) error {
+	if err := s.Reload(ctx); err != nil {
+		return err
+	}
+	validation.SetServiceArea(s)
+	if s.redis == nil {
+		return nil
+	}
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	pubsub := s.redis.Subscribe(subCtx, invalidateChannel)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case _, ok := <-messages:
+				if !ok {
+					return
+				}
+				if err := s.Reload(subCtx); err != nil {
+					logger.Error("Failed to reload zones", map[string]interface{}{
+						"error": err.Error(),
+					})
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops listening for changes
+func (s *ZoneService) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reload replaces the index with the stored active zones. A zone whose stored
+// geometry no longer parses is logged and left out.
+func (s *ZoneService) Reload(ctx context.Context) error {
+	stored, err := s.repo.Active(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load zones: %w", err)
+	}
+
+	zones := make([]*indexedZone, 0, len(stored))
+	for _, zone := range stored {
+		shape, err := geo.ParseGeometry(geo.Geometry(zone.Geometry))
+		if err != nil {
+			logger.Warn("Ignoring zone with invalid geometry", map[string]interface{}{
+				"zone_id": zone.ID.String(),
+				"name":    zone.Name,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		zones = append(zones, &indexedZone{zone: zone, shape: shape, bounds: shape.Bounds()})
+	}
+
+	s.mu.Lock()
+	s.zones = zones
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Locate returns the active zones containing point, highest priority first
+func (s *ZoneService) Locate(point geo.Point) []models.Zone {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var zones []models.Zone
+	for _, z := range s.zones {
+		if z.contains(point) {
+			zones = append(zones, z.zone)
+		}
+	}
+	return zones
+}
+
+// Contains reports whether point is in an active service_area zone, or in the
+// fallback area while there are none
+func (s *ZoneService) Contains(point geo.Point) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	defined := false
+	for _, z := range s.zones {
+		if z.zone.Kind != models.KindServiceArea {
+			continue
+		}
+		if z.contains(point) {
+			return true
+		}
+		defined = true
+	}
+	if defined {
+		return false
+	}
+	return s.fallback == nil || s.fallback.Contains(point)
+}
+
+// CheckRide decides whether a ride may be requested between two points. Pickups
+// must be in the service area; drop-offs may also be at an airport outside it.
+// Neither may be in a restricted zone. dropoff is nil while the destination is unknown.
+func (s *ZoneService) CheckRide(ctx context.Context, pickup geo.Point, dropoff *geo.Point) error {
+	err := s.checkRide(pickup, dropoff)
+	if errors.Is(err, ErrPickupOutsideServiceArea) || errors.Is(err, ErrDropoffOutsideServiceArea) {
+		// Demand from outside the service area guides where to launch next
+		fields := map[string]interface{}{
+			"pickup_lat": pickup.Lat,
+			"pickup_lng": pickup.Lng,
+			"reason":     err.Error(),
+		}
+		if dropoff != nil {
+			fields["dropoff_lat"] = dropoff.Lat
+			fields["dropoff_lng"] = dropoff.Lng
+		}
+		logger.FromContext(ctx).Info("Ride requested outside service area", fields)
+	}
+	return err
+}
+
+func (s *ZoneService) checkRide(pickup geo.Point, dropoff *geo.Point) error {
+	if s.hasKind(pickup, models.KindRestricted) || (dropoff != nil && s.hasKind(*dropoff, models.KindRestricted)) {
+		return ErrRestrictedArea
+	}
+	if !s.Contains(pickup) {
+		return ErrPickupOutsideServiceArea
+	}
+	if dropoff != nil && !s.Contains(*dropoff) && !s.hasKind(*dropoff, models.KindAirport) {
+		return ErrDropoffOutsideServiceArea
+	}
+	return nil
+}
+
+// Surcharges returns the zone charges for a ride. Each end is charged by the
+// highest-priority zone containing it that has a surcharge for that end.
+func (s *ZoneService) Surcharges(pickup, dropoff geo.Point) Surcharges {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var surcharges Surcharges
+	for _, z := range s.zones {
+		if surcharges.PickupZoneID == uuid.Nil && z.zone.PickupSurcharge > 0 && z.contains(pickup) {
+			surcharges.Pickup = z.zone.PickupSurcharge
+			surcharges.PickupZoneID = z.zone.ID
+		}
+		if surcharges.DropoffZoneID == uuid.Nil && z.zone.DropoffSurcharge > 0 && z.contains(dropoff) {
+			surcharges.Dropoff = z.zone.DropoffSurcharge
+			surcharges.DropoffZoneID = z.zone.ID
+		}
+	}
+	return surcharges
+}
+
+// CityAt returns the city a point belongs to: the city of the highest-priority
+// service_area zone containing it, else the default city
+func (s *ZoneService) CityAt(point geo.Point) uuid.UUID {
+	s.mu.RLock()
+	for _, z := range s.zones {
+		if z.zone.Kind == models.KindServiceArea && z.zone.CityID != nil && z.contains(point) {
+			cityID := *z.zone.CityID
+			s.mu.RUnlock()
+			return cityID
+		}
+	}
+	s.mu.RUnlock()
+	return s.cities.Default().ID
+}
+
+// Dispatch returns the matching parameters for a pickup at point: the overrides of
+// the highest-priority zone containing it that has any, else the settings of the
+// point's city
+func (s *ZoneService) Dispatch(point geo.Point) DispatchParams {
+	cityID := s.CityAt(point)
+	params := DispatchParams{
+		CityID:         cityID,
+		SearchRadiusKm: s.cities.Float(cityID, "search_radius_km"),
+		OfferTimeout:   s.cities.Duration(cityID, "offer_timeout"),
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, z := range s.zones {
+		if (z.zone.SearchRadiusKm == nil && z.zone.OfferTimeoutSeconds == nil) || !z.contains(point) {
+			continue
+		}
+		if z.zone.SearchRadiusKm != nil {
+			params.SearchRadiusKm = *z.zone.SearchRadiusKm
+		}
+		if z.zone.OfferTimeoutSeconds != nil {
+			params.OfferTimeout = time.Duration(*z.zone.OfferTimeoutSeconds) * time.Second
+		}
+		zoneID := z.zone.ID
+		params.ZoneID = &zoneID
+		break
+	}
+	return params
+}
+
+// Zones returns every active zone, highest priority first
+func (s *ZoneService) Zones() []models.Zone {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	zones := make([]models.Zone, 0, len(s.zones))
+	for _, z := range s.zones {
+		zones = append(zones, z.zone)
+	}
+	return zones
+}
+
+// ServiceArea returns the active service_area zones
+func (s *ZoneService) ServiceArea() []models.Zone {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var zones []models.Zone
+	for _, z := range s.zones {
+		if z.zone.Kind == models.KindServiceArea {
+			zones = append(zones, z.zone)
+		}
+	}
+	return zones
+}
+
+// List returns the zones of kind in scope, or of every kind when kind is empty, for the admin view
+func (s *ZoneService) List(ctx context.Context, scope cities.Scope, kind string) ([]models.Zone, error) {
+	stored, err := s.repo.List(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]models.Zone, 0, len(stored))
+	for _, zone := range stored {
+		if scope.Allows(zone.CityID) {
+			zones = append(zones, zone)
+		}
+	}
+	return zones, nil
+}
+
+// Get returns one zone
+func (s *ZoneService) Get(ctx context.Context, id uuid.UUID) (*models.Zone, error) {
+	zone, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		return nil, ErrZoneNotFound
+	}
+	return zone, nil
+}
+
+// Create adds a zone
+func (s *ZoneService) Create(ctx context.Context, adminID uuid.UUID, input ZoneInput) (*models.Zone, error) {
+	if err := s.check(input); err != nil {
+		return nil, err
+	}
+
+	var zone *models.Zone
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		existing, err := s.repo.LockByName(tx, input.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("%w: %s", ErrDuplicateName, input.Name)
+		}
+
+		zone = &models.Zone{ID: uuid.New()}
+		apply(zone, adminID, input)
+		return s.repo.Save(tx, zone)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, "Zone created", zone)
+	return zone, nil
+}
+
+// Update replaces a zone's geometry and rules
+func (s *ZoneService) Update(ctx context.Context, adminID, id uuid.UUID, input ZoneInput) (*models.Zone, error) {
+	if err := s.check(input); err != nil {
+		return nil, err
+	}
+
+	var zone *models.Zone
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		zone, err = s.repo.Lock(tx, id)
+		if err != nil {
+			return err
+		}
+		if zone == nil {
+			return ErrZoneNotFound
+		}
+
+		if input.Name != zone.Name {
+			existing, err := s.repo.LockByName(tx, input.Name)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return fmt.Errorf("%w: %s", ErrDuplicateName, input.Name)
+			}
+		}
+
+		apply(zone, adminID, input)
+		return s.repo.Save(tx, zone)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, "Zone updated", zone)
+	return zone, nil
+}
+
+// Import creates or updates zones by name in one transaction, e.g. from a GeoJSON
+// feature collection exported from a mapping tool
+func (s *ZoneService) Import(ctx context.Context, adminID uuid.UUID, inputs []ZoneInput) ([]models.Zone, error) {
+	seen := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		if seen[input.Name] {
+			return nil, fmt.Errorf("%w: %s appears twice", ErrDuplicateName, input.Name)
+		}
+		seen[input.Name] = true
+		if err := s.check(input); err != nil {
+			return nil, err
+		}
+	}
+
+	zones := make([]models.Zone, 0, len(inputs))
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		for _, input := range inputs {
+			zone, err := s.repo.LockByName(tx, input.Name)
+			if err != nil {
+				return err
+			}
+			if zone == nil {
+				zone = &models.Zone{ID: uuid.New()}
+			}
+
+			apply(zone, adminID, input)
+			if err := s.repo.Save(tx, zone); err != nil {
+				return err
+			}
+			zones = append(zones, *zone)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, "Zones imported", nil)
+	return zones, nil
+}
+
+// check reports whether the input's surge cap is valid and its city, if any, exists
+func (s *ZoneService) check(input ZoneInput) error {
+	if input.SurgeCap != nil && *input.SurgeCap < 1 {
+		return fmt.Errorf("%w: %s has %.2f", ErrInvalidSurgeCap, input.Name, *input.SurgeCap)
+	}
+	if input.CityID == nil {
+		return nil
+	}
+	if _, ok := s.cities.City(*input.CityID); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCity, input.CityID)
+	}
+	return nil
+}
+
+// apply copies an admin's input onto zone
+func apply(zone *models.Zone, adminID uuid.UUID, input ZoneInput) {
+	bounds := input.Shape.Bounds()
+
+	zone.Name = input.Name
+	zone.Kind = input.Kind
+	zone.CityID = input.CityID
+	zone.Geometry = models.Geometry(input.Shape.Geometry())
+	zone.MinLat, zone.MinLng = bounds.MinLat, bounds.MinLng
+	zone.MaxLat, zone.MaxLng = bounds.MaxLat, bounds.MaxLng
+	zone.PickupSurcharge = input.PickupSurcharge
+	zone.DropoffSurcharge = input.DropoffSurcharge
+	zone.SearchRadiusKm = input.SearchRadiusKm
+	zone.OfferTimeoutSeconds = input.OfferTimeoutSeconds
+	zone.SurgeCap = input.SurgeCap
+	zone.SurgeDisabled = input.SurgeDisabled
+	zone.Priority = input.Priority
+	zone.Active = input.Active
+	zone.UpdatedBy = &adminID
+}
+
+// hasKind reports whether point is in an active zone of kind
+func (s *ZoneService) hasKind(point geo.Point, kind string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, z := range s.zones {
+		if z.zone.Kind == kind && z.contains(point) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidate reloads this instance and tells the others to reload. Failures are
+// logged rather than returned, since the change itself is already committed.
+func (s *ZoneService) invalidate(ctx context.Context, message string, zone *models.Zone) {
+	log := logger.FromContext(ctx)
+
+	if err := s.Reload(ctx); err != nil {
+		log.Error("Failed to reload zones", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	if s.redis != nil {
+		if err := s.redis.Publish(ctx, invalidateChannel, "").Err(); err != nil {
+			log.Error("Failed to publish zones invalidation", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	fields := map[string]interface{}{}
+	if zone != nil {
+		fields["zone_id"] = zone.ID.String()
+		fields["name"] = zone.Name
+		fields["kind"] = zone.Kind
+	}
+	log.Info(message, fields)
+}
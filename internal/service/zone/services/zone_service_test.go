@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"theb-backend/internal/service/zone/models"
+	"theb-backend/pkg/geo"
+
+	"github.com/google/uuid"
+)
+
+// square returns a square shape from (lat, lng) to (lat+size, lng+size)
+func square(lat, lng, size float64) geo.Shape {
+	return geo.Shape{{geo.Polygon{
+		{Lat: lat, Lng: lng},
+		{Lat: lat, Lng: lng + size},
+		{Lat: lat + size, Lng: lng + size},
+		{Lat: lat + size, Lng: lng},
+	}}}
+}
+
+// newTestZoneService returns a zone service indexing zones in the given order,
+// which stands for highest priority first
+func newTestZoneService(fallback geo.Polygon, zones ...models.Zone) *ZoneService {
+	shapes := map[string]geo.Shape{
+		// The city, 0-10 on both axes, with a restricted base and a university inside
+		"city":       square(0, 0, 10),
+		"base":       square(8, 8, 1),
+		"university": square(2, 2, 2),
+		"campus":     square(2.5, 2.5, 1),
+		// The airport lies outside the city, with a terminal sharing its west edge
+		"airport":  square(0, 12, 2),
+		"terminal": square(0, 11, 1),
+	}
+
+	s := &ZoneService{}
+	if fallback != nil {
+		s.fallback = fallback
+	}
+	for _, zone := range zones {
+		shape := shapes[zone.Name]
+		s.zones = append(s.zones, &indexedZone{zone: zone, shape: shape, bounds: shape.Bounds()})
+	}
+	return s
+}
+
+func TestZoneServiceCheckRide(t *testing.T) {
+	zones := []models.Zone{
+		{ID: uuid.New(), Name: "base", Kind: models.KindRestricted},
+		{ID: uuid.New(), Name: "city", Kind: models.KindServiceArea},
+		{ID: uuid.New(), Name: "airport", Kind: models.KindAirport},
+	}
+	inCity := geo.Point{Lat: 5, Lng: 5}
+	inBase := geo.Point{Lat: 8.5, Lng: 8.5}
+	atAirport := geo.Point{Lat: 1, Lng: 13}
+	nowhere := geo.Point{Lat: 20, Lng: 20}
+	fallback := geo.Polygon{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}, {Lat: 1, Lng: 1}, {Lat: 1, Lng: 0}}
+
+	tests := []struct {
+		name    string
+		service *ZoneService
+		pickup  geo.Point
+		dropoff *geo.Point
+		wantErr error
+	}{
+		{name: "within the service area", service: newTestZoneService(nil, zones...), pickup: inCity, dropoff: &inCity},
+		{name: "unknown destination", service: newTestZoneService(nil, zones...), pickup: inCity},
+		{name: "drop-off at an airport outside the area", service: newTestZoneService(nil, zones...), pickup: inCity, dropoff: &atAirport},
+		{name: "pickup at an airport outside the area", service: newTestZoneService(nil, zones...), pickup: atAirport, dropoff: &inCity, wantErr: ErrPickupOutsideServiceArea},
+		{name: "pickup outside", service: newTestZoneService(nil, zones...), pickup: nowhere, wantErr: ErrPickupOutsideServiceArea},
+		{name: "drop-off outside", service: newTestZoneService(nil, zones...), pickup: inCity, dropoff: &nowhere, wantErr: ErrDropoffOutsideServiceArea},
+		{name: "pickup restricted inside the area", service: newTestZoneService(nil, zones...), pickup: inBase, dropoff: &inCity, wantErr: ErrRestrictedArea},
+		{name: "drop-off restricted", service: newTestZoneService(nil, zones...), pickup: inCity, dropoff: &inBase, wantErr: ErrRestrictedArea},
+		{name: "restricted wins over outside", service: newTestZoneService(nil, zones...), pickup: nowhere, dropoff: &inBase, wantErr: ErrRestrictedArea},
+		{name: "no zones allows anywhere", service: newTestZoneService(nil), pickup: nowhere, dropoff: &nowhere},
+		{name: "fallback area while no service area zone", service: newTestZoneService(fallback), pickup: geo.Point{Lat: 0.5, Lng: 0.5}},
+		{name: "outside the fallback area", service: newTestZoneService(fallback), pickup: inCity, wantErr: ErrPickupOutsideServiceArea},
+		{name: "service area zone replaces the fallback", service: newTestZoneService(fallback, zones...), pickup: inCity, dropoff: &inCity},
+		{name: "restricted zone without a service area", service: newTestZoneService(nil, zones[0]), pickup: inBase, wantErr: ErrRestrictedArea},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.service.CheckRide(context.Background(), tt.pickup, tt.dropoff)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckRide() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestZoneServiceSurcharges(t *testing.T) {
+	campus := models.Zone{ID: uuid.New(), Name: "campus", Kind: models.KindUniversity, PickupSurcharge: 100}
+	university := models.Zone{ID: uuid.New(), Name: "university", Kind: models.KindUniversity, PickupSurcharge: 250, DropoffSurcharge: 150}
+	terminal := models.Zone{ID: uuid.New(), Name: "terminal", Kind: models.KindAirport, PickupSurcharge: 500, DropoffSurcharge: 300}
+	airport := models.Zone{ID: uuid.New(), Name: "airport", Kind: models.KindAirport, PickupSurcharge: 1000, DropoffSurcharge: 500}
+	city := models.Zone{ID: uuid.New(), Name: "city", Kind: models.KindServiceArea}
+
+	s := newTestZoneService(nil, campus, university, terminal, airport, city)
+
+	inCity := geo.Point{Lat: 5, Lng: 5}
+	inCampus := geo.Point{Lat: 3, Lng: 3}
+	inUniversity := geo.Point{Lat: 2.2, Lng: 2.2}
+	atAirport := geo.Point{Lat: 1, Lng: 13}
+	onSharedEdge := geo.Point{Lat: 0.5, Lng: 12}
+
+	tests := []struct {
+		name    string
+		pickup  geo.Point
+		dropoff geo.Point
+		want    Surcharges
+	}{
+		{name: "no surcharge zones", pickup: inCity, dropoff: inCity},
+		{name: "pickup at the airport", pickup: atAirport, dropoff: inCity, want: Surcharges{Pickup: 1000, PickupZoneID: airport.ID}},
+		{name: "drop-off at the airport", pickup: inCity, dropoff: atAirport, want: Surcharges{Dropoff: 500, DropoffZoneID: airport.ID}},
+		{
+			name:    "both ends charged",
+			pickup:  atAirport,
+			dropoff: inUniversity,
+			want:    Surcharges{Pickup: 1000, PickupZoneID: airport.ID, Dropoff: 150, DropoffZoneID: university.ID},
+		},
+		{
+			name:    "higher priority zone charges its end",
+			pickup:  inCampus,
+			dropoff: inCity,
+			want:    Surcharges{Pickup: 100, PickupZoneID: campus.ID},
+		},
+		{
+			name:    "zone without a drop-off surcharge defers to the next",
+			pickup:  inCity,
+			dropoff: inCampus,
+			want:    Surcharges{Dropoff: 150, DropoffZoneID: university.ID},
+		},
+		{
+			name:    "shared edge is charged once by one zone",
+			pickup:  onSharedEdge,
+			dropoff: inCity,
+			want:    Surcharges{Pickup: 1000, PickupZoneID: airport.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.Surcharges(tt.pickup, tt.dropoff)
+			if got != tt.want {
+				t.Errorf("Surcharges() = %+v, want %+v", got, tt.want)
+			}
+			if got.Total() != tt.want.Pickup+tt.want.Dropoff {
+				t.Errorf("Total() = %d, want %d", got.Total(), tt.want.Pickup+tt.want.Dropoff)
+			}
+		})
+	}
+}
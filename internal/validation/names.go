@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Name length bounds in letters and separators, after trimming
+const (
+	minNameLength = 2
+	maxNameLength = 50
+)
+
+// validatePersonName accepts a name written entirely in Arabic or entirely in English letters
+func validatePersonName(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+	return validName(name, unicode.Arabic) || validName(name, unicode.Latin)
+}
+
+// validateArabicName accepts a name written in Arabic letters, with optional diacritics
+func validateArabicName(fl validator.FieldLevel) bool {
+	return validName(fl.Field().String(), unicode.Arabic)
+}
+
+// validateEnglishName accepts a name written in Latin letters, including accented ones
+func validateEnglishName(fl validator.FieldLevel) bool {
+	return validName(fl.Field().String(), unicode.Latin)
+}
+
+// validName reports whether name is letters of script separated by single spaces,
+// hyphens or apostrophes, starting and ending with a letter. Marks such as Arabic
+// harakat may follow a letter or another mark.
+func validName(name string, script *unicode.RangeTable) bool {
+	name = strings.TrimSpace(name)
+	if n := utf8.RuneCountInString(name); n < minNameLength || n > maxNameLength {
+		return false
+	}
+
+	prev := ' '
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) && unicode.Is(script, r):
+		case unicode.Is(unicode.Mn, r):
+			// Harakat belong to the Inherited script, so any mark may follow a letter
+			if !unicode.IsLetter(prev) && !unicode.Is(unicode.Mn, prev) {
+				return false
+			}
+		case isNameSeparator(r):
+			if isNameSeparator(prev) {
+				return false
+			}
+		default:
+			return false
+		}
+		prev = r
+	}
+	return !isNameSeparator(prev)
+}
+
+func isNameSeparator(r rune) bool {
+	return r == ' ' || r == '-' || r == '\'' || r == '’'
+}
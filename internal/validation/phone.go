@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrInvalidPhone is returned for numbers that are not accepted mobile numbers
+var ErrInvalidPhone = errors.New("invalid mobile phone number")
+
+// PhoneRules describes the accepted mobile numbers of one country
+type PhoneRules struct {
+	// CountryCode is the calling code without +, e.g. 962
+	CountryCode string
+	// MobilePrefixes are the accepted starts of the national number, e.g. 79
+	MobilePrefixes []string
+	// NationalLength is the number of digits after the country code
+	NationalLength int
+}
+
+// SetPhoneRules replaces the rules used by NormalisePhone and the phone rule
+func SetPhoneRules(rules PhoneRules) {
+	mu.Lock()
+	defer mu.Unlock()
+	phoneRules = rules
+}
+
+// NormalisePhone returns number in E.164 form under the registered rules, e.g.
+// +962791234567 for "079 123 4567", "00962791234567" or "٠٧٩١٢٣٤٥٦٧"
+func NormalisePhone(number string) (string, error) {
+	mu.RLock()
+	rules := phoneRules
+	mu.RUnlock()
+	return rules.Normalise(number)
+}
+
+// Normalise returns number in E.164 form. It accepts the international form with
+// + or 00, the country code without either, the national form with a trunk 0 and
+// the bare national number, ignoring spaces, dashes, dots and brackets.
+func (r PhoneRules) Normalise(number string) (string, error) {
+	digits, plus, ok := phoneDigits(number)
+	if !ok || r.CountryCode == "" {
+		return "", ErrInvalidPhone
+	}
+
+	var national string
+	switch {
+	case plus:
+		national, ok = strings.CutPrefix(digits, r.CountryCode)
+	case strings.HasPrefix(digits, "00"+r.CountryCode):
+		national, ok = strings.CutPrefix(digits, "00"+r.CountryCode)
+	case len(digits) == len(r.CountryCode)+r.NationalLength && strings.HasPrefix(digits, r.CountryCode):
+		national = digits[len(r.CountryCode):]
+	case len(digits) == r.NationalLength+1 && digits[0] == '0':
+		national = digits[1:]
+	default:
+		national = digits
+	}
+	if !ok || len(national) != r.NationalLength || !r.mobile(national) {
+		return "", ErrInvalidPhone
+	}
+
+	return "+" + r.CountryCode + national, nil
+}
+
+func (r PhoneRules) mobile(national string) bool {
+	for _, prefix := range r.MobilePrefixes {
+		if strings.HasPrefix(national, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// phoneDigits strips formatting from number and maps Arabic-Indic digits to ASCII.
+// plus reports a leading +; ok is false for any other character.
+func phoneDigits(number string) (digits string, plus, ok bool) {
+	number = strings.TrimSpace(number)
+	if rest, found := strings.CutPrefix(number, "+"); found {
+		number, plus = rest, true
+	}
+
+	var b strings.Builder
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= '٠' && r <= '٩':
+			b.WriteRune('0' + (r - '٠'))
+		case r >= '۰' && r <= '۹':
+			b.WriteRune('0' + (r - '۰'))
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false, false
+		}
+	}
+	return b.String(), plus, b.Len() > 0
+}
+
+// Phone is a phone number that normalises itself to E.164 when decoded from JSON.
+// A number that does not normalise is kept as sent, so the phone rule reports it
+// as a field error instead of the whole body failing to decode.
+type Phone string
+
+// UnmarshalJSON decodes and normalises the number
+func (p *Phone) UnmarshalJSON(data []byte) error {
+	var number string
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	if normalised, err := NormalisePhone(number); err == nil {
+		number = normalised
+	}
+	*p = Phone(number)
+	return nil
+}
+
+// String returns the number
+func (p Phone) String() string {
+	return string(p)
+}
+
+// validatePhone accepts strings, including Phone, that normalise under the registered rules
+func validatePhone(fl validator.FieldLevel) bool {
+	_, err := NormalisePhone(fl.Field().String())
+	return err == nil
+}
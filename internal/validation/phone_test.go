@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPhoneRulesNormalise(t *testing.T) {
+	jordan := PhoneRules{
+		CountryCode:    "962",
+		MobilePrefixes: []string{"77", "78", "79"},
+		NationalLength: 9,
+	}
+
+	tests := []struct {
+		name    string
+		rules   PhoneRules
+		number  string
+		want    string
+		wantErr bool
+	}{
+		{name: "E.164", rules: jordan, number: "+962791234567", want: "+962791234567"},
+		{name: "00 international prefix", rules: jordan, number: "00962791234567", want: "+962791234567"},
+		{name: "country code without prefix", rules: jordan, number: "962781234567", want: "+962781234567"},
+		{name: "national with trunk 0", rules: jordan, number: "0791234567", want: "+962791234567"},
+		{name: "bare national", rules: jordan, number: "771234567", want: "+962771234567"},
+		{name: "spaces and dashes", rules: jordan, number: " 079 123-4567 ", want: "+962791234567"},
+		{name: "dots and brackets", rules: jordan, number: "+962 (79) 123.4567", want: "+962791234567"},
+		{name: "Arabic-Indic digits", rules: jordan, number: "٠٧٩١٢٣٤٥٦٧", want: "+962791234567"},
+		{name: "Arabic-Indic digits with 00", rules: jordan, number: "٠٠٩٦٢٧٩١٢٣٤٥٦٧", want: "+962791234567"},
+		{name: "Eastern Arabic-Indic digits", rules: jordan, number: "۰۷۸۱۲۳۴۵۶۷", want: "+962781234567"},
+		{name: "mixed digit forms", rules: jordan, number: "+٩٦٢ 79 123 ٤٥٦٧", want: "+962791234567"},
+
+		{name: "landline prefix", rules: jordan, number: "0621234567", wantErr: true},
+		{name: "other country", rules: jordan, number: "+971501234567", wantErr: true},
+		{name: "00 other country", rules: jordan, number: "00971501234567", wantErr: true},
+		{name: "too short", rules: jordan, number: "079123456", wantErr: true},
+		{name: "too long", rules: jordan, number: "07912345678", wantErr: true},
+		{name: "trunk 0 after country code", rules: jordan, number: "+9620791234567", wantErr: true},
+		{name: "letters", rules: jordan, number: "0791234abc", wantErr: true},
+		{name: "plus in the middle", rules: jordan, number: "079+1234567", wantErr: true},
+		{name: "empty", rules: jordan, number: "", wantErr: true},
+		{name: "only formatting", rules: jordan, number: " - ", wantErr: true},
+		{name: "no rules", rules: PhoneRules{}, number: "+962791234567", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rules.Normalise(tt.number)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPhone) {
+					t.Fatalf("Normalise(%q) = %q, %v; want ErrInvalidPhone", tt.number, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalise(%q) returned %v", tt.number, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalise(%q) = %q, want %q", tt.number, got, tt.want)
+			}
+		})
+	}
+}
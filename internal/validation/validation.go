@@ -0,0 +1,174 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"theb-backend/internal/apierror"
+	"theb-backend/internal/config"
+	"theb-backend/pkg/geo"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Rules added to gin's binding tags, e.g. `binding:"required,phone"`
+const (
+	TagPhone       = "phone"
+	TagLatitude    = "lat"
+	TagLongitude   = "lng"
+	TagServiceArea = "service_area"
+	TagPersonName  = "person_name"
+	TagArabicName  = "name_ar"
+	TagEnglishName = "name_en"
+	TagSlug        = "slug"
+)
+
+// slugPattern matches lowercase codes such as city codes, e.g. "irbid" or "zarqa-new"
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Area decides whether a point is served
+type Area interface {
+	Contains(point geo.Point) bool
+}
+
+var (
+	mu          sync.RWMutex
+	phoneRules  PhoneRules
+	serviceArea Area
+)
+
+// Register installs the rules on gin's validator and reports field errors by their
+// JSON names. The service area starts as the configured polygon; an empty one
+// allows anywhere.
+func Register(cfg config.ValidationConfig) error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("gin binding validator is not go-playground/validator")
+	}
+
+	engine.RegisterTagNameFunc(fieldName)
+	engine.RegisterStructValidation(validatePoint, geo.Point{})
+
+	rules := map[string]validator.Func{
+		TagPhone:       validatePhone,
+		TagLatitude:    validateLatitude,
+		TagLongitude:   validateLongitude,
+		TagServiceArea: validateServiceArea,
+		TagPersonName:  validatePersonName,
+		TagArabicName:  validateArabicName,
+		TagEnglishName: validateEnglishName,
+		TagSlug:        validateSlug,
+	}
+	for tag, fn := range rules {
+		if err := engine.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+
+	apierror.RegisterRule(TagPhone, "Must be a valid mobile number", "يجب أن يكون رقم هاتف محمول صالحًا")
+	apierror.RegisterRule(TagLatitude, "Latitude must be between -90 and 90", "يجب أن يكون خط العرض بين -90 و 90")
+	apierror.RegisterRule(TagLongitude, "Longitude must be between -180 and 180", "يجب أن يكون خط الطول بين -180 و 180")
+	apierror.RegisterRule(TagServiceArea, "Outside the service area", "خارج منطقة الخدمة")
+	apierror.RegisterRule(TagPersonName,
+		"Must be 2 to 50 Arabic or English letters",
+		"يجب أن يتكون من 2 إلى 50 حرفًا عربيًا أو إنجليزيًا")
+	apierror.RegisterRule(TagArabicName, "Must be written in Arabic letters", "يجب أن يُكتب بأحرف عربية")
+	apierror.RegisterRule(TagEnglishName, "Must be written in English letters", "يجب أن يُكتب بأحرف إنجليزية")
+	apierror.RegisterRule(TagSlug,
+		"Must be lowercase letters and digits separated by dashes",
+		"يجب أن يتكون من أحرف إنجليزية صغيرة وأرقام تفصل بينها شرطات")
+	// Built-in rules used by request bodies
+	apierror.RegisterRule("timezone", "Must be a time zone such as Asia/Amman", "يجب أن تكون منطقة زمنية مثل Asia/Amman")
+	apierror.RegisterRule("iso4217", "Must be a currency code such as JOD", "يجب أن يكون رمز عملة مثل JOD")
+
+	SetPhoneRules(PhoneRules{
+		CountryCode:    cfg.Phone.CountryCode,
+		MobilePrefixes: cfg.Phone.MobilePrefixes,
+		NationalLength: cfg.Phone.NationalLength,
+	})
+	if len(cfg.ServiceArea) > 0 {
+		SetServiceArea(geo.Polygon(cfg.ServiceArea))
+	} else {
+		SetServiceArea(nil)
+	}
+	return nil
+}
+
+// SetServiceArea replaces the area checked by the service_area rule; nil allows anywhere
+func SetServiceArea(area Area) {
+	mu.Lock()
+	defer mu.Unlock()
+	serviceArea = area
+}
+
+// InServiceArea reports whether point is served
+func InServiceArea(point geo.Point) bool {
+	mu.RLock()
+	area := serviceArea
+	mu.RUnlock()
+	return area == nil || area.Contains(point)
+}
+
+// fieldName names a field by its json tag, or its form tag for query parameters
+func fieldName(sf reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.Split(sf.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+// validatePoint checks the coordinate ranges of every geo.Point in a request
+func validatePoint(sl validator.StructLevel) {
+	point := sl.Current().Interface().(geo.Point)
+	if point.Lat < -90 || point.Lat > 90 {
+		sl.ReportError(point.Lat, "lat", "Lat", TagLatitude, "")
+	}
+	if point.Lng < -180 || point.Lng > 180 {
+		sl.ReportError(point.Lng, "lng", "Lng", TagLongitude, "")
+	}
+}
+
+func validateLatitude(fl validator.FieldLevel) bool {
+	lat, ok := floatValue(fl.Field())
+	return ok && lat >= -90 && lat <= 90
+}
+
+func validateLongitude(fl validator.FieldLevel) bool {
+	lng, ok := floatValue(fl.Field())
+	return ok && lng >= -180 && lng <= 180
+}
+
+// validateServiceArea checks a geo.Point field. Out-of-range points pass here,
+// since validatePoint already reports them.
+func validateServiceArea(fl validator.FieldLevel) bool {
+	point, ok := fl.Field().Interface().(geo.Point)
+	if !ok {
+		return false
+	}
+	return !point.Valid() || InServiceArea(point)
+}
+
+func validateSlug(fl validator.FieldLevel) bool {
+	return slugPattern.MatchString(fl.Field().String())
+}
+
+func floatValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,49 @@
+package geo
+
+import "math"
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// Point is a WGS84 coordinate in degrees
+type Point struct {
+	Lat float64 `json:"lat" yaml:"lat"`
+	Lng float64 `json:"lng" yaml:"lng"`
+}
+
+// Valid reports whether the point's latitude and longitude are in range
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+// DistanceKm returns the great-circle distance between two points in kilometres
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Polygon is a ring of points. The last point may repeat the first or not;
+// the ring is closed either way.
+type Polygon []Point
+
+// Contains reports whether pt is inside the polygon, using ray casting on
+// lat/lng as planar coordinates, which is accurate at city scale
+func (p Polygon) Contains(pt Point) bool {
+	if len(p) < 3 {
+		return false
+	}
+
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Lat > pt.Lat) != (b.Lat > pt.Lat) &&
+			pt.Lng < (b.Lng-a.Lng)*(pt.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
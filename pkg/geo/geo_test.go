@@ -0,0 +1,80 @@
+package geo
+
+import "testing"
+
+// square returns the ring of a square from (lat, lng) to (lat+size, lng+size)
+func square(lat, lng, size float64) Polygon {
+	return Polygon{
+		{Lat: lat, Lng: lng},
+		{Lat: lat, Lng: lng + size},
+		{Lat: lat + size, Lng: lng + size},
+		{Lat: lat + size, Lng: lng},
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	unit := square(0, 0, 1)
+	closed := append(square(0, 0, 1), Point{Lat: 0, Lng: 0})
+	// An L shape missing its north-east quarter
+	concave := Polygon{
+		{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}, {Lat: 1, Lng: 2},
+		{Lat: 1, Lng: 1}, {Lat: 2, Lng: 1}, {Lat: 2, Lng: 0},
+	}
+
+	tests := []struct {
+		name    string
+		polygon Polygon
+		point   Point
+		want    bool
+	}{
+		{name: "centre", polygon: unit, point: Point{Lat: 0.5, Lng: 0.5}, want: true},
+		{name: "north of", polygon: unit, point: Point{Lat: 1.5, Lng: 0.5}},
+		{name: "east of", polygon: unit, point: Point{Lat: 0.5, Lng: 1.5}},
+		{name: "level with an edge but outside", polygon: unit, point: Point{Lat: 0.5, Lng: -0.5}},
+		// Edges are half-open, so a point on an edge shared by two zones is in exactly one
+		{name: "south edge is inside", polygon: unit, point: Point{Lat: 0, Lng: 0.5}, want: true},
+		{name: "west edge is inside", polygon: unit, point: Point{Lat: 0.5, Lng: 0}, want: true},
+		{name: "north edge is outside", polygon: unit, point: Point{Lat: 1, Lng: 0.5}},
+		{name: "east edge is outside", polygon: unit, point: Point{Lat: 0.5, Lng: 1}},
+		{name: "closing point repeated", polygon: closed, point: Point{Lat: 0.5, Lng: 0.5}, want: true},
+		{name: "concave arm", polygon: concave, point: Point{Lat: 0.5, Lng: 1.5}, want: true},
+		{name: "concave notch", polygon: concave, point: Point{Lat: 1.5, Lng: 1.5}},
+		{name: "fewer than three points", polygon: Polygon{{Lat: 0, Lng: 0}, {Lat: 1, Lng: 1}}, point: Point{Lat: 0.5, Lng: 0.5}},
+		{name: "empty", polygon: nil, point: Point{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.polygon.Contains(tt.point); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygonSharedEdge(t *testing.T) {
+	west, east := square(0, 0, 1), square(0, 1, 1)
+
+	for _, lat := range []float64{0, 0.25, 0.5, 0.99} {
+		point := Point{Lat: lat, Lng: 1}
+		if west.Contains(point) == east.Contains(point) {
+			t.Errorf("point %v on the shared edge is in both or neither square", point)
+		}
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	amman := Point{Lat: 31.9539, Lng: 35.9106}
+	irbid := Point{Lat: 32.5556, Lng: 35.85}
+
+	if got := DistanceKm(amman, amman); got != 0 {
+		t.Errorf("distance to itself = %v, want 0", got)
+	}
+	// Amman to Irbid is about 67 km in a straight line
+	if got := DistanceKm(amman, irbid); got < 66 || got > 68 {
+		t.Errorf("Amman to Irbid = %.1f km, want about 67", got)
+	}
+	if DistanceKm(amman, irbid) != DistanceKm(irbid, amman) {
+		t.Error("distance is not symmetric")
+	}
+}
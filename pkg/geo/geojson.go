@@ -0,0 +1,192 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// GeoJSON geometry types accepted by ParseGeometry
+const (
+	TypePolygon      = "Polygon"
+	TypeMultiPolygon = "MultiPolygon"
+)
+
+// ErrInvalidGeometry is returned for GeoJSON that is not a valid Polygon or MultiPolygon
+var ErrInvalidGeometry = errors.New("invalid geometry")
+
+// Geometry is a GeoJSON geometry object. Positions are [lng, lat], as GeoJSON orders them.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature
+type Feature struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Geometry   Geometry               `json:"geometry"`
+}
+
+// FeatureCollection is a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Shape is an area made of polygons, each an outer ring followed by optional
+// holes: a GeoJSON MultiPolygon, with a Polygon being a shape of one
+type Shape [][]Polygon
+
+// Contains reports whether pt is inside one of the shape's polygons and outside its holes
+func (s Shape) Contains(pt Point) bool {
+	for _, rings := range s {
+		if len(rings) == 0 || !rings[0].Contains(pt) {
+			continue
+		}
+		inHole := false
+		for _, hole := range rings[1:] {
+			if hole.Contains(pt) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// Bounds returns the smallest box holding the shape
+func (s Shape) Bounds() BBox {
+	box := BBox{MinLat: 90, MinLng: 180, MaxLat: -90, MaxLng: -180}
+	for _, rings := range s {
+		for _, ring := range rings {
+			for _, p := range ring {
+				box.MinLat = min(box.MinLat, p.Lat)
+				box.MinLng = min(box.MinLng, p.Lng)
+				box.MaxLat = max(box.MaxLat, p.Lat)
+				box.MaxLng = max(box.MaxLng, p.Lng)
+			}
+		}
+	}
+	return box
+}
+
+// Geometry returns the shape as a GeoJSON Polygon, or a MultiPolygon when it has several
+func (s Shape) Geometry() Geometry {
+	toPositions := func(rings []Polygon) [][][2]float64 {
+		out := make([][][2]float64, len(rings))
+		for i, ring := range rings {
+			out[i] = make([][2]float64, 0, len(ring)+1)
+			for _, p := range ring {
+				out[i] = append(out[i], [2]float64{p.Lng, p.Lat})
+			}
+			// GeoJSON rings repeat their first position at the end
+			if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+				out[i] = append(out[i], [2]float64{ring[0].Lng, ring[0].Lat})
+			}
+		}
+		return out
+	}
+
+	if len(s) == 1 {
+		coordinates, _ := json.Marshal(toPositions(s[0]))
+		return Geometry{Type: TypePolygon, Coordinates: coordinates}
+	}
+	polygons := make([][][][2]float64, len(s))
+	for i, rings := range s {
+		polygons[i] = toPositions(rings)
+	}
+	coordinates, _ := json.Marshal(polygons)
+	return Geometry{Type: TypeMultiPolygon, Coordinates: coordinates}
+}
+
+// ParseGeometry converts a GeoJSON Polygon or MultiPolygon into a shape. Each
+// ring needs at least three distinct positions within coordinate range.
+func ParseGeometry(g Geometry) (Shape, error) {
+	switch g.Type {
+	case TypePolygon:
+		var coordinates [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &coordinates); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+		}
+		rings, err := parseRings(coordinates)
+		if err != nil {
+			return nil, err
+		}
+		return Shape{rings}, nil
+	case TypeMultiPolygon:
+		var coordinates [][][][]float64
+		if err := json.Unmarshal(g.Coordinates, &coordinates); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+		}
+		if len(coordinates) == 0 {
+			return nil, fmt.Errorf("%w: multipolygon has no polygons", ErrInvalidGeometry)
+		}
+		shape := make(Shape, 0, len(coordinates))
+		for _, polygon := range coordinates {
+			rings, err := parseRings(polygon)
+			if err != nil {
+				return nil, err
+			}
+			shape = append(shape, rings)
+		}
+		return shape, nil
+	default:
+		return nil, fmt.Errorf("%w: type must be %s or %s, got %q", ErrInvalidGeometry, TypePolygon, TypeMultiPolygon, g.Type)
+	}
+}
+
+func parseRings(coordinates [][][]float64) ([]Polygon, error) {
+	if len(coordinates) == 0 {
+		return nil, fmt.Errorf("%w: polygon has no rings", ErrInvalidGeometry)
+	}
+
+	rings := make([]Polygon, 0, len(coordinates))
+	for _, positions := range coordinates {
+		ring := make(Polygon, 0, len(positions))
+		for _, position := range positions {
+			if len(position) < 2 {
+				return nil, fmt.Errorf("%w: position needs longitude and latitude", ErrInvalidGeometry)
+			}
+			p := Point{Lat: position[1], Lng: position[0]}
+			if !p.Valid() {
+				return nil, fmt.Errorf("%w: position [%v, %v] is out of range", ErrInvalidGeometry, position[0], position[1])
+			}
+			ring = append(ring, p)
+		}
+		// Drop the closing position so Contains sees each vertex once
+		if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+			ring = ring[:len(ring)-1]
+		}
+		if distinct(ring) < 3 {
+			return nil, fmt.Errorf("%w: ring needs at least 3 distinct positions", ErrInvalidGeometry)
+		}
+		rings = append(rings, ring)
+	}
+	return rings, nil
+}
+
+// distinct counts the different points of ring
+func distinct(ring Polygon) int {
+	seen := make(map[Point]bool, len(ring))
+	for _, p := range ring {
+		seen[p] = true
+	}
+	return len(seen)
+}
+
+// BBox is a latitude/longitude bounding box
+type BBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+// Contains reports whether pt is inside the box, edges included
+func (b BBox) Contains(pt Point) bool {
+	return pt.Lat >= b.MinLat && pt.Lat <= b.MaxLat && pt.Lng >= b.MinLng && pt.Lng <= b.MaxLng
+}
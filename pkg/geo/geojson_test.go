@@ -0,0 +1,121 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestShapeContains(t *testing.T) {
+	// A 10° square with a 2° hole in the middle, and an island inside the hole
+	withHole := Shape{{square(0, 0, 10), square(4, 4, 2)}}
+	withIsland := Shape{{square(0, 0, 10), square(4, 4, 2)}, {square(4.5, 4.5, 1)}}
+	apart := Shape{{square(0, 0, 1)}, {square(5, 5, 1)}}
+
+	tests := []struct {
+		name  string
+		shape Shape
+		point Point
+		want  bool
+	}{
+		{name: "outside the hole", shape: withHole, point: Point{Lat: 2, Lng: 2}, want: true},
+		{name: "in the hole", shape: withHole, point: Point{Lat: 5, Lng: 5}},
+		{name: "outside the outer ring", shape: withHole, point: Point{Lat: 11, Lng: 5}},
+		{name: "on the hole's west edge is in the hole", shape: withHole, point: Point{Lat: 5, Lng: 4}},
+		{name: "on the hole's east edge is in the shape", shape: withHole, point: Point{Lat: 5, Lng: 6}, want: true},
+		{name: "island in the hole", shape: withIsland, point: Point{Lat: 5, Lng: 5}, want: true},
+		{name: "hole around the island", shape: withIsland, point: Point{Lat: 4.2, Lng: 4.2}},
+		{name: "first polygon", shape: apart, point: Point{Lat: 0.5, Lng: 0.5}, want: true},
+		{name: "second polygon", shape: apart, point: Point{Lat: 5.5, Lng: 5.5}, want: true},
+		{name: "between polygons", shape: apart, point: Point{Lat: 3, Lng: 3}},
+		{name: "empty polygon is skipped", shape: Shape{{}, {square(0, 0, 1)}}, point: Point{Lat: 0.5, Lng: 0.5}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.shape.Contains(tt.point); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGeometry(t *testing.T) {
+	tests := []struct {
+		name      string
+		geometry  Geometry
+		wantErr   bool
+		wantRings []int
+		inside    Point
+		outside   Point
+	}{
+		{
+			name:      "polygon with hole",
+			geometry:  Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]]`)},
+			wantRings: []int{2},
+			inside:    Point{Lat: 2, Lng: 2},
+			outside:   Point{Lat: 5, Lng: 5},
+		},
+		{
+			name:      "multipolygon",
+			geometry:  Geometry{Type: TypeMultiPolygon, Coordinates: json.RawMessage(`[[[[0,0],[1,0],[1,1],[0,1]]],[[[5,5],[6,5],[6,6],[5,6],[5,5]]]]`)},
+			wantRings: []int{1, 1},
+			inside:    Point{Lat: 5.5, Lng: 5.5},
+			outside:   Point{Lat: 3, Lng: 3},
+		},
+		{
+			name:      "positions are longitude first",
+			geometry:  Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[35,31],[36,31],[36,32],[35,32]]]`)},
+			wantRings: []int{1},
+			inside:    Point{Lat: 31.5, Lng: 35.5},
+			outside:   Point{Lat: 35.5, Lng: 31.5},
+		},
+		{name: "point type", geometry: Geometry{Type: "Point", Coordinates: json.RawMessage(`[0,0]`)}, wantErr: true},
+		{name: "malformed coordinates", geometry: Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[0,0]]`)}, wantErr: true},
+		{name: "no rings", geometry: Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[]`)}, wantErr: true},
+		{name: "no polygons", geometry: Geometry{Type: TypeMultiPolygon, Coordinates: json.RawMessage(`[]`)}, wantErr: true},
+		{name: "two distinct positions", geometry: Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[0,0],[1,1],[0,0],[1,1]]]`)}, wantErr: true},
+		{name: "latitude out of range", geometry: Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[0,0],[1,91],[1,1]]]`)}, wantErr: true},
+		{name: "position without latitude", geometry: Geometry{Type: TypePolygon, Coordinates: json.RawMessage(`[[[0,0],[1],[1,1]]]`)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shape, err := ParseGeometry(tt.geometry)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidGeometry) {
+					t.Fatalf("ParseGeometry() error = %v, want ErrInvalidGeometry", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseGeometry() returned %v", err)
+			}
+
+			if len(shape) != len(tt.wantRings) {
+				t.Fatalf("got %d polygons, want %d", len(shape), len(tt.wantRings))
+			}
+			for i, rings := range shape {
+				if len(rings) != tt.wantRings[i] {
+					t.Errorf("polygon %d has %d rings, want %d", i, len(rings), tt.wantRings[i])
+				}
+				for _, ring := range rings {
+					if ring[0] == ring[len(ring)-1] {
+						t.Errorf("polygon %d keeps its closing position", i)
+					}
+				}
+			}
+			if !shape.Contains(tt.inside) {
+				t.Errorf("shape does not contain %v", tt.inside)
+			}
+			if shape.Contains(tt.outside) {
+				t.Errorf("shape contains %v", tt.outside)
+			}
+
+			reparsed, err := ParseGeometry(shape.Geometry())
+			if err != nil || !reparsed.Contains(tt.inside) || reparsed.Contains(tt.outside) {
+				t.Errorf("shape does not round-trip through Geometry: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at the last row of a page ordered by (created_at, id) descending
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Encode returns the opaque string handed to clients
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by Encode
+func DecodeCursor(value string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
+
+// CursorParams holds cursor-based pagination parameters; After is nil for the first page
+type CursorParams struct {
+	After *Cursor
+	Limit int
+}
+
+// CursorFromQuery reads ?cursor=&limit= from the request, applying defaults and limits
+func CursorFromQuery(c *gin.Context) (CursorParams, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultPerPage)))
+	if err != nil || limit < 1 {
+		limit = DefaultPerPage
+	}
+	if limit > MaxPerPage {
+		limit = MaxPerPage
+	}
+
+	params := CursorParams{Limit: limit}
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := DecodeCursor(value)
+		if err != nil {
+			return params, err
+		}
+		params.After = &cursor
+	}
+
+	return params, nil
+}
@@ -0,0 +1,43 @@
+package pagination
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultPerPage is used when per_page is missing or invalid
+	DefaultPerPage = 20
+	// MaxPerPage caps per_page to protect the database
+	MaxPerPage = 100
+)
+
+// Params holds offset-based pagination parameters
+type Params struct {
+	Page    int
+	PerPage int
+}
+
+// FromQuery reads ?page=&per_page= from the request, applying defaults and limits
+func FromQuery(c *gin.Context) Params {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(DefaultPerPage)))
+	if err != nil || perPage < 1 {
+		perPage = DefaultPerPage
+	}
+	if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+
+	return Params{Page: page, PerPage: perPage}
+}
+
+// Offset returns the number of rows to skip
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}